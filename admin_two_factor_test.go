@@ -0,0 +1,235 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sandromai/go-http-server/totp"
+)
+
+func (server *testServer) totpCode(
+	secret string,
+) string {
+	server.t.Helper()
+
+	code, err := totp.Code(secret, server.clock.Now())
+
+	if err != nil {
+		server.t.Fatal(err)
+	}
+
+	return code
+}
+
+func (server *testServer) wrongTOTPCode(
+	secret string,
+) string {
+	server.t.Helper()
+
+	for candidate := 0; candidate < 1000000; candidate++ {
+		code := fmt.Sprintf("%06d", candidate)
+
+		if _, valid := totp.Validate(secret, code, server.clock.Now(), 1); !valid {
+			return code
+		}
+	}
+
+	server.t.Fatal("expected an invalid authentication code")
+
+	return ""
+}
+
+func (server *testServer) requireMFA(
+	username,
+	password string,
+) string {
+	server.t.Helper()
+
+	response := server.request("POST", "/routes/admins/login", map[string]any{
+		"username": username,
+		"password": password,
+	}, nil)
+
+	response.expect(server.t, 200, "")
+
+	var result struct {
+		MFARequired bool   `json:"mfaRequired"`
+		MFAToken    string `json:"mfaToken"`
+		Token       string `json:"token"`
+	}
+
+	response.decode(server.t, &result)
+
+	if !result.MFARequired || result.MFAToken == "" || result.Token != "" {
+		server.t.Fatalf("expected an mfa token instead of a session in %s", response.Body)
+	}
+
+	return result.MFAToken
+}
+
+func (server *testServer) enrollTwoFactor(
+	adminToken string,
+) (string, []string) {
+	server.t.Helper()
+
+	response := server.request("POST", "/routes/admins/twoFactor/enroll", nil, bearer(adminToken))
+
+	response.expect(server.t, 200, "")
+
+	var enrollment struct {
+		Secret string `json:"secret"`
+		URI    string `json:"uri"`
+		QRCode string `json:"qrCode"`
+	}
+
+	response.decode(server.t, &enrollment)
+
+	if enrollment.Secret == "" || !strings.HasPrefix(enrollment.URI, "otpauth://totp/") || !strings.HasPrefix(enrollment.QRCode, "data:image/png;base64,") {
+		server.t.Fatalf("unexpected enrollment %s", response.Body)
+	}
+
+	server.request("POST", "/routes/admins/twoFactor/confirm", map[string]any{
+		"code": server.wrongTOTPCode(enrollment.Secret),
+	}, bearer(adminToken)).expect(server.t, 400, "Invalid authentication code.")
+
+	response = server.request("POST", "/routes/admins/twoFactor/confirm", map[string]any{
+		"code": server.totpCode(enrollment.Secret),
+	}, bearer(adminToken))
+
+	response.expect(server.t, 200, "")
+
+	var confirmation struct {
+		RecoveryCodes []string `json:"recoveryCodes"`
+	}
+
+	response.decode(server.t, &confirmation)
+
+	if len(confirmation.RecoveryCodes) != 10 {
+		server.t.Fatalf("expected ten recovery codes, got %s", response.Body)
+	}
+
+	return enrollment.Secret, confirmation.RecoveryCodes
+}
+
+func TestAdminTwoFactorLogin(t *testing.T) {
+	forEachBackend(t, testAdminTwoFactorLogin)
+}
+
+func testAdminTwoFactorLogin(
+	t *testing.T,
+	server *testServer,
+) {
+	adminToken := server.loginAdmin(testAdminUsername, testAdminPassword)
+
+	secret, recoveryCodes := server.enrollTwoFactor(adminToken)
+
+	server.request("POST", "/routes/admins/twoFactor/enroll", nil, bearer(adminToken)).expect(t, 400, "Two-factor authentication is already enabled.")
+
+	confirmedCode := server.totpCode(secret)
+
+	mfaToken := server.requireMFA(testAdminUsername, testAdminPassword)
+
+	server.request("GET", "/routes/adminTokens/", nil, bearer(mfaToken)).expect(t, 401, "")
+
+	server.request("POST", "/routes/admins/login/verify", map[string]any{
+		"mfaToken": mfaToken,
+		"code":     confirmedCode,
+	}, nil).expect(t, 401, "Invalid authentication code.")
+
+	server.clock.Advance(30 * time.Second)
+
+	code := server.totpCode(secret)
+
+	response := server.request("POST", "/routes/admins/login/verify", map[string]any{
+		"mfaToken": mfaToken,
+		"code":     code,
+	}, nil)
+
+	response.expect(t, 200, "")
+
+	var result struct {
+		Token string `json:"token"`
+	}
+
+	response.decode(t, &result)
+
+	server.request("GET", "/routes/adminTokens/", nil, bearer(result.Token)).expect(t, 200, "")
+
+	server.request("POST", "/routes/admins/login/verify", map[string]any{
+		"mfaToken": server.requireMFA(testAdminUsername, testAdminPassword),
+		"code":     code,
+	}, nil).expect(t, 401, "Invalid authentication code.")
+
+	mfaToken = server.requireMFA(testAdminUsername, testAdminPassword)
+
+	server.request("POST", "/routes/admins/login/verify", map[string]any{
+		"mfaToken":     mfaToken,
+		"recoveryCode": recoveryCodes[0],
+	}, nil).expect(t, 200, "")
+
+	server.request("POST", "/routes/admins/login/verify", map[string]any{
+		"mfaToken":     mfaToken,
+		"recoveryCode": recoveryCodes[0],
+	}, nil).expect(t, 401, "Invalid authentication code.")
+
+	server.clock.Advance(30 * time.Second)
+
+	server.request("POST", "/routes/admins/twoFactor/disable", map[string]any{
+		"code": server.totpCode(secret),
+	}, bearer(result.Token)).expect(t, 200, "")
+
+	server.loginAdmin(testAdminUsername, testAdminPassword)
+}
+
+func TestAdminTwoFactorLockout(t *testing.T) {
+	forEachBackend(t, testAdminTwoFactorLockout)
+}
+
+func testAdminTwoFactorLockout(
+	t *testing.T,
+	server *testServer,
+) {
+	secret, _ := server.enrollTwoFactor(server.loginAdmin(testAdminUsername, testAdminPassword))
+
+	server.clock.Advance(30 * time.Second)
+
+	mfaToken := server.requireMFA(testAdminUsername, testAdminPassword)
+
+	for attempt := 0; attempt < 5; attempt++ {
+		server.request("POST", "/routes/admins/login/verify", map[string]any{
+			"mfaToken": mfaToken,
+			"code":     server.wrongTOTPCode(secret),
+		}, nil).expect(t, 401, "Invalid authentication code.")
+	}
+
+	response := server.request("POST", "/routes/admins/login/verify", map[string]any{
+		"mfaToken": mfaToken,
+		"code":     server.totpCode(secret),
+	}, nil)
+
+	response.expect(t, 429, "Too many failed attempts, try again later.")
+
+	if retryAfter := response.Header.Get("Retry-After"); retryAfter != "60" {
+		t.Fatalf("expected a one minute lockout, got Retry-After %q", retryAfter)
+	}
+
+	server.clock.Advance(2 * time.Minute)
+
+	server.request("POST", "/routes/admins/login/verify", map[string]any{
+		"mfaToken": server.requireMFA(testAdminUsername, testAdminPassword),
+		"code":     server.totpCode(secret),
+	}, nil).expect(t, 200, "")
+
+	body := string(server.request("GET", "/metrics", nil, nil).Body)
+
+	for _, line := range []string{
+		`lockouts_total{rule="adminTwoFactorLockout"} 1`,
+		`admin_login_failures_total{stage="two_factor"} 5`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Fatalf("expected %q in\n%v", line, body)
+		}
+	}
+}
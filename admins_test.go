@@ -0,0 +1,225 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sandromai/go-http-server/types"
+)
+
+func TestAdminLogin(t *testing.T) {
+	forEachBackend(t, testAdminLogin)
+}
+
+func testAdminLogin(
+	t *testing.T,
+	server *testServer,
+) {
+	server.request("POST", "/routes/admins/login", map[string]any{
+		"password": testAdminPassword,
+	}, nil).expect(t, 400, "Insert your username.")
+
+	server.request("POST", "/routes/admins/login", map[string]any{
+		"username": testAdminUsername,
+	}, nil).expect(t, 400, "Insert your password.")
+
+	server.request("POST", "/routes/admins/login", map[string]any{
+		"username": testAdminUsername,
+		"password": "wrong-password",
+	}, nil).expect(t, 401, "Incorrect username or password.")
+
+	response := server.request("POST", "/routes/admins/login", map[string]any{
+		"username": testAdminUsername,
+		"password": testAdminPassword,
+	}, nil)
+
+	response.expect(t, 200, "")
+
+	var result struct {
+		Admin *types.Admin `json:"admin"`
+		Token string       `json:"token"`
+	}
+
+	response.decode(t, &result)
+
+	if result.Admin == nil || result.Admin.Username != testAdminUsername {
+		t.Fatalf("unexpected admin in %s", response.Body)
+	}
+
+	if !result.Admin.HasPermission(types.PermissionAdminsRegister) {
+		t.Errorf("expected the super admin to have every permission, got %v", result.Admin.Permissions)
+	}
+
+	server.request("GET", "/routes/adminTokens/", nil, nil).expect(t, 401, "No authorization provided.")
+
+	server.request("GET", "/routes/adminTokens/", nil, bearer(result.Token)).expect(t, 200, "")
+}
+
+func TestAdminSessionExpires(t *testing.T) {
+	forEachBackend(t, testAdminSessionExpires)
+}
+
+func testAdminSessionExpires(
+	t *testing.T,
+	server *testServer,
+) {
+	adminToken := server.loginAdmin(testAdminUsername, testAdminPassword)
+
+	server.clock.Advance(server.config.Lifetimes.AdminSession - time.Minute)
+
+	server.request("GET", "/routes/adminTokens/", nil, bearer(adminToken)).expect(t, 200, "")
+
+	server.clock.Advance(2 * time.Minute)
+
+	if response := server.request("GET", "/routes/adminTokens/", nil, bearer(adminToken)); response.StatusCode != 401 {
+		t.Fatalf("got status %v with body %s, expected 401", response.StatusCode, response.Body)
+	}
+}
+
+func TestAdminRegister(t *testing.T) {
+	forEachBackend(t, testAdminRegister)
+}
+
+func testAdminRegister(
+	t *testing.T,
+	server *testServer,
+) {
+	adminToken := server.loginAdmin(testAdminUsername, testAdminPassword)
+
+	newAdmin := map[string]any{
+		"name":            "Support",
+		"username":        "support",
+		"password":        "support-password",
+		"confirmPassword": "support-password",
+	}
+
+	server.request("POST", "/routes/admins/register", newAdmin, nil).expect(t, 401, "No authorization provided.")
+
+	server.request("POST", "/routes/admins/register", map[string]any{
+		"name":            "Support",
+		"username":        "support",
+		"password":        "support-password",
+		"confirmPassword": "other-password",
+	}, bearer(adminToken)).expect(t, 400, "The passwords don't match.")
+
+	response := server.request("POST", "/routes/admins/register", newAdmin, bearer(adminToken))
+
+	response.expect(t, 201, "")
+
+	var admin types.Admin
+
+	response.decode(t, &admin)
+
+	if admin.Username != "support" || len(admin.Roles) != 0 {
+		t.Fatalf("unexpected registered admin %s", response.Body)
+	}
+
+	server.request("POST", "/routes/admins/register", newAdmin, bearer(adminToken)).expect(t, 409, "Username already registered.")
+
+	supportToken := server.loginAdmin("support", "support-password")
+
+	server.request("POST", "/routes/admins/register", map[string]any{
+		"name":            "Another",
+		"username":        "another",
+		"password":        "another-password",
+		"confirmPassword": "another-password",
+	}, bearer(supportToken)).expect(t, 403, "")
+
+	server.request("PUT", "/routes/admins/"+admin.Id+"/roles", map[string]any{
+		"roles": []string{"moderator"},
+	}, bearer(adminToken)).expect(t, 200, "")
+
+	server.request("PUT", "/routes/admins/"+admin.Id+"/roles", map[string]any{
+		"roles": []string{types.SuperAdminRoleId},
+	}, bearer(supportToken)).expect(t, 403, "")
+}
+
+func TestAdminUpdate(t *testing.T) {
+	forEachBackend(t, testAdminUpdate)
+}
+
+func testAdminUpdate(
+	t *testing.T,
+	server *testServer,
+) {
+	adminToken := server.loginAdmin(testAdminUsername, testAdminPassword)
+	otherToken := server.loginAdmin(testAdminUsername, testAdminPassword)
+
+	server.request("PUT", "/routes/admins/update", map[string]any{
+		"username": testAdminUsername,
+	}, bearer(adminToken)).expect(t, 400, "Insert the name.")
+
+	response := server.request("PUT", "/routes/admins/update", map[string]any{
+		"name":     "Root",
+		"username": testAdminUsername,
+	}, bearer(adminToken))
+
+	response.expect(t, 200, "")
+
+	var admin types.Admin
+
+	response.decode(t, &admin)
+
+	if admin.Name != "Root" {
+		t.Fatalf("unexpected updated admin %s", response.Body)
+	}
+
+	server.request("GET", "/routes/adminTokens/", nil, bearer(otherToken)).expect(t, 200, "")
+
+	server.request("PUT", "/routes/admins/update", map[string]any{
+		"name":            "Root",
+		"username":        testAdminUsername,
+		"password":        "new-root-password",
+		"confirmPassword": "new-root-password",
+	}, bearer(adminToken)).expect(t, 200, "")
+
+	server.request("GET", "/routes/adminTokens/", nil, bearer(otherToken)).expect(t, 401, "Session disconnected.")
+
+	server.request("POST", "/routes/admins/login", map[string]any{
+		"username": testAdminUsername,
+		"password": testAdminPassword,
+	}, nil).expect(t, 401, "Incorrect username or password.")
+
+	server.loginAdmin(testAdminUsername, "new-root-password")
+}
+
+func TestAdminTokenDisconnect(t *testing.T) {
+	forEachBackend(t, testAdminTokenDisconnect)
+}
+
+func testAdminTokenDisconnect(
+	t *testing.T,
+	server *testServer,
+) {
+	adminToken := server.loginAdmin(testAdminUsername, testAdminPassword)
+	otherToken := server.loginAdmin(testAdminUsername, testAdminPassword)
+
+	response := server.request("GET", "/routes/adminTokens/", nil, bearer(adminToken))
+
+	response.expect(t, 200, "")
+
+	var result struct {
+		CurrentAdminTokenId string              `json:"currentAdminTokenId"`
+		AdminTokens         []*types.AdminToken `json:"adminTokens"`
+	}
+
+	response.decode(t, &result)
+
+	if len(result.AdminTokens) != 2 {
+		t.Fatalf("got %v admin tokens, expected 2", len(result.AdminTokens))
+	}
+
+	var otherTokenId string
+
+	for _, adminToken := range result.AdminTokens {
+		if adminToken.Id != result.CurrentAdminTokenId {
+			otherTokenId = adminToken.Id
+		}
+	}
+
+	server.request("PATCH", "/routes/adminTokens/"+otherTokenId+"/disconnect", nil, bearer(adminToken)).expect(t, 200, "")
+
+	server.request("PATCH", "/routes/adminTokens/"+otherTokenId+"/disconnect", nil, bearer(adminToken)).expect(t, 400, "This token was already disconnected.")
+
+	server.request("GET", "/routes/adminTokens/", nil, bearer(otherToken)).expect(t, 401, "Session disconnected.")
+}
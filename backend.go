@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/sandromai/go-http-server/clock"
+	"github.com/sandromai/go-http-server/config"
+	"github.com/sandromai/go-http-server/database"
+	"github.com/sandromai/go-http-server/models"
+	"github.com/sandromai/go-http-server/models/memory"
+)
+
+func openBackend(
+	ctx context.Context,
+	appConfig *config.Config,
+	appClock clock.Clock,
+) (models.Backend, error) {
+	if appConfig.Database.Driver == "memory" {
+		slog.Warn("serving from the in-memory store; data is lost when the server stops")
+
+		return memory.NewStore(appClock, appConfig.Security.PasswordCost), nil
+	}
+
+	dialect, err := database.NewDialect(appConfig.Database.Driver)
+
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := models.Open(
+		dialect,
+		appConfig.Database,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if appConfig.Database.AutoMigrate {
+		migrator, err := newMigrator(db, dialect, appConfig.Database)
+
+		if err == nil {
+			_, err = migrator.Up(ctx)
+		}
+
+		if err != nil {
+			db.Close()
+
+			return nil, err
+		}
+	}
+
+	return models.NewStore(
+		db,
+		dialect,
+		appConfig.Database.QueryTimeout,
+		[]byte(appConfig.Security.EncryptionKey),
+		appConfig.Security.PasswordCost,
+		appClock,
+	), nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+
+	"github.com/sandromai/go-http-server/config"
+	"github.com/sandromai/go-http-server/models"
+	"github.com/sandromai/go-http-server/types"
+)
+
+func seedSuperAdmin(
+	ctx context.Context,
+	repositories *models.Repositories,
+	admin config.Admin,
+) *types.AppError {
+	username := admin.Username
+
+	if username == "" {
+		return nil
+	}
+
+	adminModel := repositories.Admins
+
+	admins, appErr := adminModel.Count(ctx)
+
+	if appErr != nil {
+		return appErr
+	}
+
+	if admins > 0 {
+		return nil
+	}
+
+	name := admin.Name
+
+	if name == "" {
+		name = username
+	}
+
+	password := admin.Password
+
+	if password == "" {
+		return &types.AppError{
+			StatusCode: 500,
+			Message:    "ADMIN_PASSWORD must be set to seed the super-admin.",
+		}
+	}
+
+	adminId, appErr := adminModel.Create(
+		ctx,
+		name,
+		username,
+		password,
+		nil,
+	)
+
+	if appErr != nil {
+		return appErr
+	}
+
+	return repositories.Roles.SetAdminRoles(
+		ctx,
+		adminId,
+		[]string{types.SuperAdminRoleId},
+	)
+}
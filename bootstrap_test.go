@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sandromai/go-http-server/config"
+	"github.com/sandromai/go-http-server/types"
+)
+
+func TestSeedSuperAdminLeavesExistingAdmins(t *testing.T) {
+	forEachBackend(t, testSeedSuperAdminLeavesExistingAdmins)
+}
+
+func testSeedSuperAdminLeavesExistingAdmins(
+	t *testing.T,
+	server *testServer,
+) {
+	ctx := context.Background()
+
+	root, appErr := server.repositories.Admins.Authenticate(ctx, testAdminUsername, testAdminPassword)
+
+	if appErr != nil {
+		t.Fatal(appErr.Message)
+	}
+
+	moderatorId, appErr := server.repositories.Admins.Create(ctx, "Moderator", "moderator", "moderator-password", nil)
+
+	if appErr != nil {
+		t.Fatal(appErr.Message)
+	}
+
+	if appErr = server.repositories.Roles.SetAdminRoles(ctx, root.Id, nil); appErr != nil {
+		t.Fatal(appErr.Message)
+	}
+
+	for restart := 0; restart < 2; restart++ {
+		appErr = seedSuperAdmin(ctx, server.repositories, config.Admin{
+			Username: testAdminUsername,
+			Password: testAdminPassword,
+		})
+
+		if appErr != nil {
+			t.Fatal(appErr.Message)
+		}
+	}
+
+	if admins, _ := server.repositories.Admins.Count(ctx); admins != 2 {
+		t.Fatalf("expected no admin to be seeded next to existing ones, got %v admins", admins)
+	}
+
+	for _, adminId := range []string{root.Id, moderatorId} {
+		admin := &types.Admin{Id: adminId}
+
+		if appErr = server.repositories.Roles.LoadAdminAccess(ctx, admin); appErr != nil {
+			t.Fatal(appErr.Message)
+		}
+
+		if roles := strings.Join(admin.Roles, ","); roles != "" {
+			t.Fatalf("expected restarts to leave admin %v without roles, got %q", adminId, roles)
+		}
+	}
+}
+
+func TestRoleDeleteUnknownId(t *testing.T) {
+	forEachBackend(t, testRoleDeleteUnknownId)
+}
+
+func testRoleDeleteUnknownId(
+	t *testing.T,
+	server *testServer,
+) {
+	if appErr := server.repositories.Roles.Delete(context.Background(), "unknown"); appErr == nil || appErr.StatusCode != 404 {
+		t.Fatalf("expected a 404 deleting an unknown role, got %+v", appErr)
+	}
+
+	if appErr := server.repositories.Roles.Delete(context.Background(), "support"); appErr != nil {
+		t.Fatal(appErr.Message)
+	}
+
+	if appErr := server.repositories.Roles.Delete(context.Background(), "support"); appErr == nil || appErr.StatusCode != 404 {
+		t.Fatalf("expected a 404 deleting a role twice, got %+v", appErr)
+	}
+}
@@ -0,0 +1,67 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+type Clock interface {
+	Now() time.Time
+}
+
+type System struct{}
+
+func (System) Now() time.Time {
+	return time.Now().UTC()
+}
+
+func Now(
+	clock Clock,
+) time.Time {
+	if clock == nil {
+		return time.Now().UTC()
+	}
+
+	return clock.Now().UTC()
+}
+
+type Manual struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+func NewManual(
+	now time.Time,
+) *Manual {
+	return &Manual{
+		now: now.UTC(),
+	}
+}
+
+func (clock *Manual) Now() time.Time {
+	clock.mutex.Lock()
+
+	defer clock.mutex.Unlock()
+
+	return clock.now
+}
+
+func (clock *Manual) Set(
+	now time.Time,
+) {
+	clock.mutex.Lock()
+
+	defer clock.mutex.Unlock()
+
+	clock.now = now.UTC()
+}
+
+func (clock *Manual) Advance(
+	duration time.Duration,
+) {
+	clock.mutex.Lock()
+
+	defer clock.mutex.Unlock()
+
+	clock.now = clock.now.Add(duration)
+}
@@ -0,0 +1,646 @@
+package config
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v3"
+)
+
+type Server struct {
+	Address           string        `yaml:"address"`
+	ReadTimeout       time.Duration `yaml:"readTimeout"`
+	ReadHeaderTimeout time.Duration `yaml:"readHeaderTimeout"`
+	WriteTimeout      time.Duration `yaml:"writeTimeout"`
+	IdleTimeout       time.Duration `yaml:"idleTimeout"`
+	ShutdownTimeout   time.Duration `yaml:"shutdownTimeout"`
+	MaxBodyBytes      int           `yaml:"maxBodyBytes"`
+	TLSCertFile       string        `yaml:"tlsCertFile"`
+	TLSKeyFile        string        `yaml:"tlsKeyFile"`
+	HTTP2             bool          `yaml:"http2"`
+}
+
+type Database struct {
+	Driver          string        `yaml:"driver"`
+	User            string        `yaml:"user"`
+	Password        string        `yaml:"password"`
+	Host            string        `yaml:"host"`
+	Port            string        `yaml:"port"`
+	Name            string        `yaml:"name"`
+	SSLMode         string        `yaml:"sslMode"`
+	Timezone        string        `yaml:"timezone"`
+	MaxIdleConns    int           `yaml:"maxIdleConns"`
+	MaxOpenConns    int           `yaml:"maxOpenConns"`
+	ConnMaxIdleTime time.Duration `yaml:"connMaxIdleTime"`
+	ConnMaxLifetime time.Duration `yaml:"connMaxLifetime"`
+	QueryTimeout    time.Duration `yaml:"queryTimeout"`
+	AutoMigrate     bool          `yaml:"autoMigrate"`
+	MigrationLock   time.Duration `yaml:"migrationLock"`
+}
+
+type Security struct {
+	EncryptionKey           string   `yaml:"encryptionKey"`
+	JWTKey                  string   `yaml:"jwtKey"`
+	JWTIssuer               string   `yaml:"jwtIssuer"`
+	JWTSigningKeyFile       string   `yaml:"jwtSigningKeyFile"`
+	JWTSigningKeyId         string   `yaml:"jwtSigningKeyId"`
+	JWTVerificationKeyFiles []string `yaml:"jwtVerificationKeyFiles"`
+	TwoFactorIssuer         string   `yaml:"twoFactorIssuer"`
+	PasswordCost            int      `yaml:"passwordCost"`
+}
+
+type Mail struct {
+	FromAddress       string `yaml:"fromAddress"`
+	FromName          string `yaml:"fromName"`
+	LoginTokenSubject string `yaml:"loginTokenSubject"`
+}
+
+type Admin struct {
+	Username string `yaml:"username"`
+	Name     string `yaml:"name"`
+	Password string `yaml:"password"`
+}
+
+type WebAuthn struct {
+	RelyingPartyId   string   `yaml:"relyingPartyId"`
+	RelyingPartyName string   `yaml:"relyingPartyName"`
+	Origins          []string `yaml:"origins"`
+}
+
+type OAuth struct {
+	ProvidersFile string `yaml:"providersFile"`
+}
+
+type Log struct {
+	Level     string        `yaml:"level"`
+	Folder    string        `yaml:"folder"`
+	FileName  string        `yaml:"fileName"`
+	MaxSizeMB int           `yaml:"maxSizeMB"`
+	MaxFiles  int           `yaml:"maxFiles"`
+	Retention time.Duration `yaml:"retention"`
+}
+
+type Tracing struct {
+	Endpoint    string  `yaml:"endpoint"`
+	ServiceName string  `yaml:"serviceName"`
+	SampleRatio float64 `yaml:"sampleRatio"`
+}
+
+type RateLimitRule struct {
+	Algorithm string        `yaml:"algorithm"`
+	Limit     int           `yaml:"limit"`
+	Window    time.Duration `yaml:"window"`
+}
+
+type Lockout struct {
+	Threshold   int           `yaml:"threshold"`
+	Window      time.Duration `yaml:"window"`
+	Duration    time.Duration `yaml:"duration"`
+	MaxDuration time.Duration `yaml:"maxDuration"`
+}
+
+type RateLimit struct {
+	Enabled          bool          `yaml:"enabled"`
+	AdminLogin       RateLimitRule `yaml:"adminLogin"`
+	AdminTwoFactor   RateLimitRule `yaml:"adminTwoFactor"`
+	LoginTokenCreate RateLimitRule `yaml:"loginTokenCreate"`
+	LoginTokenEmail  RateLimitRule `yaml:"loginTokenEmail"`
+	LoginTokenVerify RateLimitRule `yaml:"loginTokenVerify"`
+	AdminLockout     Lockout       `yaml:"adminLockout"`
+}
+
+type Lifetimes struct {
+	LoginToken             time.Duration `yaml:"loginToken"`
+	LoginTokenResend       time.Duration `yaml:"loginTokenResend"`
+	UserSession            time.Duration `yaml:"userSession"`
+	UserSessionRefresh     time.Duration `yaml:"userSessionRefresh"`
+	AdminSession           time.Duration `yaml:"adminSession"`
+	AdminSessionRemember   time.Duration `yaml:"adminSessionRemember"`
+	AdminTwoFactor         time.Duration `yaml:"adminTwoFactor"`
+	WebAuthnChallenge      time.Duration `yaml:"webAuthnChallenge"`
+	OAuthState             time.Duration `yaml:"oauthState"`
+	OAuthAccessToken       time.Duration `yaml:"oauthAccessToken"`
+	OAuthRefreshToken      time.Duration `yaml:"oauthRefreshToken"`
+	OAuthAuthorizationCode time.Duration `yaml:"oauthAuthorizationCode"`
+}
+
+type Config struct {
+	Server    Server    `yaml:"server"`
+	Database  Database  `yaml:"database"`
+	Security  Security  `yaml:"security"`
+	Mail      Mail      `yaml:"mail"`
+	Admin     Admin     `yaml:"admin"`
+	WebAuthn  WebAuthn  `yaml:"webAuthn"`
+	OAuth     OAuth     `yaml:"oauth"`
+	Log       Log       `yaml:"log"`
+	Tracing   Tracing   `yaml:"tracing"`
+	RateLimit RateLimit `yaml:"rateLimit"`
+	Lifetimes Lifetimes `yaml:"lifetimes"`
+}
+
+func Default() *Config {
+	return &Config{
+		Server: Server{
+			Address:           ":3333",
+			ReadTimeout:       15 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+			ShutdownTimeout:   30 * time.Second,
+			MaxBodyBytes:      1 << 20,
+			HTTP2:             true,
+		},
+		Database: Database{
+			Driver:          "mysql",
+			Host:            "localhost",
+			Port:            "3306",
+			Timezone:        "UTC",
+			MaxIdleConns:    15,
+			MaxOpenConns:    25,
+			ConnMaxIdleTime: time.Second,
+			ConnMaxLifetime: 30 * time.Second,
+			QueryTimeout:    5 * time.Second,
+			MigrationLock:   time.Minute,
+		},
+		Security: Security{
+			JWTIssuer:       "go-http-server",
+			TwoFactorIssuer: "Company",
+			PasswordCost:    12,
+		},
+		Mail: Mail{
+			FromAddress:       "contact@company.com",
+			FromName:          "Company",
+			LoginTokenSubject: "Log in to Company Website",
+		},
+		WebAuthn: WebAuthn{
+			RelyingPartyId:   "localhost",
+			RelyingPartyName: "Company",
+			Origins:          []string{"http://localhost:3000"},
+		},
+		Log: Log{
+			Level:     "info",
+			FileName:  "server.log",
+			MaxSizeMB: 100,
+			MaxFiles:  60,
+			Retention: 30 * 24 * time.Hour,
+		},
+		Tracing: Tracing{
+			ServiceName: "go-http-server",
+			SampleRatio: 1,
+		},
+		RateLimit: RateLimit{
+			Enabled: true,
+			AdminLogin: RateLimitRule{
+				Algorithm: "slidingWindow",
+				Limit:     10,
+				Window:    time.Minute,
+			},
+			AdminTwoFactor: RateLimitRule{
+				Algorithm: "slidingWindow",
+				Limit:     10,
+				Window:    time.Minute,
+			},
+			LoginTokenCreate: RateLimitRule{
+				Algorithm: "slidingWindow",
+				Limit:     10,
+				Window:    time.Minute,
+			},
+			LoginTokenEmail: RateLimitRule{
+				Algorithm: "tokenBucket",
+				Limit:     5,
+				Window:    time.Hour,
+			},
+			LoginTokenVerify: RateLimitRule{
+				Algorithm: "slidingWindow",
+				Limit:     30,
+				Window:    time.Minute,
+			},
+			AdminLockout: Lockout{
+				Threshold:   5,
+				Window:      15 * time.Minute,
+				Duration:    time.Minute,
+				MaxDuration: time.Hour,
+			},
+		},
+		Lifetimes: Lifetimes{
+			LoginToken:             10 * time.Minute,
+			LoginTokenResend:       time.Minute,
+			UserSession:            30 * 24 * time.Hour,
+			UserSessionRefresh:     3 * 24 * time.Hour,
+			AdminSession:           7 * 24 * time.Hour,
+			AdminSessionRemember:   30 * 24 * time.Hour,
+			AdminTwoFactor:         5 * time.Minute,
+			WebAuthnChallenge:      5 * time.Minute,
+			OAuthState:             10 * time.Minute,
+			OAuthAccessToken:       time.Hour,
+			OAuthRefreshToken:      30 * 24 * time.Hour,
+			OAuthAuthorizationCode: 10 * time.Minute,
+		},
+	}
+}
+
+func (lifetimes *Lifetimes) UserTokenExpiresAt(
+	now time.Time,
+) time.Time {
+	return now.Add(lifetimes.UserSession + lifetimes.UserSessionRefresh)
+}
+
+func UseMemoryStore(
+	config *Config,
+) {
+	config.Database.Driver = "memory"
+}
+
+func Load(
+	path string,
+	overrides ...func(config *Config),
+) (*Config, error) {
+	config := Default()
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+
+		if err != nil {
+			return nil, err
+		}
+
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+
+		decoder.KnownFields(true)
+
+		if err = decoder.Decode(config); err != nil && err != io.EOF {
+			return nil, errors.New("config: invalid config file: " + err.Error())
+		}
+	}
+
+	if err := config.applyEnvironment(os.LookupEnv); err != nil {
+		return nil, err
+	}
+
+	for _, override := range overrides {
+		override(config)
+	}
+
+	if config.Database.Driver == "memory" {
+		if err := config.applyEphemeralKeys(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+func randomKey(
+	size int,
+) (string, error) {
+	key := make([]byte, size)
+
+	if _, err := rand.Read(key); err != nil {
+		return "", errors.New("config: failed to generate an ephemeral key")
+	}
+
+	return hex.EncodeToString(key), nil
+}
+
+func (config *Config) applyEphemeralKeys() error {
+	if config.Security.EncryptionKey == "" {
+		key, err := randomKey(16)
+
+		if err != nil {
+			return err
+		}
+
+		config.Security.EncryptionKey = key
+	}
+
+	if config.Security.JWTKey == "" && config.Security.JWTSigningKeyFile == "" {
+		key, err := randomKey(32)
+
+		if err != nil {
+			return err
+		}
+
+		config.Security.JWTKey = key
+	}
+
+	return nil
+}
+
+func (config *Config) applyEnvironment(
+	lookup func(string) (string, bool),
+) error {
+	stringFields := map[string]*string{
+		"SERVER_ADDRESS":       &config.Server.Address,
+		"TIMEZONE":             &config.Database.Timezone,
+		"TLS_CERT_FILE":        &config.Server.TLSCertFile,
+		"TLS_KEY_FILE":         &config.Server.TLSKeyFile,
+		"DB_DRIVER":            &config.Database.Driver,
+		"DB_USER":              &config.Database.User,
+		"DB_PASSWORD":          &config.Database.Password,
+		"DB_HOST":              &config.Database.Host,
+		"DB_PORT":              &config.Database.Port,
+		"DB_DATABASE":          &config.Database.Name,
+		"DB_SSL_MODE":          &config.Database.SSLMode,
+		"ENCRYPTION_KEY":       &config.Security.EncryptionKey,
+		"JWT_KEY":              &config.Security.JWTKey,
+		"JWT_ISSUER":           &config.Security.JWTIssuer,
+		"JWT_SIGNING_KEY_FILE": &config.Security.JWTSigningKeyFile,
+		"JWT_SIGNING_KEY_ID":   &config.Security.JWTSigningKeyId,
+		"MAIL_FROM_ADDRESS":    &config.Mail.FromAddress,
+		"MAIL_FROM_NAME":       &config.Mail.FromName,
+		"ADMIN_USERNAME":       &config.Admin.Username,
+		"ADMIN_NAME":           &config.Admin.Name,
+		"ADMIN_PASSWORD":       &config.Admin.Password,
+		"WEBAUTHN_RP_ID":       &config.WebAuthn.RelyingPartyId,
+		"WEBAUTHN_RP_NAME":     &config.WebAuthn.RelyingPartyName,
+		"OAUTH_PROVIDERS_FILE": &config.OAuth.ProvidersFile,
+		"LOG_LEVEL":            &config.Log.Level,
+		"LOG_FOLDER":           &config.Log.Folder,
+		"LOG_FILE_NAME":        &config.Log.FileName,
+		"TRACING_ENDPOINT":     &config.Tracing.Endpoint,
+		"TRACING_SERVICE_NAME": &config.Tracing.ServiceName,
+	}
+
+	for name, field := range stringFields {
+		if value, found := lookup(name); found {
+			*field = value
+		}
+	}
+
+	listFields := map[string]*[]string{
+		"JWT_VERIFICATION_KEY_FILES": &config.Security.JWTVerificationKeyFiles,
+		"WEBAUTHN_ORIGINS":           &config.WebAuthn.Origins,
+	}
+
+	for name, field := range listFields {
+		if value, found := lookup(name); found {
+			*field = splitList(value)
+		}
+	}
+
+	integerFields := map[string]*int{
+		"SERVER_MAX_BODY_BYTES": &config.Server.MaxBodyBytes,
+		"PASSWORD_COST":         &config.Security.PasswordCost,
+		"DB_MAX_IDLE_CONNS":     &config.Database.MaxIdleConns,
+		"DB_MAX_OPEN_CONNS":     &config.Database.MaxOpenConns,
+		"LOG_MAX_SIZE_MB":       &config.Log.MaxSizeMB,
+		"LOG_MAX_FILES":         &config.Log.MaxFiles,
+	}
+
+	for name, field := range integerFields {
+		value, found := lookup(name)
+
+		if !found {
+			continue
+		}
+
+		parsedValue, err := strconv.Atoi(value)
+
+		if err != nil {
+			return errors.New("config: " + name + " must be an integer")
+		}
+
+		*field = parsedValue
+	}
+
+	booleanFields := map[string]*bool{
+		"DB_AUTO_MIGRATE":    &config.Database.AutoMigrate,
+		"RATE_LIMIT_ENABLED": &config.RateLimit.Enabled,
+	}
+
+	for name, field := range booleanFields {
+		value, found := lookup(name)
+
+		if !found {
+			continue
+		}
+
+		parsedValue, err := strconv.ParseBool(value)
+
+		if err != nil {
+			return errors.New("config: " + name + " must be true or false")
+		}
+
+		*field = parsedValue
+	}
+
+	durationFields := map[string]*time.Duration{
+		"DB_QUERY_TIMEOUT":  &config.Database.QueryTimeout,
+		"DB_MIGRATION_LOCK": &config.Database.MigrationLock,
+		"LOG_RETENTION":     &config.Log.Retention,
+	}
+
+	for name, field := range durationFields {
+		value, found := lookup(name)
+
+		if !found {
+			continue
+		}
+
+		parsedValue, err := time.ParseDuration(value)
+
+		if err != nil {
+			return errors.New("config: " + name + " must be a duration such as 5s")
+		}
+
+		*field = parsedValue
+	}
+
+	return nil
+}
+
+func splitList(
+	value string,
+) []string {
+	var list []string
+
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+
+		if entry != "" {
+			list = append(list, entry)
+		}
+	}
+
+	return list
+}
+
+func (config *Config) Validate() error {
+	if config.Server.Address == "" {
+		return errors.New("config: server address is required")
+	}
+
+	if _, err := time.LoadLocation(config.Database.Timezone); err != nil {
+		return errors.New("config: unknown timezone " + config.Database.Timezone)
+	}
+
+	if config.Server.ReadTimeout < 0 || config.Server.ReadHeaderTimeout < 0 || config.Server.WriteTimeout < 0 || config.Server.IdleTimeout < 0 {
+		return errors.New("config: server timeouts cannot be negative")
+	}
+
+	if config.Server.ShutdownTimeout <= 0 {
+		return errors.New("config: server shutdown timeout must be positive")
+	}
+
+	if config.Server.MaxBodyBytes <= 0 {
+		return errors.New("config: server max body size must be positive")
+	}
+
+	if (config.Server.TLSCertFile == "") != (config.Server.TLSKeyFile == "") {
+		return errors.New("config: TLS requires both a certificate and a key file")
+	}
+
+	switch config.Database.Driver {
+	case "mysql", "postgres":
+		if config.Database.User == "" || config.Database.Host == "" || config.Database.Name == "" {
+			return errors.New("config: database user, host and name are required (DB_USER, DB_HOST, DB_DATABASE)")
+		}
+	case "sqlite":
+		if config.Database.Name == "" {
+			return errors.New("config: SQLite needs a database file name (DB_DATABASE)")
+		}
+	case "memory":
+	default:
+		return errors.New("config: database driver must be mysql, postgres, sqlite or memory")
+	}
+
+	if config.Database.MaxIdleConns < 0 || config.Database.MaxOpenConns < 0 {
+		return errors.New("config: database connection limits cannot be negative")
+	}
+
+	if config.Database.QueryTimeout <= 0 {
+		return errors.New("config: database query timeout must be positive")
+	}
+
+	if config.Database.MigrationLock < time.Second {
+		return errors.New("config: database migration lock timeout must be at least 1s")
+	}
+
+	switch len(config.Security.EncryptionKey) {
+	case 16, 24, 32:
+	default:
+		return errors.New("config: ENCRYPTION_KEY must be 16, 24 or 32 bytes long")
+	}
+
+	if config.Security.PasswordCost < bcrypt.MinCost || config.Security.PasswordCost > bcrypt.MaxCost {
+		return errors.New("config: password cost must be between 4 and 31")
+	}
+
+	if config.Security.JWTKey == "" && config.Security.JWTSigningKeyFile == "" {
+		return errors.New("config: JWT_SIGNING_KEY_FILE or JWT_KEY must be set")
+	}
+
+	if config.Security.JWTIssuer == "" {
+		return errors.New("config: JWT issuer must be set")
+	}
+
+	if !strings.Contains(config.Mail.FromAddress, "@") {
+		return errors.New("config: mail from address is invalid")
+	}
+
+	if config.Admin.Username != "" && config.Admin.Password == "" {
+		return errors.New("config: ADMIN_PASSWORD must be set to seed the super-admin")
+	}
+
+	if config.WebAuthn.RelyingPartyId == "" || len(config.WebAuthn.Origins) == 0 {
+		return errors.New("config: WebAuthn relying party ID and origins are required")
+	}
+
+	var level slog.Level
+
+	if err := level.UnmarshalText([]byte(config.Log.Level)); err != nil {
+		return errors.New("config: log level must be debug, info, warn or error")
+	}
+
+	if config.Log.Folder != "" && config.Log.FileName == "" {
+		return errors.New("config: log file name is required when a log folder is set")
+	}
+
+	if config.Log.MaxSizeMB < 0 || config.Log.MaxFiles < 0 || config.Log.Retention < 0 {
+		return errors.New("config: log size, file count and retention cannot be negative")
+	}
+
+	if config.Tracing.Endpoint != "" && !strings.HasPrefix(config.Tracing.Endpoint, "http://") && !strings.HasPrefix(config.Tracing.Endpoint, "https://") {
+		return errors.New("config: tracing endpoint must be an http or https URL")
+	}
+
+	if config.Tracing.ServiceName == "" {
+		return errors.New("config: tracing service name is required")
+	}
+
+	if config.Tracing.SampleRatio < 0 || config.Tracing.SampleRatio > 1 {
+		return errors.New("config: tracing sample ratio must be between 0 and 1")
+	}
+
+	rateLimitRules := map[string]RateLimitRule{
+		"adminLogin":       config.RateLimit.AdminLogin,
+		"adminTwoFactor":   config.RateLimit.AdminTwoFactor,
+		"loginTokenCreate": config.RateLimit.LoginTokenCreate,
+		"loginTokenEmail":  config.RateLimit.LoginTokenEmail,
+		"loginTokenVerify": config.RateLimit.LoginTokenVerify,
+	}
+
+	for name, rule := range rateLimitRules {
+		if rule.Algorithm != "slidingWindow" && rule.Algorithm != "tokenBucket" {
+			return errors.New("config: rate limit " + name + " algorithm must be slidingWindow or tokenBucket")
+		}
+
+		if rule.Limit < 0 {
+			return errors.New("config: rate limit " + name + " limit cannot be negative")
+		}
+
+		if rule.Limit > 0 && rule.Window < time.Second {
+			return errors.New("config: rate limit " + name + " window must be at least one second")
+		}
+	}
+
+	adminLockout := config.RateLimit.AdminLockout
+
+	if adminLockout.Threshold < 0 {
+		return errors.New("config: admin lockout threshold cannot be negative")
+	}
+
+	if adminLockout.Threshold > 0 && (adminLockout.Window < time.Second || adminLockout.Duration < time.Second) {
+		return errors.New("config: admin lockout window and duration must be at least one second")
+	}
+
+	if adminLockout.Threshold > 0 && adminLockout.MaxDuration < adminLockout.Duration {
+		return errors.New("config: admin lockout max duration cannot be shorter than its duration")
+	}
+
+	lifetimes := map[string]time.Duration{
+		"loginToken":             config.Lifetimes.LoginToken,
+		"loginTokenResend":       config.Lifetimes.LoginTokenResend,
+		"userSession":            config.Lifetimes.UserSession,
+		"userSessionRefresh":     config.Lifetimes.UserSessionRefresh,
+		"adminSession":           config.Lifetimes.AdminSession,
+		"adminSessionRemember":   config.Lifetimes.AdminSessionRemember,
+		"adminTwoFactor":         config.Lifetimes.AdminTwoFactor,
+		"webAuthnChallenge":      config.Lifetimes.WebAuthnChallenge,
+		"oauthState":             config.Lifetimes.OAuthState,
+		"oauthAccessToken":       config.Lifetimes.OAuthAccessToken,
+		"oauthRefreshToken":      config.Lifetimes.OAuthRefreshToken,
+		"oauthAuthorizationCode": config.Lifetimes.OAuthAuthorizationCode,
+	}
+
+	for name, lifetime := range lifetimes {
+		if lifetime < time.Second {
+			return errors.New("config: lifetime " + name + " must be at least one second")
+		}
+	}
+
+	return nil
+}
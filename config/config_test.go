@@ -0,0 +1,147 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func validConfig() *Config {
+	config := Default()
+
+	config.Database.User = "app"
+	config.Database.Name = "app"
+	config.Security.EncryptionKey = "0123456789abcdef"
+	config.Security.JWTKey = "secret"
+
+	return config
+}
+
+func TestLoadAppliesFileAndEnvironment(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	err := os.WriteFile(path, []byte(`
+server:
+  address: ":8080"
+database:
+  user: file-user
+  name: app
+security:
+  encryptionKey: 0123456789abcdef
+  jwtKey: secret
+lifetimes:
+  loginToken: 15m
+`), 0o600)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("DB_USER", "env-user")
+	t.Setenv("DB_AUTO_MIGRATE", "true")
+	t.Setenv("WEBAUTHN_ORIGINS", "https://a.example.com, https://b.example.com")
+
+	config, err := Load(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.Server.Address != ":8080" {
+		t.Fatalf("unexpected server config %+v", config.Server)
+	}
+
+	if config.Database.User != "env-user" || config.Database.Port != "3306" || config.Database.Timezone != "UTC" || !config.Database.AutoMigrate {
+		t.Fatalf("unexpected database config %+v", config.Database)
+	}
+
+	if len(config.WebAuthn.Origins) != 2 || config.WebAuthn.Origins[1] != "https://b.example.com" {
+		t.Fatalf("unexpected origins %v", config.WebAuthn.Origins)
+	}
+
+	if config.Lifetimes.LoginToken != 15*time.Minute || config.Lifetimes.UserSession != 30*24*time.Hour {
+		t.Fatalf("unexpected lifetimes %+v", config.Lifetimes)
+	}
+}
+
+func TestLoadRejectsUnknownFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	if err := os.WriteFile(path, []byte("server:\n  port: 3333\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load(path); err == nil {
+		t.Fatal("expected unknown field to be rejected")
+	}
+}
+
+func TestLoadMemoryStoreGeneratesKeys(t *testing.T) {
+	t.Setenv("ENCRYPTION_KEY", "")
+	t.Setenv("JWT_KEY", "")
+
+	config, err := Load("", UseMemoryStore)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.Database.Driver != "memory" {
+		t.Fatalf("unexpected driver %v", config.Database.Driver)
+	}
+
+	if len(config.Security.EncryptionKey) != 32 || config.Security.JWTKey == "" {
+		t.Fatalf("expected ephemeral keys, got %+v", config.Security)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		valid  bool
+	}{
+		{"valid", func(*Config) {}, true},
+		{"24 byte key", func(config *Config) { config.Security.EncryptionKey = "0123456789abcdef01234567" }, true},
+		{"short key", func(config *Config) { config.Security.EncryptionKey = "short" }, false},
+		{"missing key", func(config *Config) { config.Security.EncryptionKey = "" }, false},
+		{"missing jwt key", func(config *Config) { config.Security.JWTKey = "" }, false},
+		{"missing jwt issuer", func(config *Config) { config.Security.JWTIssuer = "" }, false},
+		{"unknown timezone", func(config *Config) { config.Database.Timezone = "Mars/Olympus" }, false},
+		{"password cost below bcrypt minimum", func(config *Config) { config.Security.PasswordCost = 3 }, false},
+		{"zero max body size", func(config *Config) { config.Server.MaxBodyBytes = 0 }, false},
+		{"missing database", func(config *Config) { config.Database.Name = "" }, false},
+		{"sqlite without user", func(config *Config) { config.Database.Driver, config.Database.User = "sqlite", "" }, true},
+		{"memory without database", func(config *Config) { config.Database.Driver, config.Database.Name = "memory", "" }, true},
+		{"unknown driver", func(config *Config) { config.Database.Driver = "oracle" }, false},
+		{"admin without password", func(config *Config) { config.Admin.Username = "root" }, false},
+		{"short migration lock", func(config *Config) { config.Database.MigrationLock = 0 }, false},
+		{"zero lifetime", func(config *Config) { config.Lifetimes.OAuthAccessToken = 0 }, false},
+		{"unknown log level", func(config *Config) { config.Log.Level = "verbose" }, false},
+		{"log folder without file name", func(config *Config) { config.Log.Folder, config.Log.FileName = "logs", "" }, false},
+		{"tracing endpoint without scheme", func(config *Config) { config.Tracing.Endpoint = "localhost:4318" }, false},
+		{"tracing sample ratio above one", func(config *Config) { config.Tracing.SampleRatio = 2 }, false},
+		{"unknown rate limit algorithm", func(config *Config) { config.RateLimit.AdminLogin.Algorithm = "leakyBucket" }, false},
+		{"disabled rate limit rule", func(config *Config) { config.RateLimit.LoginTokenVerify = RateLimitRule{Algorithm: "slidingWindow"} }, true},
+		{"lockout max below duration", func(config *Config) { config.RateLimit.AdminLockout.MaxDuration = time.Second }, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := validConfig()
+
+			test.modify(config)
+
+			err := config.Validate()
+
+			if test.valid && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !test.valid && err == nil {
+				t.Fatal("expected validation error")
+			}
+		})
+	}
+}
@@ -1,3 +0,0 @@
-CREATE DATABASE IF NOT EXISTS `http_server`
-CHARACTER SET utf8mb4
-COLLATE utf8mb4_unicode_ci;
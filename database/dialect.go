@@ -0,0 +1,439 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/sandromai/go-http-server/config"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+type Dialect interface {
+	Name() string
+	DriverName() string
+	DataSourceName(settings config.Database) string
+	SingleWriter() bool
+	Rebind(query string) string
+	Timestamp(value time.Time) any
+	Upsert(query string, conflictColumns ...string) string
+	IsDuplicateEntry(err error) bool
+	IsMissingTable(err error) bool
+	Lock(ctx context.Context, conn *sql.Conn, name string, timeout time.Duration) (func(), error)
+}
+
+func NewDialect(
+	name string,
+) (Dialect, error) {
+	switch name {
+	case "mysql":
+		return &mysqlDialect{}, nil
+	case "postgres":
+		return &postgresDialect{}, nil
+	case "sqlite":
+		return &sqliteDialect{}, nil
+	default:
+		return nil, errors.New("database: unknown driver " + name)
+	}
+}
+
+var (
+	valuesPattern  = regexp.MustCompile("VALUES\\((`\\w+`)\\)")
+	onDuplicateKey = " ON DUPLICATE KEY UPDATE "
+)
+
+func onConflict(
+	query string,
+	conflictColumns []string,
+) string {
+	target := "`" + strings.Join(conflictColumns, "`, `") + "`"
+
+	query = strings.Replace(query, onDuplicateKey, " ON CONFLICT ("+target+") DO UPDATE SET ", 1)
+
+	return valuesPattern.ReplaceAllString(query, "excluded.$1")
+}
+
+type mysqlDialect struct{}
+
+func (*mysqlDialect) Name() string {
+	return "mysql"
+}
+
+func (*mysqlDialect) DriverName() string {
+	return "mysql"
+}
+
+func (*mysqlDialect) DataSourceName(
+	settings config.Database,
+) string {
+	return fmt.Sprintf(
+		"%v:%v@tcp(%v:%v)/%v?parseTime=true&loc=UTC",
+		settings.User,
+		settings.Password,
+		settings.Host,
+		settings.Port,
+		settings.Name,
+	)
+}
+
+func (*mysqlDialect) SingleWriter() bool {
+	return false
+}
+
+func (*mysqlDialect) Rebind(
+	query string,
+) string {
+	return query
+}
+
+func (*mysqlDialect) Timestamp(
+	value time.Time,
+) any {
+	return value.UTC()
+}
+
+func (*mysqlDialect) Upsert(
+	query string,
+	conflictColumns ...string,
+) string {
+	return query
+}
+
+func (*mysqlDialect) IsDuplicateEntry(
+	err error,
+) bool {
+	var mysqlErr *mysql.MySQLError
+
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1062
+}
+
+func (*mysqlDialect) IsMissingTable(
+	err error,
+) bool {
+	var mysqlErr *mysql.MySQLError
+
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1146
+}
+
+func (*mysqlDialect) Lock(
+	ctx context.Context,
+	conn *sql.Conn,
+	name string,
+	timeout time.Duration,
+) (func(), error) {
+	locked := 0
+
+	err := conn.QueryRowContext(
+		ctx,
+		"SELECT COALESCE(GET_LOCK(?, ?), 0)",
+		name,
+		int(timeout.Seconds()),
+	).Scan(&locked)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if locked != 1 {
+		return nil, errors.New("database: timed out waiting for lock " + name)
+	}
+
+	return func() {
+		conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", name)
+	}, nil
+}
+
+type postgresDialect struct {
+	queries sync.Map
+}
+
+func (*postgresDialect) Name() string {
+	return "postgres"
+}
+
+func (*postgresDialect) DriverName() string {
+	return "pgx"
+}
+
+func (*postgresDialect) DataSourceName(
+	settings config.Database,
+) string {
+	query := url.Values{}
+
+	if settings.Timezone != "" {
+		query.Set("timezone", settings.Timezone)
+	}
+
+	if settings.SSLMode != "" {
+		query.Set("sslmode", settings.SSLMode)
+	}
+
+	dataSourceName := &url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(settings.User, settings.Password),
+		Host:     settings.Host + ":" + settings.Port,
+		Path:     "/" + settings.Name,
+		RawQuery: query.Encode(),
+	}
+
+	return dataSourceName.String()
+}
+
+func (*postgresDialect) SingleWriter() bool {
+	return false
+}
+
+func (dialect *postgresDialect) Rebind(
+	query string,
+) string {
+	if rebound, found := dialect.queries.Load(query); found {
+		return rebound.(string)
+	}
+
+	var builder strings.Builder
+
+	quoted := false
+	parameter := 0
+
+	for _, character := range query {
+		switch {
+		case character == '\'':
+			quoted = !quoted
+
+			builder.WriteRune(character)
+		case character == '`' && !quoted:
+			builder.WriteRune('"')
+		case character == '?' && !quoted:
+			parameter++
+
+			builder.WriteString("$" + strconv.Itoa(parameter))
+		default:
+			builder.WriteRune(character)
+		}
+	}
+
+	dialect.queries.Store(query, builder.String())
+
+	return builder.String()
+}
+
+func (*postgresDialect) Timestamp(
+	value time.Time,
+) any {
+	return value.UTC()
+}
+
+func (*postgresDialect) Upsert(
+	query string,
+	conflictColumns ...string,
+) string {
+	return onConflict(query, conflictColumns)
+}
+
+func (*postgresDialect) IsDuplicateEntry(
+	err error,
+) bool {
+	var pgErr *pgconn.PgError
+
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+func (*postgresDialect) IsMissingTable(
+	err error,
+) bool {
+	var pgErr *pgconn.PgError
+
+	return errors.As(err, &pgErr) && pgErr.Code == "42P01"
+}
+
+func (*postgresDialect) Lock(
+	ctx context.Context,
+	conn *sql.Conn,
+	name string,
+	timeout time.Duration,
+) (func(), error) {
+	hash := fnv.New64a()
+
+	hash.Write([]byte(name))
+
+	key := int64(hash.Sum64())
+	deadline := time.Now().Add(timeout)
+
+	for {
+		locked := false
+
+		if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&locked); err != nil {
+			return nil, err
+		}
+
+		if locked {
+			return func() {
+				conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", key)
+			}, nil
+		}
+
+		if time.Now().After(deadline) {
+			return nil, errors.New("database: timed out waiting for lock " + name)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(250 * time.Millisecond):
+		}
+	}
+}
+
+type sqliteDialect struct {
+	queries sync.Map
+}
+
+func (*sqliteDialect) Name() string {
+	return "sqlite"
+}
+
+func (*sqliteDialect) DriverName() string {
+	return "sqlite"
+}
+
+func (*sqliteDialect) DataSourceName(
+	settings config.Database,
+) string {
+	return "file:" + settings.Name + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_txlock=immediate"
+}
+
+func (*sqliteDialect) SingleWriter() bool {
+	return true
+}
+
+func (dialect *sqliteDialect) Rebind(
+	query string,
+) string {
+	if rebound, found := dialect.queries.Load(query); found {
+		return rebound.(string)
+	}
+
+	rebound := strings.TrimSuffix(query, " FOR UPDATE")
+
+	dialect.queries.Store(query, rebound)
+
+	return rebound
+}
+
+// SQLite has no date type, so timestamps are stored as UTC text that
+// sorts and compares in time order.
+func (*sqliteDialect) Timestamp(
+	value time.Time,
+) any {
+	return value.UTC().Format(time.DateTime)
+}
+
+func (*sqliteDialect) Upsert(
+	query string,
+	conflictColumns ...string,
+) string {
+	return onConflict(query, conflictColumns)
+}
+
+func (*sqliteDialect) IsDuplicateEntry(
+	err error,
+) bool {
+	var sqliteErr *sqlite.Error
+
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+
+	return sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE || sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+}
+
+func (*sqliteDialect) IsMissingTable(
+	err error,
+) bool {
+	var sqliteErr *sqlite.Error
+
+	return errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_ERROR && strings.Contains(sqliteErr.Error(), "no such table")
+}
+
+const lockTimeFormat = "2006-01-02 15:04:05.000000000"
+
+const sqliteLockLease = 10 * time.Minute
+
+// SQLite has no advisory locks, so the lock is a row in a lock table.
+// The row is leased for longer than anyone waits on it, so a long migration
+// keeps the lock; a migrator that crashed leaves its row behind, which is
+// taken over once the lease has passed.
+func (*sqliteDialect) Lock(
+	ctx context.Context,
+	conn *sql.Conn,
+	name string,
+	timeout time.Duration,
+) (func(), error) {
+	_, err := conn.ExecContext(
+		ctx,
+		`CREATE TABLE IF NOT EXISTS "schema_locks" ("name" varchar(255) NOT NULL, "expires_at" varchar(29) NOT NULL, PRIMARY KEY ("name"))`,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(timeout)
+
+	lease := sqliteLockLease
+
+	if timeout > lease {
+		lease = timeout
+	}
+
+	for {
+		now := time.Now().UTC()
+		expiresAt := now.Add(lease).Format(lockTimeFormat)
+
+		result, err := conn.ExecContext(
+			ctx,
+			`INSERT INTO "schema_locks" ("name", "expires_at") VALUES(?, ?) ON CONFLICT ("name") DO UPDATE SET "expires_at" = excluded."expires_at" WHERE "schema_locks"."expires_at" < ?`,
+			name,
+			expiresAt,
+			now.Format(lockTimeFormat),
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		locked, err := result.RowsAffected()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if locked == 1 {
+			return func() {
+				conn.ExecContext(context.Background(), `DELETE FROM "schema_locks" WHERE "name" = ? AND "expires_at" = ?`, name, expiresAt)
+			}, nil
+		}
+
+		if time.Now().After(deadline) {
+			return nil, errors.New("database: timed out waiting for lock " + name)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(250 * time.Millisecond):
+		}
+	}
+}
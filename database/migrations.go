@@ -0,0 +1,377 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations
+var migrationFiles embed.FS
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+const migrationLockName = "schema_migrations"
+
+// Databases created from the SQL files that predate migrations already
+// hold the tables of the first versions, which are recorded instead of run.
+const baselineVersion = 5
+
+type Migration struct {
+	Version uint64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type MigrationStatus struct {
+	Migration *Migration
+	Applied   bool
+	AppliedAt string
+}
+
+type Migrator struct {
+	DB          *sql.DB
+	Dialect     Dialect
+	LockTimeout time.Duration
+	Migrations  []*Migration
+}
+
+func Migrations(
+	dialect Dialect,
+) ([]*Migration, error) {
+	return loadMigrations(migrationFiles, "migrations/"+dialect.Name())
+}
+
+func loadMigrations(
+	files fs.FS,
+	dir string,
+) ([]*Migration, error) {
+	entries, err := fs.ReadDir(files, dir)
+
+	if err != nil {
+		return nil, err
+	}
+
+	migrationsByVersion := map[uint64]*Migration{}
+
+	for _, entry := range entries {
+		matches := migrationFileName.FindStringSubmatch(entry.Name())
+
+		if matches == nil {
+			return nil, errors.New("database: invalid migration file name " + entry.Name())
+		}
+
+		version, err := strconv.ParseUint(matches[1], 10, 64)
+
+		if err != nil || version == 0 {
+			return nil, errors.New("database: invalid migration version in " + entry.Name())
+		}
+
+		content, err := fs.ReadFile(files, dir+"/"+entry.Name())
+
+		if err != nil {
+			return nil, err
+		}
+
+		migration, found := migrationsByVersion[version]
+
+		if !found {
+			migration = &Migration{
+				Version: version,
+				Name:    matches[2],
+			}
+
+			migrationsByVersion[version] = migration
+		}
+
+		if migration.Name != matches[2] {
+			return nil, errors.New("database: migration version " + matches[1] + " is used by more than one name")
+		}
+
+		if matches[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(migrationsByVersion))
+
+	for _, migration := range migrationsByVersion {
+		if strings.TrimSpace(migration.Up) == "" || strings.TrimSpace(migration.Down) == "" {
+			return nil, errors.New("database: migration " + migration.String() + " needs both an up and a down script")
+		}
+
+		migrations = append(migrations, migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+func (migration *Migration) String() string {
+	return strconv.FormatUint(migration.Version, 10) + "_" + migration.Name
+}
+
+func splitStatements(
+	script string,
+) []string {
+	var statements []string
+
+	for _, statement := range strings.Split(script, ";\n") {
+		statement = strings.TrimSuffix(strings.TrimSpace(statement), ";")
+
+		if statement != "" {
+			statements = append(statements, statement)
+		}
+	}
+
+	return statements
+}
+
+func (migrator *Migrator) Up(
+	ctx context.Context,
+) ([]*Migration, error) {
+	var applied []*Migration
+
+	err := migrator.withLock(ctx, func(conn *sql.Conn, appliedVersions map[uint64]string) error {
+		if err := migrator.adoptBaseline(ctx, conn, appliedVersions); err != nil {
+			return err
+		}
+
+		for _, migration := range migrator.Migrations {
+			if _, found := appliedVersions[migration.Version]; found {
+				continue
+			}
+
+			err := migrator.apply(
+				ctx,
+				conn,
+				migration.Up,
+				"INSERT INTO `schema_migrations` (`version`, `name`, `applied_at`) VALUES(?, ?, ?)",
+				migration.Version,
+				migration.Name,
+				time.Now().UTC().Format(time.DateTime),
+			)
+
+			if err != nil {
+				return errors.New("database: migration " + migration.String() + " failed: " + err.Error())
+			}
+
+			applied = append(applied, migration)
+		}
+
+		return nil
+	})
+
+	return applied, err
+}
+
+func (migrator *Migrator) Down(
+	ctx context.Context,
+	steps int,
+) ([]*Migration, error) {
+	var reverted []*Migration
+
+	err := migrator.withLock(ctx, func(conn *sql.Conn, appliedVersions map[uint64]string) error {
+		for i := len(migrator.Migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			migration := migrator.Migrations[i]
+
+			if _, found := appliedVersions[migration.Version]; !found {
+				continue
+			}
+
+			err := migrator.apply(
+				ctx,
+				conn,
+				migration.Down,
+				"DELETE FROM `schema_migrations` WHERE `version` = ?",
+				migration.Version,
+			)
+
+			if err != nil {
+				return errors.New("database: reverting migration " + migration.String() + " failed: " + err.Error())
+			}
+
+			reverted = append(reverted, migration)
+		}
+
+		return nil
+	})
+
+	return reverted, err
+}
+
+func (migrator *Migrator) Status(
+	ctx context.Context,
+) ([]*MigrationStatus, error) {
+	var statuses []*MigrationStatus
+
+	err := migrator.withLock(ctx, func(conn *sql.Conn, appliedVersions map[uint64]string) error {
+		for _, migration := range migrator.Migrations {
+			status := &MigrationStatus{
+				Migration: migration,
+			}
+
+			if appliedAt, found := appliedVersions[migration.Version]; found {
+				status.Applied = true
+				status.AppliedAt = appliedAt
+			}
+
+			statuses = append(statuses, status)
+		}
+
+		return nil
+	})
+
+	return statuses, err
+}
+
+func (migrator *Migrator) adoptBaseline(
+	ctx context.Context,
+	conn *sql.Conn,
+	appliedVersions map[uint64]string,
+) error {
+	if len(appliedVersions) > 0 {
+		return nil
+	}
+
+	admins := 0
+
+	err := conn.QueryRowContext(ctx, migrator.Dialect.Rebind("SELECT COUNT(*) FROM `admins`")).Scan(&admins)
+
+	if migrator.Dialect.IsMissingTable(err) {
+		return nil
+	}
+
+	if err != nil {
+		return errors.New("database: checking for a baseline schema failed: " + err.Error())
+	}
+
+	appliedAt := time.Now().UTC().Format(time.DateTime)
+
+	for _, migration := range migrator.Migrations {
+		if migration.Version > baselineVersion {
+			break
+		}
+
+		_, err := conn.ExecContext(
+			ctx,
+			migrator.Dialect.Rebind("INSERT INTO `schema_migrations` (`version`, `name`, `applied_at`) VALUES(?, ?, ?)"),
+			migration.Version,
+			migration.Name,
+			appliedAt,
+		)
+
+		if err != nil {
+			return errors.New("database: adopting baseline migration " + migration.String() + " failed: " + err.Error())
+		}
+
+		appliedVersions[migration.Version] = appliedAt
+	}
+
+	return nil
+}
+
+func (migrator *Migrator) apply(
+	ctx context.Context,
+	conn *sql.Conn,
+	script,
+	record string,
+	recordArgs ...any,
+) error {
+	tx, err := conn.BeginTx(ctx, nil)
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	for _, statement := range splitStatements(script) {
+		if _, err = tx.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+
+	if _, err = tx.ExecContext(ctx, migrator.Dialect.Rebind(record), recordArgs...); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (migrator *Migrator) withLock(
+	ctx context.Context,
+	work func(conn *sql.Conn, appliedVersions map[uint64]string) error,
+) error {
+	conn, err := migrator.DB.Conn(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	unlock, err := migrator.Dialect.Lock(
+		ctx,
+		conn,
+		migrationLockName,
+		migrator.LockTimeout,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	defer unlock()
+
+	_, err = conn.ExecContext(
+		ctx,
+		migrator.Dialect.Rebind("CREATE TABLE IF NOT EXISTS `schema_migrations` (`version` bigint NOT NULL, `name` varchar(255) NOT NULL, `applied_at` varchar(19) NOT NULL, PRIMARY KEY (`version`))"),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	rows, err := conn.QueryContext(ctx, migrator.Dialect.Rebind("SELECT `version`, `applied_at` FROM `schema_migrations`"))
+
+	if err != nil {
+		return err
+	}
+
+	appliedVersions := map[uint64]string{}
+
+	for rows.Next() {
+		var version uint64
+		var appliedAt string
+
+		if err = rows.Scan(&version, &appliedAt); err != nil {
+			rows.Close()
+
+			return err
+		}
+
+		appliedVersions[version] = appliedAt
+	}
+
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	return work(conn, appliedVersions)
+}
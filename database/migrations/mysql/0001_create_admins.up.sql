@@ -1,5 +1,3 @@
-DROP TABLE IF EXISTS `admins`;
-
 CREATE TABLE `admins` (
   `id` varchar(255) NOT NULL,
   `name` varchar(255) NOT NULL,
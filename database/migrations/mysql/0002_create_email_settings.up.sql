@@ -1,5 +1,3 @@
-DROP TABLE IF EXISTS `email_settings`;
-
 CREATE TABLE `email_settings` (
   `id` int UNSIGNED NOT NULL AUTO_INCREMENT,
   `host` varchar(255) NOT NULL DEFAULT '',
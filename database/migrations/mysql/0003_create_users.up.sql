@@ -1,5 +1,3 @@
-DROP TABLE IF EXISTS `users`;
-
 CREATE TABLE `users` (
   `id` varchar(255) NOT NULL,
   `email` varchar(255) NOT NULL,
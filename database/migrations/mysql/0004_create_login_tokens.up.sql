@@ -1,5 +1,3 @@
-DROP TABLE IF EXISTS `login_tokens`;
-
 CREATE TABLE `login_tokens` (
   `id` varchar(255) NOT NULL,
   `email` varchar(255) NOT NULL,
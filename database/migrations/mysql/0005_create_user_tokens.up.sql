@@ -1,5 +1,3 @@
-DROP TABLE IF EXISTS `user_tokens`;
-
 CREATE TABLE `user_tokens` (
   `id` varchar(255) NOT NULL,
   `user_id` varchar(255) NOT NULL,
@@ -0,0 +1,13 @@
+CREATE TABLE `roles` (
+  `id` varchar(255) NOT NULL,
+  `name` varchar(255) NOT NULL,
+  `description` varchar(255) NOT NULL DEFAULT '',
+  `created_at` datetime NOT NULL DEFAULT current_timestamp(),
+  PRIMARY KEY (`id`),
+  UNIQUE KEY (`name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 DEFAULT COLLATE utf8mb4_unicode_ci;
+
+INSERT INTO `roles` (`id`, `name`, `description`) VALUES
+  ('super-admin', 'super-admin', 'Full access to every admin feature'),
+  ('moderator', 'moderator', 'Moderates users'),
+  ('support', 'support', 'Helps users and checks settings');
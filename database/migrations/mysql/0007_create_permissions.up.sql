@@ -0,0 +1,14 @@
+CREATE TABLE `permissions` (
+  `id` varchar(255) NOT NULL,
+  `description` varchar(255) NOT NULL DEFAULT '',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 DEFAULT COLLATE utf8mb4_unicode_ci;
+
+INSERT INTO `permissions` (`id`, `description`) VALUES
+  ('admins.register', 'Register new admins'),
+  ('admins.roles', 'Assign roles to admins'),
+  ('roles.manage', 'Create, update and delete roles'),
+  ('users.ban', 'Ban and unban users'),
+  ('emailSettings.list', 'View email settings'),
+  ('emailSettings.update', 'Update email settings'),
+  ('oauthClients.manage', 'Register and delete OAuth clients');
@@ -0,0 +1,20 @@
+CREATE TABLE `role_permissions` (
+  `role_id` varchar(255) NOT NULL,
+  `permission_id` varchar(255) NOT NULL,
+  PRIMARY KEY (`role_id`, `permission_id`),
+  FOREIGN KEY (`role_id`)
+    REFERENCES `roles` (`id`)
+      ON UPDATE CASCADE
+      ON DELETE CASCADE,
+  FOREIGN KEY (`permission_id`)
+    REFERENCES `permissions` (`id`)
+      ON UPDATE CASCADE
+      ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 DEFAULT COLLATE utf8mb4_unicode_ci;
+
+INSERT INTO `role_permissions` (`role_id`, `permission_id`)
+  SELECT 'super-admin', `id` FROM `permissions`;
+
+INSERT INTO `role_permissions` (`role_id`, `permission_id`) VALUES
+  ('moderator', 'users.ban'),
+  ('support', 'emailSettings.list');
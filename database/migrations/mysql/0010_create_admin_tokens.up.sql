@@ -0,0 +1,15 @@
+CREATE TABLE `admin_tokens` (
+  `id` varchar(255) NOT NULL,
+  `admin_id` varchar(255) NOT NULL,
+  `ip_address` varchar(255) NOT NULL,
+  `device` varchar(255) NOT NULL,
+  `disconnected` boolean NOT NULL DEFAULT false,
+  `last_activity` datetime NOT NULL DEFAULT current_timestamp(),
+  `expires_at` datetime NOT NULL DEFAULT current_timestamp(),
+  `created_at` datetime NOT NULL DEFAULT current_timestamp(),
+  PRIMARY KEY (`id`),
+  FOREIGN KEY (`admin_id`)
+    REFERENCES `admins` (`id`)
+      ON UPDATE CASCADE
+      ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 DEFAULT COLLATE utf8mb4_unicode_ci;
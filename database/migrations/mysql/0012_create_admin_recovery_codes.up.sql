@@ -0,0 +1,13 @@
+CREATE TABLE `admin_recovery_codes` (
+  `id` int UNSIGNED NOT NULL AUTO_INCREMENT,
+  `admin_id` varchar(255) NOT NULL,
+  `code_hash` varchar(255) NOT NULL,
+  `used_at` datetime NULL,
+  `created_at` datetime NOT NULL DEFAULT current_timestamp(),
+  PRIMARY KEY (`id`),
+  UNIQUE KEY (`admin_id`, `code_hash`),
+  FOREIGN KEY (`admin_id`)
+    REFERENCES `admins` (`id`)
+      ON UPDATE CASCADE
+      ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 DEFAULT COLLATE utf8mb4_unicode_ci;
@@ -0,0 +1,17 @@
+CREATE TABLE `oauth_clients` (
+  `id` varchar(255) NOT NULL,
+  `name` varchar(255) NOT NULL,
+  `secret_hash` varchar(255) NULL,
+  `redirect_uris` text NOT NULL,
+  `scopes` varchar(255) NOT NULL DEFAULT '',
+  `grant_types` varchar(255) NOT NULL DEFAULT '',
+  `confidential` boolean NOT NULL DEFAULT false,
+  `first_party` boolean NOT NULL DEFAULT false,
+  `created_by` varchar(255) NULL,
+  `created_at` datetime NOT NULL DEFAULT current_timestamp(),
+  PRIMARY KEY (`id`),
+  FOREIGN KEY (`created_by`)
+    REFERENCES `admins` (`id`)
+      ON UPDATE CASCADE
+      ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 DEFAULT COLLATE utf8mb4_unicode_ci;
@@ -0,0 +1,13 @@
+CREATE TABLE `oauth_client_tokens` (
+  `id` varchar(255) NOT NULL,
+  `client_id` varchar(255) NOT NULL,
+  `scope` varchar(255) NOT NULL DEFAULT '',
+  `revoked` boolean NOT NULL DEFAULT false,
+  `expires_at` datetime NOT NULL DEFAULT current_timestamp(),
+  `created_at` datetime NOT NULL DEFAULT current_timestamp(),
+  PRIMARY KEY (`id`),
+  FOREIGN KEY (`client_id`)
+    REFERENCES `oauth_clients` (`id`)
+      ON UPDATE CASCADE
+      ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 DEFAULT COLLATE utf8mb4_unicode_ci;
@@ -0,0 +1,16 @@
+CREATE TABLE `user_credentials` (
+  `id` varchar(255) NOT NULL,
+  `user_id` varchar(255) NOT NULL,
+  `credential_id` varchar(255) NOT NULL,
+  `public_key` text NOT NULL,
+  `sign_count` int unsigned NOT NULL DEFAULT 0,
+  `name` varchar(255) NOT NULL,
+  `last_used_at` datetime NULL,
+  `created_at` datetime NOT NULL DEFAULT current_timestamp(),
+  PRIMARY KEY (`id`),
+  UNIQUE KEY (`credential_id`),
+  FOREIGN KEY (`user_id`)
+    REFERENCES `users` (`id`)
+      ON UPDATE CASCADE
+      ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 DEFAULT COLLATE utf8mb4_unicode_ci;
@@ -0,0 +1,19 @@
+CREATE TABLE `oauth_authorization_codes` (
+  `code_hash` varchar(255) NOT NULL,
+  `client_id` varchar(255) NOT NULL,
+  `user_id` varchar(255) NOT NULL,
+  `redirect_uri` text NOT NULL,
+  `scope` varchar(255) NOT NULL DEFAULT '',
+  `code_challenge` varchar(255) NOT NULL,
+  `expires_at` datetime NOT NULL DEFAULT current_timestamp(),
+  `created_at` datetime NOT NULL DEFAULT current_timestamp(),
+  PRIMARY KEY (`code_hash`),
+  FOREIGN KEY (`client_id`)
+    REFERENCES `oauth_clients` (`id`)
+      ON UPDATE CASCADE
+      ON DELETE CASCADE,
+  FOREIGN KEY (`user_id`)
+    REFERENCES `users` (`id`)
+      ON UPDATE CASCADE
+      ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 DEFAULT COLLATE utf8mb4_unicode_ci;
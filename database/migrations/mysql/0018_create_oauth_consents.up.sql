@@ -0,0 +1,15 @@
+CREATE TABLE `oauth_consents` (
+  `user_id` varchar(255) NOT NULL,
+  `client_id` varchar(255) NOT NULL,
+  `scope` varchar(255) NOT NULL DEFAULT '',
+  `created_at` datetime NOT NULL DEFAULT current_timestamp(),
+  PRIMARY KEY (`user_id`, `client_id`),
+  FOREIGN KEY (`user_id`)
+    REFERENCES `users` (`id`)
+      ON UPDATE CASCADE
+      ON DELETE CASCADE,
+  FOREIGN KEY (`client_id`)
+    REFERENCES `oauth_clients` (`id`)
+      ON UPDATE CASCADE
+      ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 DEFAULT COLLATE utf8mb4_unicode_ci;
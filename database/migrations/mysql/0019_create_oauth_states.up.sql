@@ -0,0 +1,9 @@
+CREATE TABLE `oauth_states` (
+  `state` varchar(255) NOT NULL,
+  `provider` varchar(255) NOT NULL,
+  `nonce` varchar(255) NOT NULL,
+  `code_verifier` varchar(255) NOT NULL,
+  `expires_at` datetime NOT NULL DEFAULT current_timestamp(),
+  `created_at` datetime NOT NULL DEFAULT current_timestamp(),
+  PRIMARY KEY (`state`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 DEFAULT COLLATE utf8mb4_unicode_ci;
@@ -0,0 +1,13 @@
+CREATE TABLE `webauthn_challenges` (
+  `id` varchar(255) NOT NULL,
+  `user_id` varchar(255) NULL,
+  `type` varchar(255) NOT NULL,
+  `challenge` varchar(255) NOT NULL,
+  `expires_at` datetime NOT NULL DEFAULT current_timestamp(),
+  `created_at` datetime NOT NULL DEFAULT current_timestamp(),
+  PRIMARY KEY (`id`),
+  FOREIGN KEY (`user_id`)
+    REFERENCES `users` (`id`)
+      ON UPDATE CASCADE
+      ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 DEFAULT COLLATE utf8mb4_unicode_ci;
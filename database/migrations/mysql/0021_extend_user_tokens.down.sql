@@ -0,0 +1,12 @@
+ALTER TABLE `user_tokens`
+  DROP FOREIGN KEY `user_tokens_from_credential_fk`,
+  DROP FOREIGN KEY `user_tokens_from_identity_fk`,
+  DROP FOREIGN KEY `user_tokens_client_id_fk`;
+
+ALTER TABLE `user_tokens`
+  DROP INDEX `refresh_token_hash`,
+  DROP COLUMN `from_credential`,
+  DROP COLUMN `from_identity`,
+  DROP COLUMN `client_id`,
+  DROP COLUMN `scope`,
+  DROP COLUMN `refresh_token_hash`;
@@ -0,0 +1,19 @@
+ALTER TABLE `user_tokens`
+  ADD COLUMN `from_credential` varchar(255) NULL AFTER `from_user_token`,
+  ADD COLUMN `from_identity` varchar(255) NULL AFTER `from_credential`,
+  ADD COLUMN `client_id` varchar(255) NULL AFTER `from_identity`,
+  ADD COLUMN `scope` varchar(255) NULL AFTER `client_id`,
+  ADD COLUMN `refresh_token_hash` varchar(255) NULL AFTER `scope`,
+  ADD UNIQUE KEY `refresh_token_hash` (`refresh_token_hash`),
+  ADD CONSTRAINT `user_tokens_from_credential_fk` FOREIGN KEY (`from_credential`)
+    REFERENCES `user_credentials` (`id`)
+      ON UPDATE CASCADE
+      ON DELETE SET NULL,
+  ADD CONSTRAINT `user_tokens_from_identity_fk` FOREIGN KEY (`from_identity`)
+    REFERENCES `user_identities` (`id`)
+      ON UPDATE CASCADE
+      ON DELETE SET NULL,
+  ADD CONSTRAINT `user_tokens_client_id_fk` FOREIGN KEY (`client_id`)
+    REFERENCES `oauth_clients` (`id`)
+      ON UPDATE CASCADE
+      ON DELETE CASCADE;
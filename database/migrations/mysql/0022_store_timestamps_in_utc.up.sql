@@ -0,0 +1,58 @@
+UPDATE `admins` SET
+  `created_at` = `created_at` - INTERVAL TIMESTAMPDIFF(SECOND, UTC_TIMESTAMP(), NOW()) SECOND;
+
+UPDATE `roles` SET
+  `created_at` = `created_at` - INTERVAL TIMESTAMPDIFF(SECOND, UTC_TIMESTAMP(), NOW()) SECOND;
+
+UPDATE `admin_tokens` SET
+  `last_activity` = `last_activity` - INTERVAL TIMESTAMPDIFF(SECOND, UTC_TIMESTAMP(), NOW()) SECOND,
+  `expires_at` = `expires_at` - INTERVAL TIMESTAMPDIFF(SECOND, UTC_TIMESTAMP(), NOW()) SECOND,
+  `created_at` = `created_at` - INTERVAL TIMESTAMPDIFF(SECOND, UTC_TIMESTAMP(), NOW()) SECOND;
+
+UPDATE `admin_two_factor` SET
+  `created_at` = `created_at` - INTERVAL TIMESTAMPDIFF(SECOND, UTC_TIMESTAMP(), NOW()) SECOND;
+
+UPDATE `admin_recovery_codes` SET
+  `used_at` = `used_at` - INTERVAL TIMESTAMPDIFF(SECOND, UTC_TIMESTAMP(), NOW()) SECOND,
+  `created_at` = `created_at` - INTERVAL TIMESTAMPDIFF(SECOND, UTC_TIMESTAMP(), NOW()) SECOND;
+
+UPDATE `users` SET
+  `created_at` = `created_at` - INTERVAL TIMESTAMPDIFF(SECOND, UTC_TIMESTAMP(), NOW()) SECOND;
+
+UPDATE `login_tokens` SET
+  `expires_at` = `expires_at` - INTERVAL TIMESTAMPDIFF(SECOND, UTC_TIMESTAMP(), NOW()) SECOND,
+  `created_at` = `created_at` - INTERVAL TIMESTAMPDIFF(SECOND, UTC_TIMESTAMP(), NOW()) SECOND;
+
+UPDATE `oauth_clients` SET
+  `created_at` = `created_at` - INTERVAL TIMESTAMPDIFF(SECOND, UTC_TIMESTAMP(), NOW()) SECOND;
+
+UPDATE `oauth_client_tokens` SET
+  `expires_at` = `expires_at` - INTERVAL TIMESTAMPDIFF(SECOND, UTC_TIMESTAMP(), NOW()) SECOND,
+  `created_at` = `created_at` - INTERVAL TIMESTAMPDIFF(SECOND, UTC_TIMESTAMP(), NOW()) SECOND;
+
+UPDATE `user_credentials` SET
+  `last_used_at` = `last_used_at` - INTERVAL TIMESTAMPDIFF(SECOND, UTC_TIMESTAMP(), NOW()) SECOND,
+  `created_at` = `created_at` - INTERVAL TIMESTAMPDIFF(SECOND, UTC_TIMESTAMP(), NOW()) SECOND;
+
+UPDATE `user_identities` SET
+  `created_at` = `created_at` - INTERVAL TIMESTAMPDIFF(SECOND, UTC_TIMESTAMP(), NOW()) SECOND;
+
+UPDATE `oauth_authorization_codes` SET
+  `expires_at` = `expires_at` - INTERVAL TIMESTAMPDIFF(SECOND, UTC_TIMESTAMP(), NOW()) SECOND,
+  `created_at` = `created_at` - INTERVAL TIMESTAMPDIFF(SECOND, UTC_TIMESTAMP(), NOW()) SECOND;
+
+UPDATE `oauth_consents` SET
+  `created_at` = `created_at` - INTERVAL TIMESTAMPDIFF(SECOND, UTC_TIMESTAMP(), NOW()) SECOND;
+
+UPDATE `oauth_states` SET
+  `expires_at` = `expires_at` - INTERVAL TIMESTAMPDIFF(SECOND, UTC_TIMESTAMP(), NOW()) SECOND,
+  `created_at` = `created_at` - INTERVAL TIMESTAMPDIFF(SECOND, UTC_TIMESTAMP(), NOW()) SECOND;
+
+UPDATE `webauthn_challenges` SET
+  `expires_at` = `expires_at` - INTERVAL TIMESTAMPDIFF(SECOND, UTC_TIMESTAMP(), NOW()) SECOND,
+  `created_at` = `created_at` - INTERVAL TIMESTAMPDIFF(SECOND, UTC_TIMESTAMP(), NOW()) SECOND;
+
+UPDATE `user_tokens` SET
+  `last_activity` = `last_activity` - INTERVAL TIMESTAMPDIFF(SECOND, UTC_TIMESTAMP(), NOW()) SECOND,
+  `expires_at` = `expires_at` - INTERVAL TIMESTAMPDIFF(SECOND, UTC_TIMESTAMP(), NOW()) SECOND,
+  `created_at` = `created_at` - INTERVAL TIMESTAMPDIFF(SECOND, UTC_TIMESTAMP(), NOW()) SECOND;
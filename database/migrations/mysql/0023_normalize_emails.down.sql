@@ -0,0 +1 @@
+ALTER TABLE `login_tokens` DROP INDEX `email`;
@@ -0,0 +1,5 @@
+UPDATE `users` SET `email` = LOWER(TRIM(`email`));
+
+UPDATE `login_tokens` SET `email` = LOWER(TRIM(`email`));
+
+ALTER TABLE `login_tokens` ADD KEY `email` (`email`);
@@ -0,0 +1,10 @@
+CREATE TABLE "email_settings" (
+  "id" integer GENERATED BY DEFAULT AS IDENTITY,
+  "host" varchar(255) NOT NULL DEFAULT '',
+  "port" varchar(255) NOT NULL DEFAULT '',
+  "username" varchar(255) NOT NULL DEFAULT '',
+  "password" varchar(255) NOT NULL DEFAULT '',
+  PRIMARY KEY ("id")
+);
+
+INSERT INTO "email_settings" DEFAULT VALUES;
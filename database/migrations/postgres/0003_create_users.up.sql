@@ -0,0 +1,8 @@
+CREATE TABLE "users" (
+  "id" varchar(255) NOT NULL,
+  "email" varchar(255) NOT NULL,
+  "banned" boolean NOT NULL DEFAULT false,
+  "created_at" varchar(19) NOT NULL DEFAULT to_char(LOCALTIMESTAMP, 'YYYY-MM-DD HH24:MI:SS'),
+  PRIMARY KEY ("id"),
+  UNIQUE ("email")
+);
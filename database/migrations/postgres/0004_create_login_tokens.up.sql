@@ -0,0 +1,11 @@
+CREATE TABLE "login_tokens" (
+  "id" varchar(255) NOT NULL,
+  "email" varchar(255) NOT NULL,
+  "ip_address" varchar(255) NOT NULL,
+  "device" varchar(255) NOT NULL,
+  "authorized" boolean NOT NULL DEFAULT false,
+  "denied" boolean NOT NULL DEFAULT false,
+  "expires_at" varchar(19) NOT NULL DEFAULT to_char(LOCALTIMESTAMP, 'YYYY-MM-DD HH24:MI:SS'),
+  "created_at" varchar(19) NOT NULL DEFAULT to_char(LOCALTIMESTAMP, 'YYYY-MM-DD HH24:MI:SS'),
+  PRIMARY KEY ("id")
+);
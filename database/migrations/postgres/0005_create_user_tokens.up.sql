@@ -0,0 +1,26 @@
+CREATE TABLE "user_tokens" (
+  "id" varchar(255) NOT NULL,
+  "user_id" varchar(255) NOT NULL,
+  "from_login_token" varchar(255) NULL,
+  "from_user_token" varchar(255) NULL,
+  "ip_address" varchar(255) NOT NULL,
+  "device" varchar(255) NOT NULL,
+  "disconnected" boolean NOT NULL DEFAULT false,
+  "last_activity" varchar(19) NOT NULL DEFAULT to_char(LOCALTIMESTAMP, 'YYYY-MM-DD HH24:MI:SS'),
+  "expires_at" varchar(19) NOT NULL DEFAULT to_char(LOCALTIMESTAMP, 'YYYY-MM-DD HH24:MI:SS'),
+  "created_at" varchar(19) NOT NULL DEFAULT to_char(LOCALTIMESTAMP, 'YYYY-MM-DD HH24:MI:SS'),
+  PRIMARY KEY ("id"),
+  UNIQUE ("from_login_token"),
+  FOREIGN KEY ("user_id")
+    REFERENCES "users" ("id")
+      ON UPDATE CASCADE
+      ON DELETE CASCADE,
+  FOREIGN KEY ("from_login_token")
+    REFERENCES "login_tokens" ("id")
+      ON UPDATE CASCADE
+      ON DELETE SET NULL,
+  FOREIGN KEY ("from_user_token")
+    REFERENCES "user_tokens" ("id")
+      ON UPDATE CASCADE
+      ON DELETE SET NULL
+);
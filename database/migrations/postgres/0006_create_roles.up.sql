@@ -0,0 +1,13 @@
+CREATE TABLE "roles" (
+  "id" varchar(255) NOT NULL,
+  "name" varchar(255) NOT NULL,
+  "description" varchar(255) NOT NULL DEFAULT '',
+  "created_at" varchar(19) NOT NULL DEFAULT to_char(LOCALTIMESTAMP, 'YYYY-MM-DD HH24:MI:SS'),
+  PRIMARY KEY ("id"),
+  UNIQUE ("name")
+);
+
+INSERT INTO "roles" ("id", "name", "description") VALUES
+  ('super-admin', 'super-admin', 'Full access to every admin feature'),
+  ('moderator', 'moderator', 'Moderates users'),
+  ('support', 'support', 'Helps users and checks settings');
@@ -0,0 +1,16 @@
+CREATE TABLE "admin_roles" (
+  "admin_id" varchar(255) NOT NULL,
+  "role_id" varchar(255) NOT NULL,
+  PRIMARY KEY ("admin_id", "role_id"),
+  FOREIGN KEY ("admin_id")
+    REFERENCES "admins" ("id")
+      ON UPDATE CASCADE
+      ON DELETE CASCADE,
+  FOREIGN KEY ("role_id")
+    REFERENCES "roles" ("id")
+      ON UPDATE CASCADE
+      ON DELETE CASCADE
+);
+
+INSERT INTO "admin_roles" ("admin_id", "role_id")
+  SELECT "id", 'super-admin' FROM "admins";
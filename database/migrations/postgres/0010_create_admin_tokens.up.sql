@@ -0,0 +1,15 @@
+CREATE TABLE "admin_tokens" (
+  "id" varchar(255) NOT NULL,
+  "admin_id" varchar(255) NOT NULL,
+  "ip_address" varchar(255) NOT NULL,
+  "device" varchar(255) NOT NULL,
+  "disconnected" boolean NOT NULL DEFAULT false,
+  "last_activity" varchar(19) NOT NULL DEFAULT to_char(LOCALTIMESTAMP, 'YYYY-MM-DD HH24:MI:SS'),
+  "expires_at" varchar(19) NOT NULL DEFAULT to_char(LOCALTIMESTAMP, 'YYYY-MM-DD HH24:MI:SS'),
+  "created_at" varchar(19) NOT NULL DEFAULT to_char(LOCALTIMESTAMP, 'YYYY-MM-DD HH24:MI:SS'),
+  PRIMARY KEY ("id"),
+  FOREIGN KEY ("admin_id")
+    REFERENCES "admins" ("id")
+      ON UPDATE CASCADE
+      ON DELETE CASCADE
+);
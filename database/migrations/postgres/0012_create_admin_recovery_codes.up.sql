@@ -0,0 +1,13 @@
+CREATE TABLE "admin_recovery_codes" (
+  "id" integer GENERATED BY DEFAULT AS IDENTITY,
+  "admin_id" varchar(255) NOT NULL,
+  "code_hash" varchar(255) NOT NULL,
+  "used_at" varchar(19) NULL,
+  "created_at" varchar(19) NOT NULL DEFAULT to_char(LOCALTIMESTAMP, 'YYYY-MM-DD HH24:MI:SS'),
+  PRIMARY KEY ("id"),
+  UNIQUE ("admin_id", "code_hash"),
+  FOREIGN KEY ("admin_id")
+    REFERENCES "admins" ("id")
+      ON UPDATE CASCADE
+      ON DELETE CASCADE
+);
@@ -0,0 +1,17 @@
+CREATE TABLE "oauth_clients" (
+  "id" varchar(255) NOT NULL,
+  "name" varchar(255) NOT NULL,
+  "secret_hash" varchar(255) NULL,
+  "redirect_uris" text NOT NULL,
+  "scopes" varchar(255) NOT NULL DEFAULT '',
+  "grant_types" varchar(255) NOT NULL DEFAULT '',
+  "confidential" boolean NOT NULL DEFAULT false,
+  "first_party" boolean NOT NULL DEFAULT false,
+  "created_by" varchar(255) NULL,
+  "created_at" varchar(19) NOT NULL DEFAULT to_char(LOCALTIMESTAMP, 'YYYY-MM-DD HH24:MI:SS'),
+  PRIMARY KEY ("id"),
+  FOREIGN KEY ("created_by")
+    REFERENCES "admins" ("id")
+      ON UPDATE CASCADE
+      ON DELETE SET NULL
+);
@@ -0,0 +1,13 @@
+CREATE TABLE "oauth_client_tokens" (
+  "id" varchar(255) NOT NULL,
+  "client_id" varchar(255) NOT NULL,
+  "scope" varchar(255) NOT NULL DEFAULT '',
+  "revoked" boolean NOT NULL DEFAULT false,
+  "expires_at" varchar(19) NOT NULL DEFAULT to_char(LOCALTIMESTAMP, 'YYYY-MM-DD HH24:MI:SS'),
+  "created_at" varchar(19) NOT NULL DEFAULT to_char(LOCALTIMESTAMP, 'YYYY-MM-DD HH24:MI:SS'),
+  PRIMARY KEY ("id"),
+  FOREIGN KEY ("client_id")
+    REFERENCES "oauth_clients" ("id")
+      ON UPDATE CASCADE
+      ON DELETE CASCADE
+);
@@ -0,0 +1,16 @@
+CREATE TABLE "user_credentials" (
+  "id" varchar(255) NOT NULL,
+  "user_id" varchar(255) NOT NULL,
+  "credential_id" varchar(255) NOT NULL,
+  "public_key" text NOT NULL,
+  "sign_count" bigint NOT NULL DEFAULT 0,
+  "name" varchar(255) NOT NULL,
+  "last_used_at" varchar(19) NULL,
+  "created_at" varchar(19) NOT NULL DEFAULT to_char(LOCALTIMESTAMP, 'YYYY-MM-DD HH24:MI:SS'),
+  PRIMARY KEY ("id"),
+  UNIQUE ("credential_id"),
+  FOREIGN KEY ("user_id")
+    REFERENCES "users" ("id")
+      ON UPDATE CASCADE
+      ON DELETE CASCADE
+);
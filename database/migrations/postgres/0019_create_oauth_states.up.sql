@@ -0,0 +1,9 @@
+CREATE TABLE "oauth_states" (
+  "state" varchar(255) NOT NULL,
+  "provider" varchar(255) NOT NULL,
+  "nonce" varchar(255) NOT NULL,
+  "code_verifier" varchar(255) NOT NULL,
+  "expires_at" varchar(19) NOT NULL DEFAULT to_char(LOCALTIMESTAMP, 'YYYY-MM-DD HH24:MI:SS'),
+  "created_at" varchar(19) NOT NULL DEFAULT to_char(LOCALTIMESTAMP, 'YYYY-MM-DD HH24:MI:SS'),
+  PRIMARY KEY ("state")
+);
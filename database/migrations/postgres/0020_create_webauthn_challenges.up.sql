@@ -0,0 +1,13 @@
+CREATE TABLE "webauthn_challenges" (
+  "id" varchar(255) NOT NULL,
+  "user_id" varchar(255) NULL,
+  "type" varchar(255) NOT NULL,
+  "challenge" varchar(255) NOT NULL,
+  "expires_at" varchar(19) NOT NULL DEFAULT to_char(LOCALTIMESTAMP, 'YYYY-MM-DD HH24:MI:SS'),
+  "created_at" varchar(19) NOT NULL DEFAULT to_char(LOCALTIMESTAMP, 'YYYY-MM-DD HH24:MI:SS'),
+  PRIMARY KEY ("id"),
+  FOREIGN KEY ("user_id")
+    REFERENCES "users" ("id")
+      ON UPDATE CASCADE
+      ON DELETE CASCADE
+);
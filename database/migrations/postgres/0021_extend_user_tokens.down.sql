@@ -0,0 +1,6 @@
+ALTER TABLE "user_tokens"
+  DROP COLUMN "from_credential",
+  DROP COLUMN "from_identity",
+  DROP COLUMN "client_id",
+  DROP COLUMN "scope",
+  DROP COLUMN "refresh_token_hash";
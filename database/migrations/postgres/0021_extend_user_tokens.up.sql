@@ -0,0 +1,15 @@
+ALTER TABLE "user_tokens"
+  ADD COLUMN "from_credential" varchar(255) NULL
+    REFERENCES "user_credentials" ("id")
+      ON UPDATE CASCADE
+      ON DELETE SET NULL,
+  ADD COLUMN "from_identity" varchar(255) NULL
+    REFERENCES "user_identities" ("id")
+      ON UPDATE CASCADE
+      ON DELETE SET NULL,
+  ADD COLUMN "client_id" varchar(255) NULL
+    REFERENCES "oauth_clients" ("id")
+      ON UPDATE CASCADE
+      ON DELETE CASCADE,
+  ADD COLUMN "scope" varchar(255) NULL,
+  ADD COLUMN "refresh_token_hash" varchar(255) NULL UNIQUE;
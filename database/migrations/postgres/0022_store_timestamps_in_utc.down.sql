@@ -0,0 +1,110 @@
+ALTER TABLE "admins"
+  ALTER COLUMN "created_at" DROP DEFAULT,
+  ALTER COLUMN "created_at" TYPE varchar(19) USING to_char(("created_at" AT TIME ZONE 'UTC') AT TIME ZONE current_setting('TimeZone'), 'YYYY-MM-DD HH24:MI:SS'),
+  ALTER COLUMN "created_at" SET DEFAULT to_char(LOCALTIMESTAMP, 'YYYY-MM-DD HH24:MI:SS');
+
+ALTER TABLE "roles"
+  ALTER COLUMN "created_at" DROP DEFAULT,
+  ALTER COLUMN "created_at" TYPE varchar(19) USING to_char(("created_at" AT TIME ZONE 'UTC') AT TIME ZONE current_setting('TimeZone'), 'YYYY-MM-DD HH24:MI:SS'),
+  ALTER COLUMN "created_at" SET DEFAULT to_char(LOCALTIMESTAMP, 'YYYY-MM-DD HH24:MI:SS');
+
+ALTER TABLE "admin_tokens"
+  ALTER COLUMN "last_activity" DROP DEFAULT,
+  ALTER COLUMN "last_activity" TYPE varchar(19) USING to_char(("last_activity" AT TIME ZONE 'UTC') AT TIME ZONE current_setting('TimeZone'), 'YYYY-MM-DD HH24:MI:SS'),
+  ALTER COLUMN "last_activity" SET DEFAULT to_char(LOCALTIMESTAMP, 'YYYY-MM-DD HH24:MI:SS'),
+  ALTER COLUMN "expires_at" DROP DEFAULT,
+  ALTER COLUMN "expires_at" TYPE varchar(19) USING to_char(("expires_at" AT TIME ZONE 'UTC') AT TIME ZONE current_setting('TimeZone'), 'YYYY-MM-DD HH24:MI:SS'),
+  ALTER COLUMN "expires_at" SET DEFAULT to_char(LOCALTIMESTAMP, 'YYYY-MM-DD HH24:MI:SS'),
+  ALTER COLUMN "created_at" DROP DEFAULT,
+  ALTER COLUMN "created_at" TYPE varchar(19) USING to_char(("created_at" AT TIME ZONE 'UTC') AT TIME ZONE current_setting('TimeZone'), 'YYYY-MM-DD HH24:MI:SS'),
+  ALTER COLUMN "created_at" SET DEFAULT to_char(LOCALTIMESTAMP, 'YYYY-MM-DD HH24:MI:SS');
+
+ALTER TABLE "admin_two_factor"
+  ALTER COLUMN "created_at" DROP DEFAULT,
+  ALTER COLUMN "created_at" TYPE varchar(19) USING to_char(("created_at" AT TIME ZONE 'UTC') AT TIME ZONE current_setting('TimeZone'), 'YYYY-MM-DD HH24:MI:SS'),
+  ALTER COLUMN "created_at" SET DEFAULT to_char(LOCALTIMESTAMP, 'YYYY-MM-DD HH24:MI:SS');
+
+ALTER TABLE "admin_recovery_codes"
+  ALTER COLUMN "used_at" DROP DEFAULT,
+  ALTER COLUMN "used_at" TYPE varchar(19) USING to_char(("used_at" AT TIME ZONE 'UTC') AT TIME ZONE current_setting('TimeZone'), 'YYYY-MM-DD HH24:MI:SS'),
+  ALTER COLUMN "created_at" DROP DEFAULT,
+  ALTER COLUMN "created_at" TYPE varchar(19) USING to_char(("created_at" AT TIME ZONE 'UTC') AT TIME ZONE current_setting('TimeZone'), 'YYYY-MM-DD HH24:MI:SS'),
+  ALTER COLUMN "created_at" SET DEFAULT to_char(LOCALTIMESTAMP, 'YYYY-MM-DD HH24:MI:SS');
+
+ALTER TABLE "users"
+  ALTER COLUMN "created_at" DROP DEFAULT,
+  ALTER COLUMN "created_at" TYPE varchar(19) USING to_char(("created_at" AT TIME ZONE 'UTC') AT TIME ZONE current_setting('TimeZone'), 'YYYY-MM-DD HH24:MI:SS'),
+  ALTER COLUMN "created_at" SET DEFAULT to_char(LOCALTIMESTAMP, 'YYYY-MM-DD HH24:MI:SS');
+
+ALTER TABLE "login_tokens"
+  ALTER COLUMN "expires_at" DROP DEFAULT,
+  ALTER COLUMN "expires_at" TYPE varchar(19) USING to_char(("expires_at" AT TIME ZONE 'UTC') AT TIME ZONE current_setting('TimeZone'), 'YYYY-MM-DD HH24:MI:SS'),
+  ALTER COLUMN "expires_at" SET DEFAULT to_char(LOCALTIMESTAMP, 'YYYY-MM-DD HH24:MI:SS'),
+  ALTER COLUMN "created_at" DROP DEFAULT,
+  ALTER COLUMN "created_at" TYPE varchar(19) USING to_char(("created_at" AT TIME ZONE 'UTC') AT TIME ZONE current_setting('TimeZone'), 'YYYY-MM-DD HH24:MI:SS'),
+  ALTER COLUMN "created_at" SET DEFAULT to_char(LOCALTIMESTAMP, 'YYYY-MM-DD HH24:MI:SS');
+
+ALTER TABLE "oauth_clients"
+  ALTER COLUMN "created_at" DROP DEFAULT,
+  ALTER COLUMN "created_at" TYPE varchar(19) USING to_char(("created_at" AT TIME ZONE 'UTC') AT TIME ZONE current_setting('TimeZone'), 'YYYY-MM-DD HH24:MI:SS'),
+  ALTER COLUMN "created_at" SET DEFAULT to_char(LOCALTIMESTAMP, 'YYYY-MM-DD HH24:MI:SS');
+
+ALTER TABLE "oauth_client_tokens"
+  ALTER COLUMN "expires_at" DROP DEFAULT,
+  ALTER COLUMN "expires_at" TYPE varchar(19) USING to_char(("expires_at" AT TIME ZONE 'UTC') AT TIME ZONE current_setting('TimeZone'), 'YYYY-MM-DD HH24:MI:SS'),
+  ALTER COLUMN "expires_at" SET DEFAULT to_char(LOCALTIMESTAMP, 'YYYY-MM-DD HH24:MI:SS'),
+  ALTER COLUMN "created_at" DROP DEFAULT,
+  ALTER COLUMN "created_at" TYPE varchar(19) USING to_char(("created_at" AT TIME ZONE 'UTC') AT TIME ZONE current_setting('TimeZone'), 'YYYY-MM-DD HH24:MI:SS'),
+  ALTER COLUMN "created_at" SET DEFAULT to_char(LOCALTIMESTAMP, 'YYYY-MM-DD HH24:MI:SS');
+
+ALTER TABLE "user_credentials"
+  ALTER COLUMN "last_used_at" DROP DEFAULT,
+  ALTER COLUMN "last_used_at" TYPE varchar(19) USING to_char(("last_used_at" AT TIME ZONE 'UTC') AT TIME ZONE current_setting('TimeZone'), 'YYYY-MM-DD HH24:MI:SS'),
+  ALTER COLUMN "created_at" DROP DEFAULT,
+  ALTER COLUMN "created_at" TYPE varchar(19) USING to_char(("created_at" AT TIME ZONE 'UTC') AT TIME ZONE current_setting('TimeZone'), 'YYYY-MM-DD HH24:MI:SS'),
+  ALTER COLUMN "created_at" SET DEFAULT to_char(LOCALTIMESTAMP, 'YYYY-MM-DD HH24:MI:SS');
+
+ALTER TABLE "user_identities"
+  ALTER COLUMN "created_at" DROP DEFAULT,
+  ALTER COLUMN "created_at" TYPE varchar(19) USING to_char(("created_at" AT TIME ZONE 'UTC') AT TIME ZONE current_setting('TimeZone'), 'YYYY-MM-DD HH24:MI:SS'),
+  ALTER COLUMN "created_at" SET DEFAULT to_char(LOCALTIMESTAMP, 'YYYY-MM-DD HH24:MI:SS');
+
+ALTER TABLE "oauth_authorization_codes"
+  ALTER COLUMN "expires_at" DROP DEFAULT,
+  ALTER COLUMN "expires_at" TYPE varchar(19) USING to_char(("expires_at" AT TIME ZONE 'UTC') AT TIME ZONE current_setting('TimeZone'), 'YYYY-MM-DD HH24:MI:SS'),
+  ALTER COLUMN "expires_at" SET DEFAULT to_char(LOCALTIMESTAMP, 'YYYY-MM-DD HH24:MI:SS'),
+  ALTER COLUMN "created_at" DROP DEFAULT,
+  ALTER COLUMN "created_at" TYPE varchar(19) USING to_char(("created_at" AT TIME ZONE 'UTC') AT TIME ZONE current_setting('TimeZone'), 'YYYY-MM-DD HH24:MI:SS'),
+  ALTER COLUMN "created_at" SET DEFAULT to_char(LOCALTIMESTAMP, 'YYYY-MM-DD HH24:MI:SS');
+
+ALTER TABLE "oauth_consents"
+  ALTER COLUMN "created_at" DROP DEFAULT,
+  ALTER COLUMN "created_at" TYPE varchar(19) USING to_char(("created_at" AT TIME ZONE 'UTC') AT TIME ZONE current_setting('TimeZone'), 'YYYY-MM-DD HH24:MI:SS'),
+  ALTER COLUMN "created_at" SET DEFAULT to_char(LOCALTIMESTAMP, 'YYYY-MM-DD HH24:MI:SS');
+
+ALTER TABLE "oauth_states"
+  ALTER COLUMN "expires_at" DROP DEFAULT,
+  ALTER COLUMN "expires_at" TYPE varchar(19) USING to_char(("expires_at" AT TIME ZONE 'UTC') AT TIME ZONE current_setting('TimeZone'), 'YYYY-MM-DD HH24:MI:SS'),
+  ALTER COLUMN "expires_at" SET DEFAULT to_char(LOCALTIMESTAMP, 'YYYY-MM-DD HH24:MI:SS'),
+  ALTER COLUMN "created_at" DROP DEFAULT,
+  ALTER COLUMN "created_at" TYPE varchar(19) USING to_char(("created_at" AT TIME ZONE 'UTC') AT TIME ZONE current_setting('TimeZone'), 'YYYY-MM-DD HH24:MI:SS'),
+  ALTER COLUMN "created_at" SET DEFAULT to_char(LOCALTIMESTAMP, 'YYYY-MM-DD HH24:MI:SS');
+
+ALTER TABLE "webauthn_challenges"
+  ALTER COLUMN "expires_at" DROP DEFAULT,
+  ALTER COLUMN "expires_at" TYPE varchar(19) USING to_char(("expires_at" AT TIME ZONE 'UTC') AT TIME ZONE current_setting('TimeZone'), 'YYYY-MM-DD HH24:MI:SS'),
+  ALTER COLUMN "expires_at" SET DEFAULT to_char(LOCALTIMESTAMP, 'YYYY-MM-DD HH24:MI:SS'),
+  ALTER COLUMN "created_at" DROP DEFAULT,
+  ALTER COLUMN "created_at" TYPE varchar(19) USING to_char(("created_at" AT TIME ZONE 'UTC') AT TIME ZONE current_setting('TimeZone'), 'YYYY-MM-DD HH24:MI:SS'),
+  ALTER COLUMN "created_at" SET DEFAULT to_char(LOCALTIMESTAMP, 'YYYY-MM-DD HH24:MI:SS');
+
+ALTER TABLE "user_tokens"
+  ALTER COLUMN "last_activity" DROP DEFAULT,
+  ALTER COLUMN "last_activity" TYPE varchar(19) USING to_char(("last_activity" AT TIME ZONE 'UTC') AT TIME ZONE current_setting('TimeZone'), 'YYYY-MM-DD HH24:MI:SS'),
+  ALTER COLUMN "last_activity" SET DEFAULT to_char(LOCALTIMESTAMP, 'YYYY-MM-DD HH24:MI:SS'),
+  ALTER COLUMN "expires_at" DROP DEFAULT,
+  ALTER COLUMN "expires_at" TYPE varchar(19) USING to_char(("expires_at" AT TIME ZONE 'UTC') AT TIME ZONE current_setting('TimeZone'), 'YYYY-MM-DD HH24:MI:SS'),
+  ALTER COLUMN "expires_at" SET DEFAULT to_char(LOCALTIMESTAMP, 'YYYY-MM-DD HH24:MI:SS'),
+  ALTER COLUMN "created_at" DROP DEFAULT,
+  ALTER COLUMN "created_at" TYPE varchar(19) USING to_char(("created_at" AT TIME ZONE 'UTC') AT TIME ZONE current_setting('TimeZone'), 'YYYY-MM-DD HH24:MI:SS'),
+  ALTER COLUMN "created_at" SET DEFAULT to_char(LOCALTIMESTAMP, 'YYYY-MM-DD HH24:MI:SS');
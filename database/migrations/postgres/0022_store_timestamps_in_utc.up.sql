@@ -0,0 +1,110 @@
+ALTER TABLE "admins"
+  ALTER COLUMN "created_at" DROP DEFAULT,
+  ALTER COLUMN "created_at" TYPE timestamp(0) USING ("created_at"::timestamp AT TIME ZONE current_setting('TimeZone')) AT TIME ZONE 'UTC',
+  ALTER COLUMN "created_at" SET DEFAULT (now() AT TIME ZONE 'UTC');
+
+ALTER TABLE "roles"
+  ALTER COLUMN "created_at" DROP DEFAULT,
+  ALTER COLUMN "created_at" TYPE timestamp(0) USING ("created_at"::timestamp AT TIME ZONE current_setting('TimeZone')) AT TIME ZONE 'UTC',
+  ALTER COLUMN "created_at" SET DEFAULT (now() AT TIME ZONE 'UTC');
+
+ALTER TABLE "admin_tokens"
+  ALTER COLUMN "last_activity" DROP DEFAULT,
+  ALTER COLUMN "last_activity" TYPE timestamp(0) USING ("last_activity"::timestamp AT TIME ZONE current_setting('TimeZone')) AT TIME ZONE 'UTC',
+  ALTER COLUMN "last_activity" SET DEFAULT (now() AT TIME ZONE 'UTC'),
+  ALTER COLUMN "expires_at" DROP DEFAULT,
+  ALTER COLUMN "expires_at" TYPE timestamp(0) USING ("expires_at"::timestamp AT TIME ZONE current_setting('TimeZone')) AT TIME ZONE 'UTC',
+  ALTER COLUMN "expires_at" SET DEFAULT (now() AT TIME ZONE 'UTC'),
+  ALTER COLUMN "created_at" DROP DEFAULT,
+  ALTER COLUMN "created_at" TYPE timestamp(0) USING ("created_at"::timestamp AT TIME ZONE current_setting('TimeZone')) AT TIME ZONE 'UTC',
+  ALTER COLUMN "created_at" SET DEFAULT (now() AT TIME ZONE 'UTC');
+
+ALTER TABLE "admin_two_factor"
+  ALTER COLUMN "created_at" DROP DEFAULT,
+  ALTER COLUMN "created_at" TYPE timestamp(0) USING ("created_at"::timestamp AT TIME ZONE current_setting('TimeZone')) AT TIME ZONE 'UTC',
+  ALTER COLUMN "created_at" SET DEFAULT (now() AT TIME ZONE 'UTC');
+
+ALTER TABLE "admin_recovery_codes"
+  ALTER COLUMN "used_at" DROP DEFAULT,
+  ALTER COLUMN "used_at" TYPE timestamp(0) USING ("used_at"::timestamp AT TIME ZONE current_setting('TimeZone')) AT TIME ZONE 'UTC',
+  ALTER COLUMN "created_at" DROP DEFAULT,
+  ALTER COLUMN "created_at" TYPE timestamp(0) USING ("created_at"::timestamp AT TIME ZONE current_setting('TimeZone')) AT TIME ZONE 'UTC',
+  ALTER COLUMN "created_at" SET DEFAULT (now() AT TIME ZONE 'UTC');
+
+ALTER TABLE "users"
+  ALTER COLUMN "created_at" DROP DEFAULT,
+  ALTER COLUMN "created_at" TYPE timestamp(0) USING ("created_at"::timestamp AT TIME ZONE current_setting('TimeZone')) AT TIME ZONE 'UTC',
+  ALTER COLUMN "created_at" SET DEFAULT (now() AT TIME ZONE 'UTC');
+
+ALTER TABLE "login_tokens"
+  ALTER COLUMN "expires_at" DROP DEFAULT,
+  ALTER COLUMN "expires_at" TYPE timestamp(0) USING ("expires_at"::timestamp AT TIME ZONE current_setting('TimeZone')) AT TIME ZONE 'UTC',
+  ALTER COLUMN "expires_at" SET DEFAULT (now() AT TIME ZONE 'UTC'),
+  ALTER COLUMN "created_at" DROP DEFAULT,
+  ALTER COLUMN "created_at" TYPE timestamp(0) USING ("created_at"::timestamp AT TIME ZONE current_setting('TimeZone')) AT TIME ZONE 'UTC',
+  ALTER COLUMN "created_at" SET DEFAULT (now() AT TIME ZONE 'UTC');
+
+ALTER TABLE "oauth_clients"
+  ALTER COLUMN "created_at" DROP DEFAULT,
+  ALTER COLUMN "created_at" TYPE timestamp(0) USING ("created_at"::timestamp AT TIME ZONE current_setting('TimeZone')) AT TIME ZONE 'UTC',
+  ALTER COLUMN "created_at" SET DEFAULT (now() AT TIME ZONE 'UTC');
+
+ALTER TABLE "oauth_client_tokens"
+  ALTER COLUMN "expires_at" DROP DEFAULT,
+  ALTER COLUMN "expires_at" TYPE timestamp(0) USING ("expires_at"::timestamp AT TIME ZONE current_setting('TimeZone')) AT TIME ZONE 'UTC',
+  ALTER COLUMN "expires_at" SET DEFAULT (now() AT TIME ZONE 'UTC'),
+  ALTER COLUMN "created_at" DROP DEFAULT,
+  ALTER COLUMN "created_at" TYPE timestamp(0) USING ("created_at"::timestamp AT TIME ZONE current_setting('TimeZone')) AT TIME ZONE 'UTC',
+  ALTER COLUMN "created_at" SET DEFAULT (now() AT TIME ZONE 'UTC');
+
+ALTER TABLE "user_credentials"
+  ALTER COLUMN "last_used_at" DROP DEFAULT,
+  ALTER COLUMN "last_used_at" TYPE timestamp(0) USING ("last_used_at"::timestamp AT TIME ZONE current_setting('TimeZone')) AT TIME ZONE 'UTC',
+  ALTER COLUMN "created_at" DROP DEFAULT,
+  ALTER COLUMN "created_at" TYPE timestamp(0) USING ("created_at"::timestamp AT TIME ZONE current_setting('TimeZone')) AT TIME ZONE 'UTC',
+  ALTER COLUMN "created_at" SET DEFAULT (now() AT TIME ZONE 'UTC');
+
+ALTER TABLE "user_identities"
+  ALTER COLUMN "created_at" DROP DEFAULT,
+  ALTER COLUMN "created_at" TYPE timestamp(0) USING ("created_at"::timestamp AT TIME ZONE current_setting('TimeZone')) AT TIME ZONE 'UTC',
+  ALTER COLUMN "created_at" SET DEFAULT (now() AT TIME ZONE 'UTC');
+
+ALTER TABLE "oauth_authorization_codes"
+  ALTER COLUMN "expires_at" DROP DEFAULT,
+  ALTER COLUMN "expires_at" TYPE timestamp(0) USING ("expires_at"::timestamp AT TIME ZONE current_setting('TimeZone')) AT TIME ZONE 'UTC',
+  ALTER COLUMN "expires_at" SET DEFAULT (now() AT TIME ZONE 'UTC'),
+  ALTER COLUMN "created_at" DROP DEFAULT,
+  ALTER COLUMN "created_at" TYPE timestamp(0) USING ("created_at"::timestamp AT TIME ZONE current_setting('TimeZone')) AT TIME ZONE 'UTC',
+  ALTER COLUMN "created_at" SET DEFAULT (now() AT TIME ZONE 'UTC');
+
+ALTER TABLE "oauth_consents"
+  ALTER COLUMN "created_at" DROP DEFAULT,
+  ALTER COLUMN "created_at" TYPE timestamp(0) USING ("created_at"::timestamp AT TIME ZONE current_setting('TimeZone')) AT TIME ZONE 'UTC',
+  ALTER COLUMN "created_at" SET DEFAULT (now() AT TIME ZONE 'UTC');
+
+ALTER TABLE "oauth_states"
+  ALTER COLUMN "expires_at" DROP DEFAULT,
+  ALTER COLUMN "expires_at" TYPE timestamp(0) USING ("expires_at"::timestamp AT TIME ZONE current_setting('TimeZone')) AT TIME ZONE 'UTC',
+  ALTER COLUMN "expires_at" SET DEFAULT (now() AT TIME ZONE 'UTC'),
+  ALTER COLUMN "created_at" DROP DEFAULT,
+  ALTER COLUMN "created_at" TYPE timestamp(0) USING ("created_at"::timestamp AT TIME ZONE current_setting('TimeZone')) AT TIME ZONE 'UTC',
+  ALTER COLUMN "created_at" SET DEFAULT (now() AT TIME ZONE 'UTC');
+
+ALTER TABLE "webauthn_challenges"
+  ALTER COLUMN "expires_at" DROP DEFAULT,
+  ALTER COLUMN "expires_at" TYPE timestamp(0) USING ("expires_at"::timestamp AT TIME ZONE current_setting('TimeZone')) AT TIME ZONE 'UTC',
+  ALTER COLUMN "expires_at" SET DEFAULT (now() AT TIME ZONE 'UTC'),
+  ALTER COLUMN "created_at" DROP DEFAULT,
+  ALTER COLUMN "created_at" TYPE timestamp(0) USING ("created_at"::timestamp AT TIME ZONE current_setting('TimeZone')) AT TIME ZONE 'UTC',
+  ALTER COLUMN "created_at" SET DEFAULT (now() AT TIME ZONE 'UTC');
+
+ALTER TABLE "user_tokens"
+  ALTER COLUMN "last_activity" DROP DEFAULT,
+  ALTER COLUMN "last_activity" TYPE timestamp(0) USING ("last_activity"::timestamp AT TIME ZONE current_setting('TimeZone')) AT TIME ZONE 'UTC',
+  ALTER COLUMN "last_activity" SET DEFAULT (now() AT TIME ZONE 'UTC'),
+  ALTER COLUMN "expires_at" DROP DEFAULT,
+  ALTER COLUMN "expires_at" TYPE timestamp(0) USING ("expires_at"::timestamp AT TIME ZONE current_setting('TimeZone')) AT TIME ZONE 'UTC',
+  ALTER COLUMN "expires_at" SET DEFAULT (now() AT TIME ZONE 'UTC'),
+  ALTER COLUMN "created_at" DROP DEFAULT,
+  ALTER COLUMN "created_at" TYPE timestamp(0) USING ("created_at"::timestamp AT TIME ZONE current_setting('TimeZone')) AT TIME ZONE 'UTC',
+  ALTER COLUMN "created_at" SET DEFAULT (now() AT TIME ZONE 'UTC');
@@ -0,0 +1,3 @@
+DROP INDEX "login_tokens_email";
+
+DROP INDEX "users_email_lower";
@@ -0,0 +1,7 @@
+UPDATE "users" SET "email" = LOWER(TRIM("email")) WHERE "email" <> LOWER(TRIM("email"));
+
+UPDATE "login_tokens" SET "email" = LOWER(TRIM("email")) WHERE "email" <> LOWER(TRIM("email"));
+
+CREATE UNIQUE INDEX "users_email_lower" ON "users" (LOWER("email"));
+
+CREATE INDEX "login_tokens_email" ON "login_tokens" ("email");
@@ -0,0 +1,14 @@
+CREATE TABLE "admins" (
+  "id" varchar(255) NOT NULL,
+  "name" varchar(255) NOT NULL,
+  "username" varchar(255) NOT NULL,
+  "password" varchar(255) NOT NULL,
+  "created_by" varchar(255) NULL,
+  "created_at" TEXT NOT NULL DEFAULT (datetime('now', 'localtime')),
+  PRIMARY KEY ("id"),
+  UNIQUE ("username"),
+  FOREIGN KEY ("created_by")
+    REFERENCES "admins" ("id")
+      ON UPDATE CASCADE
+      ON DELETE SET NULL
+);
@@ -0,0 +1,9 @@
+CREATE TABLE "email_settings" (
+  "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+  "host" varchar(255) NOT NULL DEFAULT '',
+  "port" varchar(255) NOT NULL DEFAULT '',
+  "username" varchar(255) NOT NULL DEFAULT '',
+  "password" varchar(255) NOT NULL DEFAULT ''
+);
+
+INSERT INTO "email_settings" DEFAULT VALUES;
@@ -0,0 +1,8 @@
+CREATE TABLE "users" (
+  "id" varchar(255) NOT NULL,
+  "email" varchar(255) NOT NULL,
+  "banned" boolean NOT NULL DEFAULT false,
+  "created_at" TEXT NOT NULL DEFAULT (datetime('now', 'localtime')),
+  PRIMARY KEY ("id"),
+  UNIQUE ("email")
+);
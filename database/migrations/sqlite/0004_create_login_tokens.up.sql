@@ -0,0 +1,11 @@
+CREATE TABLE "login_tokens" (
+  "id" varchar(255) NOT NULL,
+  "email" varchar(255) NOT NULL,
+  "ip_address" varchar(255) NOT NULL,
+  "device" varchar(255) NOT NULL,
+  "authorized" boolean NOT NULL DEFAULT false,
+  "denied" boolean NOT NULL DEFAULT false,
+  "expires_at" TEXT NOT NULL DEFAULT (datetime('now', 'localtime')),
+  "created_at" TEXT NOT NULL DEFAULT (datetime('now', 'localtime')),
+  PRIMARY KEY ("id")
+);
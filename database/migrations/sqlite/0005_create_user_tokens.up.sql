@@ -0,0 +1,26 @@
+CREATE TABLE "user_tokens" (
+  "id" varchar(255) NOT NULL,
+  "user_id" varchar(255) NOT NULL,
+  "from_login_token" varchar(255) NULL,
+  "from_user_token" varchar(255) NULL,
+  "ip_address" varchar(255) NOT NULL,
+  "device" varchar(255) NOT NULL,
+  "disconnected" boolean NOT NULL DEFAULT false,
+  "last_activity" TEXT NOT NULL DEFAULT (datetime('now', 'localtime')),
+  "expires_at" TEXT NOT NULL DEFAULT (datetime('now', 'localtime')),
+  "created_at" TEXT NOT NULL DEFAULT (datetime('now', 'localtime')),
+  PRIMARY KEY ("id"),
+  UNIQUE ("from_login_token"),
+  FOREIGN KEY ("user_id")
+    REFERENCES "users" ("id")
+      ON UPDATE CASCADE
+      ON DELETE CASCADE,
+  FOREIGN KEY ("from_login_token")
+    REFERENCES "login_tokens" ("id")
+      ON UPDATE CASCADE
+      ON DELETE SET NULL,
+  FOREIGN KEY ("from_user_token")
+    REFERENCES "user_tokens" ("id")
+      ON UPDATE CASCADE
+      ON DELETE SET NULL
+);
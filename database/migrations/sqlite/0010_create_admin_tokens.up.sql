@@ -0,0 +1,15 @@
+CREATE TABLE "admin_tokens" (
+  "id" varchar(255) NOT NULL,
+  "admin_id" varchar(255) NOT NULL,
+  "ip_address" varchar(255) NOT NULL,
+  "device" varchar(255) NOT NULL,
+  "disconnected" boolean NOT NULL DEFAULT false,
+  "last_activity" TEXT NOT NULL DEFAULT (datetime('now', 'localtime')),
+  "expires_at" TEXT NOT NULL DEFAULT (datetime('now', 'localtime')),
+  "created_at" TEXT NOT NULL DEFAULT (datetime('now', 'localtime')),
+  PRIMARY KEY ("id"),
+  FOREIGN KEY ("admin_id")
+    REFERENCES "admins" ("id")
+      ON UPDATE CASCADE
+      ON DELETE CASCADE
+);
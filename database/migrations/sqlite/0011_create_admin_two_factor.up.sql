@@ -0,0 +1,12 @@
+CREATE TABLE "admin_two_factor" (
+  "admin_id" varchar(255) NOT NULL,
+  "secret" varchar(255) NOT NULL,
+  "enabled" boolean NOT NULL DEFAULT false,
+  "last_used_step" bigint NOT NULL DEFAULT 0,
+  "created_at" TEXT NOT NULL DEFAULT (datetime('now', 'localtime')),
+  PRIMARY KEY ("admin_id"),
+  FOREIGN KEY ("admin_id")
+    REFERENCES "admins" ("id")
+      ON UPDATE CASCADE
+      ON DELETE CASCADE
+);
@@ -0,0 +1,12 @@
+CREATE TABLE "admin_recovery_codes" (
+  "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+  "admin_id" varchar(255) NOT NULL,
+  "code_hash" varchar(255) NOT NULL,
+  "used_at" TEXT NULL,
+  "created_at" TEXT NOT NULL DEFAULT (datetime('now', 'localtime')),
+  UNIQUE ("admin_id", "code_hash"),
+  FOREIGN KEY ("admin_id")
+    REFERENCES "admins" ("id")
+      ON UPDATE CASCADE
+      ON DELETE CASCADE
+);
@@ -0,0 +1,13 @@
+CREATE TABLE "oauth_client_tokens" (
+  "id" varchar(255) NOT NULL,
+  "client_id" varchar(255) NOT NULL,
+  "scope" varchar(255) NOT NULL DEFAULT '',
+  "revoked" boolean NOT NULL DEFAULT false,
+  "expires_at" TEXT NOT NULL DEFAULT (datetime('now', 'localtime')),
+  "created_at" TEXT NOT NULL DEFAULT (datetime('now', 'localtime')),
+  PRIMARY KEY ("id"),
+  FOREIGN KEY ("client_id")
+    REFERENCES "oauth_clients" ("id")
+      ON UPDATE CASCADE
+      ON DELETE CASCADE
+);
@@ -0,0 +1,16 @@
+CREATE TABLE "user_credentials" (
+  "id" varchar(255) NOT NULL,
+  "user_id" varchar(255) NOT NULL,
+  "credential_id" varchar(255) NOT NULL,
+  "public_key" text NOT NULL,
+  "sign_count" INTEGER NOT NULL DEFAULT 0,
+  "name" varchar(255) NOT NULL,
+  "last_used_at" TEXT NULL,
+  "created_at" TEXT NOT NULL DEFAULT (datetime('now', 'localtime')),
+  PRIMARY KEY ("id"),
+  UNIQUE ("credential_id"),
+  FOREIGN KEY ("user_id")
+    REFERENCES "users" ("id")
+      ON UPDATE CASCADE
+      ON DELETE CASCADE
+);
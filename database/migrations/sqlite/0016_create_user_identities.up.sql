@@ -0,0 +1,14 @@
+CREATE TABLE "user_identities" (
+  "id" varchar(255) NOT NULL,
+  "user_id" varchar(255) NOT NULL,
+  "provider" varchar(255) NOT NULL,
+  "subject" varchar(255) NOT NULL,
+  "email" varchar(255) NOT NULL,
+  "created_at" TEXT NOT NULL DEFAULT (datetime('now', 'localtime')),
+  PRIMARY KEY ("id"),
+  UNIQUE ("provider", "subject"),
+  FOREIGN KEY ("user_id")
+    REFERENCES "users" ("id")
+      ON UPDATE CASCADE
+      ON DELETE CASCADE
+);
@@ -0,0 +1,15 @@
+CREATE TABLE "oauth_consents" (
+  "user_id" varchar(255) NOT NULL,
+  "client_id" varchar(255) NOT NULL,
+  "scope" varchar(255) NOT NULL DEFAULT '',
+  "created_at" TEXT NOT NULL DEFAULT (datetime('now', 'localtime')),
+  PRIMARY KEY ("user_id", "client_id"),
+  FOREIGN KEY ("user_id")
+    REFERENCES "users" ("id")
+      ON UPDATE CASCADE
+      ON DELETE CASCADE,
+  FOREIGN KEY ("client_id")
+    REFERENCES "oauth_clients" ("id")
+      ON UPDATE CASCADE
+      ON DELETE CASCADE
+);
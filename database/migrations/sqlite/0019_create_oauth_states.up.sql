@@ -0,0 +1,9 @@
+CREATE TABLE "oauth_states" (
+  "state" varchar(255) NOT NULL,
+  "provider" varchar(255) NOT NULL,
+  "nonce" varchar(255) NOT NULL,
+  "code_verifier" varchar(255) NOT NULL,
+  "expires_at" TEXT NOT NULL DEFAULT (datetime('now', 'localtime')),
+  "created_at" TEXT NOT NULL DEFAULT (datetime('now', 'localtime')),
+  PRIMARY KEY ("state")
+);
@@ -0,0 +1,13 @@
+CREATE TABLE "webauthn_challenges" (
+  "id" varchar(255) NOT NULL,
+  "user_id" varchar(255) NULL,
+  "type" varchar(255) NOT NULL,
+  "challenge" varchar(255) NOT NULL,
+  "expires_at" TEXT NOT NULL DEFAULT (datetime('now', 'localtime')),
+  "created_at" TEXT NOT NULL DEFAULT (datetime('now', 'localtime')),
+  PRIMARY KEY ("id"),
+  FOREIGN KEY ("user_id")
+    REFERENCES "users" ("id")
+      ON UPDATE CASCADE
+      ON DELETE CASCADE
+);
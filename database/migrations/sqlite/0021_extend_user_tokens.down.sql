@@ -0,0 +1,35 @@
+PRAGMA defer_foreign_keys = ON;
+
+CREATE TABLE "user_tokens_legacy" (
+  "id" varchar(255) NOT NULL,
+  "user_id" varchar(255) NOT NULL,
+  "from_login_token" varchar(255) NULL,
+  "from_user_token" varchar(255) NULL,
+  "ip_address" varchar(255) NOT NULL,
+  "device" varchar(255) NOT NULL,
+  "disconnected" boolean NOT NULL DEFAULT false,
+  "last_activity" TEXT NOT NULL DEFAULT (datetime('now', 'localtime')),
+  "expires_at" TEXT NOT NULL DEFAULT (datetime('now', 'localtime')),
+  "created_at" TEXT NOT NULL DEFAULT (datetime('now', 'localtime')),
+  PRIMARY KEY ("id"),
+  UNIQUE ("from_login_token"),
+  FOREIGN KEY ("user_id")
+    REFERENCES "users" ("id")
+      ON UPDATE CASCADE
+      ON DELETE CASCADE,
+  FOREIGN KEY ("from_login_token")
+    REFERENCES "login_tokens" ("id")
+      ON UPDATE CASCADE
+      ON DELETE SET NULL,
+  FOREIGN KEY ("from_user_token")
+    REFERENCES "user_tokens_legacy" ("id")
+      ON UPDATE CASCADE
+      ON DELETE SET NULL
+);
+
+INSERT INTO "user_tokens_legacy" ("id", "user_id", "from_login_token", "from_user_token", "ip_address", "device", "disconnected", "last_activity", "expires_at", "created_at")
+  SELECT "id", "user_id", "from_login_token", "from_user_token", "ip_address", "device", "disconnected", "last_activity", "expires_at", "created_at" FROM "user_tokens";
+
+DROP TABLE "user_tokens";
+
+ALTER TABLE "user_tokens_legacy" RENAME TO "user_tokens";
@@ -0,0 +1,20 @@
+ALTER TABLE "user_tokens" ADD COLUMN "from_credential" varchar(255) NULL
+  REFERENCES "user_credentials" ("id")
+    ON UPDATE CASCADE
+    ON DELETE SET NULL;
+
+ALTER TABLE "user_tokens" ADD COLUMN "from_identity" varchar(255) NULL
+  REFERENCES "user_identities" ("id")
+    ON UPDATE CASCADE
+    ON DELETE SET NULL;
+
+ALTER TABLE "user_tokens" ADD COLUMN "client_id" varchar(255) NULL
+  REFERENCES "oauth_clients" ("id")
+    ON UPDATE CASCADE
+    ON DELETE CASCADE;
+
+ALTER TABLE "user_tokens" ADD COLUMN "scope" varchar(255) NULL;
+
+ALTER TABLE "user_tokens" ADD COLUMN "refresh_token_hash" varchar(255) NULL;
+
+CREATE UNIQUE INDEX "user_tokens_refresh_token_hash" ON "user_tokens" ("refresh_token_hash");
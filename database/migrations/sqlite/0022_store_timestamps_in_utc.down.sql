@@ -0,0 +1,58 @@
+UPDATE "admins" SET
+  "created_at" = datetime("created_at", 'localtime');
+
+UPDATE "roles" SET
+  "created_at" = datetime("created_at", 'localtime');
+
+UPDATE "admin_tokens" SET
+  "last_activity" = datetime("last_activity", 'localtime'),
+  "expires_at" = datetime("expires_at", 'localtime'),
+  "created_at" = datetime("created_at", 'localtime');
+
+UPDATE "admin_two_factor" SET
+  "created_at" = datetime("created_at", 'localtime');
+
+UPDATE "admin_recovery_codes" SET
+  "used_at" = datetime("used_at", 'localtime'),
+  "created_at" = datetime("created_at", 'localtime');
+
+UPDATE "users" SET
+  "created_at" = datetime("created_at", 'localtime');
+
+UPDATE "login_tokens" SET
+  "expires_at" = datetime("expires_at", 'localtime'),
+  "created_at" = datetime("created_at", 'localtime');
+
+UPDATE "oauth_clients" SET
+  "created_at" = datetime("created_at", 'localtime');
+
+UPDATE "oauth_client_tokens" SET
+  "expires_at" = datetime("expires_at", 'localtime'),
+  "created_at" = datetime("created_at", 'localtime');
+
+UPDATE "user_credentials" SET
+  "last_used_at" = datetime("last_used_at", 'localtime'),
+  "created_at" = datetime("created_at", 'localtime');
+
+UPDATE "user_identities" SET
+  "created_at" = datetime("created_at", 'localtime');
+
+UPDATE "oauth_authorization_codes" SET
+  "expires_at" = datetime("expires_at", 'localtime'),
+  "created_at" = datetime("created_at", 'localtime');
+
+UPDATE "oauth_consents" SET
+  "created_at" = datetime("created_at", 'localtime');
+
+UPDATE "oauth_states" SET
+  "expires_at" = datetime("expires_at", 'localtime'),
+  "created_at" = datetime("created_at", 'localtime');
+
+UPDATE "webauthn_challenges" SET
+  "expires_at" = datetime("expires_at", 'localtime'),
+  "created_at" = datetime("created_at", 'localtime');
+
+UPDATE "user_tokens" SET
+  "last_activity" = datetime("last_activity", 'localtime'),
+  "expires_at" = datetime("expires_at", 'localtime'),
+  "created_at" = datetime("created_at", 'localtime');
@@ -0,0 +1,361 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/sandromai/go-http-server/config"
+)
+
+func TestEmbeddedMigrationsAreOrdered(t *testing.T) {
+	var names []string
+
+	for _, dialect := range []Dialect{&mysqlDialect{}, &postgresDialect{}, &sqliteDialect{}} {
+		migrations, err := Migrations(dialect)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(migrations) == 0 {
+			t.Fatalf("expected embedded %v migrations", dialect.Name())
+		}
+
+		for i, migration := range migrations {
+			if migration.Version != uint64(i+1) {
+				t.Errorf("%v migration %v has version %v, expected %v", dialect.Name(), migration, migration.Version, i+1)
+			}
+		}
+
+		if names == nil {
+			for _, migration := range migrations {
+				names = append(names, migration.String())
+			}
+
+			continue
+		}
+
+		if len(migrations) != len(names) {
+			t.Fatalf("%v has %v migrations, expected %v", dialect.Name(), len(migrations), len(names))
+		}
+
+		for i, migration := range migrations {
+			if migration.String() != names[i] {
+				t.Errorf("%v migration %v does not match %v", dialect.Name(), migration, names[i])
+			}
+		}
+	}
+}
+
+func TestLoadMigrationsRejectsInvalidSets(t *testing.T) {
+	tests := map[string]fstest.MapFS{
+		"missing down": {
+			"migrations/0001_create_users.up.sql": {Data: []byte("CREATE TABLE `users` (`id` int);")},
+		},
+		"invalid name": {
+			"migrations/create_users.sql": {Data: []byte("CREATE TABLE `users` (`id` int);")},
+		},
+		"duplicate version": {
+			"migrations/0001_create_users.up.sql":   {Data: []byte("CREATE TABLE `users` (`id` int);")},
+			"migrations/0001_create_users.down.sql": {Data: []byte("DROP TABLE `users`;")},
+			"migrations/0001_create_admins.up.sql":  {Data: []byte("CREATE TABLE `admins` (`id` int);")},
+		},
+	}
+
+	for name, files := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := loadMigrations(files, "migrations"); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	statements := splitStatements("CREATE TABLE `roles` (\n  `id` int\n);\n\nINSERT INTO `roles` VALUES\n  (1),\n  (2);\n")
+
+	if len(statements) != 2 {
+		t.Fatalf("got %v statements, expected 2: %q", len(statements), statements)
+	}
+
+	if statements[1] != "INSERT INTO `roles` VALUES\n  (1),\n  (2)" {
+		t.Errorf("unexpected statement %q", statements[1])
+	}
+}
+
+func TestSQLiteMigrationsRoundTrip(t *testing.T) {
+	dialect := &sqliteDialect{}
+
+	db, err := sql.Open(
+		dialect.DriverName(),
+		dialect.DataSourceName(config.Database{Name: filepath.Join(t.TempDir(), "app.db")}),
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	db.SetMaxOpenConns(1)
+
+	migrations, err := Migrations(dialect)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	migrator := &Migrator{
+		DB:          db,
+		Dialect:     dialect,
+		LockTimeout: time.Second,
+		Migrations:  migrations,
+	}
+
+	ctx := context.Background()
+
+	applied, err := migrator.Up(ctx)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(applied) != len(migrations) {
+		t.Fatalf("applied %v migrations, expected %v", len(applied), len(migrations))
+	}
+
+	if applied, err = migrator.Up(ctx); err != nil || len(applied) != 0 {
+		t.Fatalf("second run applied %v migrations with error %v", len(applied), err)
+	}
+
+	reverted, err := migrator.Down(ctx, 2)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(reverted) != 2 || reverted[0].Version != migrations[len(migrations)-1].Version {
+		t.Fatalf("unexpected reverted migrations %v", reverted)
+	}
+
+	statuses, err := migrator.Status(ctx)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pending := 0
+
+	for _, status := range statuses {
+		if !status.Applied {
+			pending++
+		}
+	}
+
+	if pending != 2 {
+		t.Fatalf("got %v pending migrations, expected 2", pending)
+	}
+
+	reverted, err = migrator.Down(ctx, len(migrations))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(reverted) != len(migrations)-2 {
+		t.Fatalf("reverted %v migrations, expected %v", len(reverted), len(migrations)-2)
+	}
+}
+
+func openSQLite(
+	t *testing.T,
+	name string,
+) *sql.DB {
+	t.Helper()
+
+	dialect := &sqliteDialect{}
+
+	db, err := sql.Open(
+		dialect.DriverName(),
+		dialect.DataSourceName(config.Database{Name: name}),
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { db.Close() })
+
+	db.SetMaxOpenConns(1)
+
+	return db
+}
+
+func TestSQLiteLockExcludesOtherConnections(t *testing.T) {
+	dialect := &sqliteDialect{}
+	name := filepath.Join(t.TempDir(), "app.db")
+	ctx := context.Background()
+
+	var conns []*sql.Conn
+
+	for i := 0; i < 2; i++ {
+		conn, err := openSQLite(t, name).Conn(ctx)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		defer conn.Close()
+
+		conns = append(conns, conn)
+	}
+
+	unlock, err := dialect.Lock(ctx, conns[0], migrationLockName, time.Minute)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = dialect.Lock(ctx, conns[1], migrationLockName, 300*time.Millisecond); err == nil {
+		t.Fatal("expected the second connection to time out while the lock is held")
+	}
+
+	unlock()
+
+	unlock, err = dialect.Lock(ctx, conns[1], migrationLockName, 300*time.Millisecond)
+
+	if err != nil {
+		t.Fatalf("expected the lock to be free after unlocking: %v", err)
+	}
+
+	time.Sleep(400 * time.Millisecond)
+
+	if _, err = dialect.Lock(ctx, conns[0], migrationLockName, 300*time.Millisecond); err == nil {
+		t.Fatal("expected the lock to outlive the holder's wait timeout")
+	}
+
+	unlock()
+
+	expired := time.Now().UTC().Add(-time.Second).Format(lockTimeFormat)
+
+	_, err = conns[1].ExecContext(ctx, `INSERT INTO "schema_locks" ("name", "expires_at") VALUES(?, ?)`, migrationLockName, expired)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unlock, err = dialect.Lock(ctx, conns[0], migrationLockName, 300*time.Millisecond)
+
+	if err != nil {
+		t.Fatalf("expected the lock left behind by a crashed holder to be taken over: %v", err)
+	}
+
+	unlock()
+}
+
+func TestMigratorAdoptsBaseline(t *testing.T) {
+	dialect := &sqliteDialect{}
+	db := openSQLite(t, filepath.Join(t.TempDir(), "app.db"))
+	ctx := context.Background()
+
+	migrations, err := Migrations(dialect)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, migration := range migrations[:baselineVersion] {
+		for _, statement := range splitStatements(migration.Up) {
+			if _, err = db.ExecContext(ctx, statement); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	_, err = db.ExecContext(ctx, `INSERT INTO "admins" ("id", "name", "username", "password") VALUES('admin', 'Admin', 'admin', 'hash')`)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	migrator := &Migrator{
+		DB:          db,
+		Dialect:     dialect,
+		LockTimeout: time.Second,
+		Migrations:  migrations,
+	}
+
+	applied, err := migrator.Up(ctx)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(applied) != len(migrations)-baselineVersion || applied[0].Version != baselineVersion+1 {
+		t.Fatalf("applied %v, expected every migration after the baseline", applied)
+	}
+
+	statuses, err := migrator.Status(ctx)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, status := range statuses {
+		if !status.Applied {
+			t.Fatalf("expected migration %v to be recorded", status.Migration)
+		}
+	}
+
+	admins := 0
+
+	if err = db.QueryRowContext(ctx, `SELECT COUNT(*) FROM "admins"`).Scan(&admins); err != nil || admins != 1 {
+		t.Fatalf("expected the existing admin to be kept, got %v admins and error %v", admins, err)
+	}
+
+	roleId := ""
+
+	if err = db.QueryRowContext(ctx, `SELECT "role_id" FROM "admin_roles" WHERE "admin_id" = 'admin'`).Scan(&roleId); err != nil || roleId != "super-admin" {
+		t.Fatalf("expected the existing admin to become super-admin, got %q and error %v", roleId, err)
+	}
+}
+
+func TestMigratorReportsBaselineCheckErrors(t *testing.T) {
+	dialect := &sqliteDialect{}
+	db := openSQLite(t, filepath.Join(t.TempDir(), "app.db"))
+	ctx := context.Background()
+
+	_, err := db.ExecContext(ctx, `CREATE VIEW "admins" AS SELECT 1 AS "id" WHERE abs(-9223372036854775808) > 0`)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	migrations, err := Migrations(dialect)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	migrator := &Migrator{
+		DB:          db,
+		Dialect:     dialect,
+		LockTimeout: time.Second,
+		Migrations:  migrations,
+	}
+
+	if applied, err := migrator.Up(ctx); err == nil || !strings.Contains(err.Error(), "checking for a baseline schema failed") || len(applied) != 0 {
+		t.Fatalf("expected a failing baseline check to stop the migration, applied %v with error %v", applied, err)
+	}
+
+	recorded := 0
+
+	if err = db.QueryRowContext(ctx, `SELECT COUNT(*) FROM "schema_migrations"`).Scan(&recorded); err != nil || recorded != 0 {
+		t.Fatalf("expected no migration to be recorded, got %v and error %v", recorded, err)
+	}
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/sandromai/go-http-server/clock"
+	"github.com/sandromai/go-http-server/config"
+	"github.com/sandromai/go-http-server/metrics"
+	"github.com/sandromai/go-http-server/middlewares"
+	"github.com/sandromai/go-http-server/models"
+	"github.com/sandromai/go-http-server/oidc"
+	"github.com/sandromai/go-http-server/ratelimit"
+	"github.com/sandromai/go-http-server/ratelimit/memory"
+	"github.com/sandromai/go-http-server/router"
+	"github.com/sandromai/go-http-server/routes"
+	"github.com/sandromai/go-http-server/token"
+	"github.com/sandromai/go-http-server/types"
+	"github.com/sandromai/go-http-server/utils"
+	"github.com/sandromai/go-http-server/webauthn"
+)
+
+type application struct {
+	Config       *config.Config
+	Repositories *models.Repositories
+	Health       models.HealthChecker
+	Tokens       *token.Engine
+	Providers    map[string]*oidc.Client
+	NewMailer    func(emailSettings *types.EmailSetting) utils.MailSender
+	Clock        clock.Clock
+	Logger       *slog.Logger
+	Metrics      *metrics.App
+	RateLimits   ratelimit.Backend
+}
+
+func (app *application) handler() http.Handler {
+	appConfig := app.Config
+	repositories := app.Repositories
+	tokenEngine := app.Tokens
+	appMetrics := app.Metrics
+
+	if appMetrics == nil {
+		appMetrics = metrics.NewApp()
+	}
+
+	authenticator := &middlewares.Authenticator{
+		Repositories: repositories,
+		Tokens:       tokenEngine,
+		Lifetimes:    &appConfig.Lifetimes,
+		Clock:        app.Clock,
+		Metrics:      appMetrics,
+	}
+
+	rateLimitConfig := appConfig.RateLimit
+
+	rateLimit := &middlewares.RateLimit{
+		Metrics: appMetrics,
+	}
+
+	if rateLimitConfig.Enabled {
+		rateLimitBackend := app.RateLimits
+
+		if rateLimitBackend == nil {
+			rateLimitBackend = memory.NewStore()
+		}
+
+		rateLimit.Limiter = &ratelimit.Limiter{
+			Backend: rateLimitBackend,
+			Clock:   app.Clock,
+		}
+	}
+
+	appRouter := &router.Router{}
+
+	accessLog := &middlewares.AccessLog{
+		Logger: app.Logger,
+	}
+
+	requestMetrics := &middlewares.RequestMetrics{
+		Metrics: appMetrics,
+	}
+
+	appRouter.Use(
+		middlewares.Trace,
+		accessLog.Handle,
+		requestMetrics.Handle,
+		middlewares.LimitBody(int64(app.Config.Server.MaxBodyBytes)),
+	)
+
+	appRouter.HandleFunc("GET /", func(writer http.ResponseWriter, request *http.Request) {
+		writer.Write([]byte("<h1>Hello world!</h1>"))
+	})
+
+	healthRoutes := &routes.Health{
+		Database: app.Health,
+	}
+
+	appRouter.HandleFunc("GET /health/live", healthRoutes.Live)
+	appRouter.HandleFunc("GET /health/ready", healthRoutes.Ready)
+
+	metricsRoutes := &routes.Metrics{
+		Registry: appMetrics.Registry,
+	}
+
+	appRouter.HandleFunc("GET /metrics", metricsRoutes.List)
+
+	jwksRoutes := &routes.JWKS{
+		Tokens: tokenEngine,
+	}
+
+	appRouter.HandleFunc("GET /.well-known/jwks.json", jwksRoutes.List)
+
+	apiRoutes := appRouter.Group("/routes")
+
+	adminRoutes := &routes.Admin{
+		Repositories:     repositories,
+		Tokens:           tokenEngine,
+		Lifetimes:        &appConfig.Lifetimes,
+		Clock:            app.Clock,
+		Metrics:          appMetrics,
+		RateLimit:        rateLimit,
+		PasswordLockout:  lockout("adminPasswordLockout", rateLimitConfig.AdminLockout),
+		TwoFactorLockout: lockout("adminTwoFactorLockout", rateLimitConfig.AdminLockout),
+	}
+
+	adminGroup := apiRoutes.Group("/admins")
+
+	adminGroup.HandleFunc("POST /login", adminRoutes.Login, rateLimit.PerIP(rateLimitRule("adminLogin", rateLimitConfig.AdminLogin)))
+	adminGroup.HandleFunc("POST /login/verify", adminRoutes.VerifyTwoFactor, rateLimit.PerIP(rateLimitRule("adminTwoFactor", rateLimitConfig.AdminTwoFactor)))
+	adminGroup.HandleFunc("POST /register", adminRoutes.Register, authenticator.AuthenticateAdmin(types.PermissionAdminsRegister))
+	adminGroup.HandleFunc("PUT /update", adminRoutes.Update, authenticator.AuthenticateAdmin())
+	adminGroup.HandleFunc("PUT /{id}/roles", adminRoutes.SetRoles, authenticator.AuthenticateAdmin(types.PermissionAdminsRoles))
+
+	adminTwoFactorRoutes := &routes.AdminTwoFactor{
+		Repositories: repositories,
+		Issuer:       appConfig.Security.TwoFactorIssuer,
+		Clock:        app.Clock,
+	}
+
+	adminTwoFactorGroup := adminGroup.Group("/twoFactor", authenticator.AuthenticateAdmin())
+
+	adminTwoFactorGroup.HandleFunc("POST /enroll", adminTwoFactorRoutes.Enroll)
+	adminTwoFactorGroup.HandleFunc("POST /confirm", adminTwoFactorRoutes.Confirm)
+	adminTwoFactorGroup.HandleFunc("POST /disable", adminTwoFactorRoutes.Disable)
+
+	adminTokenRoutes := &routes.AdminToken{
+		Repositories: repositories,
+	}
+
+	adminTokenGroup := apiRoutes.Group("/adminTokens", authenticator.AuthenticateAdmin())
+
+	adminTokenGroup.HandleFunc("GET /", adminTokenRoutes.List)
+	adminTokenGroup.HandleFunc("PATCH /{id}/disconnect", adminTokenRoutes.Disconnect)
+
+	roleRoutes := &routes.Role{
+		Repositories: repositories,
+	}
+
+	roleGroup := apiRoutes.Group("/roles", authenticator.AuthenticateAdmin(types.PermissionRolesManage))
+
+	roleGroup.HandleFunc("GET /", roleRoutes.List)
+	roleGroup.HandleFunc("GET /permissions", roleRoutes.ListPermissions)
+	roleGroup.HandleFunc("POST /", roleRoutes.Create)
+	roleGroup.HandleFunc("PUT /{id}", roleRoutes.Update)
+	roleGroup.HandleFunc("DELETE /{id}", roleRoutes.Delete)
+
+	emailSettingRoutes := &routes.EmailSetting{
+		Repositories: repositories,
+	}
+
+	emailSettingGroup := apiRoutes.Group("/emailSettings")
+
+	emailSettingGroup.HandleFunc("GET /list", emailSettingRoutes.List, authenticator.AuthenticateAdmin(types.PermissionEmailSettingsList))
+	emailSettingGroup.HandleFunc("PUT /update", emailSettingRoutes.Update, authenticator.AuthenticateAdmin(types.PermissionEmailSettingsUpdate))
+
+	loginTokenRoutes := &routes.LoginToken{
+		Repositories: repositories,
+		Template:     loginTokenTemplate,
+		Tokens:       tokenEngine,
+		Mail:         &appConfig.Mail,
+		Lifetimes:    &appConfig.Lifetimes,
+		NewMailer:    app.NewMailer,
+		Clock:        app.Clock,
+		Metrics:      appMetrics,
+		RateLimit:    rateLimit,
+		EmailRule:    rateLimitRule("loginTokenEmail", rateLimitConfig.LoginTokenEmail),
+	}
+
+	loginTokenGroup := apiRoutes.Group("/loginTokens")
+
+	loginTokenVerifyLimit := rateLimit.PerIP(rateLimitRule("loginTokenVerify", rateLimitConfig.LoginTokenVerify))
+
+	loginTokenGroup.HandleFunc("POST /create", loginTokenRoutes.Create, rateLimit.PerIP(rateLimitRule("loginTokenCreate", rateLimitConfig.LoginTokenCreate)))
+	loginTokenGroup.HandleFunc("POST /check", loginTokenRoutes.Check, loginTokenVerifyLimit)
+	loginTokenGroup.HandleFunc("POST /deny", loginTokenRoutes.Deny, loginTokenVerifyLimit)
+	loginTokenGroup.HandleFunc("POST /authorize", loginTokenRoutes.Authorize, loginTokenVerifyLimit)
+
+	userRoutes := &routes.User{
+		Repositories: repositories,
+		Metrics:      appMetrics,
+	}
+
+	userGroup := apiRoutes.Group("/users")
+
+	userGroup.HandleFunc("GET /authenticate", userRoutes.Authenticate, authenticator.AuthenticateUser)
+	userGroup.HandleFunc("PATCH /{id}/ban", userRoutes.Ban, authenticator.AuthenticateAdmin(types.PermissionUsersBan))
+	userGroup.HandleFunc("PATCH /{id}/unban", userRoutes.Unban, authenticator.AuthenticateAdmin(types.PermissionUsersBan))
+
+	webAuthnRoutes := &routes.WebAuthn{
+		Repositories: repositories,
+		Tokens:       tokenEngine,
+		RelyingParty: &webauthn.RelyingParty{
+			Id:      appConfig.WebAuthn.RelyingPartyId,
+			Name:    appConfig.WebAuthn.RelyingPartyName,
+			Origins: appConfig.WebAuthn.Origins,
+		},
+		Lifetimes: &appConfig.Lifetimes,
+		Clock:     app.Clock,
+	}
+
+	webAuthnGroup := apiRoutes.Group("/webAuthn")
+
+	webAuthnGroup.HandleFunc("POST /registration/options", webAuthnRoutes.RegistrationOptions, authenticator.AuthenticateUser)
+	webAuthnGroup.HandleFunc("POST /registration", webAuthnRoutes.Register, authenticator.AuthenticateUser)
+	webAuthnGroup.HandleFunc("POST /authentication/options", webAuthnRoutes.AuthenticationOptions)
+	webAuthnGroup.HandleFunc("POST /authentication", webAuthnRoutes.Authenticate)
+
+	oauthRoutes := &routes.OAuth{
+		Repositories: repositories,
+		Tokens:       tokenEngine,
+		Providers:    app.Providers,
+		Lifetimes:    &appConfig.Lifetimes,
+		Clock:        app.Clock,
+	}
+
+	oauthGroup := apiRoutes.Group("/oauth")
+
+	oauthGroup.HandleFunc("GET /providers", oauthRoutes.ListProviders)
+	oauthGroup.HandleFunc("POST /{provider}/authorize", oauthRoutes.Authorize)
+	oauthGroup.HandleFunc("POST /{provider}/callback", oauthRoutes.Callback)
+
+	oauthServerRoutes := &routes.OAuthServer{
+		Repositories:  repositories,
+		Authenticator: authenticator,
+		Lifetimes:     &appConfig.Lifetimes,
+		Clock:         app.Clock,
+	}
+
+	oauthServerGroup := apiRoutes.Group("/oauth2")
+
+	oauthServerGroup.HandleFunc("GET /authorize", oauthServerRoutes.AuthorizationDetails, authenticator.AuthenticateUser)
+	oauthServerGroup.HandleFunc("POST /authorize", oauthServerRoutes.Authorize, authenticator.AuthenticateUser)
+	oauthServerGroup.HandleFunc("POST /token", oauthServerRoutes.Token)
+	oauthServerGroup.HandleFunc("POST /introspect", oauthServerRoutes.Introspect)
+	oauthServerGroup.HandleFunc("POST /revoke", oauthServerRoutes.Revoke)
+	oauthServerGroup.HandleFunc("GET /userinfo", oauthServerRoutes.UserInfo, authenticator.AuthenticateOAuth(types.OAuthScopeProfile))
+
+	oauthClientRoutes := &routes.OAuthClient{
+		Repositories: repositories,
+	}
+
+	oauthClientGroup := apiRoutes.Group("/oauthClients", authenticator.AuthenticateAdmin(types.PermissionOAuthClientsManage))
+
+	oauthClientGroup.HandleFunc("GET /", oauthClientRoutes.List)
+	oauthClientGroup.HandleFunc("POST /", oauthClientRoutes.Create)
+	oauthClientGroup.HandleFunc("DELETE /{id}", oauthClientRoutes.Delete)
+
+	userCredentialRoutes := &routes.UserCredential{
+		Repositories: repositories,
+	}
+
+	userCredentialGroup := apiRoutes.Group("/userCredentials", authenticator.AuthenticateUser)
+
+	userCredentialGroup.HandleFunc("GET /", userCredentialRoutes.List)
+	userCredentialGroup.HandleFunc("DELETE /{id}", userCredentialRoutes.Delete)
+
+	userTokenRoutes := &routes.UserToken{
+		Repositories: repositories,
+	}
+
+	userTokenGroup := apiRoutes.Group("/userTokens", authenticator.AuthenticateUser)
+
+	userTokenGroup.HandleFunc("PATCH /{id}/disconnect", userTokenRoutes.Disconnect)
+
+	return appRouter
+}
+
+func rateLimitRule(
+	name string,
+	rule config.RateLimitRule,
+) ratelimit.Rule {
+	return ratelimit.Rule{
+		Name:      name,
+		Algorithm: ratelimit.Algorithm(rule.Algorithm),
+		Limit:     rule.Limit,
+		Window:    rule.Window,
+	}
+}
+
+func lockout(
+	name string,
+	settings config.Lockout,
+) ratelimit.Lockout {
+	return ratelimit.Lockout{
+		Name:        name,
+		Threshold:   settings.Threshold,
+		Window:      settings.Window,
+		Duration:    settings.Duration,
+		MaxDuration: settings.MaxDuration,
+	}
+}
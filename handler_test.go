@@ -0,0 +1,421 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/sandromai/go-http-server/clock"
+	"github.com/sandromai/go-http-server/config"
+	"github.com/sandromai/go-http-server/metrics"
+	"github.com/sandromai/go-http-server/models"
+	"github.com/sandromai/go-http-server/models/memory"
+	"github.com/sandromai/go-http-server/oidc"
+	"github.com/sandromai/go-http-server/token"
+	"github.com/sandromai/go-http-server/types"
+	"github.com/sandromai/go-http-server/utils"
+)
+
+const (
+	testAdminUsername = "root"
+	testAdminPassword = "root-password"
+)
+
+var loginTokenLink = regexp.MustCompile(`loginToken=([A-Za-z0-9_\-.]+)`)
+
+type sentMail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type fakeMailer struct {
+	mutex    sync.Mutex
+	messages []sentMail
+	fail     bool
+}
+
+func (mailer *fakeMailer) Send(
+	ctx context.Context,
+	fromEmail,
+	fromName,
+	toEmail,
+	toName,
+	subject,
+	body string,
+) *types.AppError {
+	mailer.mutex.Lock()
+
+	defer mailer.mutex.Unlock()
+
+	if mailer.fail {
+		return &types.AppError{
+			StatusCode: 500,
+			Message:    "Error sending email.",
+		}
+	}
+
+	mailer.messages = append(mailer.messages, sentMail{
+		To:      toEmail,
+		Subject: subject,
+		Body:    body,
+	})
+
+	return nil
+}
+
+func (mailer *fakeMailer) last(
+	t *testing.T,
+) sentMail {
+	t.Helper()
+
+	mailer.mutex.Lock()
+
+	defer mailer.mutex.Unlock()
+
+	if len(mailer.messages) == 0 {
+		t.Fatal("expected a sent email")
+	}
+
+	return mailer.messages[len(mailer.messages)-1]
+}
+
+func (mailer *fakeMailer) loginToken(
+	t *testing.T,
+) string {
+	t.Helper()
+
+	matches := loginTokenLink.FindStringSubmatch(mailer.last(t).Body)
+
+	if matches == nil {
+		t.Fatal("expected a login token link in the email body")
+	}
+
+	return matches[1]
+}
+
+type testResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+func (response *testResponse) decode(
+	t *testing.T,
+	target any,
+) {
+	t.Helper()
+
+	if err := json.Unmarshal(response.Body, target); err != nil {
+		t.Fatalf("decoding %q: %v", response.Body, err)
+	}
+}
+
+func (response *testResponse) expect(
+	t *testing.T,
+	statusCode int,
+	message string,
+) {
+	t.Helper()
+
+	if response.StatusCode != statusCode {
+		t.Fatalf("got status %v with body %s, expected %v", response.StatusCode, response.Body, statusCode)
+	}
+
+	if message == "" {
+		return
+	}
+
+	var returnError types.ReturnError
+
+	response.decode(t, &returnError)
+
+	if returnError.Error != message {
+		t.Fatalf("got error %q, expected %q", returnError.Error, message)
+	}
+}
+
+type testServer struct {
+	t            *testing.T
+	server       *httptest.Server
+	config       *config.Config
+	repositories *models.Repositories
+	tokens       *token.Engine
+	clock        *clock.Manual
+	mailer       *fakeMailer
+	metrics      *metrics.App
+	providers    map[string]*oidc.Client
+}
+
+func newTestServer(
+	t *testing.T,
+) *testServer {
+	t.Helper()
+
+	return newTestServerWithBackend(t, func(appConfig *config.Config, appClock clock.Clock) models.Backend {
+		return memory.NewStore(appClock, appConfig.Security.PasswordCost)
+	})
+}
+
+func newTestServerWithBackend(
+	t *testing.T,
+	newBackend func(appConfig *config.Config, appClock clock.Clock) models.Backend,
+) *testServer {
+	t.Helper()
+
+	appConfig := config.Default()
+
+	appConfig.Security.EncryptionKey = "0123456789abcdef0123456789abcdef"
+	appConfig.Security.JWTKey = "test-signing-key-with-enough-entropy"
+	appConfig.Security.PasswordCost = bcrypt.MinCost
+
+	appClock := clock.NewManual(time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC))
+
+	store := newBackend(appConfig, appClock)
+
+	repositories := store.Repositories()
+
+	appErr := seedSuperAdmin(context.Background(), repositories, config.Admin{
+		Username: testAdminUsername,
+		Password: testAdminPassword,
+	})
+
+	if appErr != nil {
+		t.Fatal(appErr.Message)
+	}
+
+	tokenEngine, err := loadTokenEngine(appConfig.Security, appClock)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mailer := &fakeMailer{}
+
+	appMetrics := metrics.NewApp()
+
+	providers := map[string]*oidc.Client{}
+
+	app := &application{
+		Config:       appConfig,
+		Repositories: repositories,
+		Health:       store,
+		Tokens:       tokenEngine,
+		NewMailer: func(emailSettings *types.EmailSetting) utils.MailSender {
+			return mailer
+		},
+		Clock:     appClock,
+		Logger:    slog.New(slog.NewJSONHandler(io.Discard, nil)),
+		Metrics:   appMetrics,
+		Providers: providers,
+	}
+
+	server := httptest.NewServer(app.handler())
+
+	t.Cleanup(server.Close)
+
+	return &testServer{
+		t:            t,
+		server:       server,
+		config:       appConfig,
+		repositories: repositories,
+		tokens:       tokenEngine,
+		clock:        appClock,
+		mailer:       mailer,
+		metrics:      appMetrics,
+		providers:    providers,
+	}
+}
+
+func (server *testServer) request(
+	method,
+	path string,
+	body any,
+	header http.Header,
+) *testResponse {
+	server.t.Helper()
+
+	var requestBody io.Reader
+
+	if body != nil {
+		jsonBody, err := json.Marshal(body)
+
+		if err != nil {
+			server.t.Fatal(err)
+		}
+
+		requestBody = bytes.NewReader(jsonBody)
+	}
+
+	request, err := http.NewRequest(method, server.server.URL+path, requestBody)
+
+	if err != nil {
+		server.t.Fatal(err)
+	}
+
+	for key, values := range header {
+		request.Header[key] = values
+	}
+
+	return server.send(request)
+}
+
+func (server *testServer) send(
+	request *http.Request,
+) *testResponse {
+	server.t.Helper()
+
+	response, err := server.server.Client().Do(request)
+
+	if err != nil {
+		server.t.Fatal(err)
+	}
+
+	defer response.Body.Close()
+
+	responseBody, err := io.ReadAll(response.Body)
+
+	if err != nil {
+		server.t.Fatal(err)
+	}
+
+	return &testResponse{
+		StatusCode: response.StatusCode,
+		Header:     response.Header,
+		Body:       responseBody,
+	}
+}
+
+func bearer(
+	token string,
+) http.Header {
+	return http.Header{"Authorization": {"Bearer " + token}}
+}
+
+func (server *testServer) loginAdmin(
+	username,
+	password string,
+) string {
+	server.t.Helper()
+
+	response := server.request("POST", "/routes/admins/login", map[string]any{
+		"username": username,
+		"password": password,
+	}, nil)
+
+	response.expect(server.t, 200, "")
+
+	var result struct {
+		Token string `json:"token"`
+	}
+
+	response.decode(server.t, &result)
+
+	if result.Token == "" {
+		server.t.Fatalf("expected an admin token in %s", response.Body)
+	}
+
+	return result.Token
+}
+
+func (server *testServer) createLoginToken(
+	email string,
+) (string, string) {
+	server.t.Helper()
+
+	response := server.request("POST", "/routes/loginTokens/create", map[string]any{
+		"email": email,
+	}, nil)
+
+	response.expect(server.t, 200, "")
+
+	var result struct {
+		LoginTokenId string `json:"loginTokenId"`
+	}
+
+	response.decode(server.t, &result)
+
+	return result.LoginTokenId, server.mailer.loginToken(server.t)
+}
+
+type authenticatedUser struct {
+	User  *types.User `json:"user"`
+	Token string      `json:"token"`
+}
+
+func (server *testServer) loginUser(
+	email string,
+) *authenticatedUser {
+	server.t.Helper()
+
+	loginTokenId, loginToken := server.createLoginToken(email)
+
+	server.request("POST", "/routes/loginTokens/authorize", map[string]any{
+		"token": loginToken,
+	}, nil).expect(server.t, 200, "")
+
+	response := server.request("GET", "/routes/users/authenticate", nil, http.Header{
+		"X-Login-Token-Id": {loginTokenId},
+	})
+
+	response.expect(server.t, 200, "")
+
+	var result authenticatedUser
+
+	response.decode(server.t, &result)
+
+	if result.User == nil || result.Token == "" {
+		server.t.Fatalf("expected a user and a token in %s", response.Body)
+	}
+
+	return &result
+}
+
+func (server *testServer) userTokenId(
+	userToken string,
+) string {
+	server.t.Helper()
+
+	payload := &types.UserTokenPayload{}
+
+	if appErr := payload.FromJWT(server.tokens, userToken); appErr != nil {
+		server.t.Fatal(appErr.Message)
+	}
+
+	return payload.UserTokenId
+}
+
+func TestHealth(t *testing.T) {
+	forEachBackend(t, testHealth)
+}
+
+func testHealth(
+	t *testing.T,
+	server *testServer,
+) {
+	for _, path := range []string{"/health/live", "/health/ready"} {
+		response := server.request("GET", path, nil, nil)
+
+		response.expect(t, 200, "")
+
+		var status struct {
+			Status string `json:"status"`
+		}
+
+		response.decode(t, &status)
+
+		if status.Status != "ok" {
+			t.Errorf("%v returned status %q", path, status.Status)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sandromai/go-http-server/routes"
+	"github.com/sandromai/go-http-server/token"
+	"github.com/sandromai/go-http-server/types"
+)
+
+func TestJWKSEndpoint(t *testing.T) {
+	server := newTestServer(t)
+
+	response := server.request("GET", "/.well-known/jwks.json", nil, nil)
+
+	response.expect(t, 200, "")
+
+	if strings.TrimSpace(string(response.Body)) != `{"keys":[]}` {
+		t.Fatalf("expected an HMAC-only server to publish no keys, got %s", response.Body)
+	}
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signingKey := &token.ECDSAKey{Id: "current", PrivateKey: privateKey}
+
+	recorder := httptest.NewRecorder()
+
+	(&routes.JWKS{
+		Tokens: &token.Engine{
+			Signer:    signingKey,
+			Verifiers: []token.Verifier{signingKey, &token.HMACKey{Id: "legacy", Secret: []byte("secret")}},
+		},
+	}).List(recorder, httptest.NewRequest("GET", "/.well-known/jwks.json", nil))
+
+	if recorder.Code != 200 || recorder.Header().Get("Cache-Control") != "public, max-age=300" {
+		t.Fatalf("unexpected response %v %v", recorder.Code, recorder.Header())
+	}
+
+	var set struct {
+		Keys []map[string]string `json:"keys"`
+	}
+
+	if err = json.Unmarshal(recorder.Body.Bytes(), &set); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(set.Keys) != 1 || set.Keys[0]["kid"] != "current" || set.Keys[0]["alg"] != "ES256" || set.Keys[0]["d"] != "" {
+		t.Fatalf("unexpected key set %s", recorder.Body)
+	}
+}
+
+func TestTokenEngineEnforcesConfiguredIssuer(t *testing.T) {
+	server := newTestServer(t)
+
+	user := server.loginUser("user@example.com")
+
+	payload := &types.UserTokenPayload{}
+
+	if appErr := payload.FromJWT(server.tokens, user.Token); appErr != nil || payload.Issuer != server.config.Security.JWTIssuer {
+		t.Fatalf("expected the token to carry issuer %q, got %q and %+v", server.config.Security.JWTIssuer, payload.Issuer, appErr)
+	}
+
+	forged, appErr := (&types.UserTokenPayload{
+		RegisteredClaims: token.RegisteredClaims{
+			ExpiresAt: server.clock.Now().Add(time.Hour).Unix(),
+		},
+		UserTokenId: payload.UserTokenId,
+	}).ToJWT(&token.Engine{
+		Signer: server.tokens.Signer,
+		Issuer: "https://other.example.com",
+		Clock:  server.clock,
+	})
+
+	if appErr != nil {
+		t.Fatal(appErr.Message)
+	}
+
+	server.request("GET", "/routes/users/authenticate", nil, bearer(forged)).expect(t, 401, "Invalid token.")
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	security := server.config.Security
+
+	security.JWTSigningKeyFile = filepath.Join(t.TempDir(), "signing.pem")
+
+	if err = os.WriteFile(security.JWTSigningKeyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tokenEngine, err := loadTokenEngine(security, server.clock)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tokenEngine.Issuer != security.JWTIssuer {
+		t.Fatalf("expected the key file engine to check issuer %q, got %q", security.JWTIssuer, tokenEngine.Issuer)
+	}
+}
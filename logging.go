@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/sandromai/go-http-server/clock"
+	"github.com/sandromai/go-http-server/config"
+	"github.com/sandromai/go-http-server/utils"
+)
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+func openLogger(
+	settings config.Log,
+	appClock clock.Clock,
+) (*slog.Logger, io.Closer, error) {
+	var level slog.Level
+
+	if err := level.UnmarshalText([]byte(settings.Level)); err != nil {
+		return nil, nil, err
+	}
+
+	var writer io.Writer = os.Stdout
+	var closer io.Closer = nopCloser{}
+
+	if settings.Folder != "" {
+		fileLogger := &utils.Logger{
+			FolderPath: settings.Folder,
+			FileName:   settings.FileName,
+			MaxSize:    int64(settings.MaxSizeMB) * 1024 * 1024,
+			MaxFiles:   settings.MaxFiles,
+			Retention:  settings.Retention,
+			Clock:      appClock,
+		}
+
+		if appErr := fileLogger.Open(); appErr != nil {
+			return nil, nil, errors.New(appErr.Message)
+		}
+
+		writer, closer = fileLogger, fileLogger
+	}
+
+	logger := slog.New(slog.NewJSONHandler(writer, &slog.HandlerOptions{
+		Level: level,
+	}))
+
+	return logger, closer, nil
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoginTokenLifecycle(t *testing.T) {
+	forEachBackend(t, testLoginTokenLifecycle)
+}
+
+func testLoginTokenLifecycle(
+	t *testing.T,
+	server *testServer,
+) {
+	server.request("POST", "/routes/loginTokens/create", map[string]any{}, nil).expect(t, 400, "Insert your email address.")
+
+	server.request("POST", "/routes/loginTokens/create", map[string]any{
+		"email": "not-an-email",
+	}, nil).expect(t, 400, "Invalid email address.")
+
+	loginTokenId, loginToken := server.createLoginToken("user@example.com")
+
+	mail := server.mailer.last(t)
+
+	if mail.To != "user@example.com" || mail.Subject != server.config.Mail.LoginTokenSubject {
+		t.Fatalf("unexpected email %+v", mail)
+	}
+
+	if !strings.Contains(mail.Body, "/auth/confirm?loginToken="+loginToken) {
+		t.Fatalf("expected a confirmation link in %q", mail.Body)
+	}
+
+	loginTokenHeader := http.Header{"X-Login-Token-Id": {loginTokenId}}
+
+	server.request("GET", "/routes/users/authenticate", nil, loginTokenHeader).expect(t, 400, "Login token not authorized.")
+
+	server.request("POST", "/routes/loginTokens/check", map[string]any{}, nil).expect(t, 400, "Login token not identified.")
+
+	server.request("POST", "/routes/loginTokens/check", map[string]any{
+		"token": loginToken,
+	}, nil).expect(t, 200, "")
+
+	server.request("POST", "/routes/loginTokens/authorize", map[string]any{
+		"token": loginToken,
+	}, nil).expect(t, 200, "")
+
+	server.request("POST", "/routes/loginTokens/check", map[string]any{
+		"token": loginToken,
+	}, nil).expect(t, 400, "This login token was already authorized.")
+
+	server.request("POST", "/routes/loginTokens/deny", map[string]any{
+		"token": loginToken,
+	}, nil).expect(t, 400, "This login token was already authorized.")
+
+	response := server.request("GET", "/routes/users/authenticate", nil, loginTokenHeader)
+
+	response.expect(t, 200, "")
+
+	var result authenticatedUser
+
+	response.decode(t, &result)
+
+	if result.User == nil || result.User.Email != "user@example.com" || result.Token == "" {
+		t.Fatalf("unexpected authentication %s", response.Body)
+	}
+
+	if response := server.request("GET", "/routes/users/authenticate", nil, loginTokenHeader); response.StatusCode == 200 {
+		t.Fatal("expected a login token to be usable only once")
+	}
+
+	response = server.request("GET", "/routes/users/authenticate", nil, bearer(result.Token))
+
+	response.expect(t, 200, "")
+
+	var refreshed authenticatedUser
+
+	response.decode(t, &refreshed)
+
+	if refreshed.User.Id != result.User.Id || refreshed.Token != "" {
+		t.Fatalf("unexpected authentication %s", response.Body)
+	}
+
+	server.clock.Advance(server.config.Lifetimes.LoginTokenResend + time.Second)
+
+	second := server.loginUser("user@example.com")
+
+	if second.User.Id != result.User.Id {
+		t.Fatalf("expected the same user on a second login, got %v and %v", second.User.Id, result.User.Id)
+	}
+}
+
+func TestLoginTokenDeny(t *testing.T) {
+	forEachBackend(t, testLoginTokenDeny)
+}
+
+func testLoginTokenDeny(
+	t *testing.T,
+	server *testServer,
+) {
+	loginTokenId, loginToken := server.createLoginToken("user@example.com")
+
+	server.request("POST", "/routes/loginTokens/deny", map[string]any{
+		"token": loginToken,
+	}, nil).expect(t, 200, "")
+
+	server.request("POST", "/routes/loginTokens/check", map[string]any{
+		"token": loginToken,
+	}, nil).expect(t, 400, "This login token was denied.")
+
+	server.request("POST", "/routes/loginTokens/deny", map[string]any{
+		"token": loginToken,
+	}, nil).expect(t, 400, "This login token was already denied.")
+
+	server.request("POST", "/routes/loginTokens/authorize", map[string]any{
+		"token": loginToken,
+	}, nil).expect(t, 400, "This login token was already denied.")
+
+	server.request("GET", "/routes/users/authenticate", nil, http.Header{
+		"X-Login-Token-Id": {loginTokenId},
+	}).expect(t, 400, "Login token denied.")
+}
+
+func TestLoginTokenExpires(t *testing.T) {
+	forEachBackend(t, testLoginTokenExpires)
+}
+
+func testLoginTokenExpires(
+	t *testing.T,
+	server *testServer,
+) {
+	loginTokenId, loginToken := server.createLoginToken("user@example.com")
+
+	server.request("POST", "/routes/loginTokens/authorize", map[string]any{
+		"token": loginToken,
+	}, nil).expect(t, 200, "")
+
+	server.clock.Advance(server.config.Lifetimes.LoginToken + time.Second)
+
+	if response := server.request("POST", "/routes/loginTokens/check", map[string]any{
+		"token": loginToken,
+	}, nil); response.StatusCode == 200 {
+		t.Fatal("expected an expired login token to be rejected")
+	}
+
+	server.request("GET", "/routes/users/authenticate", nil, http.Header{
+		"X-Login-Token-Id": {loginTokenId},
+	}).expect(t, 400, "Login token has expired.")
+}
+
+func TestLoginTokenLimits(t *testing.T) {
+	forEachBackend(t, testLoginTokenLimits)
+}
+
+func testLoginTokenLimits(
+	t *testing.T,
+	server *testServer,
+) {
+	server.createLoginToken("user@example.com")
+
+	server.clock.Advance(20 * time.Second)
+
+	response := server.request("POST", "/routes/loginTokens/create", map[string]any{
+		"email": "user@example.com",
+	}, nil)
+
+	response.expect(t, 429, "Wait before trying again.")
+
+	if retryAfter := response.Header.Get("Retry-After"); retryAfter != "40" {
+		t.Fatalf("expected Retry-After to cover the rest of the resend window, got %q", retryAfter)
+	}
+
+	server.clock.Advance(server.config.Lifetimes.LoginTokenResend + time.Second)
+
+	server.createLoginToken("user@example.com")
+
+	server.clock.Advance(server.config.Lifetimes.LoginTokenResend + time.Second)
+
+	server.createLoginToken("user@example.com")
+
+	server.clock.Advance(server.config.Lifetimes.LoginTokenResend + time.Second)
+
+	response = server.request("POST", "/routes/loginTokens/create", map[string]any{
+		"email": "user@example.com",
+	}, nil)
+
+	response.expect(t, 429, "You've reached max active tokens, please wait to send new login tokens.")
+
+	firstExpiresIn := server.config.Lifetimes.LoginToken - 20*time.Second - 3*(server.config.Lifetimes.LoginTokenResend+time.Second)
+
+	if retryAfter := response.Header.Get("Retry-After"); retryAfter != fmt.Sprint(int64(firstExpiresIn.Seconds())) {
+		t.Fatalf("expected Retry-After to cover the time until the first active token expires, got %q", retryAfter)
+	}
+
+	if limit, remaining := response.Header.Get("RateLimit-Limit"), response.Header.Get("RateLimit-Remaining"); limit != "3" || remaining != "0" {
+		t.Fatalf("expected rate limit headers for the active token limit, got %q and %q", limit, remaining)
+	}
+
+	server.clock.Advance(server.config.Lifetimes.LoginToken)
+
+	server.createLoginToken("user@example.com")
+}
+
+func TestLoginTokenMailFailure(t *testing.T) {
+	forEachBackend(t, testLoginTokenMailFailure)
+}
+
+func testLoginTokenMailFailure(
+	t *testing.T,
+	server *testServer,
+) {
+	server.mailer.fail = true
+
+	server.request("POST", "/routes/loginTokens/create", map[string]any{
+		"email": "user@example.com",
+	}, nil).expect(t, 500, "Error sending email.")
+}
+
+func TestLoginTokenEmailCase(t *testing.T) {
+	forEachBackend(t, testLoginTokenEmailCase)
+}
+
+func testLoginTokenEmailCase(
+	t *testing.T,
+	server *testServer,
+) {
+	user := server.loginUser(" User@Example.com ")
+
+	if user.User.Email != "user@example.com" {
+		t.Fatalf("expected a normalized email, got %q", user.User.Email)
+	}
+
+	server.request("POST", "/routes/loginTokens/create", map[string]any{
+		"email": "USER@example.COM",
+	}, nil).expect(t, 429, "Wait before trying again.")
+
+	server.clock.Advance(server.config.Lifetimes.LoginTokenResend + time.Second)
+
+	again := server.loginUser("USER@example.COM")
+
+	if again.User.Id != user.User.Id {
+		t.Fatalf("expected the same user for another casing, got %+v", again.User)
+	}
+}
@@ -1,77 +1,195 @@
 package main
 
 import (
+	"context"
 	_ "embed"
-	"net/http"
-	"time"
-
-	"github.com/sandromai/go-http-server/routes"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/sandromai/go-http-server/clock"
+	"github.com/sandromai/go-http-server/config"
+	"github.com/sandromai/go-http-server/metrics"
+	"github.com/sandromai/go-http-server/models"
+	"github.com/sandromai/go-http-server/oidc"
+	"github.com/sandromai/go-http-server/token"
+	"github.com/sandromai/go-http-server/tracing"
 )
 
 //go:embed templates/emails/loginToken.min.html
 var loginTokenTemplate string
 
 func main() {
-	timezone, err := time.LoadLocation("America/Sao_Paulo")
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	configFile := flag.String("config", os.Getenv("CONFIG_FILE"), "path to the YAML configuration file")
+	memoryStore := flag.Bool("memory", false, "serve from an in-memory store instead of the configured database")
+
+	flag.Parse()
+
+	var overrides []func(*config.Config)
+
+	if *memoryStore {
+		overrides = append(overrides, config.UseMemoryStore)
+	}
+
+	appConfig, err := config.Load(*configFile, overrides...)
 
 	if err != nil {
-		panic(err)
+		return err
 	}
 
-	http.HandleFunc("/", func(writer http.ResponseWriter, request *http.Request) {
-		if request.URL.Path != "/" {
-			writer.WriteHeader(404)
+	if flag.Arg(0) == "migrate" {
+		return migrate(context.Background(), appConfig, flag.Args()[1:])
+	}
 
-			return
-		}
+	appClock := clock.System{}
 
-		if request.Method != "GET" {
-			writer.WriteHeader(405)
+	logger, logCloser, err := openLogger(appConfig.Log, appClock)
 
-			return
-		}
+	if err != nil {
+		return err
+	}
 
-		writer.Write([]byte("<h1>Hello world!</h1>"))
-	})
+	defer logCloser.Close()
 
-	adminRoutes := &routes.Admin{}
+	slog.SetDefault(logger)
 
-	http.HandleFunc("/routes/admins/login", adminRoutes.Login)
-	http.HandleFunc("/routes/admins/register", adminRoutes.Register)
-	http.HandleFunc("/routes/admins/update", adminRoutes.Update)
+	shutdownTracing, err := tracing.Setup(context.Background(), appConfig.Tracing)
 
-	emailSettingRoutes := &routes.EmailSetting{}
+	if err != nil {
+		return err
+	}
+
+	defer shutdownTracing(context.Background())
+
+	backend, err := openBackend(context.Background(), appConfig, appClock)
+
+	if err != nil {
+		return err
+	}
 
-	http.HandleFunc("/routes/emailSettings/list", emailSettingRoutes.List)
-	http.HandleFunc("/routes/emailSettings/update", emailSettingRoutes.Update)
+	defer backend.Close()
 
-	loginTokenRoutes := &routes.LoginToken{
-		Template: loginTokenTemplate,
-		Timezone: timezone,
+	repositories := backend.Repositories()
+
+	appMetrics := metrics.NewApp()
+
+	if pool, ok := backend.(models.PoolStatser); ok {
+		appMetrics.RegisterDBStats(pool.Stats)
 	}
 
-	http.HandleFunc("/routes/loginTokens/create", loginTokenRoutes.Create)
-	http.HandleFunc("/routes/loginTokens/check", loginTokenRoutes.Check)
-	http.HandleFunc("/routes/loginTokens/deny", loginTokenRoutes.Deny)
-	http.HandleFunc("/routes/loginTokens/authorize", loginTokenRoutes.Authorize)
+	tokenEngine, err := loadTokenEngine(appConfig.Security, appClock)
 
-	userRoutes := &routes.User{
-		Timezone: timezone,
+	if err != nil {
+		return err
 	}
 
-	http.HandleFunc("/routes/users/authenticate", userRoutes.Authenticate)
-	http.HandleFunc("/routes/users/ban/", userRoutes.Ban)
-	http.HandleFunc("/routes/users/unban/", userRoutes.Unban)
+	oauthProviders, err := loadOAuthProviders(appConfig.OAuth)
 
-	userTokenRoutes := &routes.UserToken{
-		Timezone: timezone,
+	if err != nil {
+		return err
 	}
 
-	http.HandleFunc("/routes/userTokens/disconnect/", userTokenRoutes.Disconnect)
+	if appErr := seedSuperAdmin(context.Background(), repositories, appConfig.Admin); appErr != nil {
+		return errors.New(appErr.Message)
+	}
 
-	err = http.ListenAndServe(":3333", nil)
+	app := &application{
+		Config:       appConfig,
+		Repositories: repositories,
+		Health:       backend,
+		Tokens:       tokenEngine,
+		Providers:    oauthProviders,
+		Clock:        appClock,
+		Logger:       logger,
+		Metrics:      appMetrics,
+	}
+
+	return serve(appConfig.Server, app.handler())
+}
+
+func loadTokenEngine(
+	security config.Security,
+	appClock clock.Clock,
+) (*token.Engine, error) {
+	if security.JWTSigningKeyFile == "" {
+		signingKey := &token.HMACKey{
+			Secret: []byte(security.JWTKey),
+		}
+
+		return &token.Engine{
+			Signer:    signingKey,
+			Verifiers: []token.Verifier{signingKey},
+			Issuer:    security.JWTIssuer,
+			Clock:     appClock,
+		}, nil
+	}
+
+	signingKey, err := token.LoadKeyFile(
+		security.JWTSigningKeyFile,
+		security.JWTSigningKeyId,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !token.CanSign(signingKey) {
+		return nil, errors.New("JWT signing key file must contain a private key")
+	}
+
+	verifiers := []token.Verifier{signingKey}
+
+	for _, entry := range security.JWTVerificationKeyFiles {
+		keyId, keyFile, found := strings.Cut(entry, "=")
+
+		if !found {
+			keyId, keyFile = "", entry
+		}
+
+		verificationKey, err := token.LoadKeyFile(keyFile, keyId)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if verificationKey.KeyId() == signingKey.KeyId() {
+			return nil, errors.New("duplicate key ID " + verificationKey.KeyId() + " in JWT verification key files")
+		}
+
+		verifiers = append(verifiers, verificationKey)
+	}
+
+	return &token.Engine{
+		Signer:    signingKey.(token.Signer),
+		Verifiers: verifiers,
+		Issuer:    security.JWTIssuer,
+		Clock:     appClock,
+	}, nil
+}
+
+func loadOAuthProviders(
+	oauth config.OAuth,
+) (map[string]*oidc.Client, error) {
+	if oauth.ProvidersFile == "" {
+		return map[string]*oidc.Client{}, nil
+	}
+
+	providersConfig, err := oidc.LoadConfig(oauth.ProvidersFile)
 
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
+
+	return oidc.NewClients(providersConfig), nil
 }
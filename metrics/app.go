@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"database/sql"
+)
+
+type App struct {
+	Registry           *Registry
+	HTTPRequests       *Counter
+	HTTPDuration       *Histogram
+	LoginTokens        *Counter
+	UserSessions       *Counter
+	UserBans           *Counter
+	AdminLoginFailures *Counter
+	MailFailures       *Counter
+	RateLimited        *Counter
+	Lockouts           *Counter
+}
+
+func NewApp() *App {
+	registry := &Registry{}
+
+	return &App{
+		Registry:           registry,
+		HTTPRequests:       registry.Counter("http_requests_total", "HTTP requests by method, route and status.", "method", "route", "status"),
+		HTTPDuration:       registry.Histogram("http_request_duration_seconds", "HTTP request latency by method and route.", DefaultBuckets, "method", "route"),
+		LoginTokens:        registry.Counter("login_tokens_total", "Login tokens by event: created, authorized, denied or expired.", "event"),
+		UserSessions:       registry.Counter("user_sessions_total", "User sessions by event: created or refreshed.", "event"),
+		UserBans:           registry.Counter("user_bans_total", "Users banned by an admin."),
+		AdminLoginFailures: registry.Counter("admin_login_failures_total", "Failed admin logins by stage: password or two_factor.", "stage"),
+		MailFailures:       registry.Counter("mail_send_failures_total", "Emails that could not be sent."),
+		RateLimited:        registry.Counter("rate_limited_total", "Requests rejected by a rate limit or lockout, by rule.", "rule"),
+		Lockouts:           registry.Counter("lockouts_total", "Lockouts started after repeated failures, by rule.", "rule"),
+	}
+}
+
+func (app *App) RegisterDBStats(
+	stats func() sql.DBStats,
+) {
+	registry := app.Registry
+
+	registry.GaugeFunc("db_max_open_connections", "Maximum number of open database connections.", func() float64 {
+		return float64(stats().MaxOpenConnections)
+	})
+
+	registry.GaugeFunc("db_open_connections", "Open database connections, in use and idle.", func() float64 {
+		return float64(stats().OpenConnections)
+	})
+
+	registry.GaugeFunc("db_in_use_connections", "Database connections currently in use.", func() float64 {
+		return float64(stats().InUse)
+	})
+
+	registry.GaugeFunc("db_idle_connections", "Idle database connections.", func() float64 {
+		return float64(stats().Idle)
+	})
+
+	registry.CounterFunc("db_wait_count_total", "Database connections waited for.", func() float64 {
+		return float64(stats().WaitCount)
+	})
+
+	registry.CounterFunc("db_wait_duration_seconds_total", "Time spent waiting for database connections.", func() float64 {
+		return stats().WaitDuration.Seconds()
+	})
+
+	registry.CounterFunc("db_max_idle_closed_total", "Database connections closed by the idle limit.", func() float64 {
+		return float64(stats().MaxIdleClosed)
+	})
+
+	registry.CounterFunc("db_max_idle_time_closed_total", "Database connections closed by the idle time limit.", func() float64 {
+		return float64(stats().MaxIdleTimeClosed)
+	})
+
+	registry.CounterFunc("db_max_lifetime_closed_total", "Database connections closed by the lifetime limit.", func() float64 {
+		return float64(stats().MaxLifetimeClosed)
+	})
+}
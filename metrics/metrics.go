@@ -0,0 +1,375 @@
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type collector interface {
+	write(writer *bufio.Writer)
+}
+
+type Registry struct {
+	mutex      sync.Mutex
+	collectors []collector
+}
+
+func (registry *Registry) register(
+	collector collector,
+) {
+	registry.mutex.Lock()
+
+	defer registry.mutex.Unlock()
+
+	registry.collectors = append(registry.collectors, collector)
+}
+
+func (registry *Registry) WriteTo(
+	destination io.Writer,
+) (int64, error) {
+	registry.mutex.Lock()
+
+	collectors := append([]collector{}, registry.collectors...)
+
+	registry.mutex.Unlock()
+
+	counter := &countingWriter{writer: destination}
+	writer := bufio.NewWriter(counter)
+
+	for _, collector := range collectors {
+		collector.write(writer)
+	}
+
+	err := writer.Flush()
+
+	return counter.written, err
+}
+
+type countingWriter struct {
+	writer  io.Writer
+	written int64
+}
+
+func (counter *countingWriter) Write(
+	data []byte,
+) (int, error) {
+	written, err := counter.writer.Write(data)
+
+	counter.written += int64(written)
+
+	return written, err
+}
+
+type descriptor struct {
+	name       string
+	help       string
+	metricType string
+	labelNames []string
+}
+
+func (descriptor *descriptor) writeHeader(
+	writer *bufio.Writer,
+) {
+	writer.WriteString("# HELP " + descriptor.name + " " + escapeHelp(descriptor.help) + "\n")
+	writer.WriteString("# TYPE " + descriptor.name + " " + descriptor.metricType + "\n")
+}
+
+func (descriptor *descriptor) key(
+	labelValues []string,
+) string {
+	if len(labelValues) != len(descriptor.labelNames) {
+		panic("metrics: " + descriptor.name + " expects " + strconv.Itoa(len(descriptor.labelNames)) + " label values")
+	}
+
+	return strings.Join(labelValues, "\xff")
+}
+
+func (descriptor *descriptor) labels(
+	key string,
+	extra ...string,
+) string {
+	var pairs []string
+
+	if len(descriptor.labelNames) > 0 {
+		for i, value := range strings.Split(key, "\xff") {
+			pairs = append(pairs, descriptor.labelNames[i]+`="`+escapeLabel(value)+`"`)
+		}
+	}
+
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, extra[i]+`="`+escapeLabel(extra[i+1])+`"`)
+	}
+
+	if len(pairs) == 0 {
+		return ""
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func escapeHelp(
+	help string,
+) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+}
+
+func escapeLabel(
+	value string,
+) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+func formatValue(
+	value float64,
+) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+func sortedKeys[Value any](
+	series map[string]Value,
+) []string {
+	keys := make([]string, 0, len(series))
+
+	for key := range series {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+type Counter struct {
+	descriptor
+	mutex  sync.Mutex
+	series map[string]float64
+}
+
+func (registry *Registry) Counter(
+	name string,
+	help string,
+	labelNames ...string,
+) *Counter {
+	counter := &Counter{
+		descriptor: descriptor{
+			name:       name,
+			help:       help,
+			metricType: "counter",
+			labelNames: labelNames,
+		},
+		series: map[string]float64{},
+	}
+
+	if len(labelNames) == 0 {
+		counter.series[""] = 0
+	}
+
+	registry.register(counter)
+
+	return counter
+}
+
+func (counter *Counter) Add(
+	value float64,
+	labelValues ...string,
+) {
+	if counter == nil || value < 0 {
+		return
+	}
+
+	key := counter.key(labelValues)
+
+	counter.mutex.Lock()
+
+	defer counter.mutex.Unlock()
+
+	counter.series[key] += value
+}
+
+func (counter *Counter) Inc(
+	labelValues ...string,
+) {
+	counter.Add(1, labelValues...)
+}
+
+func (counter *Counter) Value(
+	labelValues ...string,
+) float64 {
+	if counter == nil {
+		return 0
+	}
+
+	key := counter.key(labelValues)
+
+	counter.mutex.Lock()
+
+	defer counter.mutex.Unlock()
+
+	return counter.series[key]
+}
+
+func (counter *Counter) write(
+	writer *bufio.Writer,
+) {
+	counter.mutex.Lock()
+
+	defer counter.mutex.Unlock()
+
+	counter.writeHeader(writer)
+
+	for _, key := range sortedKeys(counter.series) {
+		writer.WriteString(counter.name + counter.labels(key) + " " + formatValue(counter.series[key]) + "\n")
+	}
+}
+
+type histogramSeries struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+type Histogram struct {
+	descriptor
+	buckets []float64
+	mutex   sync.Mutex
+	series  map[string]*histogramSeries
+}
+
+func (registry *Registry) Histogram(
+	name string,
+	help string,
+	buckets []float64,
+	labelNames ...string,
+) *Histogram {
+	histogram := &Histogram{
+		descriptor: descriptor{
+			name:       name,
+			help:       help,
+			metricType: "histogram",
+			labelNames: labelNames,
+		},
+		buckets: append([]float64{}, buckets...),
+		series:  map[string]*histogramSeries{},
+	}
+
+	sort.Float64s(histogram.buckets)
+
+	registry.register(histogram)
+
+	return histogram
+}
+
+func (histogram *Histogram) Observe(
+	value float64,
+	labelValues ...string,
+) {
+	if histogram == nil {
+		return
+	}
+
+	key := histogram.key(labelValues)
+
+	histogram.mutex.Lock()
+
+	defer histogram.mutex.Unlock()
+
+	series, found := histogram.series[key]
+
+	if !found {
+		series = &histogramSeries{
+			counts: make([]uint64, len(histogram.buckets)),
+		}
+
+		histogram.series[key] = series
+	}
+
+	for i, bucket := range histogram.buckets {
+		if value <= bucket {
+			series.counts[i]++
+		}
+	}
+
+	series.count++
+	series.sum += value
+}
+
+func (histogram *Histogram) write(
+	writer *bufio.Writer,
+) {
+	histogram.mutex.Lock()
+
+	defer histogram.mutex.Unlock()
+
+	histogram.writeHeader(writer)
+
+	for _, key := range sortedKeys(histogram.series) {
+		series := histogram.series[key]
+
+		for i, bucket := range histogram.buckets {
+			writer.WriteString(histogram.name + "_bucket" + histogram.labels(key, "le", formatValue(bucket)) + " " + strconv.FormatUint(series.counts[i], 10) + "\n")
+		}
+
+		writer.WriteString(histogram.name + "_bucket" + histogram.labels(key, "le", "+Inf") + " " + strconv.FormatUint(series.count, 10) + "\n")
+		writer.WriteString(histogram.name + "_sum" + histogram.labels(key) + " " + formatValue(series.sum) + "\n")
+		writer.WriteString(histogram.name + "_count" + histogram.labels(key) + " " + strconv.FormatUint(series.count, 10) + "\n")
+	}
+}
+
+type valueFunc struct {
+	descriptor
+	value func() float64
+}
+
+func (registry *Registry) GaugeFunc(
+	name string,
+	help string,
+	value func() float64,
+) {
+	registry.register(&valueFunc{
+		descriptor: descriptor{
+			name:       name,
+			help:       help,
+			metricType: "gauge",
+		},
+		value: value,
+	})
+}
+
+func (registry *Registry) CounterFunc(
+	name string,
+	help string,
+	value func() float64,
+) {
+	registry.register(&valueFunc{
+		descriptor: descriptor{
+			name:       name,
+			help:       help,
+			metricType: "counter",
+		},
+		value: value,
+	})
+}
+
+func (gauge *valueFunc) write(
+	writer *bufio.Writer,
+) {
+	gauge.writeHeader(writer)
+
+	writer.WriteString(gauge.name + " " + formatValue(gauge.value()) + "\n")
+}
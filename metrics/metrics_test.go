@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestExposition(t *testing.T) {
+	registry := &Registry{}
+
+	requests := registry.Counter("requests_total", "Requests by route.", "method", "route")
+	registry.Counter("failures_total", "Failures.")
+	latency := registry.Histogram("latency_seconds", "Latency.", []float64{0.5, 0.1}, "route")
+
+	registry.GaugeFunc("connections", "Open connections.", func() float64 {
+		return 3
+	})
+
+	requests.Inc("GET", "/users/{id}")
+	requests.Add(2, "POST", `/say "hi"`)
+	requests.Add(-1, "GET", "/users/{id}")
+
+	latency.Observe(0.05, "/")
+	latency.Observe(0.3, "/")
+	latency.Observe(2, "/")
+
+	var output strings.Builder
+
+	if _, err := registry.WriteTo(&output); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `# HELP requests_total Requests by route.
+# TYPE requests_total counter
+requests_total{method="GET",route="/users/{id}"} 1
+requests_total{method="POST",route="/say \"hi\""} 2
+# HELP failures_total Failures.
+# TYPE failures_total counter
+failures_total 0
+# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{route="/",le="0.1"} 1
+latency_seconds_bucket{route="/",le="0.5"} 2
+latency_seconds_bucket{route="/",le="+Inf"} 3
+latency_seconds_sum{route="/"} 2.35
+latency_seconds_count{route="/"} 3
+# HELP connections Open connections.
+# TYPE connections gauge
+connections 3
+`
+
+	if output.String() != expected {
+		t.Fatalf("got\n%v\nexpected\n%v", output.String(), expected)
+	}
+}
+
+func TestCounterConcurrency(t *testing.T) {
+	registry := &Registry{}
+
+	counter := registry.Counter("events_total", "Events.", "event")
+
+	var group sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		group.Add(1)
+
+		go func() {
+			defer group.Done()
+
+			for j := 0; j < 100; j++ {
+				counter.Inc("created")
+			}
+		}()
+	}
+
+	group.Wait()
+
+	if value := counter.Value("created"); value != 1000 {
+		t.Fatalf("got %v, expected 1000", value)
+	}
+}
+
+func TestNilMetricsAreNoOps(t *testing.T) {
+	var counter *Counter
+	var histogram *Histogram
+
+	counter.Inc()
+	histogram.Observe(1)
+
+	if counter.Value() != 0 {
+		t.Fatal("expected a nil counter to read as zero")
+	}
+}
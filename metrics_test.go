@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sandromai/go-http-server/token"
+	"github.com/sandromai/go-http-server/types"
+)
+
+func TestMetrics(t *testing.T) {
+	forEachBackend(t, testMetrics)
+}
+
+func testMetrics(
+	t *testing.T,
+	server *testServer,
+) {
+	_, loginToken := server.createLoginToken("user@example.com")
+
+	server.request("POST", "/routes/loginTokens/deny", map[string]any{
+		"token": loginToken,
+	}, nil).expect(t, 200, "")
+
+	user := server.loginUser("other@example.com")
+
+	server.clock.Advance(server.config.Lifetimes.UserSession - time.Hour)
+
+	server.request("GET", "/routes/users/authenticate", nil, bearer(user.Token)).expect(t, 200, "")
+
+	server.clock.Advance(2 * time.Hour)
+
+	server.request("GET", "/routes/users/authenticate", nil, bearer(user.Token)).expect(t, 200, "")
+
+	server.request("POST", "/routes/admins/login", map[string]any{
+		"username": testAdminUsername,
+		"password": "wrong-password",
+	}, nil).expect(t, 401, "")
+
+	adminToken := server.loginAdmin(testAdminUsername, testAdminPassword)
+
+	server.request("PATCH", "/routes/users/"+user.User.Id+"/ban", nil, bearer(adminToken)).expect(t, 200, "")
+
+	expiredTokenId, appErr := server.repositories.LoginTokens.Create(context.Background(), "expired@example.com", "127.0.0.1", "test", 1)
+
+	if appErr != nil {
+		t.Fatal(appErr.Message)
+	}
+
+	expiredToken, appErr := (&types.LoginTokenPayload{
+		RegisteredClaims: token.RegisteredClaims{
+			ExpiresAt: server.clock.Now().Add(time.Hour).Unix(),
+		},
+		LoginTokenId: expiredTokenId,
+	}).ToJWT(server.tokens)
+
+	if appErr != nil {
+		t.Fatal(appErr.Message)
+	}
+
+	server.clock.Advance(2 * time.Second)
+
+	server.request("POST", "/routes/loginTokens/check", map[string]any{
+		"token": expiredToken,
+	}, nil).expect(t, 400, "Login token has expired.")
+
+	server.request("PURGE", "/health/live", nil, nil)
+
+	server.mailer.fail = true
+
+	server.request("POST", "/routes/loginTokens/create", map[string]any{
+		"email": "third@example.com",
+	}, nil).expect(t, 500, "Error sending email.")
+
+	response := server.request("GET", "/metrics", nil, nil)
+
+	if response.StatusCode != 200 || !strings.HasPrefix(response.Header.Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Fatalf("unexpected metrics response %v %v", response.StatusCode, response.Header)
+	}
+
+	body := string(response.Body)
+
+	for _, line := range []string{
+		`login_tokens_total{event="created"} 2`,
+		`login_tokens_total{event="denied"} 1`,
+		`login_tokens_total{event="expired"} 1`,
+		`login_tokens_total{event="authorized"} 1`,
+		`user_sessions_total{event="created"} 1`,
+		`user_sessions_total{event="refreshed"} 1`,
+		`user_bans_total 1`,
+		`admin_login_failures_total{stage="password"} 1`,
+		`mail_send_failures_total 1`,
+		`http_requests_total{method="PATCH",route="/routes/users/{id}/ban",status="200"} 1`,
+		`http_request_duration_seconds_count{method="POST",route="/routes/loginTokens/create"} 3`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Fatalf("expected %q in\n%v", line, body)
+		}
+	}
+
+	if !strings.Contains(body, `http_requests_total{method="OTHER",`) || strings.Contains(body, `method="PURGE"`) {
+		t.Fatalf("expected unknown methods to be counted as OTHER in\n%v", body)
+	}
+}
@@ -0,0 +1,149 @@
+package middlewares
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/sandromai/go-http-server/utils"
+)
+
+type AccessLog struct {
+	Logger *slog.Logger
+}
+
+type accessLogEntry struct {
+	userId  string
+	adminId string
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (recorder *statusRecorder) WriteHeader(
+	status int,
+) {
+	if recorder.status == 0 {
+		recorder.status = status
+	}
+
+	recorder.ResponseWriter.WriteHeader(status)
+}
+
+func (recorder *statusRecorder) Write(
+	data []byte,
+) (int, error) {
+	if recorder.status == 0 {
+		recorder.status = 200
+	}
+
+	return recorder.ResponseWriter.Write(data)
+}
+
+func (recorder *statusRecorder) Unwrap() http.ResponseWriter {
+	return recorder.ResponseWriter
+}
+
+func (accessLog *AccessLog) logger() *slog.Logger {
+	if accessLog.Logger != nil {
+		return accessLog.Logger
+	}
+
+	return slog.Default()
+}
+
+func (accessLog *AccessLog) Handle(
+	next http.Handler,
+) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		startedAt := time.Now()
+
+		requestId, _ := utils.GenerateUUIDv4()
+
+		logger := accessLog.logger().With("request_id", requestId)
+
+		if spanContext := trace.SpanContextFromContext(request.Context()); spanContext.IsValid() {
+			logger = logger.With("trace_id", spanContext.TraceID().String())
+		}
+
+		entry := &accessLogEntry{}
+
+		writer.Header().Set("X-Request-Id", requestId)
+
+		request = withValue(request, requestIdContextKey, requestId)
+		request = withValue(request, loggerContextKey, logger)
+		request = withValue(request, accessLogContextKey, entry)
+
+		recorder := &statusRecorder{ResponseWriter: writer}
+
+		next.ServeHTTP(recorder, request)
+
+		if recorder.status == 0 {
+			recorder.status = 200
+		}
+
+		attributes := []any{
+			"method", request.Method,
+			"path", request.URL.Path,
+			"status", recorder.status,
+			"latency", time.Since(startedAt),
+			"remote_ip", utils.GetRemoteIP(request),
+		}
+
+		if entry.userId != "" {
+			attributes = append(attributes, "user_id", entry.userId)
+		}
+
+		if entry.adminId != "" {
+			attributes = append(attributes, "admin_id", entry.adminId)
+		}
+
+		level := slog.LevelInfo
+
+		if recorder.status >= 500 {
+			level = slog.LevelError
+		}
+
+		logger.Log(request.Context(), level, "request", attributes...)
+	})
+}
+
+func recordUser(
+	request *http.Request,
+	userId string,
+) {
+	if entry, ok := request.Context().Value(accessLogContextKey).(*accessLogEntry); ok {
+		entry.userId = userId
+	}
+}
+
+func recordAdmin(
+	request *http.Request,
+	adminId string,
+) {
+	if entry, ok := request.Context().Value(accessLogContextKey).(*accessLogEntry); ok {
+		entry.adminId = adminId
+	}
+}
+
+func RequestId(
+	request *http.Request,
+) string {
+	requestId, _ := request.Context().Value(requestIdContextKey).(string)
+
+	return requestId
+}
+
+func Logger(
+	request *http.Request,
+) *slog.Logger {
+	if logger, ok := request.Context().Value(loggerContextKey).(*slog.Logger); ok {
+		return logger
+	}
+
+	return slog.Default()
+}
@@ -0,0 +1,107 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAccessLog(t *testing.T) {
+	var output bytes.Buffer
+
+	accessLog := &AccessLog{
+		Logger: slog.New(slog.NewJSONHandler(&output, nil)),
+	}
+
+	var handlerRequestId string
+
+	handler := accessLog.Handle(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		handlerRequestId = RequestId(request)
+
+		recordUser(request, "user-id")
+
+		Logger(request).Info("handled")
+
+		writer.WriteHeader(http.StatusTeapot)
+	}))
+
+	request := httptest.NewRequest("GET", "/routes/users/authenticate", nil)
+	request.RemoteAddr = "203.0.113.9:5123"
+
+	recorder := httptest.NewRecorder()
+
+	handler.ServeHTTP(recorder, request)
+
+	requestId := recorder.Header().Get("X-Request-Id")
+
+	if requestId == "" || requestId != handlerRequestId {
+		t.Fatalf("got request ID %q in the header and %q in the handler", requestId, handlerRequestId)
+	}
+
+	decoder := json.NewDecoder(&output)
+
+	var handled, entry map[string]any
+
+	if err := decoder.Decode(&handled); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := decoder.Decode(&entry); err != nil {
+		t.Fatal(err)
+	}
+
+	if handled["request_id"] != requestId {
+		t.Fatalf("expected handler logs to carry the request ID, got %v", handled)
+	}
+
+	expected := map[string]any{
+		"msg":        "request",
+		"request_id": requestId,
+		"method":     "GET",
+		"path":       "/routes/users/authenticate",
+		"status":     float64(http.StatusTeapot),
+		"remote_ip":  "203.0.113.9",
+		"user_id":    "user-id",
+	}
+
+	for key, value := range expected {
+		if entry[key] != value {
+			t.Fatalf("got %v for %v, expected %v", entry[key], key, value)
+		}
+	}
+
+	if _, found := entry["latency"]; !found {
+		t.Fatal("expected the latency to be logged")
+	}
+
+	if _, found := entry["admin_id"]; found {
+		t.Fatal("expected no admin ID for a user request")
+	}
+}
+
+func TestAccessLogDefaultStatus(t *testing.T) {
+	var output bytes.Buffer
+
+	accessLog := &AccessLog{
+		Logger: slog.New(slog.NewJSONHandler(&output, nil)),
+	}
+
+	handler := accessLog.Handle(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Write([]byte("ok"))
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	var entry map[string]any
+
+	if err := json.Unmarshal(output.Bytes(), &entry); err != nil {
+		t.Fatal(err)
+	}
+
+	if entry["status"] != float64(200) || entry["level"] != "INFO" {
+		t.Fatalf("unexpected entry %v", entry)
+	}
+}
@@ -3,21 +3,27 @@ package middlewares
 import (
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/sandromai/go-http-server/models"
+	"github.com/sandromai/go-http-server/token"
 	"github.com/sandromai/go-http-server/types"
 )
 
-func AuthenticateAdmin(
+func authenticateAdmin(
 	request *http.Request,
+	repositories *models.Repositories,
+	tokens *token.Engine,
+	now func() time.Time,
 ) (
 	*types.Admin,
+	*types.AdminToken,
 	*types.AppError,
 ) {
 	authorizationHeader := request.Header.Get("Authorization")
 
 	if authorizationHeader == "" {
-		return nil, &types.AppError{
+		return nil, nil, &types.AppError{
 			StatusCode: 401,
 			Message:    "No authorization provided.",
 		}
@@ -26,7 +32,7 @@ func AuthenticateAdmin(
 	tokenParts := strings.Split(authorizationHeader, " ")
 
 	if len(tokenParts) < 2 || tokenParts[0] != "Bearer" {
-		return nil, &types.AppError{
+		return nil, nil, &types.AppError{
 			StatusCode: 401,
 			Message:    "Invalid token.",
 		}
@@ -34,19 +40,57 @@ func AuthenticateAdmin(
 
 	adminTokenPayload := &types.AdminTokenPayload{}
 
-	appErr := adminTokenPayload.FromJWT(tokenParts[1])
+	appErr := adminTokenPayload.FromJWT(tokens, tokenParts[1])
 
 	if appErr != nil {
-		return nil, appErr
+		return nil, nil, appErr
 	}
 
-	admin, appErr := (&models.Admin{}).FindById(
-		adminTokenPayload.AdminId,
+	adminTokenModel := repositories.AdminTokens
+
+	adminToken, appErr := adminTokenModel.FindById(
+		request.Context(),
+		adminTokenPayload.AdminTokenId,
+	)
+
+	if appErr != nil {
+		if appErr.StatusCode == 404 {
+			return nil, nil, &types.AppError{
+				StatusCode: 401,
+				Message:    "Invalid token.",
+			}
+		}
+
+		return nil, nil, appErr
+	}
+
+	if adminToken.Disconnected {
+		return nil, nil, &types.AppError{
+			StatusCode: 401,
+			Message:    "Session disconnected.",
+		}
+	}
+
+	if adminToken.ExpiresAt.Before(now()) {
+		return nil, nil, &types.AppError{
+			StatusCode: 401,
+			Message:    "Expired token.",
+		}
+	}
+
+	admin, appErr := repositories.Admins.FindById(
+		request.Context(),
+		adminToken.AdminId,
 	)
 
 	if appErr != nil {
-		return nil, appErr
+		return nil, nil, appErr
 	}
 
-	return admin, nil
+	adminTokenModel.UpdateActivity(
+		request.Context(),
+		adminToken.Id,
+	)
+
+	return admin, adminToken, nil
 }
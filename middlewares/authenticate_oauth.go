@@ -0,0 +1,142 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sandromai/go-http-server/models"
+	"github.com/sandromai/go-http-server/token"
+	"github.com/sandromai/go-http-server/types"
+)
+
+func inspectAccessToken(
+	ctx context.Context,
+	repositories *models.Repositories,
+	tokens *token.Engine,
+	now func() time.Time,
+	tokenString string,
+) (*types.OAuthAccess, *types.AppError) {
+	payload := &types.OAuthAccessTokenPayload{}
+
+	if appErr := payload.FromJWT(tokens, tokenString); appErr != nil {
+		return nil, appErr
+	}
+
+	access := &types.OAuthAccess{
+		ClientId:      payload.ClientId,
+		Scopes:        types.ParseOAuthScope(payload.Scope),
+		UserTokenId:   payload.UserTokenId,
+		ClientTokenId: payload.ClientTokenId,
+		ExpiresAt:     payload.ExpiresAt,
+		IssuedAt:      payload.IssuedAt,
+	}
+
+	invalidToken := &types.AppError{
+		StatusCode: 401,
+		Message:    "Invalid token.",
+	}
+
+	if payload.UserTokenId != "" {
+		userToken, appErr := repositories.UserTokens.FindById(
+			ctx,
+			payload.UserTokenId,
+		)
+
+		if appErr != nil && appErr.StatusCode == 404 {
+			return nil, invalidToken
+		}
+
+		if appErr != nil {
+			return nil, appErr
+		}
+
+		if userToken.ClientId == nil || *userToken.ClientId != payload.ClientId || userToken.Disconnected {
+			return nil, invalidToken
+		}
+
+		if userToken.ExpiresAt.Before(now()) {
+			return nil, invalidToken
+		}
+
+		user, appErr := repositories.Users.FindById(
+			ctx,
+			userToken.UserId,
+		)
+
+		if appErr != nil {
+			return nil, appErr
+		}
+
+		if user.Banned {
+			return nil, &types.AppError{
+				StatusCode: 403,
+				Message:    "User banned.",
+			}
+		}
+
+		access.User = user
+
+		return access, nil
+	}
+
+	if payload.ClientTokenId != "" {
+		clientToken, appErr := repositories.OAuthClientTokens.FindById(
+			ctx,
+			payload.ClientTokenId,
+		)
+
+		if appErr != nil && appErr.StatusCode == 404 {
+			return nil, invalidToken
+		}
+
+		if appErr != nil {
+			return nil, appErr
+		}
+
+		if clientToken.ClientId != payload.ClientId || clientToken.Revoked {
+			return nil, invalidToken
+		}
+
+		return access, nil
+	}
+
+	return nil, invalidToken
+}
+
+func bearerToken(
+	authorizationHeader string,
+) (string, *types.AppError) {
+	if authorizationHeader == "" {
+		return "", &types.AppError{
+			StatusCode: 401,
+			Message:    "No authorization provided.",
+		}
+	}
+
+	tokenParts := strings.Split(authorizationHeader, " ")
+
+	if len(tokenParts) < 2 || tokenParts[0] != "Bearer" {
+		return "", &types.AppError{
+			StatusCode: 401,
+			Message:    "Invalid token.",
+		}
+	}
+
+	return tokenParts[1], nil
+}
+
+func (authenticator *Authenticator) authenticateOAuth(
+	request *http.Request,
+) (*types.OAuthAccess, *types.AppError) {
+	tokenString, appErr := bearerToken(
+		request.Header.Get("Authorization"),
+	)
+
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	return authenticator.InspectAccessToken(request.Context(), tokenString)
+}
@@ -1,29 +1,37 @@
 package middlewares
 
 import (
+	"context"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/sandromai/go-http-server/config"
+	"github.com/sandromai/go-http-server/metrics"
 	"github.com/sandromai/go-http-server/models"
+	"github.com/sandromai/go-http-server/token"
 	"github.com/sandromai/go-http-server/types"
 	"github.com/sandromai/go-http-server/utils"
 )
 
-func AuthenticateUser(
+func authenticateUser(
 	request *http.Request,
-	timezone *time.Location,
+	repositories *models.Repositories,
+	tokens *token.Engine,
+	now func() time.Time,
+	lifetimes *config.Lifetimes,
+	appMetrics *metrics.App,
 ) (
 	user *types.User,
-	token string,
+	tokenString string,
 	appErr *types.AppError,
 ) {
 	var userTokenId string
 
-	userTokenModel := &models.UserToken{}
-	userModel := &models.User{}
+	userTokenModel := repositories.UserTokens
+	userModel := repositories.Users
 
-	ipAddress := strings.Split(request.RemoteAddr, ":")[0]
+	ipAddress := utils.GetRemoteIP(request)
 	platform, browser := utils.GetDeviceInfo(request.Header.Get("User-Agent"))
 
 	var device string
@@ -35,128 +43,42 @@ func AuthenticateUser(
 	loginTokenIdHeader := request.Header.Get("X-Login-Token-Id")
 
 	if loginTokenIdHeader != "" {
-		loginToken, appErr := (&models.LoginToken{}).FindById(
-			loginTokenIdHeader,
+		appErr = repositories.UnitOfWork.Run(
+			request.Context(),
+			func(transaction *models.Repositories) *types.AppError {
+				user, userTokenId, appErr = consumeLoginToken(
+					request.Context(),
+					transaction,
+					now,
+					loginTokenIdHeader,
+					ipAddress,
+					device,
+					int64(lifetimes.UserSession.Seconds()),
+				)
+
+				return appErr
+			},
 		)
 
 		if appErr != nil {
 			return nil, "", appErr
 		}
 
-		tokenExpiresAt, err := time.ParseInLocation(
-			time.DateTime,
-			loginToken.ExpiresAt,
-			timezone,
-		)
-
-		if err != nil {
-			return nil, "", &types.AppError{
-				StatusCode: 500,
-				Message:    "Error parsing date.",
-			}
-		}
-
-		if tokenExpiresAt.Before(time.Now()) {
-			return nil, "", &types.AppError{
-				StatusCode: 400,
-				Message:    "Login token has expired.",
-			}
-		}
-
-		tokenCreatedAt, err := time.ParseInLocation(
-			time.DateTime,
-			loginToken.CreatedAt,
-			timezone,
-		)
-
-		if err != nil {
-			return nil, "", &types.AppError{
-				StatusCode: 500,
-				Message:    "Error parsing date.",
-			}
-		}
-
-		if tokenCreatedAt.After(time.Now()) {
-			return nil, "", &types.AppError{
-				StatusCode: 400,
-				Message:    "Invalid login token date.",
-			}
-		}
-
-		if loginToken.Denied {
-			return nil, "", &types.AppError{
-				StatusCode: 400,
-				Message:    "Login token denied.",
-			}
-		}
-
-		if !loginToken.Authorized {
-			return nil, "", &types.AppError{
-				StatusCode: 400,
-				Message:    "Login token not authorized.",
-			}
-		}
-
-		emailAvailable, appErr := userModel.CheckEmailAvailability(
-			loginToken.Email,
-		)
-
-		if appErr != nil {
-			return nil, "", appErr
-		}
-
-		if emailAvailable {
-			userId, appErr := userModel.Create(
-				loginToken.Email,
-			)
+		expiredAt := lifetimes.UserTokenExpiresAt(now()).Unix()
 
-			if appErr != nil {
-				return nil, "", appErr
-			}
-
-			user, appErr = userModel.FindById(
-				userId,
-			)
-
-			if appErr != nil {
-				return nil, "", appErr
-			}
-		} else {
-			user, appErr = userModel.FindByEmail(
-				loginToken.Email,
-			)
-
-			if appErr != nil {
-				return nil, "", appErr
-			}
-		}
-
-		expiresIn := int64(30 * 24 * 60 * 60)
-
-		userTokenId, appErr = userTokenModel.Create(
-			user.Id,
-			&loginToken.Id,
-			nil,
-			ipAddress,
-			device,
-			expiresIn,
-		)
-
-		if appErr != nil {
-			return nil, "", appErr
-		}
-
-		expiredAt := time.Now().Add(30 * 24 * time.Hour).Unix()
-
-		token, appErr = (&types.UserTokenPayload{
+		tokenString, appErr = (&types.UserTokenPayload{
+			RegisteredClaims: token.RegisteredClaims{
+				ExpiresAt: expiredAt,
+				IssuedAt:  now().Unix(),
+			},
 			UserTokenId: userTokenId,
-			ExpiresAt:   expiredAt,
-			CreatedAt:   time.Now().Unix(),
-		}).ToJWT()
+		}).ToJWT(tokens)
 
 		if appErr != nil {
 			return nil, "", appErr
 		}
+
+		appMetrics.UserSessions.Inc("created")
 	} else {
 		authorizationHeader := request.Header.Get("Authorization")
 
@@ -178,7 +100,7 @@ func AuthenticateUser(
 
 		userTokenPayload := &types.UserTokenPayload{}
 
-		appErr := userTokenPayload.FromJWT(tokenParts[1])
+		appErr := userTokenPayload.FromJWT(tokens, tokenParts[1])
 
 		if appErr != nil {
 			return nil, "", appErr
@@ -188,6 +110,7 @@ func AuthenticateUser(
 	}
 
 	userToken, appErr := userTokenModel.FindById(
+		request.Context(),
 		userTokenId,
 	)
 
@@ -195,70 +118,61 @@ func AuthenticateUser(
 		return nil, "", appErr
 	}
 
-	tokenExpiresAt, err := time.ParseInLocation(
-		time.DateTime,
-		userToken.ExpiresAt,
-		timezone,
-	)
-
-	if err != nil {
+	if userToken.ClientId != nil {
 		return nil, "", &types.AppError{
-			StatusCode: 500,
-			Message:    "Error parsing date.",
+			StatusCode: 401,
+			Message:    "Invalid token.",
 		}
 	}
 
-	if tokenExpiresAt.Before(time.Now()) {
-		tokenLastActivity, err := time.ParseInLocation(
-			time.DateTime,
-			userToken.LastActivity,
-			timezone,
-		)
+	if userToken.ExpiresAt.Before(now()) {
+		timeGap := -lifetimes.UserSessionRefresh
 
-		if err != nil {
-			return nil, "", &types.AppError{
-				StatusCode: 500,
-				Message:    "Error parsing date.",
+		if userToken.LastActivity.After(now().Add(timeGap)) && userToken.ExpiresAt.After(now().Add(timeGap)) {
+			if userToken.Disconnected {
+				return nil, "", &types.AppError{
+					StatusCode: 400,
+					Message:    "Session disconnected.",
+				}
 			}
-		}
-
-		timeGap := -(3 * 24) * time.Hour
-
-		if tokenLastActivity.After(time.Now().Add(timeGap)) && tokenExpiresAt.After(time.Now().Add(timeGap)) {
-			expiresIn := int64(30 * 24 * 60 * 60)
 
-			userTokenId, appErr = userTokenModel.Create(
-				userToken.Id,
-				nil,
-				&userToken.Id,
-				ipAddress,
-				device,
-				expiresIn,
+			previousUserToken := userToken
+
+			appErr = repositories.UnitOfWork.Run(
+				request.Context(),
+				func(transaction *models.Repositories) *types.AppError {
+					userToken, appErr = refreshUserToken(
+						request.Context(),
+						transaction,
+						previousUserToken,
+						ipAddress,
+						device,
+						int64(lifetimes.UserSession.Seconds()),
+					)
+
+					return appErr
+				},
 			)
 
 			if appErr != nil {
 				return nil, "", appErr
 			}
 
-			expiredAt := time.Now().Add(30 * 24 * time.Hour).Unix()
+			expiredAt := lifetimes.UserTokenExpiresAt(now()).Unix()
 
-			token, appErr = (&types.UserTokenPayload{
-				UserTokenId: userTokenId,
-				ExpiresAt:   expiredAt,
-				CreatedAt:   time.Now().Unix(),
-			}).ToJWT()
+			tokenString, appErr = (&types.UserTokenPayload{
+				RegisteredClaims: token.RegisteredClaims{
+					ExpiresAt: expiredAt,
+					IssuedAt:  now().Unix(),
+				},
+				UserTokenId: userToken.Id,
+			}).ToJWT(tokens)
 
 			if appErr != nil {
 				return nil, "", appErr
 			}
 
-			userToken, appErr = userTokenModel.FindById(
-				userTokenId,
-			)
-
-			if appErr != nil {
-				return nil, "", appErr
-			}
+			appMetrics.UserSessions.Inc("refreshed")
 		} else {
 			return nil, "", &types.AppError{
 				StatusCode: 400,
@@ -267,20 +181,7 @@ func AuthenticateUser(
 		}
 	}
 
-	tokenCreatedAt, err := time.ParseInLocation(
-		time.DateTime,
-		userToken.CreatedAt,
-		timezone,
-	)
-
-	if err != nil {
-		return nil, "", &types.AppError{
-			StatusCode: 500,
-			Message:    "Error parsing date.",
-		}
-	}
-
-	if tokenCreatedAt.After(time.Now()) {
+	if userToken.CreatedAt.After(now()) {
 		return nil, "", &types.AppError{
 			StatusCode: 400,
 			Message:    "Invalid user token date.",
@@ -296,6 +197,7 @@ func AuthenticateUser(
 
 	if user == nil {
 		user, appErr = userModel.FindById(
+			request.Context(),
 			userToken.UserId,
 		)
 
@@ -312,8 +214,148 @@ func AuthenticateUser(
 	}
 
 	userTokenModel.UpdateActivity(
+		request.Context(),
 		userToken.Id,
 	)
 
-	return user, token, nil
+	return user, tokenString, nil
+}
+
+func consumeLoginToken(
+	ctx context.Context,
+	repositories *models.Repositories,
+	now func() time.Time,
+	loginTokenId,
+	ipAddress,
+	device string,
+	expiresIn int64,
+) (
+	*types.User,
+	string,
+	*types.AppError,
+) {
+	loginToken, appErr := repositories.LoginTokens.FindByIdForUpdate(
+		ctx,
+		loginTokenId,
+	)
+
+	if appErr != nil {
+		return nil, "", appErr
+	}
+
+	if loginToken.ExpiresAt.Before(now()) {
+		return nil, "", &types.AppError{
+			StatusCode: 400,
+			Message:    "Login token has expired.",
+		}
+	}
+
+	if loginToken.CreatedAt.After(now()) {
+		return nil, "", &types.AppError{
+			StatusCode: 400,
+			Message:    "Invalid login token date.",
+		}
+	}
+
+	if loginToken.Denied {
+		return nil, "", &types.AppError{
+			StatusCode: 400,
+			Message:    "Login token denied.",
+		}
+	}
+
+	if !loginToken.Authorized {
+		return nil, "", &types.AppError{
+			StatusCode: 400,
+			Message:    "Login token not authorized.",
+		}
+	}
+
+	emailAvailable, appErr := repositories.Users.CheckEmailAvailability(
+		ctx,
+		loginToken.Email,
+	)
+
+	if appErr != nil {
+		return nil, "", appErr
+	}
+
+	var user *types.User
+
+	if emailAvailable {
+		userId, appErr := repositories.Users.Create(
+			ctx,
+			loginToken.Email,
+		)
+
+		if appErr != nil {
+			return nil, "", appErr
+		}
+
+		user, appErr = repositories.Users.FindById(
+			ctx,
+			userId,
+		)
+
+		if appErr != nil {
+			return nil, "", appErr
+		}
+	} else {
+		user, appErr = repositories.Users.FindByEmail(
+			ctx,
+			loginToken.Email,
+		)
+
+		if appErr != nil {
+			return nil, "", appErr
+		}
+	}
+
+	userTokenId, appErr := repositories.UserTokens.Create(
+		ctx,
+		user.Id,
+		&loginToken.Id,
+		nil,
+		nil,
+		nil,
+		ipAddress,
+		device,
+		expiresIn,
+	)
+
+	if appErr != nil {
+		return nil, "", appErr
+	}
+
+	return user, userTokenId, nil
+}
+
+func refreshUserToken(
+	ctx context.Context,
+	repositories *models.Repositories,
+	userToken *types.UserToken,
+	ipAddress,
+	device string,
+	expiresIn int64,
+) (*types.UserToken, *types.AppError) {
+	userTokenId, appErr := repositories.UserTokens.Create(
+		ctx,
+		userToken.UserId,
+		nil,
+		&userToken.Id,
+		nil,
+		nil,
+		ipAddress,
+		device,
+		expiresIn,
+	)
+
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	return repositories.UserTokens.FindById(
+		ctx,
+		userTokenId,
+	)
 }
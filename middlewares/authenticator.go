@@ -0,0 +1,166 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sandromai/go-http-server/clock"
+	"github.com/sandromai/go-http-server/config"
+	"github.com/sandromai/go-http-server/metrics"
+	"github.com/sandromai/go-http-server/models"
+	"github.com/sandromai/go-http-server/token"
+	"github.com/sandromai/go-http-server/types"
+	"github.com/sandromai/go-http-server/utils"
+)
+
+type Authenticator struct {
+	Repositories *models.Repositories
+	Tokens       *token.Engine
+	Lifetimes    *config.Lifetimes
+	Clock        clock.Clock
+	Metrics      *metrics.App
+}
+
+func (authenticator *Authenticator) now() time.Time {
+	return clock.Now(authenticator.Clock)
+}
+
+func (authenticator *Authenticator) AuthenticateAdmin(
+	permissions ...string,
+) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			admin, adminToken, appErr := authenticateAdmin(
+				request,
+				authenticator.Repositories,
+				authenticator.Tokens,
+				authenticator.now,
+			)
+
+			if appErr != nil {
+				utils.ReturnJSONResponse(
+					writer,
+					appErr.StatusCode,
+					&types.ReturnError{Error: appErr.Message},
+				)
+
+				return
+			}
+
+			for _, permission := range permissions {
+				if !admin.HasPermission(permission) {
+					utils.ReturnJSONResponse(writer, 403, &types.ReturnError{
+						Error: "You don't have permission to perform this action.",
+					})
+
+					return
+				}
+			}
+
+			recordAdmin(request, admin.Id)
+
+			request = withValue(request, adminContextKey, admin)
+			request = withValue(request, adminTokenContextKey, adminToken)
+
+			next.ServeHTTP(writer, request)
+		})
+	}
+}
+
+func (authenticator *Authenticator) AuthenticateUser(
+	next http.Handler,
+) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		user, userToken, appErr := authenticateUser(
+			request,
+			authenticator.Repositories,
+			authenticator.Tokens,
+			authenticator.now,
+			authenticator.Lifetimes,
+			authenticator.Metrics,
+		)
+
+		if appErr != nil {
+			utils.ReturnJSONResponse(
+				writer,
+				appErr.StatusCode,
+				&types.ReturnError{Error: appErr.Message},
+			)
+
+			return
+		}
+
+		recordUser(request, user.Id)
+
+		request = withValue(request, userContextKey, user)
+
+		if userToken != "" {
+			writer.Header().Set("X-Refreshed-Token", userToken)
+
+			request = withValue(request, refreshedUserTokenContextKey, userToken)
+		}
+
+		next.ServeHTTP(writer, request)
+	})
+}
+
+func (authenticator *Authenticator) InspectAccessToken(
+	ctx context.Context,
+	tokenString string,
+) (*types.OAuthAccess, *types.AppError) {
+	return inspectAccessToken(
+		ctx,
+		authenticator.Repositories,
+		authenticator.Tokens,
+		authenticator.now,
+		tokenString,
+	)
+}
+
+func (authenticator *Authenticator) AuthenticateOAuth(
+	scopes ...string,
+) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			access, appErr := authenticator.authenticateOAuth(request)
+
+			if appErr != nil {
+				if appErr.StatusCode == 401 {
+					writer.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				}
+
+				utils.ReturnJSONResponse(
+					writer,
+					appErr.StatusCode,
+					&types.ReturnError{Error: appErr.Message},
+				)
+
+				return
+			}
+
+			for _, scope := range scopes {
+				if !access.HasScope(scope) {
+					writer.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope", scope="`+strings.Join(scopes, " ")+`"`)
+
+					utils.ReturnJSONResponse(writer, 403, &types.ReturnError{
+						Error: "Insufficient scope.",
+					})
+
+					return
+				}
+			}
+
+			request = withValue(request, oauthAccessContextKey, access)
+
+			if access.User != nil {
+				recordUser(request, access.User.Id)
+
+				request = withValue(request, userContextKey, access.User)
+			}
+
+			next.ServeHTTP(writer, request)
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/sandromai/go-http-server/types"
+)
+
+type contextKey int
+
+const (
+	adminContextKey contextKey = iota
+	adminTokenContextKey
+	userContextKey
+	refreshedUserTokenContextKey
+	oauthAccessContextKey
+	requestIdContextKey
+	loggerContextKey
+	accessLogContextKey
+)
+
+func withValue(
+	request *http.Request,
+	key contextKey,
+	value any,
+) *http.Request {
+	return request.WithContext(
+		context.WithValue(request.Context(), key, value),
+	)
+}
+
+func AuthenticatedAdmin(
+	request *http.Request,
+) *types.Admin {
+	admin, _ := request.Context().Value(adminContextKey).(*types.Admin)
+
+	return admin
+}
+
+func AuthenticatedAdminToken(
+	request *http.Request,
+) *types.AdminToken {
+	adminToken, _ := request.Context().Value(adminTokenContextKey).(*types.AdminToken)
+
+	return adminToken
+}
+
+func AuthenticatedUser(
+	request *http.Request,
+) *types.User {
+	user, _ := request.Context().Value(userContextKey).(*types.User)
+
+	return user
+}
+
+func RefreshedUserToken(
+	request *http.Request,
+) string {
+	userToken, _ := request.Context().Value(refreshedUserTokenContextKey).(string)
+
+	return userToken
+}
+
+func AuthenticatedOAuthAccess(
+	request *http.Request,
+) *types.OAuthAccess {
+	access, _ := request.Context().Value(oauthAccessContextKey).(*types.OAuthAccess)
+
+	return access
+}
@@ -0,0 +1,29 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/sandromai/go-http-server/router"
+	"github.com/sandromai/go-http-server/types"
+	"github.com/sandromai/go-http-server/utils"
+)
+
+func LimitBody(
+	maxBytes int64,
+) router.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			if request.ContentLength > maxBytes {
+				utils.ReturnJSONResponse(writer, 413, &types.ReturnError{
+					Error: "Request body too large.",
+				})
+
+				return
+			}
+
+			request.Body = http.MaxBytesReader(writer, request.Body, maxBytes)
+
+			next.ServeHTTP(writer, request)
+		})
+	}
+}
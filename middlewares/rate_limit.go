@@ -0,0 +1,136 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/sandromai/go-http-server/metrics"
+	"github.com/sandromai/go-http-server/ratelimit"
+	"github.com/sandromai/go-http-server/router"
+	"github.com/sandromai/go-http-server/types"
+	"github.com/sandromai/go-http-server/utils"
+)
+
+type RateLimit struct {
+	Limiter *ratelimit.Limiter
+	Metrics *metrics.App
+}
+
+func (rateLimit *RateLimit) PerIP(
+	rule ratelimit.Rule,
+) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			ipAddress := utils.GetRemoteIP(request)
+
+			if !rateLimit.Allow(writer, request, rule, router.RoutePattern(request)+":"+ipAddress) {
+				return
+			}
+
+			next.ServeHTTP(writer, request)
+		})
+	}
+}
+
+func (rateLimit *RateLimit) Allow(
+	writer http.ResponseWriter,
+	request *http.Request,
+	rule ratelimit.Rule,
+	key string,
+) bool {
+	if rateLimit == nil {
+		return true
+	}
+
+	result, err := rateLimit.Limiter.Allow(request.Context(), rule, key)
+
+	if err != nil {
+		Logger(request).Warn("rate limit lookup failed", "rule", rule.Name, "error", err.Error())
+
+		return true
+	}
+
+	ratelimit.SetHeaders(writer.Header(), result)
+
+	if result.Allowed {
+		return true
+	}
+
+	rateLimit.Metrics.RateLimited.Inc(rule.Name)
+
+	utils.ReturnJSONResponse(writer, 429, &types.ReturnError{
+		Error: "Too many requests, try again later.",
+	})
+
+	return false
+}
+
+func (rateLimit *RateLimit) Locked(
+	writer http.ResponseWriter,
+	request *http.Request,
+	lockout ratelimit.Lockout,
+	key string,
+) bool {
+	if rateLimit == nil {
+		return false
+	}
+
+	remaining, err := rateLimit.Limiter.Locked(request.Context(), lockout, key)
+
+	if err != nil {
+		Logger(request).Warn("lockout lookup failed", "rule", lockout.Name, "error", err.Error())
+
+		return false
+	}
+
+	if remaining <= 0 {
+		return false
+	}
+
+	rateLimit.Metrics.RateLimited.Inc(lockout.Name)
+
+	ratelimit.SetHeaders(writer.Header(), &ratelimit.Result{RetryAfter: remaining})
+
+	utils.ReturnJSONResponse(writer, 429, &types.ReturnError{
+		Error: "Too many failed attempts, try again later.",
+	})
+
+	return true
+}
+
+func (rateLimit *RateLimit) Fail(
+	request *http.Request,
+	lockout ratelimit.Lockout,
+	key string,
+) {
+	if rateLimit == nil {
+		return
+	}
+
+	lockedFor, err := rateLimit.Limiter.Fail(request.Context(), lockout, key)
+
+	if err != nil {
+		Logger(request).Warn("lockout update failed", "rule", lockout.Name, "error", err.Error())
+
+		return
+	}
+
+	if lockedFor > 0 {
+		rateLimit.Metrics.Lockouts.Inc(lockout.Name)
+
+		Logger(request).Warn("lockout started", "rule", lockout.Name, "duration", lockedFor.String())
+	}
+}
+
+func (rateLimit *RateLimit) Clear(
+	request *http.Request,
+	lockout ratelimit.Lockout,
+	key string,
+) {
+	if rateLimit == nil {
+		return
+	}
+
+	if err := rateLimit.Limiter.Clear(request.Context(), lockout, key); err != nil {
+		Logger(request).Warn("lockout reset failed", "rule", lockout.Name, "error", err.Error())
+	}
+}
@@ -0,0 +1,58 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sandromai/go-http-server/clock"
+	"github.com/sandromai/go-http-server/metrics"
+	"github.com/sandromai/go-http-server/ratelimit"
+	"github.com/sandromai/go-http-server/ratelimit/memory"
+)
+
+func TestPerIPKeysByHost(t *testing.T) {
+	rateLimit := &RateLimit{
+		Limiter: &ratelimit.Limiter{
+			Backend: memory.NewStore(),
+			Clock:   clock.NewManual(time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)),
+		},
+		Metrics: metrics.NewApp(),
+	}
+
+	handler := rateLimit.PerIP(ratelimit.Rule{
+		Name:      "test",
+		Algorithm: ratelimit.SlidingWindow,
+		Limit:     1,
+		Window:    time.Minute,
+	})(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {}))
+
+	tests := []struct {
+		remoteAddr string
+		status     int
+	}{
+		{"[2001:db8::1]:5000", 200},
+		{"[2001:db8::1]:6000", 429},
+		{"[2001:db8::2]:5000", 200},
+		{"[::1]:5000", 200},
+		{"203.0.113.9:5000", 200},
+		{"203.0.113.9:6000", 429},
+		{"203.0.113.10:5000", 200},
+		{"203.0.113.11", 200},
+		{"203.0.113.11:5000", 429},
+	}
+
+	for _, test := range tests {
+		request := httptest.NewRequest("POST", "/login", nil)
+		request.RemoteAddr = test.remoteAddr
+
+		recorder := httptest.NewRecorder()
+
+		handler.ServeHTTP(recorder, request)
+
+		if recorder.Code != test.status {
+			t.Fatalf("%v: got %v, expected %v", test.remoteAddr, recorder.Code, test.status)
+		}
+	}
+}
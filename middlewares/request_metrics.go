@@ -0,0 +1,52 @@
+package middlewares
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sandromai/go-http-server/metrics"
+	"github.com/sandromai/go-http-server/router"
+)
+
+type RequestMetrics struct {
+	Metrics *metrics.App
+}
+
+func metricMethod(
+	method string,
+) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	}
+
+	return "OTHER"
+}
+
+func (requestMetrics *RequestMetrics) Handle(
+	next http.Handler,
+) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		startedAt := time.Now()
+
+		recorder := &statusRecorder{ResponseWriter: writer}
+
+		next.ServeHTTP(recorder, request)
+
+		if recorder.status == 0 {
+			recorder.status = 200
+		}
+
+		route := router.RoutePattern(request)
+
+		if route == "" {
+			route = "unmatched"
+		}
+
+		method := metricMethod(request.Method)
+
+		requestMetrics.Metrics.HTTPRequests.Inc(method, route, strconv.Itoa(recorder.status))
+		requestMetrics.Metrics.HTTPDuration.Observe(time.Since(startedAt).Seconds(), method, route)
+	})
+}
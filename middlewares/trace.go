@@ -0,0 +1,59 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/sandromai/go-http-server/router"
+	"github.com/sandromai/go-http-server/tracing"
+)
+
+func Trace(
+	next http.Handler,
+) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(
+			request.Context(),
+			propagation.HeaderCarrier(request.Header),
+		)
+
+		ctx, span := tracing.Tracer().Start(
+			ctx,
+			request.Method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", request.Method),
+				attribute.String("url.path", request.URL.Path),
+				attribute.String("user_agent.original", request.UserAgent()),
+			),
+		)
+
+		defer span.End()
+
+		request = request.WithContext(ctx)
+
+		recorder := &statusRecorder{ResponseWriter: writer}
+
+		next.ServeHTTP(recorder, request)
+
+		if recorder.status == 0 {
+			recorder.status = 200
+		}
+
+		if route := router.RoutePattern(request); route != "" {
+			span.SetName(request.Method + " " + route)
+			span.SetAttributes(attribute.String("http.route", route))
+		}
+
+		span.SetAttributes(attribute.Int("http.response.status_code", recorder.status))
+
+		if recorder.status >= 500 {
+			span.SetStatus(codes.Error, http.StatusText(recorder.status))
+		}
+	})
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/sandromai/go-http-server/config"
+	"github.com/sandromai/go-http-server/database"
+	"github.com/sandromai/go-http-server/models"
+)
+
+const migrateUsage = "usage: migrate up | migrate down [steps] | migrate status"
+
+func newMigrator(
+	db *sql.DB,
+	dialect database.Dialect,
+	settings config.Database,
+) (*database.Migrator, error) {
+	migrations, err := database.Migrations(dialect)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &database.Migrator{
+		DB:          db,
+		Dialect:     dialect,
+		LockTimeout: settings.MigrationLock,
+		Migrations:  migrations,
+	}, nil
+}
+
+func migrate(
+	ctx context.Context,
+	appConfig *config.Config,
+	args []string,
+) error {
+	if appConfig.Database.Driver == "memory" {
+		return errors.New("migrate: the in-memory store has no schema to migrate")
+	}
+
+	dialect, err := database.NewDialect(appConfig.Database.Driver)
+
+	if err != nil {
+		return err
+	}
+
+	db, err := models.Open(
+		dialect,
+		appConfig.Database,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	defer db.Close()
+
+	migrator, err := newMigrator(db, dialect, appConfig.Database)
+
+	if err != nil {
+		return err
+	}
+
+	return runMigrate(ctx, migrator, args)
+}
+
+func runMigrate(
+	ctx context.Context,
+	migrator *database.Migrator,
+	args []string,
+) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+
+		for _, migration := range applied {
+			fmt.Println("applied", migration)
+		}
+
+		if err == nil && len(applied) == 0 {
+			fmt.Println("database is up to date")
+		}
+
+		return err
+	case "down":
+		steps := 1
+
+		if len(args) > 1 {
+			parsedSteps, err := strconv.Atoi(args[1])
+
+			if err != nil || parsedSteps < 1 {
+				return errors.New("migrate down: steps must be a positive integer")
+			}
+
+			steps = parsedSteps
+		}
+
+		reverted, err := migrator.Down(ctx, steps)
+
+		for _, migration := range reverted {
+			fmt.Println("reverted", migration)
+		}
+
+		return err
+	case "status":
+		statuses, err := migrator.Status(ctx)
+
+		if err != nil {
+			return err
+		}
+
+		for _, status := range statuses {
+			if status.Applied {
+				fmt.Printf("%-45v applied at %v\n", status.Migration, status.AppliedAt)
+			} else {
+				fmt.Printf("%-45v pending\n", status.Migration)
+			}
+		}
+
+		return nil
+	default:
+		return errors.New(migrateUsage)
+	}
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"context"
 	"database/sql"
 
 	"golang.org/x/crypto/bcrypt"
@@ -9,74 +10,73 @@ import (
 	"github.com/sandromai/go-http-server/utils"
 )
 
-type Admin struct{}
+type Admin struct {
+	connection
+	passwordCost int
+}
 
-func (*Admin) checkIdAvailability(
+func (model *Admin) checkIdAvailability(
+	ctx context.Context,
 	id string,
 ) (bool, *types.AppError) {
-	dbConnection, appErr := getDBInstance()
+	dbConnection := model.db
 
-	if appErr != nil {
-		return false, appErr
-	}
+	ctx, cancel := model.withTimeout(ctx)
 
-	statement, err := dbConnection.Prepare(
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
 		"SELECT `id` FROM `admins` WHERE `id` = ? LIMIT 1",
 	)
 
 	if err != nil {
-		return false, &types.AppError{
-			StatusCode: 500,
-			Message:    "Failed to check ID availability.",
-		}
+		return false, databaseError(err, "Failed to check ID availability.")
 	}
 
 	defer statement.Close()
 
 	adminId := ""
 
-	err = statement.QueryRow(id).Scan(&adminId)
+	err = statement.QueryRowContext(ctx, id).Scan(&adminId)
 
 	if err == sql.ErrNoRows {
 		return true, nil
 	}
 
 	if err != nil {
-		return false, &types.AppError{
-			StatusCode: 500,
-			Message:    "Error checking ID availability.",
-		}
+		return false, databaseError(err, "Error checking ID availability.")
 	}
 
 	return false, nil
 }
 
-func (*Admin) checkUsernameAvailability(
+func (model *Admin) checkUsernameAvailability(
+	ctx context.Context,
 	username string,
 	excludeId string,
 ) (bool, *types.AppError) {
-	dbConnection, appErr := getDBInstance()
+	dbConnection := model.db
 
-	if appErr != nil {
-		return false, appErr
-	}
+	ctx, cancel := model.withTimeout(ctx)
 
-	statement, err := dbConnection.Prepare(
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
 		"SELECT `id` FROM `admins` WHERE `username` = ? AND `id` != ? LIMIT 1",
 	)
 
 	if err != nil {
-		return false, &types.AppError{
-			StatusCode: 500,
-			Message:    "Failed to check username availability.",
-		}
+		return false, databaseError(err, "Failed to check username availability.")
 	}
 
 	defer statement.Close()
 
 	adminId := ""
 
-	err = statement.QueryRow(
+	err = statement.QueryRowContext(
+		ctx,
 		username,
 		excludeId,
 	).Scan(
@@ -88,16 +88,15 @@ func (*Admin) checkUsernameAvailability(
 	}
 
 	if err != nil {
-		return false, &types.AppError{
-			StatusCode: 500,
-			Message:    "Error checking username availability.",
-		}
+		return false, databaseError(err, "Error checking username availability.")
 	}
 
 	return false, nil
 }
 
-func (admin *Admin) generateId() (
+func (model *Admin) generateId(
+	ctx context.Context,
+) (
 	string,
 	*types.AppError,
 ) {
@@ -107,7 +106,8 @@ func (admin *Admin) generateId() (
 		return "", appErr
 	}
 
-	idAvailability, appErr := admin.checkIdAvailability(
+	idAvailability, appErr := model.checkIdAvailability(
+		ctx,
 		id,
 	)
 
@@ -122,7 +122,8 @@ func (admin *Admin) generateId() (
 			return "", appErr
 		}
 
-		idAvailability, appErr = admin.checkIdAvailability(
+		idAvailability, appErr = model.checkIdAvailability(
+			ctx,
 			id,
 		)
 
@@ -141,36 +142,36 @@ func (admin *Admin) generateId() (
 	return id, nil
 }
 
-func (*Admin) FindById(
+func (model *Admin) FindById(
+	ctx context.Context,
 	id string,
 ) (*types.Admin, *types.AppError) {
-	dbConnection, appErr := getDBInstance()
+	dbConnection := model.db
 
-	if appErr != nil {
-		return nil, appErr
-	}
+	ctx, cancel := model.withTimeout(ctx)
 
-	statement, err := dbConnection.Prepare(
-		"SELECT `id`, `name`, `username`, `created_by`, `created_at` FROM `admins` WHERE `id` = ? LIMIT 1",
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"SELECT `admins`.`id`, `admins`.`name`, `admins`.`username`, COALESCE(`admin_two_factor`.`enabled`, FALSE), `admins`.`created_by`, `admins`.`created_at` FROM `admins` LEFT JOIN `admin_two_factor` ON `admin_two_factor`.`admin_id` = `admins`.`id` WHERE `admins`.`id` = ? LIMIT 1",
 	)
 
 	if err != nil {
-		return nil, &types.AppError{
-			StatusCode: 500,
-			Message:    "Failed to find admin.",
-		}
+		return nil, databaseError(err, "Failed to find admin.")
 	}
 
 	defer statement.Close()
 
 	admin := &types.Admin{}
 
-	err = statement.QueryRow(id).Scan(
+	err = statement.QueryRowContext(ctx, id).Scan(
 		&admin.Id,
 		&admin.Name,
 		&admin.Username,
+		&admin.TwoFactorEnabled,
 		&admin.CreatedBy,
-		&admin.CreatedAt,
+		scanTimestamp(&admin.CreatedAt),
 	)
 
 	if err == sql.ErrNoRows {
@@ -181,16 +182,18 @@ func (*Admin) FindById(
 	}
 
 	if err != nil {
-		return nil, &types.AppError{
-			StatusCode: 500,
-			Message:    "Error searching for admin.",
-		}
+		return nil, databaseError(err, "Error searching for admin.")
+	}
+
+	if appErr := (&Role{connection: model.connection}).LoadAdminAccess(ctx, admin); appErr != nil {
+		return nil, appErr
 	}
 
 	return admin, nil
 }
 
-func (admin *Admin) Create(
+func (model *Admin) Create(
+	ctx context.Context,
 	name,
 	username,
 	password string,
@@ -199,13 +202,14 @@ func (admin *Admin) Create(
 	id string,
 	appErr *types.AppError,
 ) {
-	id, appErr = admin.generateId()
+	id, appErr = model.generateId(ctx)
 
 	if appErr != nil {
 		return "", appErr
 	}
 
-	usernameIsAvailable, appErr := admin.checkUsernameAvailability(
+	usernameIsAvailable, appErr := model.checkUsernameAvailability(
+		ctx,
 		username,
 		"",
 	)
@@ -221,64 +225,60 @@ func (admin *Admin) Create(
 		}
 	}
 
-	dbConnection, appErr := getDBInstance()
+	dbConnection := model.db
 
-	if appErr != nil {
-		return "", appErr
-	}
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
 
-	statement, err := dbConnection.Prepare(
-		"INSERT INTO `admins` (`id`, `name`, `username`, `password`, `created_by`) VALUES(?, ?, ?, ?, ?)",
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"INSERT INTO `admins` (`id`, `name`, `username`, `password`, `created_by`, `created_at`) VALUES(?, ?, ?, ?, ?, ?)",
 	)
 
 	if err != nil {
-		return "", &types.AppError{
-			StatusCode: 500,
-			Message:    "Failed to create admin.",
-		}
+		return "", databaseError(err, "Failed to create admin.")
 	}
 
 	defer statement.Close()
 
 	passwordBytes, err := bcrypt.GenerateFromPassword(
 		[]byte(password),
-		12,
+		model.passwordCost,
 	)
 
 	if err != nil {
-		return "", &types.AppError{
-			StatusCode: 500,
-			Message:    "Failed to hash password.",
-		}
+		return "", databaseError(err, "Failed to hash password.")
 	}
 
 	encryptedPassword := string(passwordBytes)
 
-	_, err = statement.Exec(
+	_, err = statement.ExecContext(
+		ctx,
 		id,
 		name,
 		username,
 		encryptedPassword,
 		createdBy,
+		model.timestamp(model.now()),
 	)
 
 	if err != nil {
-		return "", &types.AppError{
-			StatusCode: 500,
-			Message:    "Error creating admin.",
-		}
+		return "", databaseError(err, "Error creating admin.")
 	}
 
 	return id, nil
 }
 
-func (admin *Admin) Update(
+func (model *Admin) Update(
+	ctx context.Context,
 	name,
 	username,
 	password,
 	id string,
 ) *types.AppError {
-	usernameIsAvailable, appErr := admin.checkUsernameAvailability(
+	usernameIsAvailable, appErr := model.checkUsernameAvailability(
+		ctx,
 		username,
 		id,
 	)
@@ -302,19 +302,16 @@ func (admin *Admin) Update(
 
 	query += " WHERE `id` = ?"
 
-	dbConnection, appErr := getDBInstance()
+	dbConnection := model.db
 
-	if appErr != nil {
-		return appErr
-	}
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
 
-	statement, err := dbConnection.Prepare(query)
+	statement, err := dbConnection.PrepareContext(ctx, query)
 
 	if err != nil {
-		return &types.AppError{
-			StatusCode: 500,
-			Message:    "Failed to update admin.",
-		}
+		return databaseError(err, "Failed to update admin.")
 	}
 
 	defer statement.Close()
@@ -322,19 +319,17 @@ func (admin *Admin) Update(
 	if password != "" {
 		passwordBytes, err := bcrypt.GenerateFromPassword(
 			[]byte(password),
-			12,
+			model.passwordCost,
 		)
 
 		if err != nil {
-			return &types.AppError{
-				StatusCode: 500,
-				Message:    "Failed to hash password.",
-			}
+			return databaseError(err, "Failed to hash password.")
 		}
 
 		encryptedPassword := string(passwordBytes)
 
-		_, err = statement.Exec(
+		_, err = statement.ExecContext(
+			ctx,
 			name,
 			username,
 			encryptedPassword,
@@ -342,48 +337,42 @@ func (admin *Admin) Update(
 		)
 
 		if err != nil {
-			return &types.AppError{
-				StatusCode: 500,
-				Message:    "Error updating admin.",
-			}
+			return databaseError(err, "Error updating admin.")
 		}
 	} else {
-		_, err = statement.Exec(
+		_, err = statement.ExecContext(
+			ctx,
 			name,
 			username,
 			id,
 		)
 
 		if err != nil {
-			return &types.AppError{
-				StatusCode: 500,
-				Message:    "Error updating admin.",
-			}
+			return databaseError(err, "Error updating admin.")
 		}
 	}
 
 	return nil
 }
 
-func (*Admin) Authenticate(
+func (model *Admin) Authenticate(
+	ctx context.Context,
 	username,
 	password string,
 ) (*types.Admin, *types.AppError) {
-	dbConnection, appErr := getDBInstance()
+	dbConnection := model.db
 
-	if appErr != nil {
-		return nil, appErr
-	}
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
 
-	statement, err := dbConnection.Prepare(
-		"SELECT `id`, `name`, `username`, `password`, `created_by`, `created_at` FROM `admins` WHERE `username` = ? LIMIT 1",
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"SELECT `admins`.`id`, `admins`.`name`, `admins`.`username`, `admins`.`password`, COALESCE(`admin_two_factor`.`enabled`, FALSE), `admins`.`created_by`, `admins`.`created_at` FROM `admins` LEFT JOIN `admin_two_factor` ON `admin_two_factor`.`admin_id` = `admins`.`id` WHERE `admins`.`username` = ? LIMIT 1",
 	)
 
 	if err != nil {
-		return nil, &types.AppError{
-			StatusCode: 500,
-			Message:    "Failed to authenticate admin.",
-		}
+		return nil, databaseError(err, "Failed to authenticate admin.")
 	}
 
 	defer statement.Close()
@@ -391,13 +380,14 @@ func (*Admin) Authenticate(
 	admin := &types.Admin{}
 	adminPassword := ""
 
-	err = statement.QueryRow(username).Scan(
+	err = statement.QueryRowContext(ctx, username).Scan(
 		&admin.Id,
 		&admin.Name,
 		&admin.Username,
 		&adminPassword,
+		&admin.TwoFactorEnabled,
 		&admin.CreatedBy,
-		&admin.CreatedAt,
+		scanTimestamp(&admin.CreatedAt),
 	)
 
 	if err == sql.ErrNoRows {
@@ -408,10 +398,7 @@ func (*Admin) Authenticate(
 	}
 
 	if err != nil {
-		return nil, &types.AppError{
-			StatusCode: 500,
-			Message:    "Error authenticating admin.",
-		}
+		return nil, databaseError(err, "Error authenticating admin.")
 	}
 
 	err = bcrypt.CompareHashAndPassword(
@@ -426,5 +413,31 @@ func (*Admin) Authenticate(
 		}
 	}
 
+	if appErr := (&Role{connection: model.connection}).LoadAdminAccess(ctx, admin); appErr != nil {
+		return nil, appErr
+	}
+
 	return admin, nil
 }
+
+func (model *Admin) Count(
+	ctx context.Context,
+) (int64, *types.AppError) {
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	admins := int64(0)
+
+	err := dbConnection.QueryRowContext(ctx, "SELECT COUNT(`id`) FROM `admins`").Scan(
+		&admins,
+	)
+
+	if err != nil {
+		return 0, databaseError(err, "Error counting admins.")
+	}
+
+	return admins, nil
+}
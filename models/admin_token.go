@@ -0,0 +1,347 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/sandromai/go-http-server/types"
+	"github.com/sandromai/go-http-server/utils"
+)
+
+type AdminToken struct {
+	connection
+}
+
+func (model *AdminToken) checkIdAvailability(
+	ctx context.Context,
+	id string,
+) (bool, *types.AppError) {
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"SELECT `id` FROM `admin_tokens` WHERE `id` = ? LIMIT 1",
+	)
+
+	if err != nil {
+		return false, databaseError(err, "Failed to check ID availability.")
+	}
+
+	defer statement.Close()
+
+	adminTokenId := ""
+
+	err = statement.QueryRowContext(ctx, id).Scan(&adminTokenId)
+
+	if err == sql.ErrNoRows {
+		return true, nil
+	}
+
+	if err != nil {
+		return false, databaseError(err, "Error checking ID availability.")
+	}
+
+	return false, nil
+}
+
+func (model *AdminToken) generateId(
+	ctx context.Context,
+) (
+	string,
+	*types.AppError,
+) {
+	id, appErr := utils.GenerateUUIDv4()
+
+	if appErr != nil {
+		return "", appErr
+	}
+
+	idAvailability, appErr := model.checkIdAvailability(
+		ctx,
+		id,
+	)
+
+	if appErr != nil {
+		return "", appErr
+	}
+
+	for i := 0; i < 20 && !idAvailability; i++ {
+		id, appErr = utils.GenerateUUIDv4()
+
+		if appErr != nil {
+			return "", appErr
+		}
+
+		idAvailability, appErr = model.checkIdAvailability(
+			ctx,
+			id,
+		)
+
+		if appErr != nil {
+			return "", appErr
+		}
+	}
+
+	if !idAvailability {
+		return "", &types.AppError{
+			StatusCode: 500,
+			Message:    "Failed to generate ID.",
+		}
+	}
+
+	return id, nil
+}
+
+func (model *AdminToken) FindById(
+	ctx context.Context,
+	id string,
+) (
+	*types.AdminToken,
+	*types.AppError,
+) {
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"SELECT `id`, `admin_id`, `ip_address`, `device`, `disconnected`, `last_activity`, `expires_at`, `created_at` FROM `admin_tokens` WHERE `id` = ? LIMIT 1",
+	)
+
+	if err != nil {
+		return nil, databaseError(err, "Failed to find admin token.")
+	}
+
+	defer statement.Close()
+
+	adminToken := &types.AdminToken{}
+
+	err = statement.QueryRowContext(ctx, id).Scan(
+		&adminToken.Id,
+		&adminToken.AdminId,
+		&adminToken.IPAddress,
+		&adminToken.Device,
+		&adminToken.Disconnected,
+		scanTimestamp(&adminToken.LastActivity),
+		scanTimestamp(&adminToken.ExpiresAt),
+		scanTimestamp(&adminToken.CreatedAt),
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, &types.AppError{
+			StatusCode: 404,
+			Message:    "Admin token not found.",
+		}
+	}
+
+	if err != nil {
+		return nil, databaseError(err, "Error searching admin token.")
+	}
+
+	return adminToken, nil
+}
+
+func (model *AdminToken) ListByAdmin(
+	ctx context.Context,
+	adminId string,
+) (
+	[]*types.AdminToken,
+	*types.AppError,
+) {
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"SELECT `id`, `admin_id`, `ip_address`, `device`, `disconnected`, `last_activity`, `expires_at`, `created_at` FROM `admin_tokens` WHERE `admin_id` = ? AND `disconnected` = FALSE AND `expires_at` > ? ORDER BY `last_activity` DESC",
+	)
+
+	if err != nil {
+		return nil, databaseError(err, "Failed to list admin tokens.")
+	}
+
+	defer statement.Close()
+
+	rows, err := statement.QueryContext(ctx, adminId, model.timestamp(model.now()))
+
+	if err != nil {
+		return nil, databaseError(err, "Error listing admin tokens.")
+	}
+
+	defer rows.Close()
+
+	adminTokens := []*types.AdminToken{}
+
+	for rows.Next() {
+		adminToken := &types.AdminToken{}
+
+		err = rows.Scan(
+			&adminToken.Id,
+			&adminToken.AdminId,
+			&adminToken.IPAddress,
+			&adminToken.Device,
+			&adminToken.Disconnected,
+			scanTimestamp(&adminToken.LastActivity),
+			scanTimestamp(&adminToken.ExpiresAt),
+			scanTimestamp(&adminToken.CreatedAt),
+		)
+
+		if err != nil {
+			return nil, databaseError(err, "Error listing admin tokens.")
+		}
+
+		adminTokens = append(adminTokens, adminToken)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, databaseError(err, "Error listing admin tokens.")
+	}
+
+	return adminTokens, nil
+}
+
+func (model *AdminToken) Create(
+	ctx context.Context,
+	adminId,
+	ipAddress,
+	device string,
+	expiresIn int64,
+) (
+	id string,
+	appErr *types.AppError,
+) {
+	id, appErr = model.generateId(ctx)
+
+	if appErr != nil {
+		return "", appErr
+	}
+
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"INSERT INTO `admin_tokens` (`id`, `admin_id`, `ip_address`, `device`, `last_activity`, `expires_at`, `created_at`) VALUES(?, ?, ?, ?, ?, ?, ?)",
+	)
+
+	if err != nil {
+		return "", databaseError(err, "Failed to create admin token.")
+	}
+
+	defer statement.Close()
+
+	now := model.now()
+
+	_, err = statement.ExecContext(
+		ctx,
+		id,
+		adminId,
+		ipAddress,
+		device,
+		model.timestamp(now),
+		model.expiresAt(now, expiresIn),
+		model.timestamp(now),
+	)
+
+	if err != nil {
+		return "", databaseError(err, "Error creating admin token.")
+	}
+
+	return id, nil
+}
+
+func (model *AdminToken) UpdateActivity(
+	ctx context.Context,
+	id string,
+) *types.AppError {
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"UPDATE `admin_tokens` SET `last_activity` = ? WHERE `id` = ?",
+	)
+
+	if err != nil {
+		return databaseError(err, "Failed to update admin token activity.")
+	}
+
+	defer statement.Close()
+
+	if _, err = statement.ExecContext(ctx, model.timestamp(model.now()), id); err != nil {
+		return databaseError(err, "Error updating admin token activity.")
+	}
+
+	return nil
+}
+
+func (model *AdminToken) Disconnect(
+	ctx context.Context,
+	id string,
+) *types.AppError {
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"UPDATE `admin_tokens` SET `disconnected` = TRUE WHERE `id` = ?",
+	)
+
+	if err != nil {
+		return databaseError(err, "Failed to disconnect admin token.")
+	}
+
+	defer statement.Close()
+
+	if _, err = statement.ExecContext(ctx, id); err != nil {
+		return databaseError(err, "Error disconnecting admin token.")
+	}
+
+	return nil
+}
+
+func (model *AdminToken) DisconnectAllByAdmin(
+	ctx context.Context,
+	adminId string,
+) *types.AppError {
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"UPDATE `admin_tokens` SET `disconnected` = TRUE WHERE `admin_id` = ? AND `disconnected` = FALSE",
+	)
+
+	if err != nil {
+		return databaseError(err, "Failed to disconnect admin tokens.")
+	}
+
+	defer statement.Close()
+
+	if _, err = statement.ExecContext(ctx, adminId); err != nil {
+		return databaseError(err, "Error disconnecting admin tokens.")
+	}
+
+	return nil
+}
@@ -0,0 +1,285 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/sandromai/go-http-server/types"
+	"github.com/sandromai/go-http-server/utils"
+)
+
+type AdminTwoFactor struct {
+	connection
+	encryptionKey []byte
+}
+
+func (model *AdminTwoFactor) FindByAdmin(
+	ctx context.Context,
+	adminId string,
+) (
+	*types.AdminTwoFactor,
+	*types.AppError,
+) {
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"SELECT `admin_id`, `secret`, `enabled`, `last_used_step`, `created_at` FROM `admin_two_factor` WHERE `admin_id` = ? LIMIT 1",
+	)
+
+	if err != nil {
+		return nil, databaseError(err, "Failed to find two-factor settings.")
+	}
+
+	defer statement.Close()
+
+	twoFactor := &types.AdminTwoFactor{}
+
+	err = statement.QueryRowContext(ctx, adminId).Scan(
+		&twoFactor.AdminId,
+		&twoFactor.Secret,
+		&twoFactor.Enabled,
+		&twoFactor.LastUsedStep,
+		scanTimestamp(&twoFactor.CreatedAt),
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, &types.AppError{
+			StatusCode: 404,
+			Message:    "Two-factor authentication not configured.",
+		}
+	}
+
+	if err != nil {
+		return nil, databaseError(err, "Error searching for two-factor settings.")
+	}
+
+	secret, appErr := utils.Decrypt(model.encryptionKey, twoFactor.Secret)
+
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	twoFactor.Secret = secret
+
+	return twoFactor, nil
+}
+
+func (model *AdminTwoFactor) Enroll(
+	ctx context.Context,
+	adminId,
+	secret string,
+) *types.AppError {
+	encryptedSecret, appErr := utils.Encrypt(model.encryptionKey, secret)
+
+	if appErr != nil {
+		return appErr
+	}
+
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		model.dialect.Upsert(
+			"INSERT INTO `admin_two_factor` (`admin_id`, `secret`, `created_at`) VALUES(?, ?, ?) ON DUPLICATE KEY UPDATE `secret` = VALUES(`secret`), `enabled` = FALSE, `last_used_step` = 0",
+			"admin_id",
+		),
+	)
+
+	if err != nil {
+		return databaseError(err, "Failed to enroll two-factor authentication.")
+	}
+
+	defer statement.Close()
+
+	if _, err = statement.ExecContext(ctx, adminId, encryptedSecret, model.timestamp(model.now())); err != nil {
+		return databaseError(err, "Error enrolling two-factor authentication.")
+	}
+
+	return nil
+}
+
+func (model *AdminTwoFactor) Enable(
+	ctx context.Context,
+	adminId string,
+	recoveryCodeHashes []string,
+) *types.AppError {
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	transaction, err := model.begin(ctx)
+
+	if err != nil {
+		return databaseError(err, "Failed to enable two-factor authentication.")
+	}
+
+	defer transaction.Rollback()
+
+	_, err = transaction.ExecContext(
+		ctx,
+		"UPDATE `admin_two_factor` SET `enabled` = TRUE WHERE `admin_id` = ?",
+		adminId,
+	)
+
+	if err != nil {
+		return databaseError(err, "Error enabling two-factor authentication.")
+	}
+
+	_, err = transaction.ExecContext(
+		ctx,
+		"DELETE FROM `admin_recovery_codes` WHERE `admin_id` = ?",
+		adminId,
+	)
+
+	if err != nil {
+		return databaseError(err, "Error creating recovery codes.")
+	}
+
+	createdAt := model.timestamp(model.now())
+
+	for _, codeHash := range recoveryCodeHashes {
+		_, err = transaction.ExecContext(
+			ctx,
+			"INSERT INTO `admin_recovery_codes` (`admin_id`, `code_hash`, `created_at`) VALUES(?, ?, ?)",
+			adminId,
+			codeHash,
+			createdAt,
+		)
+
+		if err != nil {
+			return databaseError(err, "Error creating recovery codes.")
+		}
+	}
+
+	if err = transaction.Commit(); err != nil {
+		return databaseError(err, "Error enabling two-factor authentication.")
+	}
+
+	return nil
+}
+
+func (model *AdminTwoFactor) Disable(
+	ctx context.Context,
+	adminId string,
+) *types.AppError {
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	transaction, err := model.begin(ctx)
+
+	if err != nil {
+		return databaseError(err, "Failed to disable two-factor authentication.")
+	}
+
+	defer transaction.Rollback()
+
+	_, err = transaction.ExecContext(
+		ctx,
+		"DELETE FROM `admin_recovery_codes` WHERE `admin_id` = ?",
+		adminId,
+	)
+
+	if err != nil {
+		return databaseError(err, "Error disabling two-factor authentication.")
+	}
+
+	_, err = transaction.ExecContext(
+		ctx,
+		"DELETE FROM `admin_two_factor` WHERE `admin_id` = ?",
+		adminId,
+	)
+
+	if err != nil {
+		return databaseError(err, "Error disabling two-factor authentication.")
+	}
+
+	if err = transaction.Commit(); err != nil {
+		return databaseError(err, "Error disabling two-factor authentication.")
+	}
+
+	return nil
+}
+
+func (model *AdminTwoFactor) UseStep(
+	ctx context.Context,
+	adminId string,
+	step int64,
+) (bool, *types.AppError) {
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"UPDATE `admin_two_factor` SET `last_used_step` = ? WHERE `admin_id` = ? AND `last_used_step` < ?",
+	)
+
+	if err != nil {
+		return false, databaseError(err, "Failed to use two-factor code.")
+	}
+
+	defer statement.Close()
+
+	result, err := statement.ExecContext(ctx, step, adminId, step)
+
+	if err != nil {
+		return false, databaseError(err, "Error using two-factor code.")
+	}
+
+	affectedRows, err := result.RowsAffected()
+
+	if err != nil {
+		return false, databaseError(err, "Error using two-factor code.")
+	}
+
+	return affectedRows == 1, nil
+}
+
+func (model *AdminTwoFactor) UseRecoveryCode(
+	ctx context.Context,
+	adminId,
+	codeHash string,
+) (bool, *types.AppError) {
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"UPDATE `admin_recovery_codes` SET `used_at` = ? WHERE `admin_id` = ? AND `code_hash` = ? AND `used_at` IS NULL",
+	)
+
+	if err != nil {
+		return false, databaseError(err, "Failed to use recovery code.")
+	}
+
+	defer statement.Close()
+
+	result, err := statement.ExecContext(ctx, model.timestamp(model.now()), adminId, codeHash)
+
+	if err != nil {
+		return false, databaseError(err, "Error using recovery code.")
+	}
+
+	affectedRows, err := result.RowsAffected()
+
+	if err != nil {
+		return false, databaseError(err, "Error using recovery code.")
+	}
+
+	return affectedRows == 1, nil
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strings"
@@ -9,21 +10,26 @@ import (
 	"github.com/sandromai/go-http-server/utils"
 )
 
-type EmailSetting struct{}
+type EmailSetting struct {
+	connection
+	encryptionKey []byte
+}
 
-func (*EmailSetting) List() (
+func (model *EmailSetting) List(
+	ctx context.Context,
+) (
 	*types.EmailSetting,
 	*types.AppError,
 ) {
-	dbConnection, appErr := getDBInstance()
+	dbConnection := model.db
 
-	if appErr != nil {
-		return nil, appErr
-	}
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
 
 	emailSettings := &types.EmailSetting{}
 
-	err := dbConnection.QueryRow("SELECT `host`, `port`, `username`, `password` FROM `email_settings` ORDER BY `id` DESC LIMIT 1").Scan(
+	err := dbConnection.QueryRowContext(ctx, "SELECT `host`, `port`, `username`, `password` FROM `email_settings` ORDER BY `id` DESC LIMIT 1").Scan(
 		&emailSettings.Host,
 		&emailSettings.Port,
 		&emailSettings.Username,
@@ -38,22 +44,22 @@ func (*EmailSetting) List() (
 	}
 
 	if err != nil {
-		return nil, &types.AppError{
-			StatusCode: 500,
-			Message:    "Error searching for email settings.",
-		}
+		return nil, databaseError(err, "Error searching for email settings.")
 	}
 
-	emailSettings.Password, appErr = utils.Decrypt(emailSettings.Password)
+	password, appErr := utils.Decrypt(model.encryptionKey, emailSettings.Password)
 
 	if appErr != nil {
 		return nil, appErr
 	}
 
+	emailSettings.Password = password
+
 	return emailSettings, nil
 }
 
-func (*EmailSetting) Update(
+func (model *EmailSetting) Update(
+	ctx context.Context,
 	data map[string]string,
 ) *types.AppError {
 	var updates []string
@@ -65,7 +71,7 @@ func (*EmailSetting) Update(
 				continue
 			}
 
-			encryptedPassword, err := utils.Encrypt(value)
+			encryptedPassword, err := utils.Encrypt(model.encryptionKey, value)
 
 			if err != nil {
 				return &types.AppError{
@@ -82,32 +88,26 @@ func (*EmailSetting) Update(
 		updates = append(updates, "`"+column+"` = ?")
 	}
 
-	dbConnection, appErr := getDBInstance()
+	dbConnection := model.db
 
-	if appErr != nil {
-		return appErr
-	}
+	ctx, cancel := model.withTimeout(ctx)
 
-	statement, err := dbConnection.Prepare("UPDATE `email_settings` SET " + strings.Join(updates, ", "))
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(ctx, "UPDATE `email_settings` SET "+strings.Join(updates, ", "))
 
 	if err != nil {
-		return &types.AppError{
-			StatusCode: 500,
-			Message:    "Failed to update email settings.",
-		}
+		return databaseError(err, "Failed to update email settings.")
 	}
 
 	defer statement.Close()
 
-	_, err = statement.Exec(values...)
+	_, err = statement.ExecContext(ctx, values...)
 
 	if err != nil {
 		fmt.Println(err.Error())
 
-		return &types.AppError{
-			StatusCode: 500,
-			Message:    "Error updating email settings.",
-		}
+		return databaseError(err, "Error updating email settings.")
 	}
 
 	return nil
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sandromai/go-http-server/types"
+)
+
+func databaseError(
+	err error,
+	message string,
+) *types.AppError {
+	if errors.Is(err, context.Canceled) {
+		return &types.AppError{
+			StatusCode: 499,
+			Message:    "Request canceled.",
+		}
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return &types.AppError{
+			StatusCode: 504,
+			Message:    "Database query timed out.",
+		}
+	}
+
+	return &types.AppError{
+		StatusCode: 500,
+		Message:    message,
+	}
+}
@@ -1,55 +1,57 @@
 package models
 
 import (
+	"context"
 	"database/sql"
+	"time"
 
 	"github.com/sandromai/go-http-server/types"
 	"github.com/sandromai/go-http-server/utils"
 )
 
-type LoginToken struct{}
+type LoginToken struct {
+	connection
+}
 
-func (*LoginToken) checkIdAvailability(
+func (model *LoginToken) checkIdAvailability(
+	ctx context.Context,
 	id string,
 ) (bool, *types.AppError) {
-	dbConnection, appErr := getDBInstance()
+	dbConnection := model.db
 
-	if appErr != nil {
-		return false, appErr
-	}
+	ctx, cancel := model.withTimeout(ctx)
 
-	statement, err := dbConnection.Prepare(
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
 		"SELECT `id` FROM `login_tokens` WHERE `id` = ? LIMIT 1",
 	)
 
 	if err != nil {
-		return false, &types.AppError{
-			StatusCode: 500,
-			Message:    "Failed to check ID availability.",
-		}
+		return false, databaseError(err, "Failed to check ID availability.")
 	}
 
 	defer statement.Close()
 
 	loginTokenId := ""
 
-	err = statement.QueryRow(id).Scan(&loginTokenId)
+	err = statement.QueryRowContext(ctx, id).Scan(&loginTokenId)
 
 	if err == sql.ErrNoRows {
 		return true, nil
 	}
 
 	if err != nil {
-		return false, &types.AppError{
-			StatusCode: 500,
-			Message:    "Error checking ID availability.",
-		}
+		return false, databaseError(err, "Error checking ID availability.")
 	}
 
 	return false, nil
 }
 
-func (loginToken *LoginToken) generateId() (
+func (model *LoginToken) generateId(
+	ctx context.Context,
+) (
 	string,
 	*types.AppError,
 ) {
@@ -59,7 +61,8 @@ func (loginToken *LoginToken) generateId() (
 		return "", appErr
 	}
 
-	idAvailability, appErr := loginToken.checkIdAvailability(
+	idAvailability, appErr := model.checkIdAvailability(
+		ctx,
 		id,
 	)
 
@@ -74,7 +77,8 @@ func (loginToken *LoginToken) generateId() (
 			return "", appErr
 		}
 
-		idAvailability, appErr = loginToken.checkIdAvailability(
+		idAvailability, appErr = model.checkIdAvailability(
+			ctx,
 			id,
 		)
 
@@ -93,39 +97,45 @@ func (loginToken *LoginToken) generateId() (
 	return id, nil
 }
 
-func (*LoginToken) FindById(
+func (model *LoginToken) find(
+	ctx context.Context,
 	id string,
+	forUpdate bool,
 ) (*types.LoginToken, *types.AppError) {
-	dbConnection, appErr := getDBInstance()
+	dbConnection := model.db
 
-	if appErr != nil {
-		return nil, appErr
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	query := "SELECT `id`, `email`, `ip_address`, `device`, `authorized`, `denied`, `expires_at`, `created_at` FROM `login_tokens` WHERE `id` = ? LIMIT 1"
+
+	if forUpdate {
+		query += " FOR UPDATE"
 	}
 
-	statement, err := dbConnection.Prepare(
-		"SELECT `id`, `email`, `ip_address`, `device`, `authorized`, `denied`, `expires_at`, `created_at` FROM `login_tokens` WHERE `id` = ? LIMIT 1",
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		query,
 	)
 
 	if err != nil {
-		return nil, &types.AppError{
-			StatusCode: 500,
-			Message:    "Failed to find login token.",
-		}
+		return nil, databaseError(err, "Failed to find login token.")
 	}
 
 	defer statement.Close()
 
 	loginToken := &types.LoginToken{}
 
-	err = statement.QueryRow(id).Scan(
+	err = statement.QueryRowContext(ctx, id).Scan(
 		&loginToken.Id,
 		&loginToken.Email,
 		&loginToken.IPAddress,
 		&loginToken.Device,
 		&loginToken.Authorized,
 		&loginToken.Denied,
-		&loginToken.ExpiresAt,
-		&loginToken.CreatedAt,
+		scanTimestamp(&loginToken.ExpiresAt),
+		scanTimestamp(&loginToken.CreatedAt),
 	)
 
 	if err == sql.ErrNoRows {
@@ -136,16 +146,28 @@ func (*LoginToken) FindById(
 	}
 
 	if err != nil {
-		return nil, &types.AppError{
-			StatusCode: 500,
-			Message:    "Error searching login token.",
-		}
+		return nil, databaseError(err, "Error searching login token.")
 	}
 
 	return loginToken, nil
 }
 
-func (loginToken *LoginToken) Create(
+func (model *LoginToken) FindById(
+	ctx context.Context,
+	id string,
+) (*types.LoginToken, *types.AppError) {
+	return model.find(ctx, id, false)
+}
+
+func (model *LoginToken) FindByIdForUpdate(
+	ctx context.Context,
+	id string,
+) (*types.LoginToken, *types.AppError) {
+	return model.find(ctx, id, true)
+}
+
+func (model *LoginToken) Create(
+	ctx context.Context,
 	email,
 	ipAddress,
 	device string,
@@ -154,185 +176,212 @@ func (loginToken *LoginToken) Create(
 	id string,
 	appErr *types.AppError,
 ) {
-	id, appErr = loginToken.generateId()
+	email = utils.NormalizeEmail(email)
+
+	id, appErr = model.generateId(ctx)
 
 	if appErr != nil {
 		return "", appErr
 	}
 
-	dbConnection, appErr := getDBInstance()
+	dbConnection := model.db
 
-	if appErr != nil {
-		return "", appErr
-	}
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
 
-	statement, err := dbConnection.Prepare(
-		"INSERT INTO `login_tokens` (`id`, `email`, `ip_address`, `device`, `expires_at`) VALUES(?, ?, ?, ?, DATE_ADD(NOW(), INTERVAL ? SECOND))",
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"INSERT INTO `login_tokens` (`id`, `email`, `ip_address`, `device`, `expires_at`, `created_at`) VALUES(?, ?, ?, ?, ?, ?)",
 	)
 
 	if err != nil {
-		return "", &types.AppError{
-			StatusCode: 500,
-			Message:    "Failed to create login token.",
-		}
+		return "", databaseError(err, "Failed to create login token.")
 	}
 
 	defer statement.Close()
 
-	_, err = statement.Exec(
+	now := model.now()
+
+	_, err = statement.ExecContext(
+		ctx,
 		id,
 		email,
 		ipAddress,
 		device,
-		expiresIn,
+		model.expiresAt(now, expiresIn),
+		model.timestamp(now),
 	)
 
 	if err != nil {
-		return "", &types.AppError{
-			StatusCode: 500,
-			Message:    "Error creating login token.",
-		}
+		return "", databaseError(err, "Error creating login token.")
 	}
 
 	return id, nil
 }
 
-func (loginToken *LoginToken) Authorize(
+func (model *LoginToken) Authorize(
+	ctx context.Context,
 	id string,
 ) *types.AppError {
-	dbConnection, appErr := getDBInstance()
+	dbConnection := model.db
 
-	if appErr != nil {
-		return appErr
-	}
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
 
-	statement, err := dbConnection.Prepare("UPDATE `login_tokens` SET `authorized` = 1 WHERE `id` = ?")
+	statement, err := dbConnection.PrepareContext(ctx, "UPDATE `login_tokens` SET `authorized` = TRUE WHERE `id` = ?")
 
 	if err != nil {
-		return &types.AppError{
-			StatusCode: 500,
-			Message:    "Failed to authorize login token.",
-		}
+		return databaseError(err, "Failed to authorize login token.")
 	}
 
 	defer statement.Close()
 
-	if _, err = statement.Exec(id); err != nil {
-		return &types.AppError{
-			StatusCode: 500,
-			Message:    "Error authorizing login token.",
-		}
+	if _, err = statement.ExecContext(ctx, id); err != nil {
+		return databaseError(err, "Error authorizing login token.")
 	}
 
 	return nil
 }
 
-func (loginToken *LoginToken) Deny(
+func (model *LoginToken) Deny(
+	ctx context.Context,
 	id string,
 ) *types.AppError {
-	dbConnection, appErr := getDBInstance()
+	dbConnection := model.db
 
-	if appErr != nil {
-		return appErr
-	}
+	ctx, cancel := model.withTimeout(ctx)
 
-	statement, err := dbConnection.Prepare("UPDATE `login_tokens` SET `denied` = 1 WHERE `id` = ?")
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(ctx, "UPDATE `login_tokens` SET `denied` = TRUE WHERE `id` = ?")
 
 	if err != nil {
-		return &types.AppError{
-			StatusCode: 500,
-			Message:    "Failed to deny login token.",
-		}
+		return databaseError(err, "Failed to deny login token.")
 	}
 
 	defer statement.Close()
 
-	if _, err = statement.Exec(id); err != nil {
-		return &types.AppError{
-			StatusCode: 500,
-			Message:    "Error denying login token.",
-		}
+	if _, err = statement.ExecContext(ctx, id); err != nil {
+		return databaseError(err, "Error denying login token.")
 	}
 
 	return nil
 }
 
-func (*LoginToken) CountActiveByEmail(
+func (model *LoginToken) CountActiveByEmail(
+	ctx context.Context,
 	email string,
 ) (int64, *types.AppError) {
-	dbConnection, appErr := getDBInstance()
+	email = utils.NormalizeEmail(email)
 
-	if appErr != nil {
-		return 0, appErr
-	}
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
 
-	statement, err := dbConnection.Prepare(
-		"SELECT COUNT(`id`) FROM `login_tokens` WHERE `email` = ? AND `expires_at` > NOW()",
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"SELECT COUNT(`id`) FROM `login_tokens` WHERE `email` = ? AND `expires_at` > ?",
 	)
 
 	if err != nil {
-		return 0, &types.AppError{
-			StatusCode: 500,
-			Message:    "Failed to count active login tokens.",
-		}
+		return 0, databaseError(err, "Failed to count active login tokens.")
 	}
 
 	defer statement.Close()
 
 	activeLoginTokens := int64(0)
 
-	err = statement.QueryRow(email).Scan(
+	err = statement.QueryRowContext(ctx, email, model.timestamp(model.now())).Scan(
 		&activeLoginTokens,
 	)
 
 	if err != nil {
-		return 0, &types.AppError{
-			StatusCode: 500,
-			Message:    "Error counting active login tokens.",
-		}
+		return 0, databaseError(err, "Error counting active login tokens.")
 	}
 
 	return activeLoginTokens, nil
 }
 
-func (*LoginToken) GetLastCreationTimeByEmail(
+func (model *LoginToken) GetLastCreationTimeByEmail(
+	ctx context.Context,
 	email string,
-) (string, *types.AppError) {
-	dbConnection, appErr := getDBInstance()
+) (time.Time, *types.AppError) {
+	email = utils.NormalizeEmail(email)
 
-	if appErr != nil {
-		return "", appErr
-	}
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
 
-	statement, err := dbConnection.Prepare(
-		"SELECT `created_at` FROM `login_tokens` WHERE `email` = ? ORDER BY `id` DESC LIMIT 1",
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"SELECT `created_at` FROM `login_tokens` WHERE `email` = ? ORDER BY `created_at` DESC LIMIT 1",
 	)
 
 	if err != nil {
-		return "", &types.AppError{
-			StatusCode: 500,
-			Message:    "Failed to get creation time from last login token.",
-		}
+		return time.Time{}, databaseError(err, "Failed to get creation time from last login token.")
 	}
 
 	defer statement.Close()
 
-	creationTime := ""
+	creationTime := time.Time{}
 
-	err = statement.QueryRow(email).Scan(
-		&creationTime,
+	err = statement.QueryRowContext(ctx, email).Scan(
+		scanTimestamp(&creationTime),
 	)
 
 	if err == sql.ErrNoRows {
-		return "", nil
+		return time.Time{}, nil
 	}
 
 	if err != nil {
-		return "", &types.AppError{
-			StatusCode: 500,
-			Message:    "Error getting creation time from last login token.",
-		}
+		return time.Time{}, databaseError(err, "Error getting creation time from last login token.")
 	}
 
 	return creationTime, nil
 }
+
+func (model *LoginToken) GetFirstActiveExpirationTimeByEmail(
+	ctx context.Context,
+	email string,
+) (time.Time, *types.AppError) {
+	email = utils.NormalizeEmail(email)
+
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"SELECT `expires_at` FROM `login_tokens` WHERE `email` = ? AND `expires_at` > ? ORDER BY `expires_at` ASC LIMIT 1",
+	)
+
+	if err != nil {
+		return time.Time{}, databaseError(err, "Failed to get expiration time from active login tokens.")
+	}
+
+	defer statement.Close()
+
+	expirationTime := time.Time{}
+
+	err = statement.QueryRowContext(ctx, email, model.timestamp(model.now())).Scan(
+		scanTimestamp(&expirationTime),
+	)
+
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil
+	}
+
+	if err != nil {
+		return time.Time{}, databaseError(err, "Error getting expiration time from active login tokens.")
+	}
+
+	return expirationTime, nil
+}
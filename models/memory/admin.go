@@ -0,0 +1,222 @@
+package memory
+
+import (
+	"context"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/sandromai/go-http-server/types"
+)
+
+type Admin struct {
+	session
+}
+
+func (model *Admin) usernameTaken(
+	username,
+	excludeId string,
+) bool {
+	for _, admin := range model.tables().admins {
+		if admin.Username == username && admin.Id != excludeId {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (model *Admin) load(
+	record adminRecord,
+) *types.Admin {
+	admin := record.Admin
+
+	if twoFactor, found := model.tables().adminTwoFactors[admin.Id]; found {
+		admin.TwoFactorEnabled = twoFactor.Enabled
+	}
+
+	loadAdminAccess(model.tables(), &admin)
+
+	return &admin
+}
+
+func (model *Admin) FindById(
+	ctx context.Context,
+	id string,
+) (
+	*types.Admin,
+	*types.AppError,
+) {
+	defer model.lock()()
+
+	record, found := model.tables().admins[id]
+
+	if !found {
+		return nil, &types.AppError{
+			StatusCode: 404,
+			Message:    "Admin not found.",
+		}
+	}
+
+	return model.load(record), nil
+}
+
+func (model *Admin) Create(
+	ctx context.Context,
+	name,
+	username,
+	password string,
+	createdBy *string,
+) (
+	string,
+	*types.AppError,
+) {
+	passwordBytes, err := bcrypt.GenerateFromPassword(
+		[]byte(password),
+		model.store.passwordCost,
+	)
+
+	if err != nil {
+		return "", &types.AppError{
+			StatusCode: 500,
+			Message:    "Failed to hash password.",
+		}
+	}
+
+	defer model.lock()()
+
+	id, appErr := model.generateId(func(id string) bool {
+		_, found := model.tables().admins[id]
+
+		return found
+	})
+
+	if appErr != nil {
+		return "", appErr
+	}
+
+	if model.usernameTaken(username, "") {
+		return "", &types.AppError{
+			StatusCode: 409,
+			Message:    "Username already registered.",
+		}
+	}
+
+	model.tables().admins[id] = adminRecord{
+		Admin: types.Admin{
+			Id:        id,
+			Name:      name,
+			Username:  username,
+			CreatedBy: createdBy,
+			CreatedAt: model.store.now(),
+		},
+		password: string(passwordBytes),
+	}
+
+	return id, nil
+}
+
+func (model *Admin) Update(
+	ctx context.Context,
+	name,
+	username,
+	password,
+	id string,
+) *types.AppError {
+	passwordHash := ""
+
+	if password != "" {
+		passwordBytes, err := bcrypt.GenerateFromPassword(
+			[]byte(password),
+			model.store.passwordCost,
+		)
+
+		if err != nil {
+			return &types.AppError{
+				StatusCode: 500,
+				Message:    "Failed to hash password.",
+			}
+		}
+
+		passwordHash = string(passwordBytes)
+	}
+
+	defer model.lock()()
+
+	if model.usernameTaken(username, id) {
+		return &types.AppError{
+			StatusCode: 409,
+			Message:    "Username already registered.",
+		}
+	}
+
+	record, found := model.tables().admins[id]
+
+	if !found {
+		return nil
+	}
+
+	record.Name = name
+	record.Username = username
+
+	if passwordHash != "" {
+		record.password = passwordHash
+	}
+
+	model.tables().admins[id] = record
+
+	return nil
+}
+
+func (model *Admin) Authenticate(
+	ctx context.Context,
+	username,
+	password string,
+) (
+	*types.Admin,
+	*types.AppError,
+) {
+	unlock := model.lock()
+
+	var admin *types.Admin
+	passwordHash := ""
+
+	for _, record := range model.tables().admins {
+		if record.Username == username {
+			admin = model.load(record)
+			passwordHash = record.password
+
+			break
+		}
+	}
+
+	unlock()
+
+	if admin == nil {
+		return nil, &types.AppError{
+			StatusCode: 401,
+			Message:    "Incorrect username or password.",
+		}
+	}
+
+	err := bcrypt.CompareHashAndPassword(
+		[]byte(passwordHash),
+		[]byte(password),
+	)
+
+	if err != nil {
+		return nil, &types.AppError{
+			StatusCode: 401,
+			Message:    "Incorrect username or password.",
+		}
+	}
+
+	return admin, nil
+}
+
+func (model *Admin) Count(
+	ctx context.Context,
+) (int64, *types.AppError) {
+	defer model.lock()()
+
+	return int64(len(model.tables().admins)), nil
+}
@@ -0,0 +1,152 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/sandromai/go-http-server/types"
+)
+
+type AdminToken struct {
+	session
+}
+
+func disconnectAdminTokens(
+	data *tables,
+	adminId string,
+) {
+	for id, adminToken := range data.adminTokens {
+		if adminToken.AdminId == adminId && !adminToken.Disconnected {
+			adminToken.Disconnected = true
+
+			data.adminTokens[id] = adminToken
+		}
+	}
+}
+
+func (model *AdminToken) FindById(
+	ctx context.Context,
+	id string,
+) (
+	*types.AdminToken,
+	*types.AppError,
+) {
+	defer model.lock()()
+
+	adminToken, found := model.tables().adminTokens[id]
+
+	if !found {
+		return nil, &types.AppError{
+			StatusCode: 404,
+			Message:    "Admin token not found.",
+		}
+	}
+
+	return &adminToken, nil
+}
+
+func (model *AdminToken) ListByAdmin(
+	ctx context.Context,
+	adminId string,
+) (
+	[]*types.AdminToken,
+	*types.AppError,
+) {
+	defer model.lock()()
+
+	now := model.store.now()
+
+	adminTokens := []*types.AdminToken{}
+
+	for _, adminToken := range model.tables().adminTokens {
+		if adminToken.AdminId == adminId && !adminToken.Disconnected && adminToken.ExpiresAt.After(now) {
+			adminToken := adminToken
+
+			adminTokens = append(adminTokens, &adminToken)
+		}
+	}
+
+	sort.SliceStable(adminTokens, func(i, j int) bool {
+		return adminTokens[i].LastActivity.After(adminTokens[j].LastActivity)
+	})
+
+	return adminTokens, nil
+}
+
+func (model *AdminToken) Create(
+	ctx context.Context,
+	adminId,
+	ipAddress,
+	device string,
+	expiresIn int64,
+) (
+	string,
+	*types.AppError,
+) {
+	defer model.lock()()
+
+	id, appErr := model.generateId(func(id string) bool {
+		_, found := model.tables().adminTokens[id]
+
+		return found
+	})
+
+	if appErr != nil {
+		return "", appErr
+	}
+
+	now := model.store.now()
+
+	model.tables().adminTokens[id] = types.AdminToken{
+		Id:           id,
+		AdminId:      adminId,
+		IPAddress:    ipAddress,
+		Device:       device,
+		LastActivity: now,
+		ExpiresAt:    model.store.expiresAt(expiresIn),
+		CreatedAt:    now,
+	}
+
+	return id, nil
+}
+
+func (model *AdminToken) UpdateActivity(
+	ctx context.Context,
+	id string,
+) *types.AppError {
+	defer model.lock()()
+
+	if adminToken, found := model.tables().adminTokens[id]; found {
+		adminToken.LastActivity = model.store.now()
+
+		model.tables().adminTokens[id] = adminToken
+	}
+
+	return nil
+}
+
+func (model *AdminToken) Disconnect(
+	ctx context.Context,
+	id string,
+) *types.AppError {
+	defer model.lock()()
+
+	if adminToken, found := model.tables().adminTokens[id]; found {
+		adminToken.Disconnected = true
+
+		model.tables().adminTokens[id] = adminToken
+	}
+
+	return nil
+}
+
+func (model *AdminToken) DisconnectAllByAdmin(
+	ctx context.Context,
+	adminId string,
+) *types.AppError {
+	defer model.lock()()
+
+	disconnectAdminTokens(model.tables(), adminId)
+
+	return nil
+}
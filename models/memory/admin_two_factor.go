@@ -0,0 +1,147 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/sandromai/go-http-server/types"
+)
+
+type AdminTwoFactor struct {
+	session
+}
+
+func (model *AdminTwoFactor) FindByAdmin(
+	ctx context.Context,
+	adminId string,
+) (
+	*types.AdminTwoFactor,
+	*types.AppError,
+) {
+	defer model.lock()()
+
+	twoFactor, found := model.tables().adminTwoFactors[adminId]
+
+	if !found {
+		return nil, &types.AppError{
+			StatusCode: 404,
+			Message:    "Two-factor authentication not configured.",
+		}
+	}
+
+	return &twoFactor, nil
+}
+
+func (model *AdminTwoFactor) Enroll(
+	ctx context.Context,
+	adminId,
+	secret string,
+) *types.AppError {
+	defer model.lock()()
+
+	twoFactor, found := model.tables().adminTwoFactors[adminId]
+
+	if !found {
+		twoFactor = types.AdminTwoFactor{
+			AdminId:   adminId,
+			CreatedAt: model.store.now(),
+		}
+	}
+
+	twoFactor.Secret = secret
+	twoFactor.Enabled = false
+	twoFactor.LastUsedStep = 0
+
+	model.tables().adminTwoFactors[adminId] = twoFactor
+
+	return nil
+}
+
+func (model *AdminTwoFactor) removeRecoveryCodes(
+	adminId string,
+) {
+	recoveryCodes := []recoveryCode{}
+
+	for _, code := range model.tables().adminRecoveryCodes {
+		if code.adminId != adminId {
+			recoveryCodes = append(recoveryCodes, code)
+		}
+	}
+
+	model.tables().adminRecoveryCodes = recoveryCodes
+}
+
+func (model *AdminTwoFactor) Enable(
+	ctx context.Context,
+	adminId string,
+	recoveryCodeHashes []string,
+) *types.AppError {
+	defer model.lock()()
+
+	if twoFactor, found := model.tables().adminTwoFactors[adminId]; found {
+		twoFactor.Enabled = true
+
+		model.tables().adminTwoFactors[adminId] = twoFactor
+	}
+
+	model.removeRecoveryCodes(adminId)
+
+	for _, codeHash := range recoveryCodeHashes {
+		model.tables().adminRecoveryCodes = append(model.tables().adminRecoveryCodes, recoveryCode{
+			adminId:  adminId,
+			codeHash: codeHash,
+		})
+	}
+
+	return nil
+}
+
+func (model *AdminTwoFactor) Disable(
+	ctx context.Context,
+	adminId string,
+) *types.AppError {
+	defer model.lock()()
+
+	model.removeRecoveryCodes(adminId)
+
+	delete(model.tables().adminTwoFactors, adminId)
+
+	return nil
+}
+
+func (model *AdminTwoFactor) UseStep(
+	ctx context.Context,
+	adminId string,
+	step int64,
+) (bool, *types.AppError) {
+	defer model.lock()()
+
+	twoFactor, found := model.tables().adminTwoFactors[adminId]
+
+	if !found || twoFactor.LastUsedStep >= step {
+		return false, nil
+	}
+
+	twoFactor.LastUsedStep = step
+
+	model.tables().adminTwoFactors[adminId] = twoFactor
+
+	return true, nil
+}
+
+func (model *AdminTwoFactor) UseRecoveryCode(
+	ctx context.Context,
+	adminId,
+	codeHash string,
+) (bool, *types.AppError) {
+	defer model.lock()()
+
+	for i, code := range model.tables().adminRecoveryCodes {
+		if code.adminId == adminId && code.codeHash == codeHash && !code.used {
+			model.tables().adminRecoveryCodes[i].used = true
+
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
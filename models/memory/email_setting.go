@@ -0,0 +1,68 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/sandromai/go-http-server/types"
+)
+
+type EmailSetting struct {
+	session
+}
+
+func (model *EmailSetting) List(
+	ctx context.Context,
+) (
+	*types.EmailSetting,
+	*types.AppError,
+) {
+	defer model.lock()()
+
+	emailSettings := model.tables().emailSettings
+
+	if len(emailSettings) == 0 {
+		return nil, &types.AppError{
+			StatusCode: 404,
+			Message:    "Email settings not found.",
+		}
+	}
+
+	emailSetting := emailSettings[len(emailSettings)-1]
+
+	return &emailSetting, nil
+}
+
+func (model *EmailSetting) Update(
+	ctx context.Context,
+	data map[string]string,
+) *types.AppError {
+	defer model.lock()()
+
+	emailSettings := append([]types.EmailSetting{}, model.tables().emailSettings...)
+
+	for i := range emailSettings {
+		for column, value := range data {
+			switch column {
+			case "host":
+				emailSettings[i].Host = value
+			case "port":
+				emailSettings[i].Port = value
+			case "username":
+				emailSettings[i].Username = value
+			case "password":
+				if value != "" {
+					emailSettings[i].Password = value
+				}
+			default:
+				return &types.AppError{
+					StatusCode: 500,
+					Message:    "Error updating email settings.",
+				}
+			}
+		}
+	}
+
+	model.tables().emailSettings = emailSettings
+
+	return nil
+}
@@ -0,0 +1,178 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/sandromai/go-http-server/types"
+	"github.com/sandromai/go-http-server/utils"
+)
+
+type LoginToken struct {
+	session
+}
+
+func (model *LoginToken) FindById(
+	ctx context.Context,
+	id string,
+) (*types.LoginToken, *types.AppError) {
+	defer model.lock()()
+
+	record, found := model.tables().loginTokens[id]
+
+	if !found {
+		return nil, &types.AppError{
+			StatusCode: 404,
+			Message:    "Login token not found.",
+		}
+	}
+
+	loginToken := record.LoginToken
+
+	return &loginToken, nil
+}
+
+func (model *LoginToken) FindByIdForUpdate(
+	ctx context.Context,
+	id string,
+) (*types.LoginToken, *types.AppError) {
+	return model.FindById(ctx, id)
+}
+
+func (model *LoginToken) Create(
+	ctx context.Context,
+	email,
+	ipAddress,
+	device string,
+	expiresIn int64,
+) (
+	string,
+	*types.AppError,
+) {
+	email = utils.NormalizeEmail(email)
+
+	defer model.lock()()
+
+	id, appErr := model.generateId(func(id string) bool {
+		_, found := model.tables().loginTokens[id]
+
+		return found
+	})
+
+	if appErr != nil {
+		return "", appErr
+	}
+
+	model.tables().sequence++
+
+	model.tables().loginTokens[id] = loginTokenRecord{
+		LoginToken: types.LoginToken{
+			Id:        id,
+			Email:     email,
+			IPAddress: ipAddress,
+			Device:    device,
+			ExpiresAt: model.store.expiresAt(expiresIn),
+			CreatedAt: model.store.now(),
+		},
+		sequence: model.tables().sequence,
+	}
+
+	return id, nil
+}
+
+func (model *LoginToken) Authorize(
+	ctx context.Context,
+	id string,
+) *types.AppError {
+	defer model.lock()()
+
+	if record, found := model.tables().loginTokens[id]; found {
+		record.Authorized = true
+
+		model.tables().loginTokens[id] = record
+	}
+
+	return nil
+}
+
+func (model *LoginToken) Deny(
+	ctx context.Context,
+	id string,
+) *types.AppError {
+	defer model.lock()()
+
+	if record, found := model.tables().loginTokens[id]; found {
+		record.Denied = true
+
+		model.tables().loginTokens[id] = record
+	}
+
+	return nil
+}
+
+func (model *LoginToken) CountActiveByEmail(
+	ctx context.Context,
+	email string,
+) (int64, *types.AppError) {
+	email = utils.NormalizeEmail(email)
+
+	defer model.lock()()
+
+	now := model.store.now()
+
+	var count int64
+
+	for _, record := range model.tables().loginTokens {
+		if record.Email == email && record.ExpiresAt.After(now) {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (model *LoginToken) GetLastCreationTimeByEmail(
+	ctx context.Context,
+	email string,
+) (time.Time, *types.AppError) {
+	email = utils.NormalizeEmail(email)
+
+	defer model.lock()()
+
+	var last *loginTokenRecord
+
+	for _, record := range model.tables().loginTokens {
+		if record.Email == email && (last == nil || record.sequence > last.sequence) {
+			record := record
+
+			last = &record
+		}
+	}
+
+	if last == nil {
+		return time.Time{}, nil
+	}
+
+	return last.CreatedAt, nil
+}
+
+func (model *LoginToken) GetFirstActiveExpirationTimeByEmail(
+	ctx context.Context,
+	email string,
+) (time.Time, *types.AppError) {
+	email = utils.NormalizeEmail(email)
+
+	defer model.lock()()
+
+	now := model.store.now()
+
+	var first time.Time
+
+	for _, record := range model.tables().loginTokens {
+		if record.Email == email && record.ExpiresAt.After(now) && (first.IsZero() || record.ExpiresAt.Before(first)) {
+			first = record.ExpiresAt
+		}
+	}
+
+	return first, nil
+}
@@ -0,0 +1,67 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/sandromai/go-http-server/types"
+)
+
+type OAuthAuthorizationCode struct {
+	session
+}
+
+func (model *OAuthAuthorizationCode) Create(
+	ctx context.Context,
+	codeHash,
+	clientId,
+	userId,
+	redirectURI,
+	scope,
+	codeChallenge string,
+	expiresIn int64,
+) *types.AppError {
+	defer model.lock()()
+
+	if _, found := model.tables().oauthAuthorizationCodes[codeHash]; found {
+		return &types.AppError{
+			StatusCode: 500,
+			Message:    "Error creating authorization code.",
+		}
+	}
+
+	model.tables().oauthAuthorizationCodes[codeHash] = types.OAuthAuthorizationCode{
+		CodeHash:      codeHash,
+		ClientId:      clientId,
+		UserId:        userId,
+		RedirectURI:   redirectURI,
+		Scope:         scope,
+		CodeChallenge: codeChallenge,
+		ExpiresAt:     model.store.expiresAt(expiresIn),
+		CreatedAt:     model.store.now(),
+	}
+
+	return nil
+}
+
+func (model *OAuthAuthorizationCode) Consume(
+	ctx context.Context,
+	codeHash string,
+) (
+	*types.OAuthAuthorizationCode,
+	*types.AppError,
+) {
+	defer model.lock()()
+
+	authorizationCode, found := model.tables().oauthAuthorizationCodes[codeHash]
+
+	if !found || !authorizationCode.ExpiresAt.After(model.store.now()) {
+		return nil, &types.AppError{
+			StatusCode: 400,
+			Message:    "Invalid or expired authorization code.",
+		}
+	}
+
+	delete(model.tables().oauthAuthorizationCodes, codeHash)
+
+	return &authorizationCode, nil
+}
@@ -0,0 +1,141 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/sandromai/go-http-server/types"
+)
+
+type OAuthClient struct {
+	session
+}
+
+func copyOAuthClient(
+	client types.OAuthClient,
+) *types.OAuthClient {
+	client.RedirectURIs = append([]string{}, client.RedirectURIs...)
+	client.Scopes = append([]string{}, client.Scopes...)
+	client.GrantTypes = append([]string{}, client.GrantTypes...)
+
+	return &client
+}
+
+func (model *OAuthClient) List(
+	ctx context.Context,
+) (
+	[]*types.OAuthClient,
+	*types.AppError,
+) {
+	defer model.lock()()
+
+	clients := []*types.OAuthClient{}
+
+	for _, client := range model.tables().oauthClients {
+		clients = append(clients, copyOAuthClient(client))
+	}
+
+	sort.Slice(clients, func(i, j int) bool {
+		return clients[i].Name < clients[j].Name
+	})
+
+	return clients, nil
+}
+
+func (model *OAuthClient) FindById(
+	ctx context.Context,
+	id string,
+) (
+	*types.OAuthClient,
+	*types.AppError,
+) {
+	defer model.lock()()
+
+	client, found := model.tables().oauthClients[id]
+
+	if !found {
+		return nil, &types.AppError{
+			StatusCode: 404,
+			Message:    "Client not found.",
+		}
+	}
+
+	return copyOAuthClient(client), nil
+}
+
+func (model *OAuthClient) Create(
+	ctx context.Context,
+	name string,
+	secretHash *string,
+	redirectURIs,
+	scopes,
+	grantTypes []string,
+	confidential,
+	firstParty bool,
+	createdBy string,
+) (
+	string,
+	*types.AppError,
+) {
+	defer model.lock()()
+
+	id, appErr := model.generateId(func(id string) bool {
+		_, found := model.tables().oauthClients[id]
+
+		return found
+	})
+
+	if appErr != nil {
+		return "", appErr
+	}
+
+	model.tables().oauthClients[id] = *copyOAuthClient(types.OAuthClient{
+		Id:           id,
+		Name:         name,
+		SecretHash:   secretHash,
+		RedirectURIs: redirectURIs,
+		Scopes:       scopes,
+		GrantTypes:   grantTypes,
+		Confidential: confidential,
+		FirstParty:   firstParty,
+		CreatedBy:    &createdBy,
+		CreatedAt:    model.store.now(),
+	})
+
+	return id, nil
+}
+
+func (model *OAuthClient) Delete(
+	ctx context.Context,
+	id string,
+) *types.AppError {
+	defer model.lock()()
+
+	data := model.tables()
+
+	delete(data.oauthClients, id)
+
+	for tokenId, clientToken := range data.oauthClientTokens {
+		if clientToken.ClientId == id {
+			delete(data.oauthClientTokens, tokenId)
+		}
+	}
+
+	for codeHash, authorizationCode := range data.oauthAuthorizationCodes {
+		if authorizationCode.ClientId == id {
+			delete(data.oauthAuthorizationCodes, codeHash)
+		}
+	}
+
+	for key := range data.oauthConsents {
+		if key.clientId == id {
+			delete(data.oauthConsents, key)
+		}
+	}
+
+	deleteUserTokens(data, func(userToken userTokenRecord) bool {
+		return userToken.ClientId != nil && *userToken.ClientId == id
+	})
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/sandromai/go-http-server/types"
+)
+
+type OAuthClientToken struct {
+	session
+}
+
+func (model *OAuthClientToken) FindById(
+	ctx context.Context,
+	id string,
+) (
+	*types.OAuthClientToken,
+	*types.AppError,
+) {
+	defer model.lock()()
+
+	clientToken, found := model.tables().oauthClientTokens[id]
+
+	if !found {
+		return nil, &types.AppError{
+			StatusCode: 404,
+			Message:    "Client token not found.",
+		}
+	}
+
+	return &clientToken, nil
+}
+
+func (model *OAuthClientToken) Create(
+	ctx context.Context,
+	clientId,
+	scope string,
+	expiresIn int64,
+) (
+	string,
+	*types.AppError,
+) {
+	defer model.lock()()
+
+	id, appErr := model.generateId(func(id string) bool {
+		_, found := model.tables().oauthClientTokens[id]
+
+		return found
+	})
+
+	if appErr != nil {
+		return "", appErr
+	}
+
+	model.tables().oauthClientTokens[id] = types.OAuthClientToken{
+		Id:        id,
+		ClientId:  clientId,
+		Scope:     scope,
+		ExpiresAt: model.store.expiresAt(expiresIn),
+		CreatedAt: model.store.now(),
+	}
+
+	return id, nil
+}
+
+func (model *OAuthClientToken) Revoke(
+	ctx context.Context,
+	id string,
+) *types.AppError {
+	defer model.lock()()
+
+	if clientToken, found := model.tables().oauthClientTokens[id]; found {
+		clientToken.Revoked = true
+
+		model.tables().oauthClientTokens[id] = clientToken
+	}
+
+	return nil
+}
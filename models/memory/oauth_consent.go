@@ -0,0 +1,43 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/sandromai/go-http-server/types"
+)
+
+type OAuthConsent struct {
+	session
+}
+
+func (model *OAuthConsent) FindScope(
+	ctx context.Context,
+	userId,
+	clientId string,
+) (string, *types.AppError) {
+	defer model.lock()()
+
+	scope, found := model.tables().oauthConsents[consentKey{userId: userId, clientId: clientId}]
+
+	if !found {
+		return "", &types.AppError{
+			StatusCode: 404,
+			Message:    "Consent not found.",
+		}
+	}
+
+	return scope, nil
+}
+
+func (model *OAuthConsent) Save(
+	ctx context.Context,
+	userId,
+	clientId,
+	scope string,
+) *types.AppError {
+	defer model.lock()()
+
+	model.tables().oauthConsents[consentKey{userId: userId, clientId: clientId}] = scope
+
+	return nil
+}
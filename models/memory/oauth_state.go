@@ -0,0 +1,64 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/sandromai/go-http-server/types"
+)
+
+type OAuthState struct {
+	session
+}
+
+func (model *OAuthState) Create(
+	ctx context.Context,
+	state,
+	provider,
+	nonce,
+	codeVerifier string,
+	expiresIn int64,
+) *types.AppError {
+	defer model.lock()()
+
+	if _, found := model.tables().oauthStates[state]; found {
+		return &types.AppError{
+			StatusCode: 500,
+			Message:    "Error creating authorization state.",
+		}
+	}
+
+	model.tables().oauthStates[state] = types.OAuthState{
+		State:        state,
+		Provider:     provider,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		ExpiresAt:    model.store.expiresAt(expiresIn),
+		CreatedAt:    model.store.now(),
+	}
+
+	return nil
+}
+
+func (model *OAuthState) Consume(
+	ctx context.Context,
+	state,
+	provider string,
+) (
+	*types.OAuthState,
+	*types.AppError,
+) {
+	defer model.lock()()
+
+	oauthState, found := model.tables().oauthStates[state]
+
+	if !found || oauthState.Provider != provider || !oauthState.ExpiresAt.After(model.store.now()) {
+		return nil, &types.AppError{
+			StatusCode: 400,
+			Message:    "Invalid or expired authorization state.",
+		}
+	}
+
+	delete(model.tables().oauthStates, state)
+
+	return &oauthState, nil
+}
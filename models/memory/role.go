@@ -0,0 +1,322 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/sandromai/go-http-server/types"
+)
+
+type Role struct {
+	session
+}
+
+func loadAdminAccess(
+	data *tables,
+	admin *types.Admin,
+) {
+	var roles []types.Role
+
+	for key := range data.adminRoles {
+		if role, found := data.roles[key.roleId]; found && key.adminId == admin.Id {
+			roles = append(roles, role)
+		}
+	}
+
+	sort.Slice(roles, func(i, j int) bool {
+		return roles[i].Name < roles[j].Name
+	})
+
+	admin.Roles = []string{}
+	admin.Permissions = []string{}
+
+	loadedPermissions := map[string]bool{}
+
+	for _, role := range roles {
+		admin.Roles = append(admin.Roles, role.Name)
+
+		for _, permission := range rolePermissions(data, role.Id) {
+			if !loadedPermissions[permission] {
+				loadedPermissions[permission] = true
+				admin.Permissions = append(admin.Permissions, permission)
+			}
+		}
+	}
+}
+
+func rolePermissions(
+	data *tables,
+	roleId string,
+) []string {
+	permissions := []string{}
+
+	for key := range data.rolePermissions {
+		if key.roleId == roleId {
+			permissions = append(permissions, key.permissionId)
+		}
+	}
+
+	sort.Strings(permissions)
+
+	return permissions
+}
+
+func (model *Role) nameTaken(
+	name,
+	excludeId string,
+) bool {
+	for _, role := range model.tables().roles {
+		if role.Name == name && role.Id != excludeId {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (model *Role) checkPermissions(
+	permissions []string,
+) *types.AppError {
+	for _, permission := range permissions {
+		if _, found := model.tables().permissions[permission]; !found {
+			return &types.AppError{
+				StatusCode: 400,
+				Message:    "Invalid permission.",
+			}
+		}
+	}
+
+	return nil
+}
+
+func (model *Role) replacePermissions(
+	roleId string,
+	permissions []string,
+) {
+	for key := range model.tables().rolePermissions {
+		if key.roleId == roleId {
+			delete(model.tables().rolePermissions, key)
+		}
+	}
+
+	for _, permission := range permissions {
+		model.tables().rolePermissions[rolePermission{roleId: roleId, permissionId: permission}] = true
+	}
+}
+
+func (model *Role) List(
+	ctx context.Context,
+) (
+	[]*types.Role,
+	*types.AppError,
+) {
+	defer model.lock()()
+
+	roles := []*types.Role{}
+
+	for _, role := range model.tables().roles {
+		role := role
+
+		role.Permissions = rolePermissions(model.tables(), role.Id)
+
+		roles = append(roles, &role)
+	}
+
+	sort.Slice(roles, func(i, j int) bool {
+		return roles[i].Name < roles[j].Name
+	})
+
+	return roles, nil
+}
+
+func (model *Role) FindById(
+	ctx context.Context,
+	id string,
+) (
+	*types.Role,
+	*types.AppError,
+) {
+	defer model.lock()()
+
+	role, found := model.tables().roles[id]
+
+	if !found {
+		return nil, &types.AppError{
+			StatusCode: 404,
+			Message:    "Role not found.",
+		}
+	}
+
+	role.Permissions = rolePermissions(model.tables(), role.Id)
+
+	return &role, nil
+}
+
+func (model *Role) Create(
+	ctx context.Context,
+	name,
+	description string,
+	permissions []string,
+) (
+	string,
+	*types.AppError,
+) {
+	defer model.lock()()
+
+	if model.nameTaken(name, "") {
+		return "", &types.AppError{
+			StatusCode: 409,
+			Message:    "Role name already registered.",
+		}
+	}
+
+	if appErr := model.checkPermissions(permissions); appErr != nil {
+		return "", appErr
+	}
+
+	id, appErr := model.generateId(func(id string) bool {
+		_, found := model.tables().roles[id]
+
+		return found
+	})
+
+	if appErr != nil {
+		return "", appErr
+	}
+
+	model.tables().roles[id] = types.Role{
+		Id:          id,
+		Name:        name,
+		Description: description,
+		CreatedAt:   model.store.now(),
+	}
+
+	model.replacePermissions(id, permissions)
+
+	return id, nil
+}
+
+func (model *Role) Update(
+	ctx context.Context,
+	id,
+	name,
+	description string,
+	permissions []string,
+) *types.AppError {
+	defer model.lock()()
+
+	if model.nameTaken(name, id) {
+		return &types.AppError{
+			StatusCode: 409,
+			Message:    "Role name already registered.",
+		}
+	}
+
+	if appErr := model.checkPermissions(permissions); appErr != nil {
+		return appErr
+	}
+
+	role, found := model.tables().roles[id]
+
+	if !found {
+		return nil
+	}
+
+	role.Name = name
+	role.Description = description
+
+	model.tables().roles[id] = role
+
+	model.replacePermissions(id, permissions)
+
+	return nil
+}
+
+func (model *Role) Delete(
+	ctx context.Context,
+	id string,
+) *types.AppError {
+	defer model.lock()()
+
+	if _, found := model.tables().roles[id]; !found {
+		return &types.AppError{
+			StatusCode: 404,
+			Message:    "Role not found.",
+		}
+	}
+
+	delete(model.tables().roles, id)
+
+	model.replacePermissions(id, nil)
+
+	for key := range model.tables().adminRoles {
+		if key.roleId == id {
+			delete(model.tables().adminRoles, key)
+		}
+	}
+
+	return nil
+}
+
+func (model *Role) ListPermissions(
+	ctx context.Context,
+) (
+	[]*types.Permission,
+	*types.AppError,
+) {
+	defer model.lock()()
+
+	permissions := []*types.Permission{}
+
+	for _, permission := range model.tables().permissions {
+		permission := permission
+
+		permissions = append(permissions, &permission)
+	}
+
+	sort.Slice(permissions, func(i, j int) bool {
+		return permissions[i].Id < permissions[j].Id
+	})
+
+	return permissions, nil
+}
+
+func (model *Role) LoadAdminAccess(
+	ctx context.Context,
+	admin *types.Admin,
+) *types.AppError {
+	defer model.lock()()
+
+	loadAdminAccess(model.tables(), admin)
+
+	return nil
+}
+
+func (model *Role) SetAdminRoles(
+	ctx context.Context,
+	adminId string,
+	roleIds []string,
+) *types.AppError {
+	defer model.lock()()
+
+	for _, roleId := range roleIds {
+		if _, found := model.tables().roles[roleId]; !found {
+			return &types.AppError{
+				StatusCode: 400,
+				Message:    "Invalid role.",
+			}
+		}
+	}
+
+	for key := range model.tables().adminRoles {
+		if key.adminId == adminId {
+			delete(model.tables().adminRoles, key)
+		}
+	}
+
+	for _, roleId := range roleIds {
+		model.tables().adminRoles[adminRole{adminId: adminId, roleId: roleId}] = true
+	}
+
+	return nil
+}
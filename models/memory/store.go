@@ -0,0 +1,312 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sandromai/go-http-server/clock"
+	"github.com/sandromai/go-http-server/models"
+	"github.com/sandromai/go-http-server/types"
+	"github.com/sandromai/go-http-server/utils"
+)
+
+type adminRecord struct {
+	types.Admin
+	password string
+}
+
+type recoveryCode struct {
+	adminId  string
+	codeHash string
+	used     bool
+}
+
+type loginTokenRecord struct {
+	types.LoginToken
+	sequence uint64
+}
+
+type userTokenRecord struct {
+	types.UserToken
+	refreshTokenHash *string
+}
+
+type consentKey struct {
+	userId   string
+	clientId string
+}
+
+type adminRole struct {
+	adminId string
+	roleId  string
+}
+
+type rolePermission struct {
+	roleId       string
+	permissionId string
+}
+
+type tables struct {
+	admins                  map[string]adminRecord
+	adminRoles              map[adminRole]bool
+	adminTokens             map[string]types.AdminToken
+	adminTwoFactors         map[string]types.AdminTwoFactor
+	adminRecoveryCodes      []recoveryCode
+	emailSettings           []types.EmailSetting
+	loginTokens             map[string]loginTokenRecord
+	oauthAuthorizationCodes map[string]types.OAuthAuthorizationCode
+	oauthClients            map[string]types.OAuthClient
+	oauthClientTokens       map[string]types.OAuthClientToken
+	oauthConsents           map[consentKey]string
+	oauthStates             map[string]types.OAuthState
+	permissions             map[string]types.Permission
+	roles                   map[string]types.Role
+	rolePermissions         map[rolePermission]bool
+	users                   map[string]types.User
+	userCredentials         map[string]types.UserCredential
+	userIdentities          map[string]types.UserIdentity
+	userTokens              map[string]userTokenRecord
+	webAuthnChallenges      map[string]types.WebAuthnChallenge
+	sequence                uint64
+}
+
+func cloneMap[K comparable, V any](
+	source map[K]V,
+) map[K]V {
+	clone := make(map[K]V, len(source))
+
+	for key, value := range source {
+		clone[key] = value
+	}
+
+	return clone
+}
+
+func (data *tables) clone() *tables {
+	return &tables{
+		admins:                  cloneMap(data.admins),
+		adminRoles:              cloneMap(data.adminRoles),
+		adminTokens:             cloneMap(data.adminTokens),
+		adminTwoFactors:         cloneMap(data.adminTwoFactors),
+		adminRecoveryCodes:      append([]recoveryCode{}, data.adminRecoveryCodes...),
+		emailSettings:           append([]types.EmailSetting{}, data.emailSettings...),
+		loginTokens:             cloneMap(data.loginTokens),
+		oauthAuthorizationCodes: cloneMap(data.oauthAuthorizationCodes),
+		oauthClients:            cloneMap(data.oauthClients),
+		oauthClientTokens:       cloneMap(data.oauthClientTokens),
+		oauthConsents:           cloneMap(data.oauthConsents),
+		oauthStates:             cloneMap(data.oauthStates),
+		permissions:             cloneMap(data.permissions),
+		roles:                   cloneMap(data.roles),
+		rolePermissions:         cloneMap(data.rolePermissions),
+		users:                   cloneMap(data.users),
+		userCredentials:         cloneMap(data.userCredentials),
+		userIdentities:          cloneMap(data.userIdentities),
+		userTokens:              cloneMap(data.userTokens),
+		webAuthnChallenges:      cloneMap(data.webAuthnChallenges),
+		sequence:                data.sequence,
+	}
+}
+
+type Store struct {
+	mutex        sync.Mutex
+	data         *tables
+	clock        clock.Clock
+	passwordCost int
+}
+
+func NewStore(
+	clock clock.Clock,
+	passwordCost int,
+) *Store {
+	store := &Store{
+		data: &tables{
+			admins:                  map[string]adminRecord{},
+			adminRoles:              map[adminRole]bool{},
+			adminTokens:             map[string]types.AdminToken{},
+			adminTwoFactors:         map[string]types.AdminTwoFactor{},
+			emailSettings:           []types.EmailSetting{{}},
+			loginTokens:             map[string]loginTokenRecord{},
+			oauthAuthorizationCodes: map[string]types.OAuthAuthorizationCode{},
+			oauthClients:            map[string]types.OAuthClient{},
+			oauthClientTokens:       map[string]types.OAuthClientToken{},
+			oauthConsents:           map[consentKey]string{},
+			oauthStates:             map[string]types.OAuthState{},
+			permissions:             map[string]types.Permission{},
+			roles:                   map[string]types.Role{},
+			rolePermissions:         map[rolePermission]bool{},
+			users:                   map[string]types.User{},
+			userCredentials:         map[string]types.UserCredential{},
+			userIdentities:          map[string]types.UserIdentity{},
+			userTokens:              map[string]userTokenRecord{},
+			webAuthnChallenges:      map[string]types.WebAuthnChallenge{},
+		},
+		clock:        clock,
+		passwordCost: passwordCost,
+	}
+
+	store.seed()
+
+	return store
+}
+
+func (store *Store) seed() {
+	createdAt := store.now()
+
+	for _, permission := range []types.Permission{
+		{Id: types.PermissionAdminsRegister, Description: "Register new admins"},
+		{Id: types.PermissionAdminsRoles, Description: "Assign roles to admins"},
+		{Id: types.PermissionRolesManage, Description: "Create, update and delete roles"},
+		{Id: types.PermissionUsersBan, Description: "Ban and unban users"},
+		{Id: types.PermissionEmailSettingsList, Description: "View email settings"},
+		{Id: types.PermissionEmailSettingsUpdate, Description: "Update email settings"},
+		{Id: types.PermissionOAuthClientsManage, Description: "Register and delete OAuth clients"},
+	} {
+		store.data.permissions[permission.Id] = permission
+		store.data.rolePermissions[rolePermission{roleId: types.SuperAdminRoleId, permissionId: permission.Id}] = true
+	}
+
+	for _, role := range []types.Role{
+		{Id: types.SuperAdminRoleId, Name: types.SuperAdminRoleId, Description: "Full access to every admin feature"},
+		{Id: "moderator", Name: "moderator", Description: "Moderates users"},
+		{Id: "support", Name: "support", Description: "Helps users and checks settings"},
+	} {
+		role.CreatedAt = createdAt
+
+		store.data.roles[role.Id] = role
+	}
+
+	store.data.rolePermissions[rolePermission{roleId: "moderator", permissionId: types.PermissionUsersBan}] = true
+	store.data.rolePermissions[rolePermission{roleId: "support", permissionId: types.PermissionEmailSettingsList}] = true
+}
+
+func (store *Store) now() time.Time {
+	return clock.Now(store.clock).Truncate(time.Second)
+}
+
+func (store *Store) expiresAt(
+	expiresIn int64,
+) time.Time {
+	return store.now().Add(time.Duration(expiresIn) * time.Second)
+}
+
+func (store *Store) Ping(
+	ctx context.Context,
+) *types.AppError {
+	return nil
+}
+
+func (store *Store) Close() *types.AppError {
+	return nil
+}
+
+func (store *Store) repositories(
+	session session,
+) *models.Repositories {
+	return &models.Repositories{
+		Admins:                  &Admin{session: session},
+		AdminTokens:             &AdminToken{session: session},
+		AdminTwoFactors:         &AdminTwoFactor{session: session},
+		EmailSettings:           &EmailSetting{session: session},
+		LoginTokens:             &LoginToken{session: session},
+		OAuthAuthorizationCodes: &OAuthAuthorizationCode{session: session},
+		OAuthClients:            &OAuthClient{session: session},
+		OAuthClientTokens:       &OAuthClientToken{session: session},
+		OAuthConsents:           &OAuthConsent{session: session},
+		OAuthStates:             &OAuthState{session: session},
+		Roles:                   &Role{session: session},
+		Users:                   &User{session: session},
+		UserCredentials:         &UserCredential{session: session},
+		UserIdentities:          &UserIdentity{session: session},
+		UserTokens:              &UserToken{session: session},
+		WebAuthnChallenges:      &WebAuthnChallenge{session: session},
+	}
+}
+
+func (store *Store) Repositories() *models.Repositories {
+	repositories := store.repositories(session{store: store})
+
+	repositories.UnitOfWork = store
+
+	return repositories
+}
+
+func (store *Store) Run(
+	ctx context.Context,
+	work func(repositories *models.Repositories) *types.AppError,
+) *types.AppError {
+	store.mutex.Lock()
+
+	defer store.mutex.Unlock()
+
+	snapshot := store.data.clone()
+
+	repositories := store.repositories(session{
+		store:         store,
+		inTransaction: true,
+	})
+
+	repositories.UnitOfWork = &joinedUnitOfWork{
+		repositories: repositories,
+	}
+
+	if appErr := work(repositories); appErr != nil {
+		store.data = snapshot
+
+		return appErr
+	}
+
+	return nil
+}
+
+type joinedUnitOfWork struct {
+	repositories *models.Repositories
+}
+
+func (unitOfWork *joinedUnitOfWork) Run(
+	ctx context.Context,
+	work func(repositories *models.Repositories) *types.AppError,
+) *types.AppError {
+	return work(unitOfWork.repositories)
+}
+
+type session struct {
+	store         *Store
+	inTransaction bool
+}
+
+func (session session) lock() func() {
+	if session.inTransaction {
+		return func() {}
+	}
+
+	session.store.mutex.Lock()
+
+	return session.store.mutex.Unlock
+}
+
+func (session session) tables() *tables {
+	return session.store.data
+}
+
+func (session session) generateId(
+	taken func(id string) bool,
+) (string, *types.AppError) {
+	for attempt := 0; attempt < 10; attempt++ {
+		id, appErr := utils.GenerateUUIDv4()
+
+		if appErr != nil {
+			return "", appErr
+		}
+
+		if !taken(id) {
+			return id, nil
+		}
+	}
+
+	return "", &types.AppError{
+		StatusCode: 500,
+		Message:    "Failed to generate ID.",
+	}
+}
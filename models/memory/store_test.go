@@ -0,0 +1,148 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/sandromai/go-http-server/clock"
+	"github.com/sandromai/go-http-server/models"
+	"github.com/sandromai/go-http-server/types"
+)
+
+func TestRunRollsBackOnError(t *testing.T) {
+	repositories := NewStore(clock.System{}, bcrypt.MinCost).Repositories()
+	ctx := context.Background()
+
+	appErr := repositories.UnitOfWork.Run(ctx, func(transaction *models.Repositories) *types.AppError {
+		if _, appErr := transaction.Users.Create(ctx, "user@example.com"); appErr != nil {
+			return appErr
+		}
+
+		return &types.AppError{StatusCode: 400, Message: "Abort."}
+	})
+
+	if appErr == nil || appErr.Message != "Abort." {
+		t.Fatalf("unexpected error %v", appErr)
+	}
+
+	if _, appErr = repositories.Users.FindByEmail(ctx, "user@example.com"); appErr == nil || appErr.StatusCode != 404 {
+		t.Fatalf("expected the user to be rolled back, got %v", appErr)
+	}
+}
+
+func TestConcurrentLoginTokenConsumeCreatesOneSession(t *testing.T) {
+	repositories := NewStore(clock.System{}, bcrypt.MinCost).Repositories()
+	ctx := context.Background()
+
+	userId, appErr := repositories.Users.Create(ctx, "user@example.com")
+
+	if appErr != nil {
+		t.Fatal(appErr.Message)
+	}
+
+	loginTokenId, appErr := repositories.LoginTokens.Create(ctx, "user@example.com", "127.0.0.1", "test", 60)
+
+	if appErr != nil {
+		t.Fatal(appErr.Message)
+	}
+
+	var waitGroup sync.WaitGroup
+	var mutex sync.Mutex
+
+	created := 0
+
+	for i := 0; i < 8; i++ {
+		waitGroup.Add(1)
+
+		go func() {
+			defer waitGroup.Done()
+
+			appErr := repositories.UnitOfWork.Run(ctx, func(transaction *models.Repositories) *types.AppError {
+				if _, appErr := transaction.LoginTokens.FindByIdForUpdate(ctx, loginTokenId); appErr != nil {
+					return appErr
+				}
+
+				_, appErr := transaction.UserTokens.Create(ctx, userId, &loginTokenId, nil, nil, nil, "127.0.0.1", "test", 60)
+
+				return appErr
+			})
+
+			if appErr == nil {
+				mutex.Lock()
+				created++
+				mutex.Unlock()
+			}
+		}()
+	}
+
+	waitGroup.Wait()
+
+	if created != 1 {
+		t.Fatalf("created %v sessions, expected 1", created)
+	}
+}
+
+func TestExpiredChallengesCannotBeConsumed(t *testing.T) {
+	storeClock := clock.NewManual(time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC))
+	repositories := NewStore(storeClock, bcrypt.MinCost).Repositories()
+	ctx := context.Background()
+
+	id, appErr := repositories.WebAuthnChallenges.Create(ctx, nil, types.WebAuthnChallengeAuthentication, "challenge", 60)
+
+	if appErr != nil {
+		t.Fatal(appErr.Message)
+	}
+
+	storeClock.Advance(time.Minute)
+
+	if _, appErr = repositories.WebAuthnChallenges.Consume(ctx, id, types.WebAuthnChallengeAuthentication); appErr == nil || appErr.StatusCode != 400 {
+		t.Fatalf("expected an expired challenge to be rejected, got %v", appErr)
+	}
+
+	id, appErr = repositories.WebAuthnChallenges.Create(ctx, nil, types.WebAuthnChallengeAuthentication, "challenge", 60)
+
+	if appErr != nil {
+		t.Fatal(appErr.Message)
+	}
+
+	if _, appErr = repositories.WebAuthnChallenges.Consume(ctx, id, types.WebAuthnChallengeAuthentication); appErr != nil {
+		t.Fatal(appErr.Message)
+	}
+
+	if _, appErr = repositories.WebAuthnChallenges.Consume(ctx, id, types.WebAuthnChallengeAuthentication); appErr == nil {
+		t.Fatal("expected a challenge to be consumed only once")
+	}
+}
+
+func TestSeededRolesGrantAdminAccess(t *testing.T) {
+	repositories := NewStore(clock.System{}, bcrypt.MinCost).Repositories()
+	ctx := context.Background()
+
+	adminId, appErr := repositories.Admins.Create(ctx, "Root", "root", "secret-password", nil)
+
+	if appErr != nil {
+		t.Fatal(appErr.Message)
+	}
+
+	if appErr = repositories.Roles.SetAdminRoles(ctx, adminId, []string{"moderator", "support"}); appErr != nil {
+		t.Fatal(appErr.Message)
+	}
+
+	admin, appErr := repositories.Admins.Authenticate(ctx, "root", "secret-password")
+
+	if appErr != nil {
+		t.Fatal(appErr.Message)
+	}
+
+	if len(admin.Roles) != 2 || admin.Roles[0] != "moderator" || !admin.HasPermission(types.PermissionUsersBan) || !admin.HasPermission(types.PermissionEmailSettingsList) {
+		t.Fatalf("unexpected admin access %+v", admin)
+	}
+
+	if _, appErr = repositories.Admins.Authenticate(ctx, "root", "wrong"); appErr == nil || appErr.StatusCode != 401 {
+		t.Fatalf("expected a wrong password to be rejected, got %v", appErr)
+	}
+}
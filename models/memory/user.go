@@ -0,0 +1,152 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/sandromai/go-http-server/types"
+	"github.com/sandromai/go-http-server/utils"
+)
+
+type User struct {
+	session
+}
+
+func (model *User) emailTaken(
+	email string,
+) bool {
+	for _, user := range model.tables().users {
+		if user.Email == email {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (model *User) FindById(
+	ctx context.Context,
+	id string,
+) (
+	*types.User,
+	*types.AppError,
+) {
+	defer model.lock()()
+
+	user, found := model.tables().users[id]
+
+	if !found {
+		return nil, &types.AppError{
+			StatusCode: 404,
+			Message:    "User not found.",
+		}
+	}
+
+	return &user, nil
+}
+
+func (model *User) FindByEmail(
+	ctx context.Context,
+	email string,
+) (
+	*types.User,
+	*types.AppError,
+) {
+	email = utils.NormalizeEmail(email)
+
+	defer model.lock()()
+
+	for _, user := range model.tables().users {
+		if user.Email == email {
+			return &user, nil
+		}
+	}
+
+	return nil, &types.AppError{
+		StatusCode: 404,
+		Message:    "User not found.",
+	}
+}
+
+func (model *User) Create(
+	ctx context.Context,
+	email string,
+) (
+	string,
+	*types.AppError,
+) {
+	email = utils.NormalizeEmail(email)
+
+	defer model.lock()()
+
+	id, appErr := model.generateId(func(id string) bool {
+		_, found := model.tables().users[id]
+
+		return found
+	})
+
+	if appErr != nil {
+		return "", appErr
+	}
+
+	if model.emailTaken(email) {
+		return "", &types.AppError{
+			StatusCode: 409,
+			Message:    "Email already registered.",
+		}
+	}
+
+	model.tables().users[id] = types.User{
+		Id:        id,
+		Email:     email,
+		CreatedAt: model.store.now(),
+	}
+
+	return id, nil
+}
+
+func (model *User) setBanned(
+	id string,
+	banned bool,
+) {
+	if user, found := model.tables().users[id]; found {
+		user.Banned = banned
+
+		model.tables().users[id] = user
+	}
+}
+
+func (model *User) Ban(
+	ctx context.Context,
+	id string,
+) *types.AppError {
+	defer model.lock()()
+
+	model.setBanned(id, true)
+
+	return nil
+}
+
+func (model *User) Unban(
+	ctx context.Context,
+	id string,
+) *types.AppError {
+	defer model.lock()()
+
+	model.setBanned(id, false)
+
+	return nil
+}
+
+func (model *User) CheckEmailAvailability(
+	ctx context.Context,
+	email string,
+) (
+	bool,
+	*types.AppError,
+) {
+	email = utils.NormalizeEmail(email)
+
+	defer model.lock()()
+
+	return !model.emailTaken(email), nil
+}
@@ -0,0 +1,178 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/sandromai/go-http-server/types"
+)
+
+type UserCredential struct {
+	session
+}
+
+func (model *UserCredential) ListByUser(
+	ctx context.Context,
+	userId string,
+) (
+	[]*types.UserCredential,
+	*types.AppError,
+) {
+	defer model.lock()()
+
+	userCredentials := []*types.UserCredential{}
+
+	for _, userCredential := range model.tables().userCredentials {
+		if userCredential.UserId == userId {
+			userCredential := userCredential
+
+			userCredentials = append(userCredentials, &userCredential)
+		}
+	}
+
+	sort.SliceStable(userCredentials, func(i, j int) bool {
+		return userCredentials[i].CreatedAt.After(userCredentials[j].CreatedAt)
+	})
+
+	return userCredentials, nil
+}
+
+func (model *UserCredential) FindById(
+	ctx context.Context,
+	id string,
+) (
+	*types.UserCredential,
+	*types.AppError,
+) {
+	defer model.lock()()
+
+	userCredential, found := model.tables().userCredentials[id]
+
+	if !found {
+		return nil, &types.AppError{
+			StatusCode: 404,
+			Message:    "Credential not found.",
+		}
+	}
+
+	return &userCredential, nil
+}
+
+func (model *UserCredential) findByCredentialId(
+	credentialId string,
+) (*types.UserCredential, bool) {
+	for _, userCredential := range model.tables().userCredentials {
+		if userCredential.CredentialId == credentialId {
+			return &userCredential, true
+		}
+	}
+
+	return nil, false
+}
+
+func (model *UserCredential) FindByCredentialId(
+	ctx context.Context,
+	credentialId string,
+) (
+	*types.UserCredential,
+	*types.AppError,
+) {
+	defer model.lock()()
+
+	userCredential, found := model.findByCredentialId(credentialId)
+
+	if !found {
+		return nil, &types.AppError{
+			StatusCode: 404,
+			Message:    "Credential not found.",
+		}
+	}
+
+	return userCredential, nil
+}
+
+func (model *UserCredential) Create(
+	ctx context.Context,
+	userId,
+	credentialId,
+	publicKey string,
+	signCount uint32,
+	name string,
+) (
+	string,
+	*types.AppError,
+) {
+	defer model.lock()()
+
+	if _, found := model.findByCredentialId(credentialId); found {
+		return "", &types.AppError{
+			StatusCode: 409,
+			Message:    "Credential already registered.",
+		}
+	}
+
+	id, appErr := model.generateId(func(id string) bool {
+		_, found := model.tables().userCredentials[id]
+
+		return found
+	})
+
+	if appErr != nil {
+		return "", appErr
+	}
+
+	model.tables().userCredentials[id] = types.UserCredential{
+		Id:           id,
+		UserId:       userId,
+		CredentialId: credentialId,
+		PublicKey:    publicKey,
+		SignCount:    signCount,
+		Name:         name,
+		CreatedAt:    model.store.now(),
+	}
+
+	return id, nil
+}
+
+func (model *UserCredential) UpdateSignCount(
+	ctx context.Context,
+	id string,
+	previousSignCount,
+	signCount uint32,
+) (bool, *types.AppError) {
+	defer model.lock()()
+
+	userCredential, found := model.tables().userCredentials[id]
+
+	if !found || userCredential.SignCount != previousSignCount {
+		return false, nil
+	}
+
+	lastUsedAt := model.store.now()
+
+	userCredential.SignCount = signCount
+	userCredential.LastUsedAt = &lastUsedAt
+
+	model.tables().userCredentials[id] = userCredential
+
+	return true, nil
+}
+
+func (model *UserCredential) Delete(
+	ctx context.Context,
+	id string,
+) *types.AppError {
+	defer model.lock()()
+
+	delete(model.tables().userCredentials, id)
+
+	for userTokenId, userToken := range model.tables().userTokens {
+		if userToken.FromCredential != nil && *userToken.FromCredential == id {
+			userToken.FromCredential = nil
+
+			model.tables().userTokens[userTokenId] = userToken
+		}
+	}
+
+	return nil
+}
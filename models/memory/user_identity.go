@@ -0,0 +1,76 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/sandromai/go-http-server/types"
+)
+
+type UserIdentity struct {
+	session
+}
+
+func (model *UserIdentity) FindByProviderSubject(
+	ctx context.Context,
+	provider,
+	subject string,
+) (
+	*types.UserIdentity,
+	*types.AppError,
+) {
+	defer model.lock()()
+
+	for _, userIdentity := range model.tables().userIdentities {
+		if userIdentity.Provider == provider && userIdentity.Subject == subject {
+			return &userIdentity, nil
+		}
+	}
+
+	return nil, &types.AppError{
+		StatusCode: 404,
+		Message:    "Identity not found.",
+	}
+}
+
+func (model *UserIdentity) Create(
+	ctx context.Context,
+	userId,
+	provider,
+	subject,
+	email string,
+) (
+	string,
+	*types.AppError,
+) {
+	defer model.lock()()
+
+	for _, userIdentity := range model.tables().userIdentities {
+		if userIdentity.Provider == provider && userIdentity.Subject == subject {
+			return "", &types.AppError{
+				StatusCode: 409,
+				Message:    "Identity already linked.",
+			}
+		}
+	}
+
+	id, appErr := model.generateId(func(id string) bool {
+		_, found := model.tables().userIdentities[id]
+
+		return found
+	})
+
+	if appErr != nil {
+		return "", appErr
+	}
+
+	model.tables().userIdentities[id] = types.UserIdentity{
+		Id:        id,
+		UserId:    userId,
+		Provider:  provider,
+		Subject:   subject,
+		Email:     email,
+		CreatedAt: model.store.now(),
+	}
+
+	return id, nil
+}
@@ -0,0 +1,282 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/sandromai/go-http-server/types"
+)
+
+type UserToken struct {
+	session
+}
+
+func copyString(
+	value *string,
+) *string {
+	if value == nil {
+		return nil
+	}
+
+	copied := *value
+
+	return &copied
+}
+
+func deleteUserTokens(
+	data *tables,
+	matches func(userToken userTokenRecord) bool,
+) {
+	deleted := map[string]bool{}
+
+	for id, userToken := range data.userTokens {
+		if matches(userToken) {
+			deleted[id] = true
+
+			delete(data.userTokens, id)
+		}
+	}
+
+	for id, userToken := range data.userTokens {
+		if userToken.FromUserToken != nil && deleted[*userToken.FromUserToken] {
+			userToken.FromUserToken = nil
+
+			data.userTokens[id] = userToken
+		}
+	}
+}
+
+func (model *UserToken) find(
+	matches func(userToken userTokenRecord) bool,
+) (
+	*types.UserToken,
+	*types.AppError,
+) {
+	defer model.lock()()
+
+	for _, record := range model.tables().userTokens {
+		if matches(record) {
+			userToken := record.UserToken
+
+			return &userToken, nil
+		}
+	}
+
+	return nil, &types.AppError{
+		StatusCode: 404,
+		Message:    "User token not found.",
+	}
+}
+
+func (model *UserToken) FindById(
+	ctx context.Context,
+	id string,
+) (
+	*types.UserToken,
+	*types.AppError,
+) {
+	return model.find(func(userToken userTokenRecord) bool {
+		return userToken.Id == id
+	})
+}
+
+func (model *UserToken) FindByRefreshTokenHash(
+	ctx context.Context,
+	refreshTokenHash string,
+) (
+	*types.UserToken,
+	*types.AppError,
+) {
+	return model.find(func(userToken userTokenRecord) bool {
+		return userToken.refreshTokenHash != nil && *userToken.refreshTokenHash == refreshTokenHash
+	})
+}
+
+func (model *UserToken) insert(
+	userToken types.UserToken,
+	refreshTokenHash *string,
+	expiresIn int64,
+) (
+	string,
+	*types.AppError,
+) {
+	id, appErr := model.generateId(func(id string) bool {
+		_, found := model.tables().userTokens[id]
+
+		return found
+	})
+
+	if appErr != nil {
+		return "", appErr
+	}
+
+	now := model.store.now()
+
+	userToken.Id = id
+	userToken.LastActivity = now
+	userToken.ExpiresAt = model.store.expiresAt(expiresIn)
+	userToken.CreatedAt = now
+
+	model.tables().userTokens[id] = userTokenRecord{
+		UserToken:        userToken,
+		refreshTokenHash: copyString(refreshTokenHash),
+	}
+
+	return id, nil
+}
+
+func (model *UserToken) Create(
+	ctx context.Context,
+	userId string,
+	fromLoginToken,
+	fromUserToken,
+	fromCredential,
+	fromIdentity *string,
+	ipAddress,
+	device string,
+	expiresIn int64,
+) (
+	string,
+	*types.AppError,
+) {
+	if fromLoginToken == nil && fromUserToken == nil && fromCredential == nil && fromIdentity == nil {
+		return "", &types.AppError{
+			StatusCode: 400,
+			Message:    "No login token, user token, credential or identity provided.",
+		}
+	}
+
+	defer model.lock()()
+
+	if fromLoginToken != nil {
+		for _, userToken := range model.tables().userTokens {
+			if userToken.FromLoginToken != nil && *userToken.FromLoginToken == *fromLoginToken {
+				return "", &types.AppError{
+					StatusCode: 400,
+					Message:    "Login token already used.",
+				}
+			}
+		}
+	}
+
+	return model.insert(
+		types.UserToken{
+			UserId:         userId,
+			FromLoginToken: copyString(fromLoginToken),
+			FromUserToken:  copyString(fromUserToken),
+			FromCredential: copyString(fromCredential),
+			FromIdentity:   copyString(fromIdentity),
+			IPAddress:      ipAddress,
+			Device:         device,
+		},
+		nil,
+		expiresIn,
+	)
+}
+
+func (model *UserToken) UpdateActivity(
+	ctx context.Context,
+	id string,
+) *types.AppError {
+	defer model.lock()()
+
+	if userToken, found := model.tables().userTokens[id]; found {
+		userToken.LastActivity = model.store.now()
+
+		model.tables().userTokens[id] = userToken
+	}
+
+	return nil
+}
+
+func (model *UserToken) Disconnect(
+	ctx context.Context,
+	id string,
+) *types.AppError {
+	defer model.lock()()
+
+	if userToken, found := model.tables().userTokens[id]; found {
+		userToken.Disconnected = true
+
+		model.tables().userTokens[id] = userToken
+	}
+
+	return nil
+}
+
+func (model *UserToken) CreateForClient(
+	ctx context.Context,
+	userId,
+	clientId,
+	scope string,
+	refreshTokenHash,
+	fromUserToken *string,
+	ipAddress,
+	device string,
+	expiresIn int64,
+) (
+	string,
+	*types.AppError,
+) {
+	defer model.lock()()
+
+	if refreshTokenHash != nil {
+		for _, userToken := range model.tables().userTokens {
+			if userToken.refreshTokenHash != nil && *userToken.refreshTokenHash == *refreshTokenHash {
+				return "", &types.AppError{
+					StatusCode: 500,
+					Message:    "Error creating user token.",
+				}
+			}
+		}
+	}
+
+	return model.insert(
+		types.UserToken{
+			UserId:        userId,
+			FromUserToken: copyString(fromUserToken),
+			ClientId:      &clientId,
+			Scope:         &scope,
+			IPAddress:     ipAddress,
+			Device:        device,
+		},
+		refreshTokenHash,
+		expiresIn,
+	)
+}
+
+func (model *UserToken) DisconnectActive(
+	ctx context.Context,
+	id string,
+) (bool, *types.AppError) {
+	defer model.lock()()
+
+	userToken, found := model.tables().userTokens[id]
+
+	if !found || userToken.Disconnected {
+		return false, nil
+	}
+
+	userToken.Disconnected = true
+
+	model.tables().userTokens[id] = userToken
+
+	return true, nil
+}
+
+func (model *UserToken) DisconnectAllByUserClient(
+	ctx context.Context,
+	userId,
+	clientId string,
+) *types.AppError {
+	defer model.lock()()
+
+	for id, userToken := range model.tables().userTokens {
+		if userToken.UserId == userId && userToken.ClientId != nil && *userToken.ClientId == clientId {
+			userToken.Disconnected = true
+
+			model.tables().userTokens[id] = userToken
+		}
+	}
+
+	return nil
+}
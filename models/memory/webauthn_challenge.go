@@ -0,0 +1,69 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/sandromai/go-http-server/types"
+)
+
+type WebAuthnChallenge struct {
+	session
+}
+
+func (model *WebAuthnChallenge) Create(
+	ctx context.Context,
+	userId *string,
+	challengeType,
+	challenge string,
+	expiresIn int64,
+) (
+	string,
+	*types.AppError,
+) {
+	defer model.lock()()
+
+	id, appErr := model.generateId(func(id string) bool {
+		_, found := model.tables().webAuthnChallenges[id]
+
+		return found
+	})
+
+	if appErr != nil {
+		return "", appErr
+	}
+
+	model.tables().webAuthnChallenges[id] = types.WebAuthnChallenge{
+		Id:        id,
+		UserId:    userId,
+		Type:      challengeType,
+		Challenge: challenge,
+		ExpiresAt: model.store.expiresAt(expiresIn),
+		CreatedAt: model.store.now(),
+	}
+
+	return id, nil
+}
+
+func (model *WebAuthnChallenge) Consume(
+	ctx context.Context,
+	id,
+	challengeType string,
+) (
+	*types.WebAuthnChallenge,
+	*types.AppError,
+) {
+	defer model.lock()()
+
+	webAuthnChallenge, found := model.tables().webAuthnChallenges[id]
+
+	if !found || webAuthnChallenge.Type != challengeType || !webAuthnChallenge.ExpiresAt.After(model.store.now()) {
+		return nil, &types.AppError{
+			StatusCode: 400,
+			Message:    "Invalid or expired challenge.",
+		}
+	}
+
+	delete(model.tables().webAuthnChallenges, id)
+
+	return &webAuthnChallenge, nil
+}
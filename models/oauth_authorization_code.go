@@ -0,0 +1,141 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/sandromai/go-http-server/types"
+)
+
+type OAuthAuthorizationCode struct {
+	connection
+}
+
+func (model *OAuthAuthorizationCode) Create(
+	ctx context.Context,
+	codeHash,
+	clientId,
+	userId,
+	redirectURI,
+	scope,
+	codeChallenge string,
+	expiresIn int64,
+) *types.AppError {
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"INSERT INTO `oauth_authorization_codes` (`code_hash`, `client_id`, `user_id`, `redirect_uri`, `scope`, `code_challenge`, `expires_at`, `created_at`) VALUES(?, ?, ?, ?, ?, ?, ?, ?)",
+	)
+
+	if err != nil {
+		return databaseError(err, "Failed to create authorization code.")
+	}
+
+	defer statement.Close()
+
+	now := model.now()
+
+	_, err = statement.ExecContext(
+		ctx,
+		codeHash,
+		clientId,
+		userId,
+		redirectURI,
+		scope,
+		codeChallenge,
+		model.expiresAt(now, expiresIn),
+		model.timestamp(now),
+	)
+
+	if err != nil {
+		return databaseError(err, "Error creating authorization code.")
+	}
+
+	return nil
+}
+
+func (model *OAuthAuthorizationCode) Consume(
+	ctx context.Context,
+	codeHash string,
+) (
+	*types.OAuthAuthorizationCode,
+	*types.AppError,
+) {
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"SELECT `code_hash`, `client_id`, `user_id`, `redirect_uri`, `scope`, `code_challenge`, `expires_at`, `created_at` FROM `oauth_authorization_codes` WHERE `code_hash` = ? AND `expires_at` > ? LIMIT 1",
+	)
+
+	if err != nil {
+		return nil, databaseError(err, "Failed to find authorization code.")
+	}
+
+	defer statement.Close()
+
+	authorizationCode := &types.OAuthAuthorizationCode{}
+
+	err = statement.QueryRowContext(ctx, codeHash, model.timestamp(model.now())).Scan(
+		&authorizationCode.CodeHash,
+		&authorizationCode.ClientId,
+		&authorizationCode.UserId,
+		&authorizationCode.RedirectURI,
+		&authorizationCode.Scope,
+		&authorizationCode.CodeChallenge,
+		scanTimestamp(&authorizationCode.ExpiresAt),
+		scanTimestamp(&authorizationCode.CreatedAt),
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, &types.AppError{
+			StatusCode: 400,
+			Message:    "Invalid or expired authorization code.",
+		}
+	}
+
+	if err != nil {
+		return nil, databaseError(err, "Error searching authorization code.")
+	}
+
+	deleteStatement, err := dbConnection.PrepareContext(
+		ctx,
+		"DELETE FROM `oauth_authorization_codes` WHERE `code_hash` = ?",
+	)
+
+	if err != nil {
+		return nil, databaseError(err, "Failed to consume authorization code.")
+	}
+
+	defer deleteStatement.Close()
+
+	result, err := deleteStatement.ExecContext(ctx, codeHash)
+
+	if err != nil {
+		return nil, databaseError(err, "Error consuming authorization code.")
+	}
+
+	affectedRows, err := result.RowsAffected()
+
+	if err != nil {
+		return nil, databaseError(err, "Error consuming authorization code.")
+	}
+
+	if affectedRows != 1 {
+		return nil, &types.AppError{
+			StatusCode: 400,
+			Message:    "Invalid or expired authorization code.",
+		}
+	}
+
+	return authorizationCode, nil
+}
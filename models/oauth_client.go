@@ -0,0 +1,307 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+
+	"github.com/sandromai/go-http-server/types"
+	"github.com/sandromai/go-http-server/utils"
+)
+
+type OAuthClient struct {
+	connection
+}
+
+func scanOAuthClient(
+	row interface{ Scan(...any) error },
+) (*types.OAuthClient, error) {
+	client := &types.OAuthClient{}
+
+	var redirectURIs, scopes, grantTypes string
+
+	err := row.Scan(
+		&client.Id,
+		&client.Name,
+		&client.SecretHash,
+		&redirectURIs,
+		&scopes,
+		&grantTypes,
+		&client.Confidential,
+		&client.FirstParty,
+		&client.CreatedBy,
+		scanTimestamp(&client.CreatedAt),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal([]byte(redirectURIs), &client.RedirectURIs); err != nil {
+		return nil, err
+	}
+
+	client.Scopes = strings.Fields(scopes)
+	client.GrantTypes = strings.Fields(grantTypes)
+
+	return client, nil
+}
+
+func (model *OAuthClient) checkIdAvailability(
+	ctx context.Context,
+	id string,
+) (bool, *types.AppError) {
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"SELECT `id` FROM `oauth_clients` WHERE `id` = ? LIMIT 1",
+	)
+
+	if err != nil {
+		return false, databaseError(err, "Failed to check ID availability.")
+	}
+
+	defer statement.Close()
+
+	clientId := ""
+
+	err = statement.QueryRowContext(ctx, id).Scan(&clientId)
+
+	if err == sql.ErrNoRows {
+		return true, nil
+	}
+
+	if err != nil {
+		return false, databaseError(err, "Error checking ID availability.")
+	}
+
+	return false, nil
+}
+
+func (model *OAuthClient) generateId(
+	ctx context.Context,
+) (
+	string,
+	*types.AppError,
+) {
+	id, appErr := utils.GenerateUUIDv4()
+
+	if appErr != nil {
+		return "", appErr
+	}
+
+	idAvailability, appErr := model.checkIdAvailability(
+		ctx,
+		id,
+	)
+
+	if appErr != nil {
+		return "", appErr
+	}
+
+	for i := 0; i < 20 && !idAvailability; i++ {
+		id, appErr = utils.GenerateUUIDv4()
+
+		if appErr != nil {
+			return "", appErr
+		}
+
+		idAvailability, appErr = model.checkIdAvailability(
+			ctx,
+			id,
+		)
+
+		if appErr != nil {
+			return "", appErr
+		}
+	}
+
+	if !idAvailability {
+		return "", &types.AppError{
+			StatusCode: 500,
+			Message:    "Failed to generate ID.",
+		}
+	}
+
+	return id, nil
+}
+
+func (model *OAuthClient) List(
+	ctx context.Context,
+) (
+	[]*types.OAuthClient,
+	*types.AppError,
+) {
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	rows, err := dbConnection.QueryContext(
+		ctx,
+		"SELECT `id`, `name`, `secret_hash`, `redirect_uris`, `scopes`, `grant_types`, `confidential`, `first_party`, `created_by`, `created_at` FROM `oauth_clients` ORDER BY `name`",
+	)
+
+	if err != nil {
+		return nil, databaseError(err, "Error listing clients.")
+	}
+
+	defer rows.Close()
+
+	clients := []*types.OAuthClient{}
+
+	for rows.Next() {
+		client, err := scanOAuthClient(rows)
+
+		if err != nil {
+			return nil, databaseError(err, "Error reading client.")
+		}
+
+		clients = append(clients, client)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, databaseError(err, "Error listing clients.")
+	}
+
+	return clients, nil
+}
+
+func (model *OAuthClient) FindById(
+	ctx context.Context,
+	id string,
+) (
+	*types.OAuthClient,
+	*types.AppError,
+) {
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"SELECT `id`, `name`, `secret_hash`, `redirect_uris`, `scopes`, `grant_types`, `confidential`, `first_party`, `created_by`, `created_at` FROM `oauth_clients` WHERE `id` = ? LIMIT 1",
+	)
+
+	if err != nil {
+		return nil, databaseError(err, "Failed to find client.")
+	}
+
+	defer statement.Close()
+
+	client, err := scanOAuthClient(statement.QueryRowContext(ctx, id))
+
+	if err == sql.ErrNoRows {
+		return nil, &types.AppError{
+			StatusCode: 404,
+			Message:    "Client not found.",
+		}
+	}
+
+	if err != nil {
+		return nil, databaseError(err, "Error searching client.")
+	}
+
+	return client, nil
+}
+
+func (model *OAuthClient) Create(
+	ctx context.Context,
+	name string,
+	secretHash *string,
+	redirectURIs,
+	scopes,
+	grantTypes []string,
+	confidential,
+	firstParty bool,
+	createdBy string,
+) (
+	id string,
+	appErr *types.AppError,
+) {
+	id, appErr = model.generateId(ctx)
+
+	if appErr != nil {
+		return "", appErr
+	}
+
+	encodedRedirectURIs, err := json.Marshal(redirectURIs)
+
+	if err != nil {
+		return "", databaseError(err, "Failed to create client.")
+	}
+
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"INSERT INTO `oauth_clients` (`id`, `name`, `secret_hash`, `redirect_uris`, `scopes`, `grant_types`, `confidential`, `first_party`, `created_by`, `created_at`) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+	)
+
+	if err != nil {
+		return "", databaseError(err, "Failed to create client.")
+	}
+
+	defer statement.Close()
+
+	_, err = statement.ExecContext(
+		ctx,
+		id,
+		name,
+		secretHash,
+		string(encodedRedirectURIs),
+		strings.Join(scopes, " "),
+		strings.Join(grantTypes, " "),
+		confidential,
+		firstParty,
+		createdBy,
+		model.timestamp(model.now()),
+	)
+
+	if err != nil {
+		return "", databaseError(err, "Error creating client.")
+	}
+
+	return id, nil
+}
+
+func (model *OAuthClient) Delete(
+	ctx context.Context,
+	id string,
+) *types.AppError {
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"DELETE FROM `oauth_clients` WHERE `id` = ?",
+	)
+
+	if err != nil {
+		return databaseError(err, "Failed to delete client.")
+	}
+
+	defer statement.Close()
+
+	if _, err = statement.ExecContext(ctx, id); err != nil {
+		return databaseError(err, "Error deleting client.")
+	}
+
+	return nil
+}
@@ -0,0 +1,224 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/sandromai/go-http-server/types"
+	"github.com/sandromai/go-http-server/utils"
+)
+
+type OAuthClientToken struct {
+	connection
+}
+
+func (model *OAuthClientToken) checkIdAvailability(
+	ctx context.Context,
+	id string,
+) (bool, *types.AppError) {
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"SELECT `id` FROM `oauth_client_tokens` WHERE `id` = ? LIMIT 1",
+	)
+
+	if err != nil {
+		return false, databaseError(err, "Failed to check ID availability.")
+	}
+
+	defer statement.Close()
+
+	oauthClientTokenId := ""
+
+	err = statement.QueryRowContext(ctx, id).Scan(&oauthClientTokenId)
+
+	if err == sql.ErrNoRows {
+		return true, nil
+	}
+
+	if err != nil {
+		return false, databaseError(err, "Error checking ID availability.")
+	}
+
+	return false, nil
+}
+
+func (model *OAuthClientToken) generateId(
+	ctx context.Context,
+) (
+	string,
+	*types.AppError,
+) {
+	id, appErr := utils.GenerateUUIDv4()
+
+	if appErr != nil {
+		return "", appErr
+	}
+
+	idAvailability, appErr := model.checkIdAvailability(
+		ctx,
+		id,
+	)
+
+	if appErr != nil {
+		return "", appErr
+	}
+
+	for i := 0; i < 20 && !idAvailability; i++ {
+		id, appErr = utils.GenerateUUIDv4()
+
+		if appErr != nil {
+			return "", appErr
+		}
+
+		idAvailability, appErr = model.checkIdAvailability(
+			ctx,
+			id,
+		)
+
+		if appErr != nil {
+			return "", appErr
+		}
+	}
+
+	if !idAvailability {
+		return "", &types.AppError{
+			StatusCode: 500,
+			Message:    "Failed to generate ID.",
+		}
+	}
+
+	return id, nil
+}
+
+func (model *OAuthClientToken) FindById(
+	ctx context.Context,
+	id string,
+) (
+	*types.OAuthClientToken,
+	*types.AppError,
+) {
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"SELECT `id`, `client_id`, `scope`, `revoked`, `expires_at`, `created_at` FROM `oauth_client_tokens` WHERE `id` = ? LIMIT 1",
+	)
+
+	if err != nil {
+		return nil, databaseError(err, "Failed to find client token.")
+	}
+
+	defer statement.Close()
+
+	oauthClientToken := &types.OAuthClientToken{}
+
+	err = statement.QueryRowContext(ctx, id).Scan(
+		&oauthClientToken.Id,
+		&oauthClientToken.ClientId,
+		&oauthClientToken.Scope,
+		&oauthClientToken.Revoked,
+		scanTimestamp(&oauthClientToken.ExpiresAt),
+		scanTimestamp(&oauthClientToken.CreatedAt),
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, &types.AppError{
+			StatusCode: 404,
+			Message:    "Client token not found.",
+		}
+	}
+
+	if err != nil {
+		return nil, databaseError(err, "Error searching client token.")
+	}
+
+	return oauthClientToken, nil
+}
+
+func (model *OAuthClientToken) Create(
+	ctx context.Context,
+	clientId,
+	scope string,
+	expiresIn int64,
+) (
+	id string,
+	appErr *types.AppError,
+) {
+	id, appErr = model.generateId(ctx)
+
+	if appErr != nil {
+		return "", appErr
+	}
+
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"INSERT INTO `oauth_client_tokens` (`id`, `client_id`, `scope`, `expires_at`, `created_at`) VALUES(?, ?, ?, ?, ?)",
+	)
+
+	if err != nil {
+		return "", databaseError(err, "Failed to create client token.")
+	}
+
+	defer statement.Close()
+
+	now := model.now()
+
+	_, err = statement.ExecContext(
+		ctx,
+		id,
+		clientId,
+		scope,
+		model.expiresAt(now, expiresIn),
+		model.timestamp(now),
+	)
+
+	if err != nil {
+		return "", databaseError(err, "Error creating client token.")
+	}
+
+	return id, nil
+}
+
+func (model *OAuthClientToken) Revoke(
+	ctx context.Context,
+	id string,
+) *types.AppError {
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"UPDATE `oauth_client_tokens` SET `revoked` = TRUE WHERE `id` = ?",
+	)
+
+	if err != nil {
+		return databaseError(err, "Failed to revoke client token.")
+	}
+
+	defer statement.Close()
+
+	if _, err = statement.ExecContext(ctx, id); err != nil {
+		return databaseError(err, "Error revoking client token.")
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/sandromai/go-http-server/types"
+)
+
+type OAuthConsent struct {
+	connection
+}
+
+func (model *OAuthConsent) FindScope(
+	ctx context.Context,
+	userId,
+	clientId string,
+) (string, *types.AppError) {
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"SELECT `scope` FROM `oauth_consents` WHERE `user_id` = ? AND `client_id` = ? LIMIT 1",
+	)
+
+	if err != nil {
+		return "", databaseError(err, "Failed to find consent.")
+	}
+
+	defer statement.Close()
+
+	scope := ""
+
+	err = statement.QueryRowContext(ctx, userId, clientId).Scan(&scope)
+
+	if err == sql.ErrNoRows {
+		return "", &types.AppError{
+			StatusCode: 404,
+			Message:    "Consent not found.",
+		}
+	}
+
+	if err != nil {
+		return "", databaseError(err, "Error searching consent.")
+	}
+
+	return scope, nil
+}
+
+func (model *OAuthConsent) Save(
+	ctx context.Context,
+	userId,
+	clientId,
+	scope string,
+) *types.AppError {
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		model.dialect.Upsert(
+			"INSERT INTO `oauth_consents` (`user_id`, `client_id`, `scope`, `created_at`) VALUES(?, ?, ?, ?) ON DUPLICATE KEY UPDATE `scope` = VALUES(`scope`)",
+			"user_id",
+			"client_id",
+		),
+	)
+
+	if err != nil {
+		return databaseError(err, "Failed to save consent.")
+	}
+
+	defer statement.Close()
+
+	if _, err = statement.ExecContext(ctx, userId, clientId, scope, model.timestamp(model.now())); err != nil {
+		return databaseError(err, "Error saving consent.")
+	}
+
+	return nil
+}
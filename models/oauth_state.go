@@ -0,0 +1,136 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/sandromai/go-http-server/types"
+)
+
+type OAuthState struct {
+	connection
+}
+
+func (model *OAuthState) Create(
+	ctx context.Context,
+	state,
+	provider,
+	nonce,
+	codeVerifier string,
+	expiresIn int64,
+) *types.AppError {
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"INSERT INTO `oauth_states` (`state`, `provider`, `nonce`, `code_verifier`, `expires_at`, `created_at`) VALUES(?, ?, ?, ?, ?, ?)",
+	)
+
+	if err != nil {
+		return databaseError(err, "Failed to create authorization state.")
+	}
+
+	defer statement.Close()
+
+	now := model.now()
+
+	_, err = statement.ExecContext(
+		ctx,
+		state,
+		provider,
+		nonce,
+		codeVerifier,
+		model.expiresAt(now, expiresIn),
+		model.timestamp(now),
+	)
+
+	if err != nil {
+		return databaseError(err, "Error creating authorization state.")
+	}
+
+	return nil
+}
+
+func (model *OAuthState) Consume(
+	ctx context.Context,
+	state,
+	provider string,
+) (
+	*types.OAuthState,
+	*types.AppError,
+) {
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"SELECT `state`, `provider`, `nonce`, `code_verifier`, `expires_at`, `created_at` FROM `oauth_states` WHERE `state` = ? AND `provider` = ? AND `expires_at` > ? LIMIT 1",
+	)
+
+	if err != nil {
+		return nil, databaseError(err, "Failed to find authorization state.")
+	}
+
+	defer statement.Close()
+
+	oauthState := &types.OAuthState{}
+
+	err = statement.QueryRowContext(ctx, state, provider, model.timestamp(model.now())).Scan(
+		&oauthState.State,
+		&oauthState.Provider,
+		&oauthState.Nonce,
+		&oauthState.CodeVerifier,
+		scanTimestamp(&oauthState.ExpiresAt),
+		scanTimestamp(&oauthState.CreatedAt),
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, &types.AppError{
+			StatusCode: 400,
+			Message:    "Invalid or expired authorization state.",
+		}
+	}
+
+	if err != nil {
+		return nil, databaseError(err, "Error searching authorization state.")
+	}
+
+	deleteStatement, err := dbConnection.PrepareContext(
+		ctx,
+		"DELETE FROM `oauth_states` WHERE `state` = ?",
+	)
+
+	if err != nil {
+		return nil, databaseError(err, "Failed to consume authorization state.")
+	}
+
+	defer deleteStatement.Close()
+
+	result, err := deleteStatement.ExecContext(ctx, state)
+
+	if err != nil {
+		return nil, databaseError(err, "Error consuming authorization state.")
+	}
+
+	affectedRows, err := result.RowsAffected()
+
+	if err != nil {
+		return nil, databaseError(err, "Error consuming authorization state.")
+	}
+
+	if affectedRows != 1 {
+		return nil, &types.AppError{
+			StatusCode: 400,
+			Message:    "Invalid or expired authorization state.",
+		}
+	}
+
+	return oauthState, nil
+}
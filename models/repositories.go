@@ -0,0 +1,167 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/sandromai/go-http-server/types"
+)
+
+type UnitOfWork interface {
+	Run(ctx context.Context, work func(repositories *Repositories) *types.AppError) *types.AppError
+}
+
+type HealthChecker interface {
+	Ping(ctx context.Context) *types.AppError
+}
+
+type PoolStatser interface {
+	Stats() sql.DBStats
+}
+
+type Backend interface {
+	HealthChecker
+	Repositories() *Repositories
+	Close() *types.AppError
+}
+
+type AdminRepository interface {
+	FindById(ctx context.Context, id string) (*types.Admin, *types.AppError)
+	Create(ctx context.Context, name, username, password string, createdBy *string) (string, *types.AppError)
+	Update(ctx context.Context, name, username, password, id string) *types.AppError
+	Authenticate(ctx context.Context, username, password string) (*types.Admin, *types.AppError)
+	Count(ctx context.Context) (int64, *types.AppError)
+}
+
+type AdminTokenRepository interface {
+	FindById(ctx context.Context, id string) (*types.AdminToken, *types.AppError)
+	ListByAdmin(ctx context.Context, adminId string) ([]*types.AdminToken, *types.AppError)
+	Create(ctx context.Context, adminId, ipAddress, device string, expiresIn int64) (string, *types.AppError)
+	UpdateActivity(ctx context.Context, id string) *types.AppError
+	Disconnect(ctx context.Context, id string) *types.AppError
+	DisconnectAllByAdmin(ctx context.Context, adminId string) *types.AppError
+}
+
+type AdminTwoFactorRepository interface {
+	FindByAdmin(ctx context.Context, adminId string) (*types.AdminTwoFactor, *types.AppError)
+	Enroll(ctx context.Context, adminId, secret string) *types.AppError
+	Enable(ctx context.Context, adminId string, recoveryCodeHashes []string) *types.AppError
+	Disable(ctx context.Context, adminId string) *types.AppError
+	UseStep(ctx context.Context, adminId string, step int64) (bool, *types.AppError)
+	UseRecoveryCode(ctx context.Context, adminId, codeHash string) (bool, *types.AppError)
+}
+
+type EmailSettingRepository interface {
+	List(ctx context.Context) (*types.EmailSetting, *types.AppError)
+	Update(ctx context.Context, data map[string]string) *types.AppError
+}
+
+type LoginTokenRepository interface {
+	FindById(ctx context.Context, id string) (*types.LoginToken, *types.AppError)
+	FindByIdForUpdate(ctx context.Context, id string) (*types.LoginToken, *types.AppError)
+	Create(ctx context.Context, email, ipAddress, device string, expiresIn int64) (string, *types.AppError)
+	Authorize(ctx context.Context, id string) *types.AppError
+	Deny(ctx context.Context, id string) *types.AppError
+	CountActiveByEmail(ctx context.Context, email string) (int64, *types.AppError)
+	GetLastCreationTimeByEmail(ctx context.Context, email string) (time.Time, *types.AppError)
+	GetFirstActiveExpirationTimeByEmail(ctx context.Context, email string) (time.Time, *types.AppError)
+}
+
+type OAuthAuthorizationCodeRepository interface {
+	Create(ctx context.Context, codeHash, clientId, userId, redirectURI, scope, codeChallenge string, expiresIn int64) *types.AppError
+	Consume(ctx context.Context, codeHash string) (*types.OAuthAuthorizationCode, *types.AppError)
+}
+
+type OAuthClientRepository interface {
+	List(ctx context.Context) ([]*types.OAuthClient, *types.AppError)
+	FindById(ctx context.Context, id string) (*types.OAuthClient, *types.AppError)
+	Create(ctx context.Context, name string, secretHash *string, redirectURIs, scopes, grantTypes []string, confidential, firstParty bool, createdBy string) (string, *types.AppError)
+	Delete(ctx context.Context, id string) *types.AppError
+}
+
+type OAuthClientTokenRepository interface {
+	FindById(ctx context.Context, id string) (*types.OAuthClientToken, *types.AppError)
+	Create(ctx context.Context, clientId, scope string, expiresIn int64) (string, *types.AppError)
+	Revoke(ctx context.Context, id string) *types.AppError
+}
+
+type OAuthConsentRepository interface {
+	FindScope(ctx context.Context, userId, clientId string) (string, *types.AppError)
+	Save(ctx context.Context, userId, clientId, scope string) *types.AppError
+}
+
+type OAuthStateRepository interface {
+	Create(ctx context.Context, state, provider, nonce, codeVerifier string, expiresIn int64) *types.AppError
+	Consume(ctx context.Context, state, provider string) (*types.OAuthState, *types.AppError)
+}
+
+type RoleRepository interface {
+	List(ctx context.Context) ([]*types.Role, *types.AppError)
+	FindById(ctx context.Context, id string) (*types.Role, *types.AppError)
+	Create(ctx context.Context, name, description string, permissions []string) (string, *types.AppError)
+	Update(ctx context.Context, id, name, description string, permissions []string) *types.AppError
+	Delete(ctx context.Context, id string) *types.AppError
+	ListPermissions(ctx context.Context) ([]*types.Permission, *types.AppError)
+	LoadAdminAccess(ctx context.Context, admin *types.Admin) *types.AppError
+	SetAdminRoles(ctx context.Context, adminId string, roleIds []string) *types.AppError
+}
+
+type UserRepository interface {
+	FindById(ctx context.Context, id string) (*types.User, *types.AppError)
+	FindByEmail(ctx context.Context, email string) (*types.User, *types.AppError)
+	Create(ctx context.Context, email string) (string, *types.AppError)
+	Ban(ctx context.Context, id string) *types.AppError
+	Unban(ctx context.Context, id string) *types.AppError
+	CheckEmailAvailability(ctx context.Context, email string) (bool, *types.AppError)
+}
+
+type UserCredentialRepository interface {
+	ListByUser(ctx context.Context, userId string) ([]*types.UserCredential, *types.AppError)
+	FindById(ctx context.Context, id string) (*types.UserCredential, *types.AppError)
+	FindByCredentialId(ctx context.Context, credentialId string) (*types.UserCredential, *types.AppError)
+	Create(ctx context.Context, userId, credentialId, publicKey string, signCount uint32, name string) (string, *types.AppError)
+	UpdateSignCount(ctx context.Context, id string, previousSignCount, signCount uint32) (bool, *types.AppError)
+	Delete(ctx context.Context, id string) *types.AppError
+}
+
+type UserIdentityRepository interface {
+	FindByProviderSubject(ctx context.Context, provider, subject string) (*types.UserIdentity, *types.AppError)
+	Create(ctx context.Context, userId, provider, subject, email string) (string, *types.AppError)
+}
+
+type UserTokenRepository interface {
+	FindById(ctx context.Context, id string) (*types.UserToken, *types.AppError)
+	FindByRefreshTokenHash(ctx context.Context, refreshTokenHash string) (*types.UserToken, *types.AppError)
+	Create(ctx context.Context, userId string, fromLoginToken, fromUserToken, fromCredential, fromIdentity *string, ipAddress, device string, expiresIn int64) (string, *types.AppError)
+	UpdateActivity(ctx context.Context, id string) *types.AppError
+	Disconnect(ctx context.Context, id string) *types.AppError
+	CreateForClient(ctx context.Context, userId, clientId, scope string, refreshTokenHash, fromUserToken *string, ipAddress, device string, expiresIn int64) (string, *types.AppError)
+	DisconnectActive(ctx context.Context, id string) (bool, *types.AppError)
+	DisconnectAllByUserClient(ctx context.Context, userId, clientId string) *types.AppError
+}
+
+type WebAuthnChallengeRepository interface {
+	Create(ctx context.Context, userId *string, challengeType, challenge string, expiresIn int64) (string, *types.AppError)
+	Consume(ctx context.Context, id, challengeType string) (*types.WebAuthnChallenge, *types.AppError)
+}
+
+type Repositories struct {
+	UnitOfWork              UnitOfWork
+	Admins                  AdminRepository
+	AdminTokens             AdminTokenRepository
+	AdminTwoFactors         AdminTwoFactorRepository
+	EmailSettings           EmailSettingRepository
+	LoginTokens             LoginTokenRepository
+	OAuthAuthorizationCodes OAuthAuthorizationCodeRepository
+	OAuthClients            OAuthClientRepository
+	OAuthClientTokens       OAuthClientTokenRepository
+	OAuthConsents           OAuthConsentRepository
+	OAuthStates             OAuthStateRepository
+	Roles                   RoleRepository
+	Users                   UserRepository
+	UserCredentials         UserCredentialRepository
+	UserIdentities          UserIdentityRepository
+	UserTokens              UserTokenRepository
+	WebAuthnChallenges      WebAuthnChallengeRepository
+}
@@ -0,0 +1,758 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/sandromai/go-http-server/types"
+	"github.com/sandromai/go-http-server/utils"
+)
+
+type Role struct {
+	connection
+}
+
+func (model *Role) checkIdAvailability(
+	ctx context.Context,
+	id string,
+) (bool, *types.AppError) {
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"SELECT `id` FROM `roles` WHERE `id` = ? LIMIT 1",
+	)
+
+	if err != nil {
+		return false, databaseError(err, "Failed to check ID availability.")
+	}
+
+	defer statement.Close()
+
+	roleId := ""
+
+	err = statement.QueryRowContext(ctx, id).Scan(&roleId)
+
+	if err == sql.ErrNoRows {
+		return true, nil
+	}
+
+	if err != nil {
+		return false, databaseError(err, "Error checking ID availability.")
+	}
+
+	return false, nil
+}
+
+func (model *Role) checkNameAvailability(
+	ctx context.Context,
+	name string,
+	excludeId string,
+) (bool, *types.AppError) {
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"SELECT `id` FROM `roles` WHERE `name` = ? AND `id` != ? LIMIT 1",
+	)
+
+	if err != nil {
+		return false, databaseError(err, "Failed to check role name availability.")
+	}
+
+	defer statement.Close()
+
+	roleId := ""
+
+	err = statement.QueryRowContext(
+		ctx,
+		name,
+		excludeId,
+	).Scan(
+		&roleId,
+	)
+
+	if err == sql.ErrNoRows {
+		return true, nil
+	}
+
+	if err != nil {
+		return false, databaseError(err, "Error checking role name availability.")
+	}
+
+	return false, nil
+}
+
+func (model *Role) generateId(
+	ctx context.Context,
+) (
+	string,
+	*types.AppError,
+) {
+	id, appErr := utils.GenerateUUIDv4()
+
+	if appErr != nil {
+		return "", appErr
+	}
+
+	idAvailability, appErr := model.checkIdAvailability(
+		ctx,
+		id,
+	)
+
+	if appErr != nil {
+		return "", appErr
+	}
+
+	for i := 0; i < 20 && !idAvailability; i++ {
+		id, appErr = utils.GenerateUUIDv4()
+
+		if appErr != nil {
+			return "", appErr
+		}
+
+		idAvailability, appErr = model.checkIdAvailability(
+			ctx,
+			id,
+		)
+
+		if appErr != nil {
+			return "", appErr
+		}
+	}
+
+	if !idAvailability {
+		return "", &types.AppError{
+			StatusCode: 500,
+			Message:    "Failed to generate ID.",
+		}
+	}
+
+	return id, nil
+}
+
+func (model *Role) checkPermissions(
+	ctx context.Context,
+	permissions []string,
+) *types.AppError {
+	if len(permissions) == 0 {
+		return nil
+	}
+
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	uniquePermissions := map[string]bool{}
+	values := []any{}
+
+	for _, permission := range permissions {
+		if uniquePermissions[permission] {
+			continue
+		}
+
+		uniquePermissions[permission] = true
+		values = append(values, permission)
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")
+
+	foundPermissions := 0
+
+	err := dbConnection.QueryRowContext(
+		ctx,
+		"SELECT COUNT(`id`) FROM `permissions` WHERE `id` IN ("+placeholders+")",
+		values...,
+	).Scan(
+		&foundPermissions,
+	)
+
+	if err != nil {
+		return databaseError(err, "Error checking permissions.")
+	}
+
+	if foundPermissions != len(values) {
+		return &types.AppError{
+			StatusCode: 400,
+			Message:    "Invalid permission.",
+		}
+	}
+
+	return nil
+}
+
+func (model *Role) replacePermissions(
+	ctx context.Context,
+	transaction transaction,
+	roleId string,
+	permissions []string,
+) *types.AppError {
+	_, err := transaction.ExecContext(
+		ctx,
+		"DELETE FROM `role_permissions` WHERE `role_id` = ?",
+		roleId,
+	)
+
+	if err != nil {
+		return databaseError(err, "Error updating role permissions.")
+	}
+
+	inserted := map[string]bool{}
+
+	for _, permission := range permissions {
+		if inserted[permission] {
+			continue
+		}
+
+		inserted[permission] = true
+
+		_, err = transaction.ExecContext(
+			ctx,
+			"INSERT INTO `role_permissions` (`role_id`, `permission_id`) VALUES(?, ?)",
+			roleId,
+			permission,
+		)
+
+		if err != nil {
+			return databaseError(err, "Error updating role permissions.")
+		}
+	}
+
+	return nil
+}
+
+func (model *Role) listPermissionsByRole(
+	ctx context.Context,
+) (
+	map[string][]string,
+	*types.AppError,
+) {
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	rows, err := dbConnection.QueryContext(
+		ctx,
+		"SELECT `role_id`, `permission_id` FROM `role_permissions` ORDER BY `permission_id`",
+	)
+
+	if err != nil {
+		return nil, databaseError(err, "Failed to list role permissions.")
+	}
+
+	defer rows.Close()
+
+	permissionsByRole := map[string][]string{}
+
+	for rows.Next() {
+		roleId := ""
+		permissionId := ""
+
+		if err = rows.Scan(&roleId, &permissionId); err != nil {
+			return nil, databaseError(err, "Error listing role permissions.")
+		}
+
+		permissionsByRole[roleId] = append(permissionsByRole[roleId], permissionId)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, databaseError(err, "Error listing role permissions.")
+	}
+
+	return permissionsByRole, nil
+}
+
+func (model *Role) List(
+	ctx context.Context,
+) (
+	[]*types.Role,
+	*types.AppError,
+) {
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	rows, err := dbConnection.QueryContext(
+		ctx,
+		"SELECT `id`, `name`, `description`, `created_at` FROM `roles` ORDER BY `name`",
+	)
+
+	if err != nil {
+		return nil, databaseError(err, "Failed to list roles.")
+	}
+
+	defer rows.Close()
+
+	roles := []*types.Role{}
+
+	for rows.Next() {
+		listedRole := &types.Role{}
+
+		err = rows.Scan(
+			&listedRole.Id,
+			&listedRole.Name,
+			&listedRole.Description,
+			scanTimestamp(&listedRole.CreatedAt),
+		)
+
+		if err != nil {
+			return nil, databaseError(err, "Error listing roles.")
+		}
+
+		roles = append(roles, listedRole)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, databaseError(err, "Error listing roles.")
+	}
+
+	permissionsByRole, appErr := model.listPermissionsByRole(ctx)
+
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	for _, listedRole := range roles {
+		listedRole.Permissions = permissionsByRole[listedRole.Id]
+
+		if listedRole.Permissions == nil {
+			listedRole.Permissions = []string{}
+		}
+	}
+
+	return roles, nil
+}
+
+func (model *Role) FindById(
+	ctx context.Context,
+	id string,
+) (*types.Role, *types.AppError) {
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"SELECT `id`, `name`, `description`, `created_at` FROM `roles` WHERE `id` = ? LIMIT 1",
+	)
+
+	if err != nil {
+		return nil, databaseError(err, "Failed to find role.")
+	}
+
+	defer statement.Close()
+
+	role := &types.Role{}
+
+	err = statement.QueryRowContext(ctx, id).Scan(
+		&role.Id,
+		&role.Name,
+		&role.Description,
+		scanTimestamp(&role.CreatedAt),
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, &types.AppError{
+			StatusCode: 404,
+			Message:    "Role not found.",
+		}
+	}
+
+	if err != nil {
+		return nil, databaseError(err, "Error searching for role.")
+	}
+
+	rows, err := dbConnection.QueryContext(
+		ctx,
+		"SELECT `permission_id` FROM `role_permissions` WHERE `role_id` = ? ORDER BY `permission_id`",
+		role.Id,
+	)
+
+	if err != nil {
+		return nil, databaseError(err, "Failed to list role permissions.")
+	}
+
+	defer rows.Close()
+
+	role.Permissions = []string{}
+
+	for rows.Next() {
+		permissionId := ""
+
+		if err = rows.Scan(&permissionId); err != nil {
+			return nil, databaseError(err, "Error listing role permissions.")
+		}
+
+		role.Permissions = append(role.Permissions, permissionId)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, databaseError(err, "Error listing role permissions.")
+	}
+
+	return role, nil
+}
+
+func (model *Role) Create(
+	ctx context.Context,
+	name,
+	description string,
+	permissions []string,
+) (
+	id string,
+	appErr *types.AppError,
+) {
+	nameIsAvailable, appErr := model.checkNameAvailability(
+		ctx,
+		name,
+		"",
+	)
+
+	if appErr != nil {
+		return "", appErr
+	}
+
+	if !nameIsAvailable {
+		return "", &types.AppError{
+			StatusCode: 409,
+			Message:    "Role name already registered.",
+		}
+	}
+
+	if appErr = model.checkPermissions(ctx, permissions); appErr != nil {
+		return "", appErr
+	}
+
+	id, appErr = model.generateId(ctx)
+
+	if appErr != nil {
+		return "", appErr
+	}
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	transaction, err := model.begin(ctx)
+
+	if err != nil {
+		return "", databaseError(err, "Failed to create role.")
+	}
+
+	defer transaction.Rollback()
+
+	_, err = transaction.ExecContext(
+		ctx,
+		"INSERT INTO `roles` (`id`, `name`, `description`, `created_at`) VALUES(?, ?, ?, ?)",
+		id,
+		name,
+		description,
+		model.timestamp(model.now()),
+	)
+
+	if err != nil {
+		return "", databaseError(err, "Error creating role.")
+	}
+
+	if appErr = model.replacePermissions(ctx, transaction, id, permissions); appErr != nil {
+		return "", appErr
+	}
+
+	if err = transaction.Commit(); err != nil {
+		return "", databaseError(err, "Error creating role.")
+	}
+
+	return id, nil
+}
+
+func (model *Role) Update(
+	ctx context.Context,
+	id,
+	name,
+	description string,
+	permissions []string,
+) *types.AppError {
+	nameIsAvailable, appErr := model.checkNameAvailability(
+		ctx,
+		name,
+		id,
+	)
+
+	if appErr != nil {
+		return appErr
+	}
+
+	if !nameIsAvailable {
+		return &types.AppError{
+			StatusCode: 409,
+			Message:    "Role name already registered.",
+		}
+	}
+
+	if appErr = model.checkPermissions(ctx, permissions); appErr != nil {
+		return appErr
+	}
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	transaction, err := model.begin(ctx)
+
+	if err != nil {
+		return databaseError(err, "Failed to update role.")
+	}
+
+	defer transaction.Rollback()
+
+	_, err = transaction.ExecContext(
+		ctx,
+		"UPDATE `roles` SET `name` = ?, `description` = ? WHERE `id` = ?",
+		name,
+		description,
+		id,
+	)
+
+	if err != nil {
+		return databaseError(err, "Error updating role.")
+	}
+
+	if appErr = model.replacePermissions(ctx, transaction, id, permissions); appErr != nil {
+		return appErr
+	}
+
+	if err = transaction.Commit(); err != nil {
+		return databaseError(err, "Error updating role.")
+	}
+
+	return nil
+}
+
+func (model *Role) Delete(
+	ctx context.Context,
+	id string,
+) *types.AppError {
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(ctx, "DELETE FROM `roles` WHERE `id` = ?")
+
+	if err != nil {
+		return databaseError(err, "Failed to delete role.")
+	}
+
+	defer statement.Close()
+
+	result, err := statement.ExecContext(ctx, id)
+
+	if err != nil {
+		return databaseError(err, "Error deleting role.")
+	}
+
+	affectedRows, err := result.RowsAffected()
+
+	if err != nil {
+		return databaseError(err, "Error deleting role.")
+	}
+
+	if affectedRows != 1 {
+		return &types.AppError{
+			StatusCode: 404,
+			Message:    "Role not found.",
+		}
+	}
+
+	return nil
+}
+
+func (model *Role) ListPermissions(
+	ctx context.Context,
+) (
+	[]*types.Permission,
+	*types.AppError,
+) {
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	rows, err := dbConnection.QueryContext(
+		ctx,
+		"SELECT `id`, `description` FROM `permissions` ORDER BY `id`",
+	)
+
+	if err != nil {
+		return nil, databaseError(err, "Failed to list permissions.")
+	}
+
+	defer rows.Close()
+
+	permissions := []*types.Permission{}
+
+	for rows.Next() {
+		permission := &types.Permission{}
+
+		if err = rows.Scan(&permission.Id, &permission.Description); err != nil {
+			return nil, databaseError(err, "Error listing permissions.")
+		}
+
+		permissions = append(permissions, permission)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, databaseError(err, "Error listing permissions.")
+	}
+
+	return permissions, nil
+}
+
+func (model *Role) LoadAdminAccess(
+	ctx context.Context,
+	admin *types.Admin,
+) *types.AppError {
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	rows, err := dbConnection.QueryContext(
+		ctx,
+		"SELECT `roles`.`name`, `role_permissions`.`permission_id` FROM `admin_roles` INNER JOIN `roles` ON `roles`.`id` = `admin_roles`.`role_id` LEFT JOIN `role_permissions` ON `role_permissions`.`role_id` = `roles`.`id` WHERE `admin_roles`.`admin_id` = ? ORDER BY `roles`.`name`, `role_permissions`.`permission_id`",
+		admin.Id,
+	)
+
+	if err != nil {
+		return databaseError(err, "Failed to load admin permissions.")
+	}
+
+	defer rows.Close()
+
+	admin.Roles = []string{}
+	admin.Permissions = []string{}
+
+	loadedRoles := map[string]bool{}
+	loadedPermissions := map[string]bool{}
+
+	for rows.Next() {
+		roleName := ""
+		var permissionId sql.NullString
+
+		if err = rows.Scan(&roleName, &permissionId); err != nil {
+			return databaseError(err, "Error loading admin permissions.")
+		}
+
+		if !loadedRoles[roleName] {
+			loadedRoles[roleName] = true
+			admin.Roles = append(admin.Roles, roleName)
+		}
+
+		if permissionId.Valid && !loadedPermissions[permissionId.String] {
+			loadedPermissions[permissionId.String] = true
+			admin.Permissions = append(admin.Permissions, permissionId.String)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return databaseError(err, "Error loading admin permissions.")
+	}
+
+	return nil
+}
+
+func (model *Role) SetAdminRoles(
+	ctx context.Context,
+	adminId string,
+	roleIds []string,
+) *types.AppError {
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	transaction, err := model.begin(ctx)
+
+	if err != nil {
+		return databaseError(err, "Failed to update admin roles.")
+	}
+
+	defer transaction.Rollback()
+
+	_, err = transaction.ExecContext(
+		ctx,
+		"DELETE FROM `admin_roles` WHERE `admin_id` = ?",
+		adminId,
+	)
+
+	if err != nil {
+		return databaseError(err, "Error updating admin roles.")
+	}
+
+	inserted := map[string]bool{}
+
+	for _, roleId := range roleIds {
+		if inserted[roleId] {
+			continue
+		}
+
+		inserted[roleId] = true
+
+		roleExists := 0
+
+		err = transaction.QueryRowContext(
+			ctx,
+			"SELECT COUNT(`id`) FROM `roles` WHERE `id` = ?",
+			roleId,
+		).Scan(
+			&roleExists,
+		)
+
+		if err != nil {
+			return databaseError(err, "Error updating admin roles.")
+		}
+
+		if roleExists == 0 {
+			return &types.AppError{
+				StatusCode: 400,
+				Message:    "Invalid role.",
+			}
+		}
+
+		_, err = transaction.ExecContext(
+			ctx,
+			"INSERT INTO `admin_roles` (`admin_id`, `role_id`) VALUES(?, ?)",
+			adminId,
+			roleId,
+		)
+
+		if err != nil {
+			return databaseError(err, "Error updating admin roles.")
+		}
+	}
+
+	if err = transaction.Commit(); err != nil {
+		return databaseError(err, "Error updating admin roles.")
+	}
+
+	return nil
+}
@@ -0,0 +1,359 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/sandromai/go-http-server/clock"
+	"github.com/sandromai/go-http-server/config"
+	"github.com/sandromai/go-http-server/database"
+	"github.com/sandromai/go-http-server/types"
+)
+
+type executor interface {
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type transaction interface {
+	PrepareContext(ctx context.Context, query string) (*statement, error)
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	Commit() error
+	Rollback() error
+}
+
+type dialectExecutor struct {
+	executor
+	dialect database.Dialect
+}
+
+func (db dialectExecutor) PrepareContext(
+	ctx context.Context,
+	query string,
+) (*statement, error) {
+	query = db.dialect.Rebind(query)
+
+	prepared, err := db.executor.PrepareContext(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &statement{
+		Stmt:    prepared,
+		query:   query,
+		dialect: db.dialect,
+	}, nil
+}
+
+func (db dialectExecutor) ExecContext(
+	ctx context.Context,
+	query string,
+	args ...any,
+) (sql.Result, error) {
+	query = db.dialect.Rebind(query)
+
+	ctx, span := startQuerySpan(ctx, db.dialect, query)
+
+	result, err := db.executor.ExecContext(ctx, query, args...)
+
+	endQuerySpan(span, err)
+
+	return result, err
+}
+
+func (db dialectExecutor) QueryContext(
+	ctx context.Context,
+	query string,
+	args ...any,
+) (*sql.Rows, error) {
+	query = db.dialect.Rebind(query)
+
+	ctx, span := startQuerySpan(ctx, db.dialect, query)
+
+	rows, err := db.executor.QueryContext(ctx, query, args...)
+
+	endQuerySpan(span, err)
+
+	return rows, err
+}
+
+func (db dialectExecutor) QueryRowContext(
+	ctx context.Context,
+	query string,
+	args ...any,
+) *sql.Row {
+	query = db.dialect.Rebind(query)
+
+	ctx, span := startQuerySpan(ctx, db.dialect, query)
+
+	row := db.executor.QueryRowContext(ctx, query, args...)
+
+	endQuerySpan(span, row.Err())
+
+	return row
+}
+
+type dialectTransaction struct {
+	dialectExecutor
+	tx *sql.Tx
+}
+
+func (transaction dialectTransaction) Commit() error {
+	return transaction.tx.Commit()
+}
+
+func (transaction dialectTransaction) Rollback() error {
+	return transaction.tx.Rollback()
+}
+
+type joinedTransaction struct {
+	dialectExecutor
+}
+
+func (joinedTransaction) Commit() error {
+	return nil
+}
+
+func (joinedTransaction) Rollback() error {
+	return nil
+}
+
+type connection struct {
+	db           dialectExecutor
+	dialect      database.Dialect
+	queryTimeout time.Duration
+	clock        clock.Clock
+}
+
+func newConnection(
+	db executor,
+	dialect database.Dialect,
+	queryTimeout time.Duration,
+	clock clock.Clock,
+) connection {
+	return connection{
+		db: dialectExecutor{
+			executor: db,
+			dialect:  dialect,
+		},
+		dialect:      dialect,
+		queryTimeout: queryTimeout,
+		clock:        clock,
+	}
+}
+
+func (conn connection) now() time.Time {
+	return clock.Now(conn.clock).Truncate(time.Second)
+}
+
+func (conn connection) timestamp(
+	value time.Time,
+) any {
+	return conn.dialect.Timestamp(value)
+}
+
+func (conn connection) expiresAt(
+	now time.Time,
+	expiresIn int64,
+) any {
+	return conn.timestamp(now.Add(time.Duration(expiresIn) * time.Second))
+}
+
+func (conn connection) withTimeout(
+	ctx context.Context,
+) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, conn.queryTimeout)
+}
+
+func (conn connection) begin(
+	ctx context.Context,
+) (transaction, error) {
+	if _, ok := conn.db.executor.(*sql.Tx); ok {
+		return joinedTransaction{dialectExecutor: conn.db}, nil
+	}
+
+	tx, err := conn.db.executor.(*sql.DB).BeginTx(ctx, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return dialectTransaction{
+		dialectExecutor: dialectExecutor{
+			executor: tx,
+			dialect:  conn.dialect,
+		},
+		tx: tx,
+	}, nil
+}
+
+type Store struct {
+	connection
+	database      *sql.DB
+	encryptionKey []byte
+	passwordCost  int
+}
+
+func Open(
+	dialect database.Dialect,
+	settings config.Database,
+) (*sql.DB, error) {
+	db, err := sql.Open(
+		dialect.DriverName(),
+		dialect.DataSourceName(settings),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxIdleConns(settings.MaxIdleConns)
+	db.SetMaxOpenConns(settings.MaxOpenConns)
+	db.SetConnMaxIdleTime(settings.ConnMaxIdleTime)
+	db.SetConnMaxLifetime(settings.ConnMaxLifetime)
+
+	if dialect.SingleWriter() {
+		db.SetMaxIdleConns(1)
+		db.SetMaxOpenConns(1)
+		db.SetConnMaxIdleTime(0)
+		db.SetConnMaxLifetime(0)
+	}
+
+	if err = db.Ping(); err != nil {
+		db.Close()
+
+		return nil, err
+	}
+
+	return db, nil
+}
+
+func NewStore(
+	db *sql.DB,
+	dialect database.Dialect,
+	queryTimeout time.Duration,
+	encryptionKey []byte,
+	passwordCost int,
+	clock clock.Clock,
+) *Store {
+	return &Store{
+		connection:    newConnection(db, dialect, queryTimeout, clock),
+		database:      db,
+		encryptionKey: encryptionKey,
+		passwordCost:  passwordCost,
+	}
+}
+
+func (store *Store) Ping(
+	ctx context.Context,
+) *types.AppError {
+	ctx, cancel := store.withTimeout(ctx)
+
+	defer cancel()
+
+	if err := store.database.PingContext(ctx); err != nil {
+		return &types.AppError{
+			StatusCode: 503,
+			Message:    "Database unreachable.",
+		}
+	}
+
+	return nil
+}
+
+func (store *Store) Stats() sql.DBStats {
+	return store.database.Stats()
+}
+
+func (store *Store) Close() *types.AppError {
+	if err := store.database.Close(); err != nil {
+		return &types.AppError{
+			StatusCode: 500,
+			Message:    "Error closing database connection.",
+		}
+	}
+
+	return nil
+}
+
+func (store *Store) repositories(
+	conn connection,
+) *Repositories {
+	return &Repositories{
+		Admins:                  &Admin{connection: conn, passwordCost: store.passwordCost},
+		AdminTokens:             &AdminToken{connection: conn},
+		AdminTwoFactors:         &AdminTwoFactor{connection: conn, encryptionKey: store.encryptionKey},
+		EmailSettings:           &EmailSetting{connection: conn, encryptionKey: store.encryptionKey},
+		LoginTokens:             &LoginToken{connection: conn},
+		OAuthAuthorizationCodes: &OAuthAuthorizationCode{connection: conn},
+		OAuthClients:            &OAuthClient{connection: conn},
+		OAuthClientTokens:       &OAuthClientToken{connection: conn},
+		OAuthConsents:           &OAuthConsent{connection: conn},
+		OAuthStates:             &OAuthState{connection: conn},
+		Roles:                   &Role{connection: conn},
+		Users:                   &User{connection: conn},
+		UserCredentials:         &UserCredential{connection: conn},
+		UserIdentities:          &UserIdentity{connection: conn},
+		UserTokens:              &UserToken{connection: conn},
+		WebAuthnChallenges:      &WebAuthnChallenge{connection: conn},
+	}
+}
+
+func (store *Store) Repositories() *Repositories {
+	repositories := store.repositories(store.connection)
+
+	repositories.UnitOfWork = store
+
+	return repositories
+}
+
+func (store *Store) Run(
+	ctx context.Context,
+	work func(repositories *Repositories) *types.AppError,
+) *types.AppError {
+	tx, err := store.database.BeginTx(ctx, nil)
+
+	if err != nil {
+		return databaseError(err, "Failed to start transaction.")
+	}
+
+	defer tx.Rollback()
+
+	repositories := store.repositories(newConnection(
+		tx,
+		store.dialect,
+		store.queryTimeout,
+		store.clock,
+	))
+
+	repositories.UnitOfWork = &joinedUnitOfWork{
+		repositories: repositories,
+	}
+
+	if appErr := work(repositories); appErr != nil {
+		return appErr
+	}
+
+	if err = tx.Commit(); err != nil {
+		return databaseError(err, "Error committing transaction.")
+	}
+
+	return nil
+}
+
+type joinedUnitOfWork struct {
+	repositories *Repositories
+}
+
+func (unitOfWork *joinedUnitOfWork) Run(
+	ctx context.Context,
+	work func(repositories *Repositories) *types.AppError,
+) *types.AppError {
+	return work(unitOfWork.repositories)
+}
@@ -0,0 +1,161 @@
+package models_test
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/sandromai/go-http-server/clock"
+	"github.com/sandromai/go-http-server/config"
+	"github.com/sandromai/go-http-server/database"
+	"github.com/sandromai/go-http-server/models"
+	"github.com/sandromai/go-http-server/types"
+)
+
+func newSQLiteStore(
+	t *testing.T,
+) *models.Store {
+	t.Helper()
+
+	dialect, err := database.NewDialect("sqlite")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := models.Open(dialect, config.Database{Name: filepath.Join(t.TempDir(), "app.db")})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { db.Close() })
+
+	migrations, err := database.Migrations(dialect)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	migrator := &database.Migrator{
+		DB:          db,
+		Dialect:     dialect,
+		LockTimeout: time.Minute,
+		Migrations:  migrations,
+	}
+
+	if _, err = migrator.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	return models.NewStore(db, dialect, time.Minute, []byte("0123456789abcdef"), bcrypt.MinCost, clock.System{})
+}
+
+func TestSQLiteConcurrentLoginTokenConsumeCreatesOneSession(t *testing.T) {
+	repositories := newSQLiteStore(t).Repositories()
+	ctx := context.Background()
+
+	userId, appErr := repositories.Users.Create(ctx, "user@example.com")
+
+	if appErr != nil {
+		t.Fatal(appErr.Message)
+	}
+
+	loginTokenId, appErr := repositories.LoginTokens.Create(ctx, "user@example.com", "127.0.0.1", "test", 60)
+
+	if appErr != nil {
+		t.Fatal(appErr.Message)
+	}
+
+	var waitGroup sync.WaitGroup
+	var mutex sync.Mutex
+
+	created := 0
+	var failures []*types.AppError
+
+	for i := 0; i < 8; i++ {
+		waitGroup.Add(1)
+
+		go func() {
+			defer waitGroup.Done()
+
+			appErr := repositories.UnitOfWork.Run(ctx, func(transaction *models.Repositories) *types.AppError {
+				if _, appErr := transaction.LoginTokens.FindByIdForUpdate(ctx, loginTokenId); appErr != nil {
+					return appErr
+				}
+
+				_, appErr := transaction.UserTokens.Create(ctx, userId, &loginTokenId, nil, nil, nil, "127.0.0.1", "test", 60)
+
+				return appErr
+			})
+
+			mutex.Lock()
+
+			defer mutex.Unlock()
+
+			if appErr == nil {
+				created++
+			} else {
+				failures = append(failures, appErr)
+			}
+		}()
+	}
+
+	waitGroup.Wait()
+
+	if created != 1 {
+		t.Fatalf("created %v sessions, expected 1", created)
+	}
+
+	for _, failure := range failures {
+		if failure.StatusCode != 400 || failure.Message != "Login token already used." {
+			t.Fatalf("expected the duplicate session to map to a 400, got %+v", failure)
+		}
+	}
+}
+
+func TestSQLiteRunRollsBackPasswordChange(t *testing.T) {
+	repositories := newSQLiteStore(t).Repositories()
+	ctx := context.Background()
+
+	adminId, appErr := repositories.Admins.Create(ctx, "Admin", "admin", "old-password", nil)
+
+	if appErr != nil {
+		t.Fatal(appErr.Message)
+	}
+
+	adminTokenId, appErr := repositories.AdminTokens.Create(ctx, adminId, "127.0.0.1", "test", 60)
+
+	if appErr != nil {
+		t.Fatal(appErr.Message)
+	}
+
+	appErr = repositories.UnitOfWork.Run(ctx, func(transaction *models.Repositories) *types.AppError {
+		if appErr := transaction.Admins.Update(ctx, "Admin", "admin", "new-password", adminId); appErr != nil {
+			return appErr
+		}
+
+		return &types.AppError{
+			StatusCode: 500,
+			Message:    "Error disconnecting admin tokens.",
+		}
+	})
+
+	if appErr == nil {
+		t.Fatal("expected the unit of work to fail")
+	}
+
+	if _, appErr = repositories.Admins.Authenticate(ctx, "admin", "old-password"); appErr != nil {
+		t.Fatalf("expected the password change to be rolled back, got %+v", appErr)
+	}
+
+	adminToken, appErr := repositories.AdminTokens.FindById(ctx, adminTokenId)
+
+	if appErr != nil || adminToken.Disconnected {
+		t.Fatalf("expected the session to stay connected, got %+v and %+v", adminToken, appErr)
+	}
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type timestamp struct {
+	destination *time.Time
+}
+
+func scanTimestamp(
+	destination *time.Time,
+) sql.Scanner {
+	return timestamp{destination: destination}
+}
+
+func (value timestamp) Scan(
+	source any,
+) error {
+	parsed, err := parseTimestamp(source)
+
+	if err != nil {
+		return err
+	}
+
+	*value.destination = parsed
+
+	return nil
+}
+
+type nullTimestamp struct {
+	destination **time.Time
+}
+
+func scanNullTimestamp(
+	destination **time.Time,
+) sql.Scanner {
+	return nullTimestamp{destination: destination}
+}
+
+func (value nullTimestamp) Scan(
+	source any,
+) error {
+	if source == nil {
+		*value.destination = nil
+
+		return nil
+	}
+
+	parsed, err := parseTimestamp(source)
+
+	if err != nil {
+		return err
+	}
+
+	*value.destination = &parsed
+
+	return nil
+}
+
+func parseTimestamp(
+	source any,
+) (time.Time, error) {
+	switch source := source.(type) {
+	case time.Time:
+		return source.UTC(), nil
+	case string:
+		return time.ParseInLocation(time.DateTime, source, time.UTC)
+	case []byte:
+		return time.ParseInLocation(time.DateTime, string(source), time.UTC)
+	default:
+		return time.Time{}, errors.New("models: unsupported timestamp value")
+	}
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/sandromai/go-http-server/database"
+	"github.com/sandromai/go-http-server/tracing"
+)
+
+func startQuerySpan(
+	ctx context.Context,
+	dialect database.Dialect,
+	query string,
+) (context.Context, trace.Span) {
+	operation := "QUERY"
+
+	if fields := strings.Fields(query); len(fields) > 0 {
+		operation = strings.ToUpper(fields[0])
+	}
+
+	return tracing.Tracer().Start(
+		ctx,
+		operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", dialect.Name()),
+			attribute.String("db.operation", operation),
+			attribute.String("db.statement", query),
+		),
+	)
+}
+
+func endQuerySpan(
+	span trace.Span,
+	err error,
+) {
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+type statement struct {
+	*sql.Stmt
+	query   string
+	dialect database.Dialect
+}
+
+func (statement *statement) ExecContext(
+	ctx context.Context,
+	args ...any,
+) (sql.Result, error) {
+	ctx, span := startQuerySpan(ctx, statement.dialect, statement.query)
+
+	result, err := statement.Stmt.ExecContext(ctx, args...)
+
+	endQuerySpan(span, err)
+
+	return result, err
+}
+
+func (statement *statement) QueryContext(
+	ctx context.Context,
+	args ...any,
+) (*sql.Rows, error) {
+	ctx, span := startQuerySpan(ctx, statement.dialect, statement.query)
+
+	rows, err := statement.Stmt.QueryContext(ctx, args...)
+
+	endQuerySpan(span, err)
+
+	return rows, err
+}
+
+func (statement *statement) QueryRowContext(
+	ctx context.Context,
+	args ...any,
+) *sql.Row {
+	ctx, span := startQuerySpan(ctx, statement.dialect, statement.query)
+
+	row := statement.Stmt.QueryRowContext(ctx, args...)
+
+	endQuerySpan(span, row.Err())
+
+	return row
+}
@@ -1,58 +1,59 @@
 package models
 
 import (
+	"context"
 	"database/sql"
 
 	"github.com/sandromai/go-http-server/types"
 	"github.com/sandromai/go-http-server/utils"
 )
 
-type User struct{}
+type User struct {
+	connection
+}
 
-func (*User) checkIdAvailability(
+func (model *User) checkIdAvailability(
+	ctx context.Context,
 	id string,
 ) (
 	bool,
 	*types.AppError,
 ) {
-	dbConnection, appErr := getDBInstance()
+	dbConnection := model.db
 
-	if appErr != nil {
-		return false, appErr
-	}
+	ctx, cancel := model.withTimeout(ctx)
 
-	statement, err := dbConnection.Prepare(
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
 		"SELECT `id` FROM `users` WHERE `id` = ? LIMIT 1",
 	)
 
 	if err != nil {
-		return false, &types.AppError{
-			StatusCode: 500,
-			Message:    "Failed to check ID availability.",
-		}
+		return false, databaseError(err, "Failed to check ID availability.")
 	}
 
 	defer statement.Close()
 
 	adminId := ""
 
-	err = statement.QueryRow(id).Scan(&adminId)
+	err = statement.QueryRowContext(ctx, id).Scan(&adminId)
 
 	if err == sql.ErrNoRows {
 		return true, nil
 	}
 
 	if err != nil {
-		return false, &types.AppError{
-			StatusCode: 500,
-			Message:    "Error checking ID availability.",
-		}
+		return false, databaseError(err, "Error checking ID availability.")
 	}
 
 	return false, nil
 }
 
-func (user *User) generateId() (
+func (model *User) generateId(
+	ctx context.Context,
+) (
 	string,
 	*types.AppError,
 ) {
@@ -62,7 +63,8 @@ func (user *User) generateId() (
 		return "", appErr
 	}
 
-	idAvailability, appErr := user.checkIdAvailability(
+	idAvailability, appErr := model.checkIdAvailability(
+		ctx,
 		id,
 	)
 
@@ -77,7 +79,8 @@ func (user *User) generateId() (
 			return "", appErr
 		}
 
-		idAvailability, appErr = user.checkIdAvailability(
+		idAvailability, appErr = model.checkIdAvailability(
+			ctx,
 			id,
 		)
 
@@ -96,38 +99,37 @@ func (user *User) generateId() (
 	return id, nil
 }
 
-func (*User) FindById(
+func (model *User) FindById(
+	ctx context.Context,
 	id string,
 ) (
 	*types.User,
 	*types.AppError,
 ) {
-	dbConnection, appErr := getDBInstance()
+	dbConnection := model.db
 
-	if appErr != nil {
-		return nil, appErr
-	}
+	ctx, cancel := model.withTimeout(ctx)
 
-	statement, err := dbConnection.Prepare(
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
 		"SELECT `id`, `email`, `banned`, `created_at` FROM `users` WHERE `id` = ? LIMIT 1",
 	)
 
 	if err != nil {
-		return nil, &types.AppError{
-			StatusCode: 500,
-			Message:    "Failed to find user.",
-		}
+		return nil, databaseError(err, "Failed to find user.")
 	}
 
 	defer statement.Close()
 
 	user := &types.User{}
 
-	err = statement.QueryRow(id).Scan(
+	err = statement.QueryRowContext(ctx, id).Scan(
 		&user.Id,
 		&user.Email,
 		&user.Banned,
-		&user.CreatedAt,
+		scanTimestamp(&user.CreatedAt),
 	)
 
 	if err == sql.ErrNoRows {
@@ -138,47 +140,45 @@ func (*User) FindById(
 	}
 
 	if err != nil {
-		return nil, &types.AppError{
-			StatusCode: 500,
-			Message:    "Error searching for user.",
-		}
+		return nil, databaseError(err, "Error searching for user.")
 	}
 
 	return user, nil
 }
 
-func (*User) FindByEmail(
+func (model *User) FindByEmail(
+	ctx context.Context,
 	email string,
 ) (
 	*types.User,
 	*types.AppError,
 ) {
-	dbConnection, appErr := getDBInstance()
+	email = utils.NormalizeEmail(email)
 
-	if appErr != nil {
-		return nil, appErr
-	}
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
 
-	statement, err := dbConnection.Prepare(
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
 		"SELECT `id`, `email`, `banned`, `created_at` FROM `users` WHERE `email` = ? LIMIT 1",
 	)
 
 	if err != nil {
-		return nil, &types.AppError{
-			StatusCode: 500,
-			Message:    "Failed to find user.",
-		}
+		return nil, databaseError(err, "Failed to find user.")
 	}
 
 	defer statement.Close()
 
 	user := &types.User{}
 
-	err = statement.QueryRow(email).Scan(
+	err = statement.QueryRowContext(ctx, email).Scan(
 		&user.Id,
 		&user.Email,
 		&user.Banned,
-		&user.CreatedAt,
+		scanTimestamp(&user.CreatedAt),
 	)
 
 	if err == sql.ErrNoRows {
@@ -189,28 +189,29 @@ func (*User) FindByEmail(
 	}
 
 	if err != nil {
-		return nil, &types.AppError{
-			StatusCode: 500,
-			Message:    "Error searching for user.",
-		}
+		return nil, databaseError(err, "Error searching for user.")
 	}
 
 	return user, nil
 }
 
-func (user *User) Create(
+func (model *User) Create(
+	ctx context.Context,
 	email string,
 ) (
 	id string,
 	appErr *types.AppError,
 ) {
-	id, appErr = user.generateId()
+	email = utils.NormalizeEmail(email)
+
+	id, appErr = model.generateId(ctx)
 
 	if appErr != nil {
 		return "", appErr
 	}
 
-	emailIsAvailable, appErr := user.CheckEmailAvailability(
+	emailIsAvailable, appErr := model.CheckEmailAvailability(
+		ctx,
 		email,
 	)
 
@@ -225,128 +226,124 @@ func (user *User) Create(
 		}
 	}
 
-	dbConnection, appErr := getDBInstance()
+	dbConnection := model.db
 
-	if appErr != nil {
-		return "", appErr
-	}
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
 
-	statement, err := dbConnection.Prepare(
-		"INSERT INTO `users` (`id`, `email`) VALUES(?, ?)",
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"INSERT INTO `users` (`id`, `email`, `created_at`) VALUES(?, ?, ?)",
 	)
 
 	if err != nil {
-		return "", &types.AppError{
-			StatusCode: 500,
-			Message:    "Failed to create user.",
-		}
+		return "", databaseError(err, "Failed to create user.")
 	}
 
 	defer statement.Close()
 
-	_, err = statement.Exec(
+	_, err = statement.ExecContext(
+		ctx,
 		id,
 		email,
+		model.timestamp(model.now()),
 	)
 
-	if err != nil {
+	if model.dialect.IsDuplicateEntry(err) {
 		return "", &types.AppError{
-			StatusCode: 500,
-			Message:    "Error creating user.",
+			StatusCode: 409,
+			Message:    "Email already registered.",
 		}
 	}
 
+	if err != nil {
+		return "", databaseError(err, "Error creating user.")
+	}
+
 	return id, nil
 }
 
-func (user *User) Ban(
+func (model *User) Ban(
+	ctx context.Context,
 	id string,
 ) *types.AppError {
-	dbConnection, appErr := getDBInstance()
+	dbConnection := model.db
 
-	if appErr != nil {
-		return appErr
-	}
+	ctx, cancel := model.withTimeout(ctx)
 
-	statement, err := dbConnection.Prepare("UPDATE `users` SET `banned` = 1 WHERE `id` = ?")
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(ctx, "UPDATE `users` SET `banned` = TRUE WHERE `id` = ?")
 
 	if err != nil {
-		return &types.AppError{
-			StatusCode: 500,
-			Message:    "Failed to ban user.",
-		}
+		return databaseError(err, "Failed to ban user.")
 	}
 
 	defer statement.Close()
 
-	if _, err = statement.Exec(id); err != nil {
-		return &types.AppError{
-			StatusCode: 500,
-			Message:    "Error banning user.",
-		}
+	if _, err = statement.ExecContext(ctx, id); err != nil {
+		return databaseError(err, "Error banning user.")
 	}
 
 	return nil
 }
 
-func (user *User) Unban(
+func (model *User) Unban(
+	ctx context.Context,
 	id string,
 ) *types.AppError {
-	dbConnection, appErr := getDBInstance()
+	dbConnection := model.db
 
-	if appErr != nil {
-		return appErr
-	}
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
 
-	statement, err := dbConnection.Prepare("UPDATE `users` SET `banned` = 0 WHERE `id` = ?")
+	statement, err := dbConnection.PrepareContext(ctx, "UPDATE `users` SET `banned` = FALSE WHERE `id` = ?")
 
 	if err != nil {
-		return &types.AppError{
-			StatusCode: 500,
-			Message:    "Failed to unban user.",
-		}
+		return databaseError(err, "Failed to unban user.")
 	}
 
 	defer statement.Close()
 
-	if _, err = statement.Exec(id); err != nil {
-		return &types.AppError{
-			StatusCode: 500,
-			Message:    "Error unbanning user.",
-		}
+	if _, err = statement.ExecContext(ctx, id); err != nil {
+		return databaseError(err, "Error unbanning user.")
 	}
 
 	return nil
 }
 
-func (*User) CheckEmailAvailability(
+func (model *User) CheckEmailAvailability(
+	ctx context.Context,
 	email string,
 ) (
 	bool,
 	*types.AppError,
 ) {
-	dbConnection, appErr := getDBInstance()
+	email = utils.NormalizeEmail(email)
 
-	if appErr != nil {
-		return false, appErr
-	}
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
 
-	statement, err := dbConnection.Prepare(
+	statement, err := dbConnection.PrepareContext(
+		ctx,
 		"SELECT `id` FROM `users` WHERE `email` = ? LIMIT 1",
 	)
 
 	if err != nil {
-		return false, &types.AppError{
-			StatusCode: 500,
-			Message:    "Failed to check email availability.",
-		}
+		return false, databaseError(err, "Failed to check email availability.")
 	}
 
 	defer statement.Close()
 
 	userId := ""
 
-	err = statement.QueryRow(
+	err = statement.QueryRowContext(
+		ctx,
 		email,
 	).Scan(
 		&userId,
@@ -357,10 +354,7 @@ func (*User) CheckEmailAvailability(
 	}
 
 	if err != nil {
-		return false, &types.AppError{
-			StatusCode: 500,
-			Message:    "Error checking email availability.",
-		}
+		return false, databaseError(err, "Error checking email availability.")
 	}
 
 	return false, nil
@@ -0,0 +1,365 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/sandromai/go-http-server/types"
+	"github.com/sandromai/go-http-server/utils"
+)
+
+type UserCredential struct {
+	connection
+}
+
+const userCredentialColumns = "`id`, `user_id`, `credential_id`, `public_key`, `sign_count`, `name`, `last_used_at`, `created_at`"
+
+func scanUserCredential(
+	row interface{ Scan(...any) error },
+) (*types.UserCredential, error) {
+	userCredential := &types.UserCredential{}
+
+	err := row.Scan(
+		&userCredential.Id,
+		&userCredential.UserId,
+		&userCredential.CredentialId,
+		&userCredential.PublicKey,
+		&userCredential.SignCount,
+		&userCredential.Name,
+		scanNullTimestamp(&userCredential.LastUsedAt),
+		scanTimestamp(&userCredential.CreatedAt),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return userCredential, nil
+}
+
+func (model *UserCredential) checkIdAvailability(
+	ctx context.Context,
+	id string,
+) (bool, *types.AppError) {
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"SELECT `id` FROM `user_credentials` WHERE `id` = ? LIMIT 1",
+	)
+
+	if err != nil {
+		return false, databaseError(err, "Failed to check ID availability.")
+	}
+
+	defer statement.Close()
+
+	userCredentialId := ""
+
+	err = statement.QueryRowContext(ctx, id).Scan(&userCredentialId)
+
+	if err == sql.ErrNoRows {
+		return true, nil
+	}
+
+	if err != nil {
+		return false, databaseError(err, "Error checking ID availability.")
+	}
+
+	return false, nil
+}
+
+func (model *UserCredential) generateId(
+	ctx context.Context,
+) (
+	string,
+	*types.AppError,
+) {
+	id, appErr := utils.GenerateUUIDv4()
+
+	if appErr != nil {
+		return "", appErr
+	}
+
+	idAvailability, appErr := model.checkIdAvailability(
+		ctx,
+		id,
+	)
+
+	if appErr != nil {
+		return "", appErr
+	}
+
+	for i := 0; i < 20 && !idAvailability; i++ {
+		id, appErr = utils.GenerateUUIDv4()
+
+		if appErr != nil {
+			return "", appErr
+		}
+
+		idAvailability, appErr = model.checkIdAvailability(
+			ctx,
+			id,
+		)
+
+		if appErr != nil {
+			return "", appErr
+		}
+	}
+
+	if !idAvailability {
+		return "", &types.AppError{
+			StatusCode: 500,
+			Message:    "Failed to generate ID.",
+		}
+	}
+
+	return id, nil
+}
+
+func (model *UserCredential) ListByUser(
+	ctx context.Context,
+	userId string,
+) (
+	[]*types.UserCredential,
+	*types.AppError,
+) {
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"SELECT "+userCredentialColumns+" FROM `user_credentials` WHERE `user_id` = ? ORDER BY `created_at` DESC",
+	)
+
+	if err != nil {
+		return nil, databaseError(err, "Failed to list credentials.")
+	}
+
+	defer statement.Close()
+
+	rows, err := statement.QueryContext(ctx, userId)
+
+	if err != nil {
+		return nil, databaseError(err, "Error listing credentials.")
+	}
+
+	defer rows.Close()
+
+	userCredentials := []*types.UserCredential{}
+
+	for rows.Next() {
+		userCredential, err := scanUserCredential(rows)
+
+		if err != nil {
+			return nil, databaseError(err, "Error reading credential.")
+		}
+
+		userCredentials = append(userCredentials, userCredential)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, databaseError(err, "Error listing credentials.")
+	}
+
+	return userCredentials, nil
+}
+
+func (model *UserCredential) find(
+	ctx context.Context,
+	column,
+	value string,
+) (
+	*types.UserCredential,
+	*types.AppError,
+) {
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"SELECT "+userCredentialColumns+" FROM `user_credentials` WHERE `"+column+"` = ? LIMIT 1",
+	)
+
+	if err != nil {
+		return nil, databaseError(err, "Failed to find credential.")
+	}
+
+	defer statement.Close()
+
+	userCredential, err := scanUserCredential(statement.QueryRowContext(ctx, value))
+
+	if err == sql.ErrNoRows {
+		return nil, &types.AppError{
+			StatusCode: 404,
+			Message:    "Credential not found.",
+		}
+	}
+
+	if err != nil {
+		return nil, databaseError(err, "Error searching credential.")
+	}
+
+	return userCredential, nil
+}
+
+func (model *UserCredential) FindById(
+	ctx context.Context,
+	id string,
+) (
+	*types.UserCredential,
+	*types.AppError,
+) {
+	return model.find(ctx, "id", id)
+}
+
+func (model *UserCredential) FindByCredentialId(
+	ctx context.Context,
+	credentialId string,
+) (
+	*types.UserCredential,
+	*types.AppError,
+) {
+	return model.find(ctx, "credential_id", credentialId)
+}
+
+func (model *UserCredential) Create(
+	ctx context.Context,
+	userId,
+	credentialId,
+	publicKey string,
+	signCount uint32,
+	name string,
+) (
+	id string,
+	appErr *types.AppError,
+) {
+	_, appErr = model.FindByCredentialId(ctx, credentialId)
+
+	if appErr == nil {
+		return "", &types.AppError{
+			StatusCode: 409,
+			Message:    "Credential already registered.",
+		}
+	}
+
+	if appErr.StatusCode != 404 {
+		return "", appErr
+	}
+
+	id, appErr = model.generateId(ctx)
+
+	if appErr != nil {
+		return "", appErr
+	}
+
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"INSERT INTO `user_credentials` (`id`, `user_id`, `credential_id`, `public_key`, `sign_count`, `name`, `created_at`) VALUES(?, ?, ?, ?, ?, ?, ?)",
+	)
+
+	if err != nil {
+		return "", databaseError(err, "Failed to create credential.")
+	}
+
+	defer statement.Close()
+
+	_, err = statement.ExecContext(
+		ctx,
+		id,
+		userId,
+		credentialId,
+		publicKey,
+		signCount,
+		name,
+		model.timestamp(model.now()),
+	)
+
+	if err != nil {
+		return "", databaseError(err, "Error creating credential.")
+	}
+
+	return id, nil
+}
+
+func (model *UserCredential) UpdateSignCount(
+	ctx context.Context,
+	id string,
+	previousSignCount,
+	signCount uint32,
+) (bool, *types.AppError) {
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"UPDATE `user_credentials` SET `sign_count` = ?, `last_used_at` = ? WHERE `id` = ? AND `sign_count` = ?",
+	)
+
+	if err != nil {
+		return false, databaseError(err, "Failed to update credential.")
+	}
+
+	defer statement.Close()
+
+	result, err := statement.ExecContext(ctx, signCount, model.timestamp(model.now()), id, previousSignCount)
+
+	if err != nil {
+		return false, databaseError(err, "Error updating credential.")
+	}
+
+	affectedRows, err := result.RowsAffected()
+
+	if err != nil {
+		return false, databaseError(err, "Error updating credential.")
+	}
+
+	return affectedRows == 1, nil
+}
+
+func (model *UserCredential) Delete(
+	ctx context.Context,
+	id string,
+) *types.AppError {
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"DELETE FROM `user_credentials` WHERE `id` = ?",
+	)
+
+	if err != nil {
+		return databaseError(err, "Failed to delete credential.")
+	}
+
+	defer statement.Close()
+
+	if _, err = statement.ExecContext(ctx, id); err != nil {
+		return databaseError(err, "Error deleting credential.")
+	}
+
+	return nil
+}
@@ -0,0 +1,204 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/sandromai/go-http-server/types"
+	"github.com/sandromai/go-http-server/utils"
+)
+
+type UserIdentity struct {
+	connection
+}
+
+func (model *UserIdentity) checkIdAvailability(
+	ctx context.Context,
+	id string,
+) (bool, *types.AppError) {
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"SELECT `id` FROM `user_identities` WHERE `id` = ? LIMIT 1",
+	)
+
+	if err != nil {
+		return false, databaseError(err, "Failed to check ID availability.")
+	}
+
+	defer statement.Close()
+
+	userIdentityId := ""
+
+	err = statement.QueryRowContext(ctx, id).Scan(&userIdentityId)
+
+	if err == sql.ErrNoRows {
+		return true, nil
+	}
+
+	if err != nil {
+		return false, databaseError(err, "Error checking ID availability.")
+	}
+
+	return false, nil
+}
+
+func (model *UserIdentity) generateId(
+	ctx context.Context,
+) (
+	string,
+	*types.AppError,
+) {
+	id, appErr := utils.GenerateUUIDv4()
+
+	if appErr != nil {
+		return "", appErr
+	}
+
+	idAvailability, appErr := model.checkIdAvailability(
+		ctx,
+		id,
+	)
+
+	if appErr != nil {
+		return "", appErr
+	}
+
+	for i := 0; i < 20 && !idAvailability; i++ {
+		id, appErr = utils.GenerateUUIDv4()
+
+		if appErr != nil {
+			return "", appErr
+		}
+
+		idAvailability, appErr = model.checkIdAvailability(
+			ctx,
+			id,
+		)
+
+		if appErr != nil {
+			return "", appErr
+		}
+	}
+
+	if !idAvailability {
+		return "", &types.AppError{
+			StatusCode: 500,
+			Message:    "Failed to generate ID.",
+		}
+	}
+
+	return id, nil
+}
+
+func (model *UserIdentity) FindByProviderSubject(
+	ctx context.Context,
+	provider,
+	subject string,
+) (
+	*types.UserIdentity,
+	*types.AppError,
+) {
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"SELECT `id`, `user_id`, `provider`, `subject`, `email`, `created_at` FROM `user_identities` WHERE `provider` = ? AND `subject` = ? LIMIT 1",
+	)
+
+	if err != nil {
+		return nil, databaseError(err, "Failed to find identity.")
+	}
+
+	defer statement.Close()
+
+	userIdentity := &types.UserIdentity{}
+
+	err = statement.QueryRowContext(ctx, provider, subject).Scan(
+		&userIdentity.Id,
+		&userIdentity.UserId,
+		&userIdentity.Provider,
+		&userIdentity.Subject,
+		&userIdentity.Email,
+		scanTimestamp(&userIdentity.CreatedAt),
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, &types.AppError{
+			StatusCode: 404,
+			Message:    "Identity not found.",
+		}
+	}
+
+	if err != nil {
+		return nil, databaseError(err, "Error searching identity.")
+	}
+
+	return userIdentity, nil
+}
+
+func (model *UserIdentity) Create(
+	ctx context.Context,
+	userId,
+	provider,
+	subject,
+	email string,
+) (
+	id string,
+	appErr *types.AppError,
+) {
+	id, appErr = model.generateId(ctx)
+
+	if appErr != nil {
+		return "", appErr
+	}
+
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"INSERT INTO `user_identities` (`id`, `user_id`, `provider`, `subject`, `email`, `created_at`) VALUES(?, ?, ?, ?, ?, ?)",
+	)
+
+	if err != nil {
+		return "", databaseError(err, "Failed to create identity.")
+	}
+
+	defer statement.Close()
+
+	_, err = statement.ExecContext(
+		ctx,
+		id,
+		userId,
+		provider,
+		subject,
+		email,
+		model.timestamp(model.now()),
+	)
+
+	if model.dialect.IsDuplicateEntry(err) {
+		return "", &types.AppError{
+			StatusCode: 409,
+			Message:    "Identity already linked.",
+		}
+	}
+
+	if err != nil {
+		return "", databaseError(err, "Error creating identity.")
+	}
+
+	return id, nil
+}
@@ -1,95 +1,92 @@
 package models
 
 import (
+	"context"
 	"database/sql"
 
 	"github.com/sandromai/go-http-server/types"
 	"github.com/sandromai/go-http-server/utils"
 )
 
-type UserToken struct{}
+type UserToken struct {
+	connection
+}
 
-func (*UserToken) checkLoginTokenAvailability(
+func (model *UserToken) checkLoginTokenAvailability(
+	ctx context.Context,
 	loginTokenId string,
 ) (bool, *types.AppError) {
-	dbConnection, appErr := getDBInstance()
+	dbConnection := model.db
 
-	if appErr != nil {
-		return false, appErr
-	}
+	ctx, cancel := model.withTimeout(ctx)
 
-	statement, err := dbConnection.Prepare(
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
 		"SELECT `id` FROM `user_tokens` WHERE `from_login_token` = ? LIMIT 1",
 	)
 
 	if err != nil {
-		return false, &types.AppError{
-			StatusCode: 500,
-			Message:    "Failed to check login token availability.",
-		}
+		return false, databaseError(err, "Failed to check login token availability.")
 	}
 
 	defer statement.Close()
 
 	userTokenId := ""
 
-	err = statement.QueryRow(loginTokenId).Scan(&userTokenId)
+	err = statement.QueryRowContext(ctx, loginTokenId).Scan(&userTokenId)
 
 	if err == sql.ErrNoRows {
 		return true, nil
 	}
 
 	if err != nil {
-		return false, &types.AppError{
-			StatusCode: 500,
-			Message:    "Error checking login token availability.",
-		}
+		return false, databaseError(err, "Error checking login token availability.")
 	}
 
 	return false, nil
 }
 
-func (*UserToken) checkIdAvailability(
+func (model *UserToken) checkIdAvailability(
+	ctx context.Context,
 	id string,
 ) (bool, *types.AppError) {
-	dbConnection, appErr := getDBInstance()
+	dbConnection := model.db
 
-	if appErr != nil {
-		return false, appErr
-	}
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
 
-	statement, err := dbConnection.Prepare(
+	statement, err := dbConnection.PrepareContext(
+		ctx,
 		"SELECT `id` FROM `user_tokens` WHERE `id` = ? LIMIT 1",
 	)
 
 	if err != nil {
-		return false, &types.AppError{
-			StatusCode: 500,
-			Message:    "Failed to check ID availability.",
-		}
+		return false, databaseError(err, "Failed to check ID availability.")
 	}
 
 	defer statement.Close()
 
 	userTokenId := ""
 
-	err = statement.QueryRow(id).Scan(&userTokenId)
+	err = statement.QueryRowContext(ctx, id).Scan(&userTokenId)
 
 	if err == sql.ErrNoRows {
 		return true, nil
 	}
 
 	if err != nil {
-		return false, &types.AppError{
-			StatusCode: 500,
-			Message:    "Error checking ID availability.",
-		}
+		return false, databaseError(err, "Error checking ID availability.")
 	}
 
 	return false, nil
 }
 
-func (userToken *UserToken) generateId() (
+func (model *UserToken) generateId(
+	ctx context.Context,
+) (
 	string,
 	*types.AppError,
 ) {
@@ -99,7 +96,8 @@ func (userToken *UserToken) generateId() (
 		return "", appErr
 	}
 
-	idAvailability, appErr := userToken.checkIdAvailability(
+	idAvailability, appErr := model.checkIdAvailability(
+		ctx,
 		id,
 	)
 
@@ -114,7 +112,8 @@ func (userToken *UserToken) generateId() (
 			return "", appErr
 		}
 
-		idAvailability, appErr = userToken.checkIdAvailability(
+		idAvailability, appErr = model.checkIdAvailability(
+			ctx,
 			id,
 		)
 
@@ -133,44 +132,48 @@ func (userToken *UserToken) generateId() (
 	return id, nil
 }
 
-func (*UserToken) FindById(
-	id string,
+func (model *UserToken) find(
+	ctx context.Context,
+	column,
+	value string,
 ) (
 	*types.UserToken,
 	*types.AppError,
 ) {
-	dbConnection, appErr := getDBInstance()
+	dbConnection := model.db
 
-	if appErr != nil {
-		return nil, appErr
-	}
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
 
-	statement, err := dbConnection.Prepare(
-		"SELECT `id`, `user_id`, `from_login_token`, `from_user_token`, `ip_address`, `device`, `disconnected`, `last_activity`, `expires_at`, `created_at` FROM `user_tokens` WHERE `id` = ? LIMIT 1",
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"SELECT `id`, `user_id`, `from_login_token`, `from_user_token`, `from_credential`, `from_identity`, `client_id`, `scope`, `ip_address`, `device`, `disconnected`, `last_activity`, `expires_at`, `created_at` FROM `user_tokens` WHERE `"+column+"` = ? LIMIT 1",
 	)
 
 	if err != nil {
-		return nil, &types.AppError{
-			StatusCode: 500,
-			Message:    "Failed to find user token.",
-		}
+		return nil, databaseError(err, "Failed to find user token.")
 	}
 
 	defer statement.Close()
 
 	userToken := &types.UserToken{}
 
-	err = statement.QueryRow(id).Scan(
+	err = statement.QueryRowContext(ctx, value).Scan(
 		&userToken.Id,
 		&userToken.UserId,
 		&userToken.FromLoginToken,
 		&userToken.FromUserToken,
+		&userToken.FromCredential,
+		&userToken.FromIdentity,
+		&userToken.ClientId,
+		&userToken.Scope,
 		&userToken.IPAddress,
 		&userToken.Device,
 		&userToken.Disconnected,
-		&userToken.LastActivity,
-		&userToken.ExpiresAt,
-		&userToken.CreatedAt,
+		scanTimestamp(&userToken.LastActivity),
+		scanTimestamp(&userToken.ExpiresAt),
+		scanTimestamp(&userToken.CreatedAt),
 	)
 
 	if err == sql.ErrNoRows {
@@ -181,19 +184,39 @@ func (*UserToken) FindById(
 	}
 
 	if err != nil {
-		return nil, &types.AppError{
-			StatusCode: 500,
-			Message:    "Error searching user token.",
-		}
+		return nil, databaseError(err, "Error searching user token.")
 	}
 
 	return userToken, nil
 }
 
-func (userToken *UserToken) Create(
+func (model *UserToken) FindById(
+	ctx context.Context,
+	id string,
+) (
+	*types.UserToken,
+	*types.AppError,
+) {
+	return model.find(ctx, "id", id)
+}
+
+func (model *UserToken) FindByRefreshTokenHash(
+	ctx context.Context,
+	refreshTokenHash string,
+) (
+	*types.UserToken,
+	*types.AppError,
+) {
+	return model.find(ctx, "refresh_token_hash", refreshTokenHash)
+}
+
+func (model *UserToken) Create(
+	ctx context.Context,
 	userId string,
 	fromLoginToken,
-	fromUserToken *string,
+	fromUserToken,
+	fromCredential,
+	fromIdentity *string,
 	ipAddress,
 	device string,
 	expiresIn int64,
@@ -201,15 +224,16 @@ func (userToken *UserToken) Create(
 	id string,
 	appErr *types.AppError,
 ) {
-	if fromLoginToken == nil && fromUserToken == nil {
+	if fromLoginToken == nil && fromUserToken == nil && fromCredential == nil && fromIdentity == nil {
 		return "", &types.AppError{
 			StatusCode: 400,
-			Message:    "No login or user token provided.",
+			Message:    "No login token, user token, credential or identity provided.",
 		}
 	}
 
 	if fromLoginToken != nil {
-		loginTokenAvailable, appErr := userToken.checkLoginTokenAvailability(
+		loginTokenAvailable, appErr := model.checkLoginTokenAvailability(
+			ctx,
 			*fromLoginToken,
 		)
 
@@ -225,110 +249,237 @@ func (userToken *UserToken) Create(
 		}
 	}
 
-	id, appErr = userToken.generateId()
+	id, appErr = model.generateId(ctx)
 
 	if appErr != nil {
 		return "", appErr
 	}
 
-	dbConnection, appErr := getDBInstance()
+	dbConnection := model.db
 
-	if appErr != nil {
-		return "", appErr
-	}
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
 
-	statement, err := dbConnection.Prepare(
-		"INSERT INTO `user_tokens` (`id`, `user_id`, `from_login_token`, `from_user_token`, `ip_address`, `device`, `expires_at`) VALUES(?, ?, ?, ?, ?, ?, DATE_ADD(NOW(), INTERVAL ? SECOND))",
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"INSERT INTO `user_tokens` (`id`, `user_id`, `from_login_token`, `from_user_token`, `from_credential`, `from_identity`, `ip_address`, `device`, `last_activity`, `expires_at`, `created_at`) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
 	)
 
 	if err != nil {
-		return "", &types.AppError{
-			StatusCode: 500,
-			Message:    "Failed to create user token.",
-		}
+		return "", databaseError(err, "Failed to create user token.")
 	}
 
 	defer statement.Close()
 
-	_, err = statement.Exec(
+	now := model.now()
+
+	_, err = statement.ExecContext(
+		ctx,
 		id,
 		userId,
 		fromLoginToken,
 		fromUserToken,
+		fromCredential,
+		fromIdentity,
 		ipAddress,
 		device,
-		expiresIn,
+		model.timestamp(now),
+		model.expiresAt(now, expiresIn),
+		model.timestamp(now),
 	)
 
-	if err != nil {
+	if fromLoginToken != nil && model.dialect.IsDuplicateEntry(err) {
 		return "", &types.AppError{
-			StatusCode: 500,
-			Message:    "Error creating user token.",
+			StatusCode: 400,
+			Message:    "Login token already used.",
 		}
 	}
 
+	if err != nil {
+		return "", databaseError(err, "Error creating user token.")
+	}
+
 	return id, nil
 }
 
-func (*UserToken) UpdateActivity(
+func (model *UserToken) UpdateActivity(
+	ctx context.Context,
 	id string,
 ) *types.AppError {
-	dbConnection, appErr := getDBInstance()
+	dbConnection := model.db
 
-	if appErr != nil {
-		return appErr
-	}
+	ctx, cancel := model.withTimeout(ctx)
 
-	statement, err := dbConnection.Prepare(
-		"UPDATE `user_tokens` SET `last_activity` = NOW() WHERE `id` = ?",
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"UPDATE `user_tokens` SET `last_activity` = ? WHERE `id` = ?",
 	)
 
 	if err != nil {
-		return &types.AppError{
-			StatusCode: 500,
-			Message:    "Failed to update user token activity.",
-		}
+		return databaseError(err, "Failed to update user token activity.")
 	}
 
 	defer statement.Close()
 
-	if _, err = statement.Exec(id); err != nil {
-		return &types.AppError{
-			StatusCode: 500,
-			Message:    "Error updating user token activity.",
-		}
+	if _, err = statement.ExecContext(ctx, model.timestamp(model.now()), id); err != nil {
+		return databaseError(err, "Error updating user token activity.")
 	}
 
 	return nil
 }
 
-func (*UserToken) Disconnect(
+func (model *UserToken) Disconnect(
+	ctx context.Context,
 	id string,
 ) *types.AppError {
-	dbConnection, appErr := getDBInstance()
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"UPDATE `user_tokens` SET `disconnected` = TRUE WHERE `id` = ?",
+	)
+
+	if err != nil {
+		return databaseError(err, "Failed to disconnect user token.")
+	}
+
+	defer statement.Close()
+
+	if _, err = statement.ExecContext(ctx, id); err != nil {
+		return databaseError(err, "Error disconnecting user token.")
+	}
+
+	return nil
+}
+
+func (model *UserToken) CreateForClient(
+	ctx context.Context,
+	userId,
+	clientId,
+	scope string,
+	refreshTokenHash,
+	fromUserToken *string,
+	ipAddress,
+	device string,
+	expiresIn int64,
+) (
+	id string,
+	appErr *types.AppError,
+) {
+	id, appErr = model.generateId(ctx)
 
 	if appErr != nil {
-		return appErr
+		return "", appErr
 	}
 
-	statement, err := dbConnection.Prepare(
-		"UPDATE `user_tokens` SET `disconnected` = 1 WHERE `id` = ?",
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"INSERT INTO `user_tokens` (`id`, `user_id`, `from_user_token`, `client_id`, `scope`, `refresh_token_hash`, `ip_address`, `device`, `last_activity`, `expires_at`, `created_at`) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
 	)
 
 	if err != nil {
-		return &types.AppError{
-			StatusCode: 500,
-			Message:    "Failed to disconnect user token.",
-		}
+		return "", databaseError(err, "Failed to create user token.")
 	}
 
 	defer statement.Close()
 
-	if _, err = statement.Exec(id); err != nil {
-		return &types.AppError{
-			StatusCode: 500,
-			Message:    "Error disconnecting user token.",
-		}
+	now := model.now()
+
+	_, err = statement.ExecContext(
+		ctx,
+		id,
+		userId,
+		fromUserToken,
+		clientId,
+		scope,
+		refreshTokenHash,
+		ipAddress,
+		device,
+		model.timestamp(now),
+		model.expiresAt(now, expiresIn),
+		model.timestamp(now),
+	)
+
+	if err != nil {
+		return "", databaseError(err, "Error creating user token.")
+	}
+
+	return id, nil
+}
+
+func (model *UserToken) DisconnectActive(
+	ctx context.Context,
+	id string,
+) (bool, *types.AppError) {
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"UPDATE `user_tokens` SET `disconnected` = TRUE WHERE `id` = ? AND `disconnected` = FALSE",
+	)
+
+	if err != nil {
+		return false, databaseError(err, "Failed to disconnect user token.")
+	}
+
+	defer statement.Close()
+
+	result, err := statement.ExecContext(ctx, id)
+
+	if err != nil {
+		return false, databaseError(err, "Error disconnecting user token.")
+	}
+
+	affectedRows, err := result.RowsAffected()
+
+	if err != nil {
+		return false, databaseError(err, "Error disconnecting user token.")
+	}
+
+	return affectedRows == 1, nil
+}
+
+func (model *UserToken) DisconnectAllByUserClient(
+	ctx context.Context,
+	userId,
+	clientId string,
+) *types.AppError {
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"UPDATE `user_tokens` SET `disconnected` = TRUE WHERE `user_id` = ? AND `client_id` = ?",
+	)
+
+	if err != nil {
+		return databaseError(err, "Failed to disconnect user tokens.")
+	}
+
+	defer statement.Close()
+
+	if _, err = statement.ExecContext(ctx, userId, clientId); err != nil {
+		return databaseError(err, "Error disconnecting user tokens.")
 	}
 
 	return nil
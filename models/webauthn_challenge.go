@@ -0,0 +1,229 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/sandromai/go-http-server/types"
+	"github.com/sandromai/go-http-server/utils"
+)
+
+type WebAuthnChallenge struct {
+	connection
+}
+
+func (model *WebAuthnChallenge) checkIdAvailability(
+	ctx context.Context,
+	id string,
+) (bool, *types.AppError) {
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"SELECT `id` FROM `webauthn_challenges` WHERE `id` = ? LIMIT 1",
+	)
+
+	if err != nil {
+		return false, databaseError(err, "Failed to check ID availability.")
+	}
+
+	defer statement.Close()
+
+	challengeId := ""
+
+	err = statement.QueryRowContext(ctx, id).Scan(&challengeId)
+
+	if err == sql.ErrNoRows {
+		return true, nil
+	}
+
+	if err != nil {
+		return false, databaseError(err, "Error checking ID availability.")
+	}
+
+	return false, nil
+}
+
+func (model *WebAuthnChallenge) generateId(
+	ctx context.Context,
+) (
+	string,
+	*types.AppError,
+) {
+	id, appErr := utils.GenerateUUIDv4()
+
+	if appErr != nil {
+		return "", appErr
+	}
+
+	idAvailability, appErr := model.checkIdAvailability(
+		ctx,
+		id,
+	)
+
+	if appErr != nil {
+		return "", appErr
+	}
+
+	for i := 0; i < 20 && !idAvailability; i++ {
+		id, appErr = utils.GenerateUUIDv4()
+
+		if appErr != nil {
+			return "", appErr
+		}
+
+		idAvailability, appErr = model.checkIdAvailability(
+			ctx,
+			id,
+		)
+
+		if appErr != nil {
+			return "", appErr
+		}
+	}
+
+	if !idAvailability {
+		return "", &types.AppError{
+			StatusCode: 500,
+			Message:    "Failed to generate ID.",
+		}
+	}
+
+	return id, nil
+}
+
+func (model *WebAuthnChallenge) Create(
+	ctx context.Context,
+	userId *string,
+	challengeType,
+	challenge string,
+	expiresIn int64,
+) (
+	id string,
+	appErr *types.AppError,
+) {
+	id, appErr = model.generateId(ctx)
+
+	if appErr != nil {
+		return "", appErr
+	}
+
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"INSERT INTO `webauthn_challenges` (`id`, `user_id`, `type`, `challenge`, `expires_at`, `created_at`) VALUES(?, ?, ?, ?, ?, ?)",
+	)
+
+	if err != nil {
+		return "", databaseError(err, "Failed to create challenge.")
+	}
+
+	defer statement.Close()
+
+	now := model.now()
+
+	_, err = statement.ExecContext(
+		ctx,
+		id,
+		userId,
+		challengeType,
+		challenge,
+		model.expiresAt(now, expiresIn),
+		model.timestamp(now),
+	)
+
+	if err != nil {
+		return "", databaseError(err, "Error creating challenge.")
+	}
+
+	return id, nil
+}
+
+func (model *WebAuthnChallenge) Consume(
+	ctx context.Context,
+	id,
+	challengeType string,
+) (
+	*types.WebAuthnChallenge,
+	*types.AppError,
+) {
+	dbConnection := model.db
+
+	ctx, cancel := model.withTimeout(ctx)
+
+	defer cancel()
+
+	statement, err := dbConnection.PrepareContext(
+		ctx,
+		"SELECT `id`, `user_id`, `type`, `challenge`, `expires_at`, `created_at` FROM `webauthn_challenges` WHERE `id` = ? AND `type` = ? AND `expires_at` > ? LIMIT 1",
+	)
+
+	if err != nil {
+		return nil, databaseError(err, "Failed to find challenge.")
+	}
+
+	defer statement.Close()
+
+	webAuthnChallenge := &types.WebAuthnChallenge{}
+
+	err = statement.QueryRowContext(ctx, id, challengeType, model.timestamp(model.now())).Scan(
+		&webAuthnChallenge.Id,
+		&webAuthnChallenge.UserId,
+		&webAuthnChallenge.Type,
+		&webAuthnChallenge.Challenge,
+		scanTimestamp(&webAuthnChallenge.ExpiresAt),
+		scanTimestamp(&webAuthnChallenge.CreatedAt),
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, &types.AppError{
+			StatusCode: 400,
+			Message:    "Invalid or expired challenge.",
+		}
+	}
+
+	if err != nil {
+		return nil, databaseError(err, "Error searching challenge.")
+	}
+
+	deleteStatement, err := dbConnection.PrepareContext(
+		ctx,
+		"DELETE FROM `webauthn_challenges` WHERE `id` = ?",
+	)
+
+	if err != nil {
+		return nil, databaseError(err, "Failed to consume challenge.")
+	}
+
+	defer deleteStatement.Close()
+
+	result, err := deleteStatement.ExecContext(ctx, id)
+
+	if err != nil {
+		return nil, databaseError(err, "Error consuming challenge.")
+	}
+
+	affectedRows, err := result.RowsAffected()
+
+	if err != nil {
+		return nil, databaseError(err, "Error consuming challenge.")
+	}
+
+	if affectedRows != 1 {
+		return nil, &types.AppError{
+			StatusCode: 400,
+			Message:    "Invalid or expired challenge.",
+		}
+	}
+
+	return webAuthnChallenge, nil
+}
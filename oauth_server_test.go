@@ -0,0 +1,807 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sandromai/go-http-server/types"
+)
+
+const testRedirectURI = "https://client.example.com/callback"
+
+type oauthTokens struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+	Scope        string `json:"scope"`
+}
+
+type oauthIntrospection struct {
+	Active   bool   `json:"active"`
+	Scope    string `json:"scope"`
+	ClientId string `json:"client_id"`
+	Subject  string `json:"sub"`
+	Username string `json:"username"`
+}
+
+func codeChallenge(
+	verifier string,
+) string {
+	sum := sha256.Sum256([]byte(verifier))
+
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func verifier(
+	name string,
+) string {
+	return name + strings.Repeat("-", 64-len(name))
+}
+
+func basicAuth(
+	clientId,
+	clientSecret string,
+) http.Header {
+	request := &http.Request{Header: http.Header{}}
+
+	request.SetBasicAuth(url.QueryEscape(clientId), url.QueryEscape(clientSecret))
+
+	return request.Header
+}
+
+func (response *testResponse) expectOAuthError(
+	t *testing.T,
+	statusCode int,
+	code string,
+) {
+	t.Helper()
+
+	if response.StatusCode != statusCode {
+		t.Fatalf("got status %v with body %s, expected %v", response.StatusCode, response.Body, statusCode)
+	}
+
+	var oauthErr struct {
+		Error string `json:"error"`
+	}
+
+	response.decode(t, &oauthErr)
+
+	if oauthErr.Error != code {
+		t.Fatalf("got error %q, expected %q", oauthErr.Error, code)
+	}
+}
+
+func (server *testServer) postForm(
+	path string,
+	values url.Values,
+	header http.Header,
+) *testResponse {
+	server.t.Helper()
+
+	request, err := http.NewRequest("POST", server.server.URL+path, strings.NewReader(values.Encode()))
+
+	if err != nil {
+		server.t.Fatal(err)
+	}
+
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	for key, values := range header {
+		request.Header[key] = values
+	}
+
+	return server.send(request)
+}
+
+func (server *testServer) createOAuthClient(
+	adminToken string,
+	body map[string]any,
+) (*types.OAuthClient, string) {
+	server.t.Helper()
+
+	response := server.request("POST", "/routes/oauthClients/", body, bearer(adminToken))
+
+	response.expect(server.t, 201, "")
+
+	var result struct {
+		Client       *types.OAuthClient `json:"client"`
+		ClientSecret string             `json:"clientSecret"`
+	}
+
+	response.decode(server.t, &result)
+
+	if result.Client == nil || result.Client.Id == "" {
+		server.t.Fatalf("expected a client in %s", response.Body)
+	}
+
+	return result.Client, result.ClientSecret
+}
+
+func (server *testServer) authorizeOAuth(
+	userToken,
+	clientId,
+	scope,
+	codeVerifier string,
+) string {
+	server.t.Helper()
+
+	response := server.request("POST", "/routes/oauth2/authorize", map[string]any{
+		"response_type":         "code",
+		"client_id":             clientId,
+		"redirect_uri":          testRedirectURI,
+		"scope":                 scope,
+		"state":                 "xyz",
+		"code_challenge":        codeChallenge(codeVerifier),
+		"code_challenge_method": "S256",
+		"approved":              true,
+	}, bearer(userToken))
+
+	response.expect(server.t, 200, "")
+
+	var result struct {
+		RedirectURL string `json:"redirectUrl"`
+	}
+
+	response.decode(server.t, &result)
+
+	redirectURL, err := url.Parse(result.RedirectURL)
+
+	if err != nil {
+		server.t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(result.RedirectURL, testRedirectURI+"?") || redirectURL.Query().Get("state") != "xyz" || redirectURL.Query().Get("code") == "" {
+		server.t.Fatalf("unexpected redirect %q", result.RedirectURL)
+	}
+
+	return redirectURL.Query().Get("code")
+}
+
+func (server *testServer) exchangeCode(
+	clientId,
+	code,
+	codeVerifier string,
+) *testResponse {
+	server.t.Helper()
+
+	return server.postForm("/routes/oauth2/token", url.Values{
+		"grant_type":    {"authorization_code"},
+		"client_id":     {clientId},
+		"code":          {code},
+		"redirect_uri":  {testRedirectURI},
+		"code_verifier": {codeVerifier},
+	}, nil)
+}
+
+func (server *testServer) oauthTokens(
+	response *testResponse,
+) *oauthTokens {
+	server.t.Helper()
+
+	response.expect(server.t, 200, "")
+
+	tokens := &oauthTokens{}
+
+	response.decode(server.t, tokens)
+
+	if tokens.AccessToken == "" || tokens.TokenType != "Bearer" {
+		server.t.Fatalf("unexpected token response %s", response.Body)
+	}
+
+	return tokens
+}
+
+func (server *testServer) refreshOAuth(
+	clientId,
+	refreshToken string,
+) *testResponse {
+	server.t.Helper()
+
+	return server.postForm("/routes/oauth2/token", url.Values{
+		"grant_type":    {"refresh_token"},
+		"client_id":     {clientId},
+		"refresh_token": {refreshToken},
+	}, nil)
+}
+
+func (server *testServer) introspect(
+	clientId,
+	clientSecret string,
+	values url.Values,
+) *oauthIntrospection {
+	server.t.Helper()
+
+	response := server.postForm("/routes/oauth2/introspect", values, basicAuth(clientId, clientSecret))
+
+	response.expect(server.t, 200, "")
+
+	introspection := &oauthIntrospection{}
+
+	response.decode(server.t, introspection)
+
+	return introspection
+}
+
+func publicClient(
+	firstParty bool,
+) map[string]any {
+	return map[string]any{
+		"name":         "Public client",
+		"redirectUris": []string{testRedirectURI},
+		"scopes":       []string{"profile", "email"},
+		"firstParty":   firstParty,
+	}
+}
+
+func TestOAuthClients(t *testing.T) {
+	forEachBackend(t, testOAuthClients)
+}
+
+func testOAuthClients(
+	t *testing.T,
+	server *testServer,
+) {
+	adminToken := server.loginAdmin(testAdminUsername, testAdminPassword)
+
+	server.request("GET", "/routes/oauthClients/", nil, nil).expect(t, 401, "No authorization provided.")
+
+	invalidClients := []struct {
+		body    map[string]any
+		message string
+	}{
+		{map[string]any{"name": " "}, "Insert the client name."},
+		{map[string]any{"name": "Client", "grantTypes": []string{"implicit"}}, "Invalid grant type: implicit."},
+		{map[string]any{"name": "Client", "grantTypes": []string{"client_credentials"}}, "Only confidential clients may use client credentials."},
+		{map[string]any{"name": "Client", "scopes": []string{"admin"}}, "Invalid scope: admin."},
+		{map[string]any{"name": "Client", "redirectUris": []string{"http://client.example.com/callback"}}, "Invalid redirect URI: http://client.example.com/callback."},
+		{map[string]any{"name": "Client", "redirectUris": []string{"javascript:alert(1)"}}, "Invalid redirect URI: javascript:alert(1)."},
+		{map[string]any{"name": "Client"}, "Insert at least one redirect URI."},
+	}
+
+	for _, invalidClient := range invalidClients {
+		server.request("POST", "/routes/oauthClients/", invalidClient.body, bearer(adminToken)).expect(t, 400, invalidClient.message)
+	}
+
+	public, secret := server.createOAuthClient(adminToken, publicClient(false))
+
+	if secret != "" || public.Confidential || len(public.GrantTypes) != 2 {
+		t.Fatalf("unexpected public client %+v with secret %q", public, secret)
+	}
+
+	confidential, secret := server.createOAuthClient(adminToken, map[string]any{
+		"name":         "Service",
+		"scopes":       []string{"profile"},
+		"grantTypes":   []string{"client_credentials"},
+		"confidential": true,
+	})
+
+	if secret == "" || !confidential.Confidential {
+		t.Fatalf("expected a confidential client with a secret, got %+v", confidential)
+	}
+
+	response := server.request("GET", "/routes/oauthClients/", nil, bearer(adminToken))
+
+	response.expect(t, 200, "")
+
+	var clients []*types.OAuthClient
+
+	response.decode(t, &clients)
+
+	if len(clients) != 2 {
+		t.Fatalf("expected two clients, got %s", response.Body)
+	}
+
+	if strings.Contains(string(response.Body), secret) {
+		t.Fatalf("expected client secrets to stay out of the list, got %s", response.Body)
+	}
+
+	server.request("DELETE", "/routes/oauthClients/"+confidential.Id, nil, bearer(adminToken)).expect(t, 200, "")
+
+	server.request("DELETE", "/routes/oauthClients/"+confidential.Id, nil, bearer(adminToken)).expect(t, 404, "Client not found.")
+
+	server.postForm("/routes/oauth2/token", url.Values{
+		"grant_type": {"client_credentials"},
+	}, basicAuth(confidential.Id, secret)).expectOAuthError(t, 401, "invalid_client")
+}
+
+func TestOAuthAuthorizationCodeFlow(t *testing.T) {
+	forEachBackend(t, testOAuthAuthorizationCodeFlow)
+}
+
+func testOAuthAuthorizationCodeFlow(
+	t *testing.T,
+	server *testServer,
+) {
+	client, _ := server.createOAuthClient(server.loginAdmin(testAdminUsername, testAdminPassword), publicClient(true))
+
+	user := server.loginUser("user@example.com")
+
+	authorizationRequest := func(changes map[string]any) map[string]any {
+		body := map[string]any{
+			"response_type":         "code",
+			"client_id":             client.Id,
+			"redirect_uri":          testRedirectURI,
+			"code_challenge":        codeChallenge(verifier("request")),
+			"code_challenge_method": "S256",
+			"approved":              true,
+		}
+
+		for key, value := range changes {
+			body[key] = value
+		}
+
+		return body
+	}
+
+	invalidRequests := []struct {
+		changes map[string]any
+		code    string
+	}{
+		{map[string]any{"redirect_uri": "https://client.example.com/other"}, "invalid_request"},
+		{map[string]any{"client_id": "unknown"}, "invalid_client"},
+		{map[string]any{"response_type": "token"}, "unsupported_response_type"},
+		{map[string]any{"code_challenge": ""}, "invalid_request"},
+		{map[string]any{"code_challenge_method": "plain"}, "invalid_request"},
+		{map[string]any{"scope": "profile admin"}, "invalid_scope"},
+	}
+
+	for _, invalidRequest := range invalidRequests {
+		server.request("POST", "/routes/oauth2/authorize", authorizationRequest(invalidRequest.changes), bearer(user.Token)).expectOAuthError(t, 400, invalidRequest.code)
+	}
+
+	server.request("POST", "/routes/oauth2/authorize", authorizationRequest(nil), nil).expect(t, 401, "No authorization provided.")
+
+	code := server.authorizeOAuth(user.Token, client.Id, "", verifier("wrong"))
+
+	server.exchangeCode(client.Id, code, verifier("other")).expectOAuthError(t, 400, "invalid_grant")
+
+	server.exchangeCode(client.Id, code, verifier("wrong")).expectOAuthError(t, 400, "invalid_grant")
+
+	code = server.authorizeOAuth(user.Token, client.Id, "", verifier("missing"))
+
+	server.exchangeCode(client.Id, code, "").expectOAuthError(t, 400, "invalid_request")
+
+	server.postForm("/routes/oauth2/token", url.Values{
+		"grant_type":    {"authorization_code"},
+		"client_id":     {client.Id},
+		"code":          {code},
+		"redirect_uri":  {"https://client.example.com/other"},
+		"code_verifier": {verifier("missing")},
+	}, nil).expectOAuthError(t, 400, "invalid_grant")
+
+	code = server.authorizeOAuth(user.Token, client.Id, "", verifier("valid"))
+
+	tokens := server.oauthTokens(server.exchangeCode(client.Id, code, verifier("valid")))
+
+	if tokens.RefreshToken == "" || tokens.Scope != "profile email" || tokens.ExpiresIn != int64(server.config.Lifetimes.OAuthAccessToken.Seconds()) {
+		t.Fatalf("unexpected tokens %+v", tokens)
+	}
+
+	server.exchangeCode(client.Id, code, verifier("valid")).expectOAuthError(t, 400, "invalid_grant")
+
+	response := server.request("GET", "/routes/oauth2/userinfo", nil, bearer(tokens.AccessToken))
+
+	response.expect(t, 200, "")
+
+	var userInfo struct {
+		Subject string `json:"sub"`
+		Email   string `json:"email"`
+	}
+
+	response.decode(t, &userInfo)
+
+	if userInfo.Subject != user.User.Id || userInfo.Email != "user@example.com" {
+		t.Fatalf("unexpected userinfo %s", response.Body)
+	}
+
+	server.request("GET", "/routes/oauth2/userinfo", nil, bearer(user.Token)).expect(t, 401, "Invalid token.")
+
+	emailOnly := server.oauthTokens(server.exchangeCode(client.Id, server.authorizeOAuth(user.Token, client.Id, "email", verifier("email")), verifier("email")))
+
+	response = server.request("GET", "/routes/oauth2/userinfo", nil, bearer(emailOnly.AccessToken))
+
+	response.expect(t, 403, "Insufficient scope.")
+
+	if !strings.Contains(response.Header.Get("WWW-Authenticate"), `error="insufficient_scope"`) {
+		t.Fatalf("expected an insufficient_scope challenge, got %v", response.Header)
+	}
+
+	code = server.authorizeOAuth(user.Token, client.Id, "", verifier("expired"))
+
+	server.clock.Advance(server.config.Lifetimes.OAuthAuthorizationCode + time.Second)
+
+	server.exchangeCode(client.Id, code, verifier("expired")).expectOAuthError(t, 400, "invalid_grant")
+}
+
+func TestOAuthRefreshTokenRotation(t *testing.T) {
+	forEachBackend(t, testOAuthRefreshTokenRotation)
+}
+
+func testOAuthRefreshTokenRotation(
+	t *testing.T,
+	server *testServer,
+) {
+	adminToken := server.loginAdmin(testAdminUsername, testAdminPassword)
+
+	client, _ := server.createOAuthClient(adminToken, publicClient(true))
+	otherClient, _ := server.createOAuthClient(adminToken, publicClient(true))
+
+	user := server.loginUser("user@example.com")
+
+	login := func(clientId, name string) *oauthTokens {
+		return server.oauthTokens(server.exchangeCode(clientId, server.authorizeOAuth(user.Token, clientId, "profile", verifier(name)), verifier(name)))
+	}
+
+	first := login(client.Id, "first")
+	parallel := login(client.Id, "parallel")
+	other := login(otherClient.Id, "other")
+
+	server.refreshOAuth(otherClient.Id, first.RefreshToken).expectOAuthError(t, 400, "invalid_grant")
+
+	server.postForm("/routes/oauth2/token", url.Values{
+		"grant_type":    {"refresh_token"},
+		"client_id":     {client.Id},
+		"refresh_token": {first.RefreshToken},
+		"scope":         {"profile email"},
+	}, nil).expectOAuthError(t, 400, "invalid_scope")
+
+	second := server.oauthTokens(server.refreshOAuth(client.Id, first.RefreshToken))
+
+	if second.RefreshToken == "" || second.RefreshToken == first.RefreshToken || second.Scope != "profile" {
+		t.Fatalf("expected a rotated refresh token, got %+v", second)
+	}
+
+	server.request("GET", "/routes/oauth2/userinfo", nil, bearer(first.AccessToken)).expect(t, 401, "Invalid token.")
+
+	server.request("GET", "/routes/oauth2/userinfo", nil, bearer(second.AccessToken)).expect(t, 200, "")
+
+	server.refreshOAuth(client.Id, first.RefreshToken).expectOAuthError(t, 400, "invalid_grant")
+
+	for _, revoked := range []*oauthTokens{second, parallel} {
+		server.request("GET", "/routes/oauth2/userinfo", nil, bearer(revoked.AccessToken)).expect(t, 401, "Invalid token.")
+
+		server.refreshOAuth(client.Id, revoked.RefreshToken).expectOAuthError(t, 400, "invalid_grant")
+	}
+
+	server.request("GET", "/routes/oauth2/userinfo", nil, bearer(other.AccessToken)).expect(t, 200, "")
+
+	server.request("GET", "/routes/users/authenticate", nil, bearer(user.Token)).expect(t, 200, "")
+
+	third := login(client.Id, "third")
+
+	server.clock.Advance(server.config.Lifetimes.OAuthRefreshToken + time.Second)
+
+	server.refreshOAuth(client.Id, third.RefreshToken).expectOAuthError(t, 400, "invalid_grant")
+}
+
+func TestOAuthClientCredentials(t *testing.T) {
+	forEachBackend(t, testOAuthClientCredentials)
+}
+
+func testOAuthClientCredentials(
+	t *testing.T,
+	server *testServer,
+) {
+	adminToken := server.loginAdmin(testAdminUsername, testAdminPassword)
+
+	client, secret := server.createOAuthClient(adminToken, map[string]any{
+		"name":         "Service",
+		"scopes":       []string{"profile"},
+		"grantTypes":   []string{"client_credentials"},
+		"confidential": true,
+	})
+
+	public, _ := server.createOAuthClient(adminToken, publicClient(true))
+
+	values := url.Values{"grant_type": {"client_credentials"}}
+
+	response := server.postForm("/routes/oauth2/token", values, basicAuth(client.Id, "wrong-secret"))
+
+	response.expectOAuthError(t, 401, "invalid_client")
+
+	if response.Header.Get("WWW-Authenticate") != `Basic realm="oauth"` {
+		t.Fatalf("expected a Basic challenge, got %v", response.Header)
+	}
+
+	server.postForm("/routes/oauth2/token", values, nil).expectOAuthError(t, 401, "invalid_client")
+
+	server.postForm("/routes/oauth2/token", url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {client.Id},
+		"client_secret": {"wrong-secret"},
+	}, nil).expectOAuthError(t, 401, "invalid_client")
+
+	server.postForm("/routes/oauth2/token", values, basicAuth(public.Id, "")).expectOAuthError(t, 400, "unauthorized_client")
+
+	server.postForm("/routes/oauth2/token", url.Values{
+		"grant_type": {"client_credentials"},
+		"scope":      {"email"},
+	}, basicAuth(client.Id, secret)).expectOAuthError(t, 400, "invalid_scope")
+
+	server.postForm("/routes/oauth2/token", url.Values{
+		"grant_type": {"password"},
+	}, basicAuth(client.Id, secret)).expectOAuthError(t, 400, "unsupported_grant_type")
+
+	response = server.postForm("/routes/oauth2/token", values, basicAuth(client.Id, secret))
+
+	tokens := server.oauthTokens(response)
+
+	if tokens.RefreshToken != "" || tokens.Scope != "profile" || response.Header.Get("Cache-Control") != "no-store" {
+		t.Fatalf("unexpected client credentials response %s %v", response.Body, response.Header)
+	}
+
+	server.request("GET", "/routes/oauth2/userinfo", nil, bearer(tokens.AccessToken)).expect(t, 403, "This token does not belong to a user.")
+}
+
+func TestOAuthIntrospection(t *testing.T) {
+	forEachBackend(t, testOAuthIntrospection)
+}
+
+func testOAuthIntrospection(
+	t *testing.T,
+	server *testServer,
+) {
+	adminToken := server.loginAdmin(testAdminUsername, testAdminPassword)
+
+	client, secret := server.createOAuthClient(adminToken, map[string]any{
+		"name":         "Confidential",
+		"redirectUris": []string{testRedirectURI},
+		"scopes":       []string{"profile", "email"},
+		"grantTypes":   []string{"authorization_code", "refresh_token", "client_credentials"},
+		"confidential": true,
+		"firstParty":   true,
+	})
+
+	public, _ := server.createOAuthClient(adminToken, publicClient(true))
+
+	user := server.loginUser("user@example.com")
+
+	code := server.authorizeOAuth(user.Token, client.Id, "profile", verifier("introspect"))
+
+	tokens := server.oauthTokens(server.postForm("/routes/oauth2/token", url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {testRedirectURI},
+		"code_verifier": {verifier("introspect")},
+	}, basicAuth(client.Id, secret)))
+
+	server.postForm("/routes/oauth2/introspect", url.Values{"token": {tokens.AccessToken}}, nil).expectOAuthError(t, 401, "invalid_client")
+
+	server.postForm("/routes/oauth2/introspect", url.Values{"token": {tokens.AccessToken}}, basicAuth(client.Id, "wrong-secret")).expectOAuthError(t, 401, "invalid_client")
+
+	server.postForm("/routes/oauth2/introspect", url.Values{
+		"client_id": {public.Id},
+		"token":     {tokens.AccessToken},
+	}, nil).expectOAuthError(t, 401, "invalid_client")
+
+	server.postForm("/routes/oauth2/introspect", url.Values{}, basicAuth(client.Id, secret)).expectOAuthError(t, 400, "invalid_request")
+
+	introspection := server.introspect(client.Id, secret, url.Values{"token": {tokens.AccessToken}})
+
+	if !introspection.Active || introspection.Subject != user.User.Id || introspection.Username != "user@example.com" || introspection.ClientId != client.Id || introspection.Scope != "profile" {
+		t.Fatalf("unexpected access token introspection %+v", introspection)
+	}
+
+	introspection = server.introspect(client.Id, secret, url.Values{
+		"token":           {tokens.RefreshToken},
+		"token_type_hint": {"refresh_token"},
+	})
+
+	if !introspection.Active || introspection.Subject != user.User.Id {
+		t.Fatalf("unexpected refresh token introspection %+v", introspection)
+	}
+
+	if server.introspect(client.Id, secret, url.Values{"token": {"unknown"}}).Active {
+		t.Fatal("expected an unknown token to be inactive")
+	}
+
+	clientTokens := server.oauthTokens(server.postForm("/routes/oauth2/token", url.Values{
+		"grant_type": {"client_credentials"},
+	}, basicAuth(client.Id, secret)))
+
+	introspection = server.introspect(client.Id, secret, url.Values{"token": {clientTokens.AccessToken}})
+
+	if !introspection.Active || introspection.Subject != client.Id || introspection.Username != "" {
+		t.Fatalf("unexpected client token introspection %+v", introspection)
+	}
+
+	server.clock.Advance(server.config.Lifetimes.OAuthAccessToken + time.Minute)
+
+	for _, expired := range []string{tokens.AccessToken, clientTokens.AccessToken} {
+		if server.introspect(client.Id, secret, url.Values{"token": {expired}}).Active {
+			t.Fatal("expected an expired access token to be inactive")
+		}
+	}
+
+	if !server.introspect(client.Id, secret, url.Values{"token": {tokens.RefreshToken}}).Active {
+		t.Fatal("expected the refresh token to outlive the access token")
+	}
+
+	server.postForm("/routes/oauth2/revoke", url.Values{"token": {tokens.RefreshToken}}, basicAuth(client.Id, secret)).expect(t, 200, "")
+
+	if server.introspect(client.Id, secret, url.Values{"token": {tokens.RefreshToken}}).Active {
+		t.Fatal("expected a revoked refresh token to be inactive")
+	}
+}
+
+func TestOAuthRevocation(t *testing.T) {
+	forEachBackend(t, testOAuthRevocation)
+}
+
+func testOAuthRevocation(
+	t *testing.T,
+	server *testServer,
+) {
+	adminToken := server.loginAdmin(testAdminUsername, testAdminPassword)
+
+	client, _ := server.createOAuthClient(adminToken, publicClient(true))
+	otherClient, _ := server.createOAuthClient(adminToken, publicClient(true))
+
+	service, secret := server.createOAuthClient(adminToken, map[string]any{
+		"name":         "Service",
+		"scopes":       []string{"profile"},
+		"grantTypes":   []string{"client_credentials"},
+		"confidential": true,
+	})
+
+	user := server.loginUser("user@example.com")
+
+	login := func(name string) *oauthTokens {
+		return server.oauthTokens(server.exchangeCode(client.Id, server.authorizeOAuth(user.Token, client.Id, "profile", verifier(name)), verifier(name)))
+	}
+
+	revoke := func(clientId, tokenString string) {
+		t.Helper()
+
+		server.postForm("/routes/oauth2/revoke", url.Values{
+			"client_id": {clientId},
+			"token":     {tokenString},
+		}, nil).expect(t, 200, "")
+	}
+
+	server.postForm("/routes/oauth2/revoke", url.Values{"client_id": {client.Id}}, nil).expectOAuthError(t, 400, "invalid_request")
+
+	server.postForm("/routes/oauth2/revoke", url.Values{"token": {"unknown"}}, nil).expectOAuthError(t, 401, "invalid_client")
+
+	revoke(client.Id, "unknown")
+
+	byAccessToken := login("access")
+
+	revoke(otherClient.Id, byAccessToken.AccessToken)
+	revoke(otherClient.Id, byAccessToken.RefreshToken)
+
+	server.request("GET", "/routes/oauth2/userinfo", nil, bearer(byAccessToken.AccessToken)).expect(t, 200, "")
+
+	revoke(client.Id, byAccessToken.AccessToken)
+
+	server.request("GET", "/routes/oauth2/userinfo", nil, bearer(byAccessToken.AccessToken)).expect(t, 401, "Invalid token.")
+
+	server.refreshOAuth(client.Id, byAccessToken.RefreshToken).expectOAuthError(t, 400, "invalid_grant")
+
+	byRefreshToken := login("refresh")
+
+	revoke(client.Id, byRefreshToken.RefreshToken)
+
+	server.request("GET", "/routes/oauth2/userinfo", nil, bearer(byRefreshToken.AccessToken)).expect(t, 401, "Invalid token.")
+
+	revoke(client.Id, byRefreshToken.RefreshToken)
+
+	clientTokens := server.oauthTokens(server.postForm("/routes/oauth2/token", url.Values{
+		"grant_type": {"client_credentials"},
+	}, basicAuth(service.Id, secret)))
+
+	server.postForm("/routes/oauth2/revoke", url.Values{"token": {clientTokens.AccessToken}}, basicAuth(service.Id, secret)).expect(t, 200, "")
+
+	if server.introspect(service.Id, secret, url.Values{"token": {clientTokens.AccessToken}}).Active {
+		t.Fatal("expected a revoked client token to be inactive")
+	}
+}
+
+func TestOAuthConsent(t *testing.T) {
+	forEachBackend(t, testOAuthConsent)
+}
+
+func testOAuthConsent(
+	t *testing.T,
+	server *testServer,
+) {
+	adminToken := server.loginAdmin(testAdminUsername, testAdminPassword)
+
+	thirdParty, _ := server.createOAuthClient(adminToken, publicClient(false))
+	firstParty, _ := server.createOAuthClient(adminToken, publicClient(true))
+
+	user := server.loginUser("user@example.com")
+
+	consentRequired := func(userToken, clientId, scope string) bool {
+		t.Helper()
+
+		query := url.Values{
+			"response_type":         {"code"},
+			"client_id":             {clientId},
+			"redirect_uri":          {testRedirectURI},
+			"scope":                 {scope},
+			"code_challenge":        {codeChallenge(verifier("consent"))},
+			"code_challenge_method": {"S256"},
+		}
+
+		response := server.request("GET", "/routes/oauth2/authorize?"+query.Encode(), nil, bearer(userToken))
+
+		response.expect(t, 200, "")
+
+		var details struct {
+			Client struct {
+				Id string `json:"id"`
+			} `json:"client"`
+			Scopes          []*types.OAuthScope `json:"scopes"`
+			ConsentRequired bool                `json:"consentRequired"`
+		}
+
+		response.decode(t, &details)
+
+		if details.Client.Id != clientId || len(details.Scopes) != len(types.ParseOAuthScope(scope)) {
+			t.Fatalf("unexpected authorization details %s", response.Body)
+		}
+
+		return details.ConsentRequired
+	}
+
+	if !consentRequired(user.Token, thirdParty.Id, "profile") {
+		t.Fatal("expected a third-party client to require consent")
+	}
+
+	if consentRequired(user.Token, firstParty.Id, "profile email") {
+		t.Fatal("expected a first-party client to skip consent")
+	}
+
+	response := server.request("POST", "/routes/oauth2/authorize", map[string]any{
+		"response_type":         "code",
+		"client_id":             thirdParty.Id,
+		"redirect_uri":          testRedirectURI,
+		"scope":                 "profile",
+		"state":                 "xyz",
+		"code_challenge":        codeChallenge(verifier("denied")),
+		"code_challenge_method": "S256",
+	}, bearer(user.Token))
+
+	response.expect(t, 200, "")
+
+	var denied struct {
+		RedirectURL string `json:"redirectUrl"`
+	}
+
+	response.decode(t, &denied)
+
+	if denied.RedirectURL != testRedirectURI+"?error=access_denied&state=xyz" {
+		t.Fatalf("unexpected denial redirect %q", denied.RedirectURL)
+	}
+
+	if !consentRequired(user.Token, thirdParty.Id, "profile") {
+		t.Fatal("expected a denial not to record consent")
+	}
+
+	server.authorizeOAuth(user.Token, thirdParty.Id, "profile", verifier("approved"))
+
+	if consentRequired(user.Token, thirdParty.Id, "profile") {
+		t.Fatal("expected the approved scope to skip consent")
+	}
+
+	if !consentRequired(user.Token, thirdParty.Id, "profile email") {
+		t.Fatal("expected a wider scope to require consent again")
+	}
+
+	server.authorizeOAuth(user.Token, thirdParty.Id, "email", verifier("email"))
+
+	if consentRequired(user.Token, thirdParty.Id, "profile email") {
+		t.Fatal("expected consent to accumulate scopes")
+	}
+
+	if !consentRequired(server.loginUser("other@example.com").Token, thirdParty.Id, "profile") {
+		t.Fatal("expected consent to be recorded per user")
+	}
+}
@@ -0,0 +1,405 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sandromai/go-http-server/oidc"
+	"github.com/sandromai/go-http-server/token"
+)
+
+type fakeProviderAccount struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+}
+
+type fakeProviderCode struct {
+	account       fakeProviderAccount
+	codeChallenge string
+	nonce         string
+}
+
+type fakeProvider struct {
+	t          *testing.T
+	server     *httptest.Server
+	signingKey *token.ECDSAKey
+	tokens     *token.Engine
+
+	mutex sync.Mutex
+	codes map[string]*fakeProviderCode
+}
+
+func (server *testServer) addProvider(
+	name string,
+) *fakeProvider {
+	server.t.Helper()
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	if err != nil {
+		server.t.Fatal(err)
+	}
+
+	provider := &fakeProvider{
+		t:          server.t,
+		signingKey: &token.ECDSAKey{Id: "provider-key", PrivateKey: privateKey},
+		codes:      map[string]*fakeProviderCode{},
+	}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(writer http.ResponseWriter, request *http.Request) {
+		json.NewEncoder(writer).Encode(map[string]string{
+			"issuer":                 provider.server.URL,
+			"authorization_endpoint": provider.server.URL + "/authorize",
+			"token_endpoint":         provider.server.URL + "/token",
+			"jwks_uri":               provider.server.URL + "/jwks",
+		})
+	})
+
+	mux.HandleFunc("/jwks", func(writer http.ResponseWriter, request *http.Request) {
+		json.NewEncoder(writer).Encode(&token.JWKSet{
+			Keys: []*token.JWK{provider.signingKey.JWK()},
+		})
+	})
+
+	mux.HandleFunc("/token", provider.token)
+
+	provider.server = httptest.NewServer(mux)
+
+	server.t.Cleanup(provider.server.Close)
+
+	provider.tokens = &token.Engine{
+		Signer: provider.signingKey,
+		Issuer: provider.server.URL,
+		Clock:  server.clock,
+	}
+
+	server.providers[name] = &oidc.Client{
+		Provider: &oidc.Provider{
+			Name:         name,
+			Type:         oidc.ProviderTypeOIDC,
+			Issuer:       provider.server.URL,
+			ClientId:     "client-id",
+			ClientSecret: "client-secret",
+			RedirectURL:  "http://localhost:3000/oauth/callback",
+			Scopes:       []string{"openid", "email"},
+		},
+		Leeway: time.Minute,
+		Clock:  server.clock,
+	}
+
+	return provider
+}
+
+func (provider *fakeProvider) authorize(
+	authorizationURL string,
+	account fakeProviderAccount,
+) string {
+	provider.t.Helper()
+
+	parsedURL, err := url.Parse(authorizationURL)
+
+	if err != nil {
+		provider.t.Fatal(err)
+	}
+
+	query := parsedURL.Query()
+
+	if query.Get("client_id") != "client-id" || query.Get("code_challenge_method") != "S256" || query.Get("nonce") == "" {
+		provider.t.Fatalf("unexpected authorization URL %q", authorizationURL)
+	}
+
+	provider.mutex.Lock()
+
+	defer provider.mutex.Unlock()
+
+	code := "code-" + query.Get("state")
+
+	provider.codes[code] = &fakeProviderCode{
+		account:       account,
+		codeChallenge: query.Get("code_challenge"),
+		nonce:         query.Get("nonce"),
+	}
+
+	return code
+}
+
+func (provider *fakeProvider) token(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	provider.mutex.Lock()
+
+	code, found := provider.codes[request.PostFormValue("code")]
+
+	delete(provider.codes, request.PostFormValue("code"))
+
+	provider.mutex.Unlock()
+
+	if !found || request.PostFormValue("client_secret") != "client-secret" || oidc.CodeChallenge(request.PostFormValue("code_verifier")) != code.codeChallenge {
+		writer.WriteHeader(400)
+		writer.Write([]byte(`{"error": "invalid_grant"}`))
+
+		return
+	}
+
+	now := provider.tokens.Clock.Now()
+
+	idToken, err := token.Sign(provider.tokens, &struct {
+		token.RegisteredClaims
+		Nonce         string `json:"nonce"`
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+	}{
+		RegisteredClaims: token.RegisteredClaims{
+			Subject:   code.account.Subject,
+			Audience:  token.Audience{"client-id"},
+			ExpiresAt: now.Add(time.Hour).Unix(),
+		},
+		Nonce:         code.nonce,
+		Email:         code.account.Email,
+		EmailVerified: code.account.EmailVerified,
+	})
+
+	if err != nil {
+		provider.t.Error(err)
+
+		writer.WriteHeader(500)
+
+		return
+	}
+
+	json.NewEncoder(writer).Encode(map[string]string{
+		"access_token": "access-token",
+		"token_type":   "Bearer",
+		"id_token":     idToken,
+	})
+}
+
+func (server *testServer) startSocialLogin(
+	providerName string,
+) (string, string) {
+	server.t.Helper()
+
+	response := server.request("POST", "/routes/oauth/"+providerName+"/authorize", nil, nil)
+
+	response.expect(server.t, 200, "")
+
+	var result struct {
+		AuthorizationURL string `json:"authorizationUrl"`
+		State            string `json:"state"`
+	}
+
+	response.decode(server.t, &result)
+
+	return result.AuthorizationURL, result.State
+}
+
+func (server *testServer) socialLogin(
+	provider *fakeProvider,
+	account fakeProviderAccount,
+) *testResponse {
+	server.t.Helper()
+
+	authorizationURL, state := server.startSocialLogin("fake")
+
+	return server.request("POST", "/routes/oauth/fake/callback", map[string]any{
+		"code":  provider.authorize(authorizationURL, account),
+		"state": state,
+	}, nil)
+}
+
+func TestSocialLogin(t *testing.T) {
+	forEachBackend(t, testSocialLogin)
+}
+
+func testSocialLogin(
+	t *testing.T,
+	server *testServer,
+) {
+	provider := server.addProvider("fake")
+
+	response := server.request("GET", "/routes/oauth/providers", nil, nil)
+
+	response.expect(t, 200, "")
+
+	var providers struct {
+		Providers []string `json:"providers"`
+	}
+
+	response.decode(t, &providers)
+
+	if len(providers.Providers) != 1 || providers.Providers[0] != "fake" {
+		t.Fatalf("unexpected providers %s", response.Body)
+	}
+
+	server.request("POST", "/routes/oauth/unknown/authorize", nil, nil).expect(t, 404, "Provider not found.")
+
+	account := fakeProviderAccount{Subject: "subject-1", Email: "Person@Example.com", EmailVerified: true}
+
+	response = server.socialLogin(provider, account)
+
+	response.expect(t, 200, "")
+
+	var session authenticatedUser
+
+	response.decode(t, &session)
+
+	if session.User == nil || session.User.Email != "person@example.com" {
+		t.Fatalf("expected a new user for the verified email, got %s", response.Body)
+	}
+
+	server.request("GET", "/routes/users/authenticate", nil, bearer(session.Token)).expect(t, 200, "")
+
+	var again authenticatedUser
+
+	response = server.socialLogin(provider, fakeProviderAccount{Subject: "subject-1", Email: "changed@example.com"})
+
+	response.expect(t, 200, "")
+
+	response.decode(t, &again)
+
+	if again.User.Id != session.User.Id {
+		t.Fatalf("expected the linked identity to sign in the same user, got %s", response.Body)
+	}
+
+	existing := server.loginUser("existing@example.com")
+
+	response = server.socialLogin(provider, fakeProviderAccount{Subject: "subject-2", Email: "existing@example.com", EmailVerified: true})
+
+	response.expect(t, 200, "")
+
+	response.decode(t, &again)
+
+	if again.User.Id != existing.User.Id {
+		t.Fatalf("expected a verified email to link the existing user, got %s", response.Body)
+	}
+
+	server.socialLogin(provider, fakeProviderAccount{Subject: "subject-3", Email: "unverified@example.com"}).expect(t, 403, "Your provider account has no verified email.")
+
+	authorizationURL, state := server.startSocialLogin("fake")
+
+	code := provider.authorize(authorizationURL, account)
+
+	server.request("POST", "/routes/oauth/fake/callback", map[string]any{
+		"code":  "wrong-code",
+		"state": state,
+	}, nil).expect(t, 401, "Failed to authenticate with provider.")
+
+	server.request("POST", "/routes/oauth/fake/callback", map[string]any{
+		"code":  code,
+		"state": state,
+	}, nil).expect(t, 400, "Invalid or expired authorization state.")
+
+	server.request("POST", "/routes/oauth/fake/callback", map[string]any{
+		"state": state,
+	}, nil).expect(t, 400, "Missing authorization code.")
+
+	authorizationURL, state = server.startSocialLogin("fake")
+
+	code = provider.authorize(authorizationURL, account)
+
+	server.clock.Advance(server.config.Lifetimes.OAuthState + time.Second)
+
+	server.request("POST", "/routes/oauth/fake/callback", map[string]any{
+		"code":  code,
+		"state": state,
+	}, nil).expect(t, 400, "Invalid or expired authorization state.")
+
+	adminToken := server.loginAdmin(testAdminUsername, testAdminPassword)
+
+	server.request("PATCH", "/routes/users/"+session.User.Id+"/ban", nil, bearer(adminToken)).expect(t, 200, "")
+
+	server.socialLogin(provider, account).expect(t, 403, "User banned.")
+}
+
+func TestSocialLoginLinksEmailAcrossCase(t *testing.T) {
+	forEachBackend(t, testSocialLoginLinksEmailAcrossCase)
+}
+
+func testSocialLoginLinksEmailAcrossCase(
+	t *testing.T,
+	server *testServer,
+) {
+	provider := server.addProvider("fake")
+
+	existing := server.loginUser("Person@Example.com")
+
+	response := server.socialLogin(provider, fakeProviderAccount{Subject: "subject-1", Email: "person@example.com", EmailVerified: true})
+
+	response.expect(t, 200, "")
+
+	var session authenticatedUser
+
+	response.decode(t, &session)
+
+	if session.User.Id != existing.User.Id {
+		t.Fatalf("expected the provider identity to link the magic link user, got %s", response.Body)
+	}
+}
+
+func TestSocialLoginConcurrentFirstLogin(t *testing.T) {
+	forEachBackend(t, testSocialLoginConcurrentFirstLogin)
+}
+
+func testSocialLoginConcurrentFirstLogin(
+	t *testing.T,
+	server *testServer,
+) {
+	provider := server.addProvider("fake")
+
+	account := fakeProviderAccount{Subject: "subject-1", Email: "person@example.com", EmailVerified: true}
+
+	var callbacks []map[string]any
+
+	for i := 0; i < 4; i++ {
+		authorizationURL, state := server.startSocialLogin("fake")
+
+		callbacks = append(callbacks, map[string]any{
+			"code":  provider.authorize(authorizationURL, account),
+			"state": state,
+		})
+	}
+
+	responses := make([]*testResponse, len(callbacks))
+
+	var waitGroup sync.WaitGroup
+
+	for i, callback := range callbacks {
+		waitGroup.Add(1)
+
+		go func(i int, callback map[string]any) {
+			defer waitGroup.Done()
+
+			responses[i] = server.request("POST", "/routes/oauth/fake/callback", callback, nil)
+		}(i, callback)
+	}
+
+	waitGroup.Wait()
+
+	userIds := map[string]bool{}
+
+	for _, response := range responses {
+		response.expect(t, 200, "")
+
+		var session authenticatedUser
+
+		response.decode(t, &session)
+
+		userIds[session.User.Id] = true
+	}
+
+	if len(userIds) != 1 {
+		t.Fatalf("expected every callback to sign in one user, got %v", userIds)
+	}
+}
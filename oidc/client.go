@@ -0,0 +1,471 @@
+package oidc
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/sandromai/go-http-server/clock"
+	"github.com/sandromai/go-http-server/token"
+)
+
+var (
+	ErrDiscovery      = errors.New("oidc: provider discovery failed")
+	ErrExchange       = errors.New("oidc: authorization code exchange failed")
+	ErrInvalidIDToken = errors.New("oidc: invalid ID token")
+	ErrNonceMismatch  = errors.New("oidc: nonce mismatch")
+	ErrUserInfo       = errors.New("oidc: failed to fetch user info")
+)
+
+const jwksRefreshBackoff = time.Minute
+
+type Identity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+type Client struct {
+	Provider   *Provider
+	HTTPClient *http.Client
+	Leeway     time.Duration
+	Clock      clock.Clock
+
+	mutex         sync.Mutex
+	discovered    bool
+	verifiers     []token.Verifier
+	jwksFetchedAt time.Time
+}
+
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserInfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IdToken     string `json:"id_token"`
+	Error       string `json:"error"`
+}
+
+type flexibleBool bool
+
+func (value *flexibleBool) UnmarshalJSON(data []byte) error {
+	var parsed any
+
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return err
+	}
+
+	switch typed := parsed.(type) {
+	case bool:
+		*value = flexibleBool(typed)
+	case string:
+		*value = flexibleBool(typed == "true")
+	default:
+		*value = false
+	}
+
+	return nil
+}
+
+type idTokenClaims struct {
+	token.RegisteredClaims
+	Nonce           string       `json:"nonce"`
+	AuthorizedParty string       `json:"azp"`
+	Email           string       `json:"email"`
+	EmailVerified   flexibleBool `json:"email_verified"`
+	Name            string       `json:"name"`
+}
+
+func NewClients(
+	config *Config,
+) map[string]*Client {
+	clients := map[string]*Client{}
+
+	for _, provider := range config.Providers {
+		clients[provider.Name] = &Client{
+			Provider: provider,
+			Leeway:   time.Minute,
+		}
+	}
+
+	return clients
+}
+
+func (client *Client) now() time.Time {
+	return clock.Now(client.Clock)
+}
+
+func (client *Client) httpClient() *http.Client {
+	if client.HTTPClient != nil {
+		return client.HTTPClient
+	}
+
+	return &http.Client{Timeout: 10 * time.Second}
+}
+
+func (client *Client) getJSON(
+	ctx context.Context,
+	endpoint,
+	accessToken string,
+	target any,
+) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Accept", "application/json")
+
+	if accessToken != "" {
+		request.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(request.Header))
+
+	response, err := client.httpClient().Do(request)
+
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return errors.New("oidc: unexpected status " + strconv.Itoa(response.StatusCode) + " from " + endpoint)
+	}
+
+	return json.NewDecoder(io.LimitReader(response.Body, 1<<20)).Decode(target)
+}
+
+func (client *Client) discover(
+	ctx context.Context,
+) error {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+
+	if client.discovered || client.Provider.Type != ProviderTypeOIDC {
+		return nil
+	}
+
+	if client.Provider.AuthorizationEndpoint != "" && client.Provider.TokenEndpoint != "" && client.Provider.JWKSURI != "" {
+		client.discovered = true
+
+		return nil
+	}
+
+	document := &discoveryDocument{}
+
+	err := client.getJSON(
+		ctx,
+		strings.TrimRight(client.Provider.Issuer, "/")+"/.well-known/openid-configuration",
+		"",
+		document,
+	)
+
+	if err != nil {
+		return errors.Join(ErrDiscovery, err)
+	}
+
+	if document.Issuer != client.Provider.Issuer {
+		return errors.Join(ErrDiscovery, errors.New("oidc: issuer mismatch in discovery document"))
+	}
+
+	if client.Provider.AuthorizationEndpoint == "" {
+		client.Provider.AuthorizationEndpoint = document.AuthorizationEndpoint
+	}
+
+	if client.Provider.TokenEndpoint == "" {
+		client.Provider.TokenEndpoint = document.TokenEndpoint
+	}
+
+	if client.Provider.UserInfoEndpoint == "" {
+		client.Provider.UserInfoEndpoint = document.UserInfoEndpoint
+	}
+
+	if client.Provider.JWKSURI == "" {
+		client.Provider.JWKSURI = document.JWKSURI
+	}
+
+	if client.Provider.AuthorizationEndpoint == "" || client.Provider.TokenEndpoint == "" || client.Provider.JWKSURI == "" {
+		return errors.Join(ErrDiscovery, errors.New("oidc: discovery document is missing endpoints"))
+	}
+
+	client.discovered = true
+
+	return nil
+}
+
+func (client *Client) keys(
+	ctx context.Context,
+	refresh bool,
+) ([]token.Verifier, error) {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+
+	if client.verifiers != nil && (!refresh || client.now().Sub(client.jwksFetchedAt) < jwksRefreshBackoff) {
+		return client.verifiers, nil
+	}
+
+	set := &token.JWKSet{}
+
+	if err := client.getJSON(ctx, client.Provider.JWKSURI, "", set); err != nil {
+		return nil, errors.Join(ErrInvalidIDToken, err)
+	}
+
+	verifiers := []token.Verifier{}
+
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		verifier, err := jwk.Verifier()
+
+		if err != nil {
+			continue
+		}
+
+		verifiers = append(verifiers, verifier)
+	}
+
+	client.verifiers = verifiers
+	client.jwksFetchedAt = client.now()
+
+	return verifiers, nil
+}
+
+func (client *Client) AuthorizationURL(
+	ctx context.Context,
+	state,
+	nonce,
+	codeVerifier string,
+) (string, error) {
+	if err := client.discover(ctx); err != nil {
+		return "", err
+	}
+
+	authorizationURL, err := url.Parse(client.Provider.AuthorizationEndpoint)
+
+	if err != nil {
+		return "", errors.Join(ErrDiscovery, err)
+	}
+
+	query := authorizationURL.Query()
+
+	query.Set("response_type", "code")
+	query.Set("client_id", client.Provider.ClientId)
+	query.Set("redirect_uri", client.Provider.RedirectURL)
+	query.Set("scope", strings.Join(client.Provider.Scopes, " "))
+	query.Set("state", state)
+	query.Set("code_challenge", CodeChallenge(codeVerifier))
+	query.Set("code_challenge_method", "S256")
+
+	if client.Provider.Type == ProviderTypeOIDC {
+		query.Set("nonce", nonce)
+	}
+
+	authorizationURL.RawQuery = query.Encode()
+
+	return authorizationURL.String(), nil
+}
+
+func (client *Client) exchangeCode(
+	ctx context.Context,
+	code,
+	codeVerifier string,
+) (*tokenResponse, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {client.Provider.RedirectURL},
+		"client_id":     {client.Provider.ClientId},
+		"client_secret": {client.Provider.ClientSecret},
+		"code_verifier": {codeVerifier},
+	}
+
+	request, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		client.Provider.TokenEndpoint,
+		strings.NewReader(form.Encode()),
+	)
+
+	if err != nil {
+		return nil, errors.Join(ErrExchange, err)
+	}
+
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(request.Header))
+
+	response, err := client.httpClient().Do(request)
+
+	if err != nil {
+		return nil, errors.Join(ErrExchange, err)
+	}
+
+	defer response.Body.Close()
+
+	tokens := &tokenResponse{}
+
+	err = json.NewDecoder(io.LimitReader(response.Body, 1<<20)).Decode(tokens)
+
+	if err != nil || response.StatusCode != http.StatusOK || tokens.Error != "" || tokens.AccessToken == "" {
+		return nil, ErrExchange
+	}
+
+	return tokens, nil
+}
+
+func (client *Client) Exchange(
+	ctx context.Context,
+	code,
+	codeVerifier,
+	nonce string,
+) (*Identity, error) {
+	if err := client.discover(ctx); err != nil {
+		return nil, err
+	}
+
+	tokens, err := client.exchangeCode(ctx, code, codeVerifier)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if client.Provider.Type == ProviderTypeGitHub {
+		return client.gitHubIdentity(ctx, tokens.AccessToken)
+	}
+
+	if tokens.IdToken == "" {
+		return nil, ErrInvalidIDToken
+	}
+
+	return client.verifyIdToken(ctx, tokens.IdToken, nonce)
+}
+
+func (client *Client) verifyIdToken(
+	ctx context.Context,
+	idToken,
+	nonce string,
+) (*Identity, error) {
+	verifiers, err := client.keys(ctx, false)
+
+	if err != nil {
+		return nil, err
+	}
+
+	engine := &token.Engine{
+		Verifiers: verifiers,
+		Issuer:    client.Provider.Issuer,
+		Leeway:    client.Leeway,
+		Clock:     client.Clock,
+	}
+
+	claims := &idTokenClaims{}
+
+	err = token.Verify(engine, idToken, client.Provider.ClientId, claims)
+
+	if errors.Is(err, token.ErrUnknownKey) {
+		if engine.Verifiers, err = client.keys(ctx, true); err != nil {
+			return nil, err
+		}
+
+		claims = &idTokenClaims{}
+
+		err = token.Verify(engine, idToken, client.Provider.ClientId, claims)
+	}
+
+	if err != nil {
+		return nil, errors.Join(ErrInvalidIDToken, err)
+	}
+
+	if claims.Issuer == "" || claims.Subject == "" {
+		return nil, ErrInvalidIDToken
+	}
+
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != client.Provider.ClientId {
+		return nil, ErrInvalidIDToken
+	}
+
+	if subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return nil, ErrNonceMismatch
+	}
+
+	return &Identity{
+		Provider:      client.Provider.Name,
+		Subject:       claims.Subject,
+		Email:         strings.ToLower(claims.Email),
+		EmailVerified: bool(claims.EmailVerified),
+		Name:          claims.Name,
+	}, nil
+}
+
+func (client *Client) gitHubIdentity(
+	ctx context.Context,
+	accessToken string,
+) (*Identity, error) {
+	user := &struct {
+		Id    int64  `json:"id"`
+		Login string `json:"login"`
+		Name  string `json:"name"`
+	}{}
+
+	if err := client.getJSON(ctx, client.Provider.UserInfoEndpoint, accessToken, user); err != nil {
+		return nil, errors.Join(ErrUserInfo, err)
+	}
+
+	if user.Id == 0 {
+		return nil, ErrUserInfo
+	}
+
+	emails := []*struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}{}
+
+	if err := client.getJSON(ctx, strings.TrimRight(client.Provider.UserInfoEndpoint, "/")+"/emails", accessToken, &emails); err != nil {
+		return nil, errors.Join(ErrUserInfo, err)
+	}
+
+	identity := &Identity{
+		Provider: client.Provider.Name,
+		Subject:  strconv.FormatInt(user.Id, 10),
+		Name:     user.Name,
+	}
+
+	if identity.Name == "" {
+		identity.Name = user.Login
+	}
+
+	for _, email := range emails {
+		if email.Primary && email.Verified {
+			identity.Email = strings.ToLower(email.Email)
+			identity.EmailVerified = true
+		}
+	}
+
+	return identity, nil
+}
@@ -0,0 +1,400 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sandromai/go-http-server/clock"
+	"github.com/sandromai/go-http-server/token"
+)
+
+type mockProvider struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mutex      sync.Mutex
+	signingKey *token.ECDSAKey
+	audience   string
+	subject    string
+	email      string
+	verified   any
+	codes      map[string]*mockAuthorization
+}
+
+type mockAuthorization struct {
+	codeChallenge string
+	nonce         string
+}
+
+func newMockProvider(t *testing.T) *mockProvider {
+	provider := &mockProvider{
+		t:        t,
+		audience: "client-id",
+		subject:  "subject-1",
+		email:    "Person@Example.com",
+		verified: true,
+		codes:    map[string]*mockAuthorization{},
+	}
+
+	provider.rotateKey("key-1")
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(writer http.ResponseWriter, request *http.Request) {
+		json.NewEncoder(writer).Encode(&discoveryDocument{
+			Issuer:                provider.server.URL,
+			AuthorizationEndpoint: provider.server.URL + "/authorize",
+			TokenEndpoint:         provider.server.URL + "/token",
+			JWKSURI:               provider.server.URL + "/jwks",
+		})
+	})
+
+	mux.HandleFunc("/jwks", func(writer http.ResponseWriter, request *http.Request) {
+		provider.mutex.Lock()
+		defer provider.mutex.Unlock()
+
+		json.NewEncoder(writer).Encode(&token.JWKSet{
+			Keys: []*token.JWK{provider.signingKey.JWK()},
+		})
+	})
+
+	mux.HandleFunc("/token", provider.token)
+
+	mux.HandleFunc("/user", func(writer http.ResponseWriter, request *http.Request) {
+		if request.Header.Get("Authorization") != "Bearer access-token" {
+			writer.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		writer.Write([]byte(`{"id": 42, "login": "octocat", "name": ""}`))
+	})
+
+	mux.HandleFunc("/user/emails", func(writer http.ResponseWriter, request *http.Request) {
+		writer.Write([]byte(`[{"email": "other@example.com", "primary": false, "verified": true}, {"email": "Octocat@Example.com", "primary": true, "verified": true}]`))
+	})
+
+	provider.server = httptest.NewServer(mux)
+
+	t.Cleanup(provider.server.Close)
+
+	return provider
+}
+
+func (provider *mockProvider) rotateKey(id string) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	if err != nil {
+		provider.t.Fatal(err)
+	}
+
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+
+	provider.signingKey = &token.ECDSAKey{
+		Id:         id,
+		PrivateKey: privateKey,
+	}
+}
+
+func (provider *mockProvider) authorize(authorizationURL string) string {
+	parsedURL, err := url.Parse(authorizationURL)
+
+	if err != nil {
+		provider.t.Fatal(err)
+	}
+
+	query := parsedURL.Query()
+
+	if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		provider.t.Fatalf("authorization URL is missing PKCE parameters: %s", authorizationURL)
+	}
+
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+
+	code := "code-" + query.Get("state")
+
+	provider.codes[code] = &mockAuthorization{
+		codeChallenge: query.Get("code_challenge"),
+		nonce:         query.Get("nonce"),
+	}
+
+	return code
+}
+
+func (provider *mockProvider) token(writer http.ResponseWriter, request *http.Request) {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+
+	authorization, ok := provider.codes[request.PostFormValue("code")]
+
+	delete(provider.codes, request.PostFormValue("code"))
+
+	if !ok || request.PostFormValue("client_secret") != "client-secret" || CodeChallenge(request.PostFormValue("code_verifier")) != authorization.codeChallenge {
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte(`{"error": "invalid_grant"}`))
+
+		return
+	}
+
+	engine := &token.Engine{
+		Signer: provider.signingKey,
+		Issuer: provider.server.URL,
+	}
+
+	idToken, err := token.Sign(engine, &struct {
+		idTokenClaims
+		EmailVerified any `json:"email_verified"`
+	}{
+		idTokenClaims: idTokenClaims{
+			RegisteredClaims: token.RegisteredClaims{
+				Subject:   provider.subject,
+				Audience:  token.Audience{provider.audience},
+				ExpiresAt: time.Now().Add(time.Hour).Unix(),
+			},
+			Nonce: authorization.nonce,
+			Email: provider.email,
+		},
+		EmailVerified: provider.verified,
+	})
+
+	if err != nil {
+		provider.t.Fatal(err)
+	}
+
+	json.NewEncoder(writer).Encode(&tokenResponse{
+		AccessToken: "access-token",
+		TokenType:   "Bearer",
+		IdToken:     idToken,
+	})
+}
+
+func (provider *mockProvider) client() *Client {
+	return &Client{
+		Provider: &Provider{
+			Name:         "mock",
+			Type:         ProviderTypeOIDC,
+			Issuer:       provider.server.URL,
+			ClientId:     "client-id",
+			ClientSecret: "client-secret",
+			RedirectURL:  "http://localhost:3000/oauth/callback",
+			Scopes:       []string{"openid", "email"},
+		},
+	}
+}
+
+func login(
+	t *testing.T,
+	provider *mockProvider,
+	client *Client,
+	nonce string,
+	codeVerifier string,
+) (*Identity, error) {
+	authorizationURL, err := client.AuthorizationURL(context.Background(), "state", nonce, codeVerifier)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code := provider.authorize(authorizationURL)
+
+	return client.Exchange(context.Background(), code, codeVerifier, nonce)
+}
+
+func TestExchangeVerifiesIdToken(t *testing.T) {
+	provider := newMockProvider(t)
+	client := provider.client()
+
+	codeVerifier, _ := NewCodeVerifier()
+	nonce, _ := NewNonce()
+
+	identity, err := login(t, provider, client, nonce, codeVerifier)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if identity.Provider != "mock" || identity.Subject != "subject-1" || identity.Email != "person@example.com" || !identity.EmailVerified {
+		t.Fatalf("unexpected identity %+v", identity)
+	}
+}
+
+func TestExchangeRejectsInvalidResponses(t *testing.T) {
+	tests := []struct {
+		name        string
+		setup       func(provider *mockProvider)
+		nonce       string
+		verifier    string
+		expectedErr error
+	}{
+		{
+			name:        "nonce",
+			setup:       func(provider *mockProvider) {},
+			nonce:       "other-nonce",
+			expectedErr: ErrNonceMismatch,
+		},
+		{
+			name: "audience",
+			setup: func(provider *mockProvider) {
+				provider.audience = "other-client"
+			},
+			expectedErr: token.ErrInvalidAudience,
+		},
+		{
+			name:        "code verifier",
+			setup:       func(provider *mockProvider) {},
+			verifier:    "other-verifier",
+			expectedErr: ErrExchange,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			provider := newMockProvider(t)
+			client := provider.client()
+
+			test.setup(provider)
+
+			authorizationURL, err := client.AuthorizationURL(context.Background(), "state", "nonce", "verifier")
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			code := provider.authorize(authorizationURL)
+
+			nonce := "nonce"
+
+			if test.nonce != "" {
+				nonce = test.nonce
+			}
+
+			verifier := "verifier"
+
+			if test.verifier != "" {
+				verifier = test.verifier
+			}
+
+			_, err = client.Exchange(context.Background(), code, verifier, nonce)
+
+			if !errors.Is(err, test.expectedErr) {
+				t.Fatalf("error = %v, want %v", err, test.expectedErr)
+			}
+		})
+	}
+}
+
+func TestExchangeReadsStringEmailVerified(t *testing.T) {
+	provider := newMockProvider(t)
+	client := provider.client()
+
+	provider.verified = "false"
+
+	identity, err := login(t, provider, client, "nonce", "verifier")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if identity.EmailVerified {
+		t.Fatal("expected email to be unverified")
+	}
+}
+
+func TestExchangeRefreshesRotatedKeys(t *testing.T) {
+	provider := newMockProvider(t)
+	client := provider.client()
+
+	if _, err := login(t, provider, client, "nonce", "verifier"); err != nil {
+		t.Fatal(err)
+	}
+
+	provider.rotateKey("key-2")
+
+	client.Clock = clock.NewManual(time.Now().Add(2 * jwksRefreshBackoff))
+
+	if _, err := login(t, provider, client, "nonce", "verifier"); err != nil {
+		t.Fatalf("login after rotation: %v", err)
+	}
+}
+
+func TestGitHubIdentity(t *testing.T) {
+	provider := newMockProvider(t)
+
+	client := &Client{
+		Provider: &Provider{
+			Name:                  "github",
+			Type:                  ProviderTypeGitHub,
+			ClientId:              "client-id",
+			ClientSecret:          "client-secret",
+			RedirectURL:           "http://localhost:3000/oauth/callback",
+			AuthorizationEndpoint: provider.server.URL + "/authorize",
+			TokenEndpoint:         provider.server.URL + "/token",
+			UserInfoEndpoint:      provider.server.URL + "/user",
+		},
+	}
+
+	identity, err := login(t, provider, client, "", "verifier")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if identity.Subject != "42" || identity.Name != "octocat" || identity.Email != "octocat@example.com" || !identity.EmailVerified {
+		t.Fatalf("unexpected identity %+v", identity)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv("TEST_OIDC_SECRET", "secret-from-env")
+
+	path := filepath.Join(t.TempDir(), "providers.json")
+
+	err := os.WriteFile(path, []byte(`{
+		"providers": [
+			{"name": "google", "issuer": "https://accounts.google.com", "clientId": "id", "clientSecretEnv": "TEST_OIDC_SECRET", "redirectUrl": "https://app.example.com/callback"},
+			{"name": "github", "type": "github", "clientId": "id", "clientSecret": "secret", "redirectUrl": "https://app.example.com/callback"}
+		]
+	}`), 0o600)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := LoadConfig(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.Providers[0].Type != ProviderTypeOIDC || config.Providers[0].ClientSecret != "secret-from-env" || len(config.Providers[0].Scopes) != 3 {
+		t.Fatalf("unexpected google provider %+v", config.Providers[0])
+	}
+
+	if config.Providers[1].TokenEndpoint != "https://github.com/login/oauth/access_token" {
+		t.Fatalf("unexpected github provider %+v", config.Providers[1])
+	}
+
+	err = os.WriteFile(path, []byte(`{"providers": [{"name": "broken", "clientId": "id", "clientSecret": "secret", "redirectUrl": "https://app.example.com/callback"}]}`), 0o600)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = LoadConfig(path); err == nil {
+		t.Fatal("expected missing issuer to be rejected")
+	}
+}
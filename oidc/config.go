@@ -0,0 +1,119 @@
+package oidc
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"os"
+)
+
+const (
+	ProviderTypeOIDC   = "oidc"
+	ProviderTypeGitHub = "github"
+)
+
+type Provider struct {
+	Name                  string   `json:"name"`
+	Type                  string   `json:"type"`
+	Issuer                string   `json:"issuer"`
+	ClientId              string   `json:"clientId"`
+	ClientSecret          string   `json:"clientSecret"`
+	ClientSecretEnv       string   `json:"clientSecretEnv"`
+	RedirectURL           string   `json:"redirectUrl"`
+	Scopes                []string `json:"scopes"`
+	AuthorizationEndpoint string   `json:"authorizationEndpoint"`
+	TokenEndpoint         string   `json:"tokenEndpoint"`
+	UserInfoEndpoint      string   `json:"userInfoEndpoint"`
+	JWKSURI               string   `json:"jwksUri"`
+}
+
+type Config struct {
+	Providers []*Provider `json:"providers"`
+}
+
+func LoadConfig(
+	path string,
+) (*Config, error) {
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	config := &Config{}
+
+	if err = json.Unmarshal(data, config); err != nil {
+		return nil, errors.New("oidc: invalid config file: " + err.Error())
+	}
+
+	names := map[string]bool{}
+
+	for _, provider := range config.Providers {
+		if err = provider.normalize(); err != nil {
+			return nil, err
+		}
+
+		if names[provider.Name] {
+			return nil, errors.New("oidc: duplicate provider " + provider.Name)
+		}
+
+		names[provider.Name] = true
+	}
+
+	return config, nil
+}
+
+func (provider *Provider) normalize() error {
+	if provider.Name == "" {
+		return errors.New("oidc: provider name is required")
+	}
+
+	if provider.Type == "" {
+		provider.Type = ProviderTypeOIDC
+	}
+
+	if provider.ClientSecret == "" && provider.ClientSecretEnv != "" {
+		provider.ClientSecret = os.Getenv(provider.ClientSecretEnv)
+	}
+
+	switch provider.Type {
+	case ProviderTypeOIDC:
+		if provider.Issuer == "" {
+			return errors.New("oidc: provider " + provider.Name + " requires an issuer")
+		}
+
+		if len(provider.Scopes) == 0 {
+			provider.Scopes = []string{"openid", "email", "profile"}
+		}
+	case ProviderTypeGitHub:
+		if provider.AuthorizationEndpoint == "" {
+			provider.AuthorizationEndpoint = "https://github.com/login/oauth/authorize"
+		}
+
+		if provider.TokenEndpoint == "" {
+			provider.TokenEndpoint = "https://github.com/login/oauth/access_token"
+		}
+
+		if provider.UserInfoEndpoint == "" {
+			provider.UserInfoEndpoint = "https://api.github.com/user"
+		}
+
+		if len(provider.Scopes) == 0 {
+			provider.Scopes = []string{"read:user", "user:email"}
+		}
+	default:
+		return errors.New("oidc: provider " + provider.Name + " has unknown type " + provider.Type)
+	}
+
+	if provider.ClientId == "" || provider.ClientSecret == "" {
+		return errors.New("oidc: provider " + provider.Name + " requires a client ID and secret")
+	}
+
+	redirectURL, err := url.Parse(provider.RedirectURL)
+
+	if err != nil || redirectURL.Scheme == "" || redirectURL.Host == "" {
+		return errors.New("oidc: provider " + provider.Name + " requires an absolute redirect URL")
+	}
+
+	return nil
+}
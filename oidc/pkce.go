@@ -0,0 +1,35 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+func randomString() (string, error) {
+	bytes := make([]byte, 32)
+
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
+func NewState() (string, error) {
+	return randomString()
+}
+
+func NewNonce() (string, error) {
+	return randomString()
+}
+
+func NewCodeVerifier() (string, error) {
+	return randomString()
+}
+
+func CodeChallenge(codeVerifier string) string {
+	digest := sha256.Sum256([]byte(codeVerifier))
+
+	return base64.RawURLEncoding.EncodeToString(digest[:])
+}
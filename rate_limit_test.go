@@ -0,0 +1,188 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sandromai/go-http-server/clock"
+	"github.com/sandromai/go-http-server/config"
+	"github.com/sandromai/go-http-server/models"
+	"github.com/sandromai/go-http-server/models/memory"
+)
+
+func TestAdminLoginRateLimit(t *testing.T) {
+	forEachBackend(t, testAdminLoginRateLimit)
+}
+
+func testAdminLoginRateLimit(
+	t *testing.T,
+	server *testServer,
+) {
+	for attempt := 0; attempt < 10; attempt++ {
+		response := server.request("POST", "/routes/admins/login", map[string]any{
+			"username": testAdminUsername,
+		}, nil)
+
+		response.expect(t, 400, "Insert your password.")
+
+		if remaining := response.Header.Get("RateLimit-Remaining"); remaining != strconv.Itoa(9-attempt) {
+			t.Fatalf("attempt %v: got RateLimit-Remaining %q", attempt, remaining)
+		}
+	}
+
+	response := server.request("POST", "/routes/admins/login", map[string]any{
+		"username": testAdminUsername,
+		"password": testAdminPassword,
+	}, nil)
+
+	response.expect(t, 429, "Too many requests, try again later.")
+
+	if response.Header.Get("RateLimit-Limit") != "10" || response.Header.Get("RateLimit-Remaining") != "0" || response.Header.Get("Retry-After") != "66" {
+		t.Fatalf("unexpected rate limit headers %v", response.Header)
+	}
+
+	response = server.request("POST", "/routes/loginTokens/check", map[string]any{
+		"token": "invalid",
+	}, nil)
+
+	response.expect(t, 401, "Invalid token.")
+
+	if remaining := response.Header.Get("RateLimit-Remaining"); remaining != "29" {
+		t.Fatalf("expected other routes to keep their own quota, got RateLimit-Remaining %q", remaining)
+	}
+
+	server.clock.Advance(2 * time.Minute)
+
+	server.loginAdmin(testAdminUsername, testAdminPassword)
+}
+
+func TestAdminLoginLockout(t *testing.T) {
+	forEachBackend(t, testAdminLoginLockout)
+}
+
+func testAdminLoginLockout(
+	t *testing.T,
+	server *testServer,
+) {
+	failLogins := func() {
+		t.Helper()
+
+		for attempt := 0; attempt < 5; attempt++ {
+			server.request("POST", "/routes/admins/login", map[string]any{
+				"username": strings.ToUpper(testAdminUsername),
+				"password": "wrong-password",
+			}, nil).expect(t, 401, "Incorrect username or password.")
+		}
+	}
+
+	failLogins()
+
+	response := server.request("POST", "/routes/admins/login", map[string]any{
+		"username": testAdminUsername,
+		"password": testAdminPassword,
+	}, nil)
+
+	response.expect(t, 429, "Too many failed attempts, try again later.")
+
+	if retryAfter := response.Header.Get("Retry-After"); retryAfter != "60" {
+		t.Fatalf("expected a one minute lockout, got Retry-After %q", retryAfter)
+	}
+
+	server.clock.Advance(2 * time.Minute)
+
+	failLogins()
+
+	response = server.request("POST", "/routes/admins/login", map[string]any{
+		"username": testAdminUsername,
+		"password": testAdminPassword,
+	}, nil)
+
+	response.expect(t, 429, "Too many failed attempts, try again later.")
+
+	if retryAfter := response.Header.Get("Retry-After"); retryAfter != "120" {
+		t.Fatalf("expected the second lockout to double, got Retry-After %q", retryAfter)
+	}
+
+	server.clock.Advance(3 * time.Minute)
+
+	server.loginAdmin(testAdminUsername, testAdminPassword)
+
+	for attempt := 0; attempt < 4; attempt++ {
+		server.request("POST", "/routes/admins/login", map[string]any{
+			"username": testAdminUsername,
+			"password": "wrong-password",
+		}, nil).expect(t, 401, "Incorrect username or password.")
+	}
+
+	server.loginAdmin(testAdminUsername, testAdminPassword)
+
+	body := string(server.request("GET", "/metrics", nil, nil).Body)
+
+	for _, line := range []string{
+		`lockouts_total{rule="adminPasswordLockout"} 2`,
+		`rate_limited_total{rule="adminPasswordLockout"} 2`,
+		`admin_login_failures_total{stage="password"} 14`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Fatalf("expected %q in\n%v", line, body)
+		}
+	}
+}
+
+func TestLoginTokenEmailRateLimit(t *testing.T) {
+	forEachBackend(t, testLoginTokenEmailRateLimit)
+}
+
+func testLoginTokenEmailRateLimit(
+	t *testing.T,
+	server *testServer,
+) {
+	server.createLoginToken("user@example.com")
+
+	for attempt := 0; attempt < 4; attempt++ {
+		server.request("POST", "/routes/loginTokens/create", map[string]any{
+			"email": "user@example.com",
+		}, nil).expect(t, 429, "Wait before trying again.")
+	}
+
+	response := server.request("POST", "/routes/loginTokens/create", map[string]any{
+		"email": "user@example.com",
+	}, nil)
+
+	response.expect(t, 429, "Too many requests, try again later.")
+
+	if response.Header.Get("RateLimit-Limit") != "5" || response.Header.Get("Retry-After") != "720" {
+		t.Fatalf("unexpected rate limit headers %v", response.Header)
+	}
+
+	server.createLoginToken("other@example.com")
+
+	server.clock.Advance(12 * time.Minute)
+
+	server.createLoginToken("user@example.com")
+}
+
+func TestRateLimitDisabled(t *testing.T) {
+	server := newTestServerWithBackend(t, func(appConfig *config.Config, appClock clock.Clock) models.Backend {
+		appConfig.RateLimit.Enabled = false
+
+		return memory.NewStore(appClock, appConfig.Security.PasswordCost)
+	})
+
+	for attempt := 0; attempt < 6; attempt++ {
+		response := server.request("POST", "/routes/admins/login", map[string]any{
+			"username": testAdminUsername,
+			"password": "wrong-password",
+		}, nil)
+
+		response.expect(t, 401, "Incorrect username or password.")
+
+		if response.Header.Get("RateLimit-Limit") != "" {
+			t.Fatalf("expected no rate limit headers, got %v", response.Header)
+		}
+	}
+
+	server.loginAdmin(testAdminUsername, testAdminPassword)
+}
@@ -0,0 +1,145 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sandromai/go-http-server/ratelimit"
+)
+
+const sweepInterval = time.Minute
+
+type entry struct {
+	state     any
+	expiresAt time.Time
+}
+
+type Store struct {
+	mutex     sync.Mutex
+	entries   map[string]*entry
+	nextSweep time.Time
+}
+
+func NewStore() *Store {
+	return &Store{
+		entries: map[string]*entry{},
+	}
+}
+
+func load[T any](
+	store *Store,
+	key string,
+	now time.Time,
+) (*entry, *T) {
+	if now.After(store.nextSweep) {
+		for entryKey, current := range store.entries {
+			if now.After(current.expiresAt) {
+				delete(store.entries, entryKey)
+			}
+		}
+
+		store.nextSweep = now.Add(sweepInterval)
+	}
+
+	current, found := store.entries[key]
+
+	if found && now.Before(current.expiresAt) {
+		if state, ok := current.state.(*T); ok {
+			return current, state
+		}
+	}
+
+	state := new(T)
+
+	current = &entry{state: state}
+
+	store.entries[key] = current
+
+	return current, state
+}
+
+func (store *Store) Take(
+	ctx context.Context,
+	key string,
+	rule ratelimit.Rule,
+	now time.Time,
+) (*ratelimit.Result, error) {
+	store.mutex.Lock()
+
+	defer store.mutex.Unlock()
+
+	if rule.Algorithm == ratelimit.TokenBucket {
+		current, state := load[ratelimit.BucketState](store, key, now)
+
+		result := state.Take(rule, now)
+
+		current.expiresAt = state.ExpiresAt(rule)
+
+		return result, nil
+	}
+
+	current, state := load[ratelimit.WindowState](store, key, now)
+
+	result := state.Take(rule, now)
+
+	current.expiresAt = state.ExpiresAt(rule)
+
+	return result, nil
+}
+
+func (store *Store) Fail(
+	ctx context.Context,
+	key string,
+	lockout ratelimit.Lockout,
+	now time.Time,
+) (time.Time, error) {
+	store.mutex.Lock()
+
+	defer store.mutex.Unlock()
+
+	current, state := load[ratelimit.LockoutState](store, key, now)
+
+	lockedUntil := state.Fail(lockout, now)
+
+	current.expiresAt = state.ExpiresAt(lockout)
+
+	return lockedUntil, nil
+}
+
+func (store *Store) LockedUntil(
+	ctx context.Context,
+	key string,
+	now time.Time,
+) (time.Time, error) {
+	store.mutex.Lock()
+
+	defer store.mutex.Unlock()
+
+	current, found := store.entries[key]
+
+	if !found {
+		return time.Time{}, nil
+	}
+
+	state, ok := current.state.(*ratelimit.LockoutState)
+
+	if !ok {
+		return time.Time{}, nil
+	}
+
+	return state.LockedUntil, nil
+}
+
+func (store *Store) Clear(
+	ctx context.Context,
+	key string,
+) error {
+	store.mutex.Lock()
+
+	defer store.mutex.Unlock()
+
+	delete(store.entries, key)
+
+	return nil
+}
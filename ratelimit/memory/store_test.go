@@ -0,0 +1,127 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sandromai/go-http-server/clock"
+	"github.com/sandromai/go-http-server/ratelimit"
+)
+
+func TestStoreIsolatesKeysAndRules(t *testing.T) {
+	appClock := clock.NewManual(time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC))
+
+	limiter := &ratelimit.Limiter{
+		Backend: NewStore(),
+		Clock:   appClock,
+	}
+
+	ctx := context.Background()
+
+	window := ratelimit.Rule{Name: "window", Algorithm: ratelimit.SlidingWindow, Limit: 2, Window: time.Minute}
+	bucket := ratelimit.Rule{Name: "bucket", Algorithm: ratelimit.TokenBucket, Limit: 2, Window: time.Minute}
+
+	for _, rule := range []ratelimit.Rule{window, bucket} {
+		for attempt := 0; attempt < 2; attempt++ {
+			if result, _ := limiter.Allow(ctx, rule, "1.2.3.4"); !result.Allowed {
+				t.Fatalf("%v: attempt %v was rejected", rule.Name, attempt)
+			}
+		}
+
+		if result, _ := limiter.Allow(ctx, rule, "1.2.3.4"); result.Allowed {
+			t.Fatalf("%v: expected the third attempt to be rejected", rule.Name)
+		}
+
+		if result, _ := limiter.Allow(ctx, rule, "5.6.7.8"); !result.Allowed {
+			t.Fatalf("%v: expected another key to have its own quota", rule.Name)
+		}
+	}
+
+	if result, _ := limiter.Allow(ctx, ratelimit.Rule{Name: "disabled"}, "1.2.3.4"); !result.Allowed || result.Limit != 0 {
+		t.Fatalf("expected a rule without a limit to allow everything, got %+v", result)
+	}
+}
+
+func TestStoreLockouts(t *testing.T) {
+	appClock := clock.NewManual(time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC))
+
+	store := NewStore()
+
+	limiter := &ratelimit.Limiter{
+		Backend: store,
+		Clock:   appClock,
+	}
+
+	ctx := context.Background()
+
+	lockout := ratelimit.Lockout{Name: "login", Threshold: 2, Window: time.Minute, Duration: time.Minute, MaxDuration: time.Hour}
+
+	limiter.Fail(ctx, lockout, "root")
+
+	if lockedFor, _ := limiter.Fail(ctx, lockout, "root"); lockedFor != time.Minute {
+		t.Fatalf("expected a one minute lockout, got %v", lockedFor)
+	}
+
+	appClock.Advance(20 * time.Second)
+
+	if lockedFor, _ := limiter.Locked(ctx, lockout, "root"); lockedFor != 40*time.Second {
+		t.Fatalf("expected 40s left on the lockout, got %v", lockedFor)
+	}
+
+	if lockedFor, _ := limiter.Locked(ctx, lockout, "admin"); lockedFor != 0 {
+		t.Fatalf("expected other keys to stay unlocked, got %v", lockedFor)
+	}
+
+	limiter.Clear(ctx, lockout, "root")
+
+	if lockedFor, _ := limiter.Locked(ctx, lockout, "root"); lockedFor != 0 {
+		t.Fatalf("expected clearing to lift the lockout, got %v", lockedFor)
+	}
+
+	limiter.Fail(ctx, lockout, "root")
+
+	appClock.Advance(time.Hour)
+
+	limiter.Allow(ctx, ratelimit.Rule{Name: "window", Algorithm: ratelimit.SlidingWindow, Limit: 1, Window: time.Second}, "sweep")
+
+	if len(store.entries) != 1 {
+		t.Fatalf("expected expired entries to be swept, got %v entries", len(store.entries))
+	}
+}
+
+func TestStoreConcurrentTakes(t *testing.T) {
+	limiter := &ratelimit.Limiter{
+		Backend: NewStore(),
+		Clock:   clock.NewManual(time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)),
+	}
+
+	rule := ratelimit.Rule{Name: "window", Algorithm: ratelimit.SlidingWindow, Limit: 50, Window: time.Minute}
+
+	var (
+		waitGroup sync.WaitGroup
+		mutex     sync.Mutex
+		allowed   int
+	)
+
+	for worker := 0; worker < 100; worker++ {
+		waitGroup.Add(1)
+
+		go func() {
+			defer waitGroup.Done()
+
+			if result, _ := limiter.Allow(context.Background(), rule, "1.2.3.4"); result.Allowed {
+				mutex.Lock()
+				allowed++
+				mutex.Unlock()
+			}
+		}()
+	}
+
+	waitGroup.Wait()
+
+	if allowed != 50 {
+		t.Fatalf("expected exactly 50 allowed requests, got %v", allowed)
+	}
+}
@@ -0,0 +1,145 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sandromai/go-http-server/clock"
+)
+
+type Algorithm string
+
+const (
+	SlidingWindow Algorithm = "slidingWindow"
+	TokenBucket   Algorithm = "tokenBucket"
+)
+
+type Rule struct {
+	Name      string
+	Algorithm Algorithm
+	Limit     int
+	Window    time.Duration
+}
+
+type Lockout struct {
+	Name        string
+	Threshold   int
+	Window      time.Duration
+	Duration    time.Duration
+	MaxDuration time.Duration
+}
+
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+type Backend interface {
+	Take(ctx context.Context, key string, rule Rule, now time.Time) (*Result, error)
+	Fail(ctx context.Context, key string, lockout Lockout, now time.Time) (time.Time, error)
+	LockedUntil(ctx context.Context, key string, now time.Time) (time.Time, error)
+	Clear(ctx context.Context, key string) error
+}
+
+type Limiter struct {
+	Backend Backend
+	Clock   clock.Clock
+}
+
+func (limiter *Limiter) Allow(
+	ctx context.Context,
+	rule Rule,
+	key string,
+) (*Result, error) {
+	if limiter == nil || rule.Limit <= 0 {
+		return &Result{Allowed: true}, nil
+	}
+
+	return limiter.Backend.Take(ctx, rule.Name+":"+key, rule, clock.Now(limiter.Clock))
+}
+
+func (limiter *Limiter) Locked(
+	ctx context.Context,
+	lockout Lockout,
+	key string,
+) (time.Duration, error) {
+	if limiter == nil || lockout.Threshold <= 0 {
+		return 0, nil
+	}
+
+	now := clock.Now(limiter.Clock)
+
+	lockedUntil, err := limiter.Backend.LockedUntil(ctx, lockout.Name+":"+key, now)
+
+	if err != nil || !lockedUntil.After(now) {
+		return 0, err
+	}
+
+	return lockedUntil.Sub(now), nil
+}
+
+func (limiter *Limiter) Fail(
+	ctx context.Context,
+	lockout Lockout,
+	key string,
+) (time.Duration, error) {
+	if limiter == nil || lockout.Threshold <= 0 {
+		return 0, nil
+	}
+
+	now := clock.Now(limiter.Clock)
+
+	lockedUntil, err := limiter.Backend.Fail(ctx, lockout.Name+":"+key, lockout, now)
+
+	if err != nil || !lockedUntil.After(now) {
+		return 0, err
+	}
+
+	return lockedUntil.Sub(now), nil
+}
+
+func (limiter *Limiter) Clear(
+	ctx context.Context,
+	lockout Lockout,
+	key string,
+) error {
+	if limiter == nil || lockout.Threshold <= 0 {
+		return nil
+	}
+
+	return limiter.Backend.Clear(ctx, lockout.Name+":"+key)
+}
+
+func SetHeaders(
+	header http.Header,
+	result *Result,
+) {
+	if result.Limit > 0 {
+		header.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+		header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		header.Set("RateLimit-Reset", strconv.FormatInt(seconds(result.Reset, 0), 10))
+	}
+
+	if !result.Allowed {
+		header.Set("Retry-After", strconv.FormatInt(seconds(result.RetryAfter, 1), 10))
+	}
+}
+
+func seconds(
+	duration time.Duration,
+	minimum int64,
+) int64 {
+	value := int64(math.Ceil(duration.Seconds()))
+
+	if value < minimum {
+		return minimum
+	}
+
+	return value
+}
@@ -0,0 +1,160 @@
+package ratelimit
+
+import (
+	"math"
+	"time"
+)
+
+type WindowState struct {
+	Start    time.Time
+	Current  int
+	Previous int
+}
+
+func (state *WindowState) Take(
+	rule Rule,
+	now time.Time,
+) *Result {
+	windowStart := now.Truncate(rule.Window)
+
+	switch windowStart.Sub(state.Start) {
+	case 0:
+	case rule.Window:
+		state.Previous, state.Current = state.Current, 0
+	default:
+		state.Previous, state.Current = 0, 0
+	}
+
+	state.Start = windowStart
+
+	windowEnd := windowStart.Add(rule.Window)
+	weight := float64(windowEnd.Sub(now)) / float64(rule.Window)
+	estimate := float64(state.Previous)*weight + float64(state.Current)
+
+	if estimate+1 > float64(rule.Limit) {
+		var retryAt time.Time
+
+		if state.Current+1 > rule.Limit {
+			elapsed := 1 - float64(rule.Limit-1)/float64(state.Current)
+
+			retryAt = windowEnd.Add(time.Duration(elapsed * float64(rule.Window)))
+		} else {
+			elapsed := 1 - float64(rule.Limit-1-state.Current)/float64(state.Previous)
+
+			retryAt = windowStart.Add(time.Duration(elapsed * float64(rule.Window)))
+		}
+
+		return &Result{
+			Limit:      rule.Limit,
+			Reset:      windowEnd.Sub(now),
+			RetryAfter: retryAt.Sub(now),
+		}
+	}
+
+	state.Current++
+
+	return &Result{
+		Allowed:   true,
+		Limit:     rule.Limit,
+		Remaining: max(rule.Limit-int(math.Ceil(estimate+1)), 0),
+		Reset:     windowEnd.Sub(now),
+	}
+}
+
+func (state *WindowState) ExpiresAt(
+	rule Rule,
+) time.Time {
+	return state.Start.Add(2 * rule.Window)
+}
+
+type BucketState struct {
+	Tokens  float64
+	Updated time.Time
+}
+
+func (state *BucketState) Take(
+	rule Rule,
+	now time.Time,
+) *Result {
+	perToken := float64(rule.Window) / float64(rule.Limit)
+
+	if state.Updated.IsZero() {
+		state.Tokens = float64(rule.Limit)
+	} else if elapsed := now.Sub(state.Updated); elapsed > 0 {
+		state.Tokens = math.Min(float64(rule.Limit), state.Tokens+float64(elapsed)/perToken)
+	}
+
+	state.Updated = now
+
+	if state.Tokens < 1 {
+		return &Result{
+			Limit:      rule.Limit,
+			Reset:      time.Duration((float64(rule.Limit) - state.Tokens) * perToken),
+			RetryAfter: time.Duration((1 - state.Tokens) * perToken),
+		}
+	}
+
+	state.Tokens--
+
+	return &Result{
+		Allowed:   true,
+		Limit:     rule.Limit,
+		Remaining: int(state.Tokens),
+		Reset:     time.Duration((float64(rule.Limit) - state.Tokens) * perToken),
+	}
+}
+
+func (state *BucketState) ExpiresAt(
+	rule Rule,
+) time.Time {
+	return state.Updated.Add(rule.Window)
+}
+
+type LockoutState struct {
+	Failures    int
+	Lockouts    int
+	LastFailure time.Time
+	LockedUntil time.Time
+}
+
+func (state *LockoutState) Fail(
+	lockout Lockout,
+	now time.Time,
+) time.Time {
+	if now.Before(state.LockedUntil) {
+		return state.LockedUntil
+	}
+
+	if now.After(state.ExpiresAt(lockout)) {
+		*state = LockoutState{}
+	}
+
+	state.Failures++
+	state.LastFailure = now
+
+	if state.Failures < lockout.Threshold {
+		return time.Time{}
+	}
+
+	duration := lockout.Duration
+
+	for lockouts := 0; lockouts < state.Lockouts && duration < lockout.MaxDuration; lockouts++ {
+		duration *= 2
+	}
+
+	state.Failures = 0
+	state.Lockouts++
+	state.LockedUntil = now.Add(min(duration, lockout.MaxDuration))
+
+	return state.LockedUntil
+}
+
+func (state *LockoutState) ExpiresAt(
+	lockout Lockout,
+) time.Time {
+	if state.LockedUntil.After(state.LastFailure) {
+		return state.LockedUntil.Add(lockout.Window)
+	}
+
+	return state.LastFailure.Add(lockout.Window)
+}
@@ -0,0 +1,106 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+var start = time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+func TestWindowStateSlides(t *testing.T) {
+	rule := Rule{Algorithm: SlidingWindow, Limit: 4, Window: time.Minute}
+
+	state := &WindowState{}
+
+	for attempt := 0; attempt < 4; attempt++ {
+		if result := state.Take(rule, start); !result.Allowed || result.Remaining != 3-attempt {
+			t.Fatalf("attempt %v: unexpected result %+v", attempt, result)
+		}
+	}
+
+	result := state.Take(rule, start.Add(30*time.Second))
+
+	if result.Allowed || result.RetryAfter != 45*time.Second || result.Reset != 30*time.Second {
+		t.Fatalf("expected a full window to be rejected until the previous one slides out, got %+v", result)
+	}
+
+	if result := state.Take(rule, start.Add(90*time.Second)); !result.Allowed {
+		t.Fatalf("expected half the previous window to have slid out, got %+v", result)
+	}
+
+	if result := state.Take(rule, start.Add(90*time.Second)); !result.Allowed {
+		t.Fatalf("expected a second slot, got %+v", result)
+	}
+
+	if result := state.Take(rule, start.Add(90*time.Second)); result.Allowed {
+		t.Fatalf("expected the weighted count to reach the limit, got %+v", result)
+	}
+
+	if result := state.Take(rule, start.Add(5*time.Minute)); !result.Allowed || result.Remaining != 3 {
+		t.Fatalf("expected an idle key to start over, got %+v", result)
+	}
+}
+
+func TestBucketStateRefills(t *testing.T) {
+	rule := Rule{Algorithm: TokenBucket, Limit: 3, Window: 3 * time.Minute}
+
+	state := &BucketState{}
+
+	for attempt := 0; attempt < 3; attempt++ {
+		if result := state.Take(rule, start); !result.Allowed || result.Remaining != 2-attempt {
+			t.Fatalf("attempt %v: unexpected result %+v", attempt, result)
+		}
+	}
+
+	result := state.Take(rule, start.Add(30*time.Second))
+
+	if result.Allowed || result.RetryAfter != 30*time.Second || result.Reset != 150*time.Second {
+		t.Fatalf("expected an empty bucket to wait for the next token, got %+v", result)
+	}
+
+	if result := state.Take(rule, start.Add(time.Minute)); !result.Allowed || result.Remaining != 0 {
+		t.Fatalf("expected one token after a minute, got %+v", result)
+	}
+
+	if result := state.Take(rule, start.Add(time.Hour)); !result.Allowed || result.Remaining != 2 {
+		t.Fatalf("expected the bucket to refill up to its limit, got %+v", result)
+	}
+}
+
+func TestLockoutStateEscalates(t *testing.T) {
+	lockout := Lockout{Threshold: 3, Window: 15 * time.Minute, Duration: time.Minute, MaxDuration: 3 * time.Minute}
+
+	state := &LockoutState{}
+
+	now := start
+
+	for _, expected := range []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute, 3 * time.Minute} {
+		for attempt := 1; attempt < lockout.Threshold; attempt++ {
+			if lockedUntil := state.Fail(lockout, now); !lockedUntil.IsZero() {
+				t.Fatalf("expected failure %v to stay under the threshold", attempt)
+			}
+		}
+
+		lockedUntil := state.Fail(lockout, now)
+
+		if lockedUntil.Sub(now) != expected {
+			t.Fatalf("expected a %v lockout, got %v", expected, lockedUntil.Sub(now))
+		}
+
+		if state.Fail(lockout, now.Add(time.Second)) != lockedUntil {
+			t.Fatal("expected failures during a lockout to leave it unchanged")
+		}
+
+		now = lockedUntil
+	}
+
+	now = now.Add(lockout.Window + time.Second)
+
+	for attempt := 1; attempt < lockout.Threshold; attempt++ {
+		state.Fail(lockout, now)
+	}
+
+	if lockedUntil := state.Fail(lockout, now); lockedUntil.Sub(now) != time.Minute {
+		t.Fatalf("expected a quiet window to reset the escalation, got %v", lockedUntil.Sub(now))
+	}
+}
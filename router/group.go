@@ -0,0 +1,61 @@
+package router
+
+import (
+	"net/http"
+	"strings"
+)
+
+type Group struct {
+	router      *Router
+	prefix      string
+	middlewares []Middleware
+}
+
+func (group *Group) join(
+	path string,
+) string {
+	path = strings.Trim(path, "/")
+
+	if path == "" {
+		return group.prefix
+	}
+
+	return strings.TrimSuffix(group.prefix, "/") + "/" + path
+}
+
+func (group *Group) Handle(
+	pattern string,
+	handler http.Handler,
+	middlewares ...Middleware,
+) {
+	method, path := parsePattern(pattern)
+
+	if method != "" {
+		method += " "
+	}
+
+	group.router.Handle(
+		method+group.join(path),
+		handler,
+		append(append([]Middleware{}, group.middlewares...), middlewares...)...,
+	)
+}
+
+func (group *Group) HandleFunc(
+	pattern string,
+	handler http.HandlerFunc,
+	middlewares ...Middleware,
+) {
+	group.Handle(pattern, handler, middlewares...)
+}
+
+func (group *Group) Group(
+	prefix string,
+	middlewares ...Middleware,
+) *Group {
+	return &Group{
+		router:      group.router,
+		prefix:      group.join(prefix),
+		middlewares: append(append([]Middleware{}, group.middlewares...), middlewares...),
+	}
+}
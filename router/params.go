@@ -0,0 +1,58 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+)
+
+type paramsKey struct{}
+
+type matchedRouteKey struct{}
+
+type matchedRoute struct {
+	path string
+}
+
+func withParams(
+	request *http.Request,
+	params map[string]string,
+) *http.Request {
+	return request.WithContext(
+		context.WithValue(request.Context(), paramsKey{}, params),
+	)
+}
+
+func Params(
+	request *http.Request,
+) map[string]string {
+	params, _ := request.Context().Value(paramsKey{}).(map[string]string)
+
+	return params
+}
+
+func Param(
+	request *http.Request,
+	name string,
+) string {
+	return Params(request)[name]
+}
+
+func ParamInt(
+	request *http.Request,
+	name string,
+) (int64, error) {
+	return strconv.ParseInt(Param(request, name), 10, 64)
+}
+
+func RoutePattern(
+	request *http.Request,
+) string {
+	matched, _ := request.Context().Value(matchedRouteKey{}).(*matchedRoute)
+
+	if matched == nil {
+		return ""
+	}
+
+	return matched.path
+}
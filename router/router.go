@@ -0,0 +1,213 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/sandromai/go-http-server/utils"
+)
+
+type Middleware func(http.Handler) http.Handler
+
+type route struct {
+	method   string
+	path     string
+	segments []string
+	handler  http.Handler
+}
+
+type Router struct {
+	routes      []*route
+	middlewares []Middleware
+}
+
+func splitPath(
+	path string,
+) []string {
+	path = strings.Trim(path, "/")
+
+	if path == "" {
+		return []string{}
+	}
+
+	return strings.Split(path, "/")
+}
+
+func parsePattern(
+	pattern string,
+) (
+	method string,
+	path string,
+) {
+	pattern = strings.TrimSpace(pattern)
+
+	if method, path, found := strings.Cut(pattern, " "); found {
+		return strings.ToUpper(method), strings.TrimSpace(path)
+	}
+
+	return "", pattern
+}
+
+func chain(
+	handler http.Handler,
+	middlewares []Middleware,
+) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+
+	return handler
+}
+
+func (r *route) match(
+	pathSegments []string,
+) (map[string]string, bool) {
+	if len(pathSegments) != len(r.segments) {
+		return nil, false
+	}
+
+	var params map[string]string
+
+	for i, segment := range r.segments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			if pathSegments[i] == "" {
+				return nil, false
+			}
+
+			if params == nil {
+				params = map[string]string{}
+			}
+
+			params[segment[1:len(segment)-1]] = pathSegments[i]
+
+			continue
+		}
+
+		if segment != pathSegments[i] {
+			return nil, false
+		}
+	}
+
+	return params, true
+}
+
+func (r *route) allows(
+	method string,
+) bool {
+	return r.method == "" || r.method == method || (r.method == http.MethodGet && method == http.MethodHead)
+}
+
+func (router *Router) Use(
+	middlewares ...Middleware,
+) {
+	router.middlewares = append(router.middlewares, middlewares...)
+}
+
+func (router *Router) Handle(
+	pattern string,
+	handler http.Handler,
+	middlewares ...Middleware,
+) {
+	method, path := parsePattern(pattern)
+
+	segments := splitPath(path)
+
+	router.routes = append(router.routes, &route{
+		method:   method,
+		path:     "/" + strings.Join(segments, "/"),
+		segments: segments,
+		handler:  chain(handler, middlewares),
+	})
+}
+
+func (router *Router) HandleFunc(
+	pattern string,
+	handler http.HandlerFunc,
+	middlewares ...Middleware,
+) {
+	router.Handle(pattern, handler, middlewares...)
+}
+
+func (router *Router) Group(
+	prefix string,
+	middlewares ...Middleware,
+) *Group {
+	return &Group{
+		router:      router,
+		prefix:      "/" + strings.Trim(prefix, "/"),
+		middlewares: middlewares,
+	}
+}
+
+func (router *Router) dispatch(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	pathSegments := splitPath(request.URL.Path)
+
+	allowedMethods := map[string]bool{}
+
+	for _, route := range router.routes {
+		params, matched := route.match(pathSegments)
+
+		if !matched {
+			continue
+		}
+
+		if !route.allows(request.Method) {
+			allowedMethods[route.method] = true
+
+			if route.method == http.MethodGet {
+				allowedMethods[http.MethodHead] = true
+			}
+
+			continue
+		}
+
+		if params != nil {
+			request = withParams(request, params)
+		}
+
+		if matched, ok := request.Context().Value(matchedRouteKey{}).(*matchedRoute); ok {
+			matched.path = route.path
+		}
+
+		route.handler.ServeHTTP(writer, request)
+
+		return
+	}
+
+	if len(allowedMethods) > 0 {
+		methods := make([]string, 0, len(allowedMethods))
+
+		for method := range allowedMethods {
+			methods = append(methods, method)
+		}
+
+		sort.Strings(methods)
+
+		writer.Header().Set("Allow", strings.Join(methods, ", "))
+
+		utils.ReturnJSONResponse(writer, 405, nil)
+
+		return
+	}
+
+	utils.ReturnJSONResponse(writer, 404, nil)
+}
+
+func (router *Router) ServeHTTP(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	request = request.WithContext(
+		context.WithValue(request.Context(), matchedRouteKey{}, &matchedRoute{}),
+	)
+
+	chain(
+		http.HandlerFunc(router.dispatch),
+		router.middlewares,
+	).ServeHTTP(writer, request)
+}
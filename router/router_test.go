@@ -0,0 +1,194 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func respond(
+	body string,
+) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		writer.Write([]byte(body + " " + RoutePattern(request) + " " + Param(request, "id") + Param(request, "name")))
+	}
+}
+
+func serve(
+	handler http.Handler,
+	method,
+	path string,
+) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+
+	handler.ServeHTTP(recorder, httptest.NewRequest(method, path, nil))
+
+	return recorder
+}
+
+func TestRouterParams(t *testing.T) {
+	router := &Router{}
+
+	router.HandleFunc("GET /users/{id}", respond("user"))
+	router.HandleFunc("GET /users/{id}/files/{name}", respond("file"))
+
+	if body := serve(router, "GET", "/users/42").Body.String(); body != "user /users/{id} 42" {
+		t.Fatalf("unexpected body %q", body)
+	}
+
+	if body := serve(router, "GET", "/users/42/files/report.pdf/").Body.String(); body != "file /users/{id}/files/{name} 42report.pdf" {
+		t.Fatalf("unexpected body %q", body)
+	}
+
+	if code := serve(router, "GET", "/users//files/report.pdf").Code; code != 404 {
+		t.Fatalf("expected an empty parameter not to match, got %v", code)
+	}
+
+	if code := serve(router, "GET", "/users/42/extra").Code; code != 404 {
+		t.Fatalf("expected extra segments not to match, got %v", code)
+	}
+
+	if value, err := ParamInt(httptest.NewRequest("GET", "/", nil), "id"); err == nil {
+		t.Fatalf("expected a missing parameter not to parse, got %v", value)
+	}
+}
+
+func TestRouterFirstMatchWins(t *testing.T) {
+	router := &Router{}
+
+	router.HandleFunc("GET /users/me", respond("me"))
+	router.HandleFunc("GET /users/{id}", respond("user"))
+	router.HandleFunc("/users/{id}", respond("any"))
+
+	tests := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{"GET", "/users/me", "me /users/me "},
+		{"GET", "/users/7", "user /users/{id} 7"},
+		{"DELETE", "/users/7", "any /users/{id} 7"},
+	}
+
+	for _, test := range tests {
+		if body := serve(router, test.method, test.path).Body.String(); body != test.body {
+			t.Fatalf("%v %v: got %q, expected %q", test.method, test.path, body, test.body)
+		}
+	}
+}
+
+func TestRouterGroupsAndMiddlewareOrder(t *testing.T) {
+	var calls []string
+
+	trace := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				calls = append(calls, name+":"+RoutePattern(request))
+
+				next.ServeHTTP(writer, request)
+			})
+		}
+	}
+
+	router := &Router{}
+
+	router.Use(trace("global-1"), trace("global-2"))
+
+	api := router.Group("/api/", trace("api"))
+	admins := api.Group("admins", trace("admins"))
+
+	admins.HandleFunc("GET /{id}", respond("admin"), trace("route"))
+	admins.HandleFunc("POST /", respond("create"))
+
+	if body := serve(router, "GET", "/api/admins/3").Body.String(); body != "admin /api/admins/{id} 3" {
+		t.Fatalf("unexpected body %q", body)
+	}
+
+	expected := "global-1:,global-2:,api:/api/admins/{id},admins:/api/admins/{id},route:/api/admins/{id}"
+
+	if strings.Join(calls, ",") != expected {
+		t.Fatalf("got middleware order %v, expected %v", calls, expected)
+	}
+
+	calls = nil
+
+	if body := serve(router, "POST", "/api/admins").Body.String(); body != "create /api/admins " {
+		t.Fatalf("unexpected body %q", body)
+	}
+
+	if len(calls) != 4 {
+		t.Fatalf("expected route middlewares to stay on their route, got %v", calls)
+	}
+
+	calls = nil
+
+	if code := serve(router, "GET", "/api/admins/3/roles").Code; code != 404 || len(calls) != 2 {
+		t.Fatalf("expected only global middlewares around a 404, got %v %v", code, calls)
+	}
+}
+
+func TestRouterNotFoundAndMethodNotAllowed(t *testing.T) {
+	router := &Router{}
+
+	router.HandleFunc("GET /items/{id}", respond("get"))
+	router.HandleFunc("PUT /items/{id}", respond("put"))
+	router.HandleFunc("PUT /items/{id}", respond("put again"))
+	router.HandleFunc("DELETE /items/{id}", respond("delete"))
+	router.HandleFunc("POST /items", respond("create"))
+
+	if code := serve(router, "GET", "/missing").Code; code != 404 {
+		t.Fatalf("expected 404, got %v", code)
+	}
+
+	response := serve(router, "PATCH", "/items/1")
+
+	if response.Code != 405 || response.Header().Get("Allow") != "DELETE, GET, HEAD, PUT" {
+		t.Fatalf("expected 405 with a deduplicated Allow header, got %v %q", response.Code, response.Header().Get("Allow"))
+	}
+
+	if response := serve(router, "GET", "/items"); response.Code != 405 || response.Header().Get("Allow") != "POST" {
+		t.Fatalf("expected 405 allowing POST, got %v %q", response.Code, response.Header().Get("Allow"))
+	}
+}
+
+func TestRouterAnswersHeadWithGetRoutes(t *testing.T) {
+	router := &Router{}
+
+	router.HandleFunc("GET /health", respond("ok"))
+	router.HandleFunc("POST /jobs", respond("job"))
+
+	server := httptest.NewServer(router)
+
+	defer server.Close()
+
+	response, err := http.Head(server.URL + "/health")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response.Body.Close()
+
+	if response.StatusCode != 200 || response.ContentLength != int64(len("ok /health ")) {
+		t.Fatalf("expected HEAD to run the GET route, got %v %v", response.StatusCode, response.ContentLength)
+	}
+
+	request, err := http.NewRequest("HEAD", server.URL+"/jobs", nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err = http.DefaultClient.Do(request)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response.Body.Close()
+
+	if response.StatusCode != 405 || response.Header.Get("Allow") != "POST" {
+		t.Fatalf("expected HEAD on a POST route to be rejected, got %v %q", response.StatusCode, response.Header.Get("Allow"))
+	}
+}
@@ -0,0 +1,109 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/sandromai/go-http-server/middlewares"
+	"github.com/sandromai/go-http-server/models"
+	"github.com/sandromai/go-http-server/router"
+	"github.com/sandromai/go-http-server/types"
+	"github.com/sandromai/go-http-server/utils"
+)
+
+type AdminToken struct {
+	Repositories *models.Repositories
+}
+
+func (a *AdminToken) List(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	admin := middlewares.AuthenticatedAdmin(request)
+
+	adminTokens, appErr := a.Repositories.AdminTokens.ListByAdmin(
+		request.Context(),
+		admin.Id,
+	)
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	utils.ReturnJSONResponse(
+		writer,
+		200,
+		&struct {
+			CurrentAdminTokenId string              `json:"currentAdminTokenId"`
+			AdminTokens         []*types.AdminToken `json:"adminTokens"`
+		}{
+			CurrentAdminTokenId: middlewares.AuthenticatedAdminToken(request).Id,
+			AdminTokens:         adminTokens,
+		},
+	)
+}
+
+func (a *AdminToken) Disconnect(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	admin := middlewares.AuthenticatedAdmin(request)
+
+	adminTokenId := router.Param(request, "id")
+
+	adminTokenModel := a.Repositories.AdminTokens
+
+	adminToken, appErr := adminTokenModel.FindById(
+		request.Context(),
+		adminTokenId,
+	)
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	if admin.Id != adminToken.AdminId {
+		utils.ReturnJSONResponse(writer, 403, &types.ReturnError{
+			Error: "Unauthorized action.",
+		})
+
+		return
+	}
+
+	if adminToken.Disconnected {
+		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
+			Error: "This token was already disconnected.",
+		})
+
+		return
+	}
+
+	appErr = adminTokenModel.Disconnect(request.Context(), adminToken.Id)
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	utils.ReturnJSONResponse(
+		writer,
+		200,
+		nil,
+	)
+}
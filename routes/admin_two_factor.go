@@ -0,0 +1,319 @@
+package routes
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/skip2/go-qrcode"
+
+	"github.com/sandromai/go-http-server/clock"
+	"github.com/sandromai/go-http-server/middlewares"
+	"github.com/sandromai/go-http-server/models"
+	"github.com/sandromai/go-http-server/totp"
+	"github.com/sandromai/go-http-server/types"
+	"github.com/sandromai/go-http-server/utils"
+)
+
+type AdminTwoFactor struct {
+	Repositories *models.Repositories
+	Issuer       string
+	Clock        clock.Clock
+}
+
+func (a *AdminTwoFactor) now() time.Time {
+	return clock.Now(a.Clock)
+}
+
+func (a *AdminTwoFactor) checkCode(
+	ctx context.Context,
+	adminId,
+	code string,
+) (
+	*types.AdminTwoFactor,
+	*types.AppError,
+) {
+	twoFactorModel := a.Repositories.AdminTwoFactors
+
+	twoFactor, appErr := twoFactorModel.FindByAdmin(ctx, adminId)
+
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	step, valid := totp.Validate(
+		twoFactor.Secret,
+		strings.TrimSpace(code),
+		a.now(),
+		1,
+	)
+
+	if !valid {
+		return nil, &types.AppError{
+			StatusCode: 400,
+			Message:    "Invalid authentication code.",
+		}
+	}
+
+	used, appErr := twoFactorModel.UseStep(
+		ctx,
+		twoFactor.AdminId,
+		step,
+	)
+
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if !used {
+		return nil, &types.AppError{
+			StatusCode: 400,
+			Message:    "Invalid authentication code.",
+		}
+	}
+
+	return twoFactor, nil
+}
+
+func (a *AdminTwoFactor) Enroll(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	admin := middlewares.AuthenticatedAdmin(request)
+
+	if admin.TwoFactorEnabled {
+		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
+			Error: "Two-factor authentication is already enabled.",
+		})
+
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+
+	if err != nil {
+		utils.ReturnJSONResponse(writer, 500, &types.ReturnError{
+			Error: "Failed to generate secret.",
+		})
+
+		return
+	}
+
+	appErr := a.Repositories.AdminTwoFactors.Enroll(
+		request.Context(),
+		admin.Id,
+		secret,
+	)
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	uri := totp.URI(a.Issuer, admin.Username, secret)
+
+	qrCode, err := qrcode.Encode(uri, qrcode.Medium, 256)
+
+	if err != nil {
+		utils.ReturnJSONResponse(writer, 500, &types.ReturnError{
+			Error: "Failed to generate QR code.",
+		})
+
+		return
+	}
+
+	utils.ReturnJSONResponse(
+		writer,
+		200,
+		&struct {
+			Secret string `json:"secret"`
+			URI    string `json:"uri"`
+			QRCode string `json:"qrCode"`
+		}{
+			Secret: secret,
+			URI:    uri,
+			QRCode: "data:image/png;base64," + base64.StdEncoding.EncodeToString(qrCode),
+		},
+	)
+}
+
+func (a *AdminTwoFactor) Confirm(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	admin := middlewares.AuthenticatedAdmin(request)
+
+	if admin.TwoFactorEnabled {
+		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
+			Error: "Two-factor authentication is already enabled.",
+		})
+
+		return
+	}
+
+	var body *struct {
+		Code string `json:"code"`
+	}
+
+	err := json.NewDecoder(request.Body).Decode(&body)
+
+	if err == io.EOF {
+		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
+			Error: "Insert the authentication code.",
+		})
+
+		return
+	}
+
+	if err != nil {
+		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
+			Error: "Invalid data.",
+		})
+
+		return
+	}
+
+	if strings.TrimSpace(body.Code) == "" {
+		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
+			Error: "Insert the authentication code.",
+		})
+
+		return
+	}
+
+	_, appErr := a.checkCode(request.Context(), admin.Id, body.Code)
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	recoveryCodes, err := totp.GenerateRecoveryCodes(10)
+
+	if err != nil {
+		utils.ReturnJSONResponse(writer, 500, &types.ReturnError{
+			Error: "Failed to generate recovery codes.",
+		})
+
+		return
+	}
+
+	recoveryCodeHashes := make([]string, 0, len(recoveryCodes))
+
+	for _, recoveryCode := range recoveryCodes {
+		recoveryCodeHashes = append(recoveryCodeHashes, totp.HashRecoveryCode(recoveryCode))
+	}
+
+	appErr = a.Repositories.AdminTwoFactors.Enable(
+		request.Context(),
+		admin.Id,
+		recoveryCodeHashes,
+	)
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	utils.ReturnJSONResponse(
+		writer,
+		200,
+		&struct {
+			RecoveryCodes []string `json:"recoveryCodes"`
+		}{RecoveryCodes: recoveryCodes},
+	)
+}
+
+func (a *AdminTwoFactor) Disable(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	admin := middlewares.AuthenticatedAdmin(request)
+
+	if !admin.TwoFactorEnabled {
+		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
+			Error: "Two-factor authentication is not enabled.",
+		})
+
+		return
+	}
+
+	var body *struct {
+		Code string `json:"code"`
+	}
+
+	err := json.NewDecoder(request.Body).Decode(&body)
+
+	if err == io.EOF {
+		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
+			Error: "Insert the authentication code.",
+		})
+
+		return
+	}
+
+	if err != nil {
+		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
+			Error: "Invalid data.",
+		})
+
+		return
+	}
+
+	if strings.TrimSpace(body.Code) == "" {
+		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
+			Error: "Insert the authentication code.",
+		})
+
+		return
+	}
+
+	_, appErr := a.checkCode(request.Context(), admin.Id, body.Code)
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	appErr = a.Repositories.AdminTwoFactors.Disable(request.Context(), admin.Id)
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	utils.ReturnJSONResponse(
+		writer,
+		200,
+		nil,
+	)
+}
@@ -4,26 +4,82 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/sandromai/go-http-server/clock"
+	"github.com/sandromai/go-http-server/config"
+	"github.com/sandromai/go-http-server/metrics"
 	"github.com/sandromai/go-http-server/middlewares"
 	"github.com/sandromai/go-http-server/models"
+	"github.com/sandromai/go-http-server/ratelimit"
+	"github.com/sandromai/go-http-server/router"
+	"github.com/sandromai/go-http-server/token"
+	"github.com/sandromai/go-http-server/totp"
 	"github.com/sandromai/go-http-server/types"
 	"github.com/sandromai/go-http-server/utils"
 )
 
-type Admin struct{}
+type Admin struct {
+	Repositories     *models.Repositories
+	Tokens           *token.Engine
+	Lifetimes        *config.Lifetimes
+	Clock            clock.Clock
+	Metrics          *metrics.App
+	RateLimit        *middlewares.RateLimit
+	PasswordLockout  ratelimit.Lockout
+	TwoFactorLockout ratelimit.Lockout
+}
 
-func (*Admin) Login(
-	writer http.ResponseWriter,
+func (a *Admin) now() time.Time {
+	return clock.Now(a.Clock)
+}
+
+func (a *Admin) startSession(
 	request *http.Request,
-) {
-	if request.Method != "POST" {
-		utils.ReturnJSONResponse(writer, 405, nil)
+	adminId string,
+	rememberMe bool,
+) (string, *types.AppError) {
+	expiresIn := int64(a.Lifetimes.AdminSession.Seconds())
 
-		return
+	if rememberMe {
+		expiresIn = int64(a.Lifetimes.AdminSessionRemember.Seconds())
+	}
+
+	ipAddress := utils.GetRemoteIP(request)
+	platform, browser := utils.GetDeviceInfo(request.Header.Get("User-Agent"))
+
+	var device string
+
+	if platform != "" && browser != "" {
+		device = platform + ":" + browser
+	}
+
+	adminTokenId, appErr := a.Repositories.AdminTokens.Create(
+		request.Context(),
+		adminId,
+		ipAddress,
+		device,
+		expiresIn,
+	)
+
+	if appErr != nil {
+		return "", appErr
 	}
 
+	return (&types.AdminTokenPayload{
+		RegisteredClaims: token.RegisteredClaims{
+			ExpiresAt: a.now().Add(time.Duration(expiresIn) * time.Second).Unix(),
+			IssuedAt:  a.now().Unix(),
+		},
+		AdminTokenId: adminTokenId,
+	}).ToJWT(a.Tokens)
+}
+
+func (a *Admin) Login(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
 	var body *struct {
 		Username   string `json:"username"`
 		Password   string `json:"password"`
@@ -64,12 +120,25 @@ func (*Admin) Login(
 		return
 	}
 
-	admin, appErr := (&models.Admin{}).Authenticate(
+	lockoutKey := strings.ToLower(strings.TrimSpace(body.Username))
+
+	if a.RateLimit.Locked(writer, request, a.PasswordLockout, lockoutKey) {
+		return
+	}
+
+	admin, appErr := a.Repositories.Admins.Authenticate(
+		request.Context(),
 		body.Username,
 		body.Password,
 	)
 
 	if appErr != nil {
+		if appErr.StatusCode == 401 {
+			a.Metrics.AdminLoginFailures.Inc("password")
+
+			a.RateLimit.Fail(request, a.PasswordLockout, lockoutKey)
+		}
+
 		utils.ReturnJSONResponse(
 			writer,
 			appErr.StatusCode,
@@ -79,19 +148,45 @@ func (*Admin) Login(
 		return
 	}
 
-	var expiredAt int64
+	a.RateLimit.Clear(request, a.PasswordLockout, lockoutKey)
+
+	if admin.TwoFactorEnabled {
+		mfaToken, appErr := (&types.MFATokenPayload{
+			RegisteredClaims: token.RegisteredClaims{
+				ExpiresAt: a.now().Add(a.Lifetimes.AdminTwoFactor).Unix(),
+				IssuedAt:  a.now().Unix(),
+			},
+			AdminId:    admin.Id,
+			RememberMe: body.RememberMe,
+		}).ToJWT(a.Tokens)
+
+		if appErr != nil {
+			utils.ReturnJSONResponse(
+				writer,
+				appErr.StatusCode,
+				&types.ReturnError{Error: appErr.Message},
+			)
 
-	if body.RememberMe {
-		expiredAt = time.Now().Add(time.Hour * 24 * 30).Unix()
-	} else {
-		expiredAt = time.Now().Add(time.Hour * 24 * 7).Unix()
+			return
+		}
+
+		utils.ReturnJSONResponse(
+			writer,
+			200,
+			&struct {
+				MFARequired bool   `json:"mfaRequired"`
+				MFAToken    string `json:"mfaToken"`
+			}{MFARequired: true, MFAToken: mfaToken},
+		)
+
+		return
 	}
 
-	adminToken, appErr := (&types.AdminTokenPayload{
-		AdminId:   admin.Id,
-		ExpiresAt: expiredAt,
-		CreatedAt: time.Now().Unix(),
-	}).ToJWT()
+	adminToken, appErr := a.startSession(
+		request,
+		admin.Id,
+		body.RememberMe,
+	)
 
 	if appErr != nil {
 		utils.ReturnJSONResponse(
@@ -113,17 +208,149 @@ func (*Admin) Login(
 	)
 }
 
-func (*Admin) Register(
+func (a *Admin) VerifyTwoFactor(
 	writer http.ResponseWriter,
 	request *http.Request,
 ) {
-	if request.Method != "POST" {
-		utils.ReturnJSONResponse(writer, 405, nil)
+	var body *struct {
+		MFAToken     string `json:"mfaToken"`
+		Code         string `json:"code"`
+		RecoveryCode string `json:"recoveryCode"`
+	}
+
+	err := json.NewDecoder(request.Body).Decode(&body)
+
+	if err == io.EOF {
+		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
+			Error: "Authentication token not identified.",
+		})
+
+		return
+	}
+
+	if err != nil {
+		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
+			Error: "Invalid data.",
+		})
+
+		return
+	}
+
+	body.MFAToken = strings.TrimSpace(body.MFAToken)
+	body.Code = strings.TrimSpace(body.Code)
+
+	if body.MFAToken == "" {
+		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
+			Error: "Authentication token not identified.",
+		})
+
+		return
+	}
+
+	if body.Code == "" && body.RecoveryCode == "" {
+		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
+			Error: "Insert the authentication code.",
+		})
+
+		return
+	}
+
+	mfaTokenPayload := &types.MFATokenPayload{}
+
+	appErr := mfaTokenPayload.FromJWT(a.Tokens, body.MFAToken)
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	if a.RateLimit.Locked(writer, request, a.TwoFactorLockout, mfaTokenPayload.AdminId) {
+		return
+	}
+
+	twoFactorModel := a.Repositories.AdminTwoFactors
+
+	twoFactor, appErr := twoFactorModel.FindByAdmin(
+		request.Context(),
+		mfaTokenPayload.AdminId,
+	)
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	if !twoFactor.Enabled {
+		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
+			Error: "Two-factor authentication is not enabled.",
+		})
+
+		return
+	}
+
+	verified := false
+
+	if body.Code != "" {
+		step, valid := totp.Validate(
+			twoFactor.Secret,
+			body.Code,
+			a.now(),
+			1,
+		)
+
+		if valid {
+			verified, appErr = twoFactorModel.UseStep(
+				request.Context(),
+				twoFactor.AdminId,
+				step,
+			)
+		}
+	} else {
+		verified, appErr = twoFactorModel.UseRecoveryCode(
+			request.Context(),
+			twoFactor.AdminId,
+			totp.HashRecoveryCode(body.RecoveryCode),
+		)
+	}
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	if !verified {
+		a.Metrics.AdminLoginFailures.Inc("two_factor")
+
+		a.RateLimit.Fail(request, a.TwoFactorLockout, mfaTokenPayload.AdminId)
+
+		utils.ReturnJSONResponse(writer, 401, &types.ReturnError{
+			Error: "Invalid authentication code.",
+		})
 
 		return
 	}
 
-	admin, appErr := middlewares.AuthenticateAdmin(request)
+	a.RateLimit.Clear(request, a.TwoFactorLockout, mfaTokenPayload.AdminId)
+
+	admin, appErr := a.Repositories.Admins.FindById(
+		request.Context(),
+		twoFactor.AdminId,
+	)
 
 	if appErr != nil {
 		utils.ReturnJSONResponse(
@@ -135,6 +362,38 @@ func (*Admin) Register(
 		return
 	}
 
+	adminToken, appErr := a.startSession(
+		request,
+		admin.Id,
+		mfaTokenPayload.RememberMe,
+	)
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	utils.ReturnJSONResponse(
+		writer,
+		200,
+		&struct {
+			Admin *types.Admin `json:"admin"`
+			Token string       `json:"token"`
+		}{Admin: admin, Token: adminToken},
+	)
+}
+
+func (a *Admin) Register(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	admin := middlewares.AuthenticatedAdmin(request)
+
 	var body *struct {
 		Name            string `json:"name"`
 		Username        string `json:"username"`
@@ -200,9 +459,10 @@ func (*Admin) Register(
 		return
 	}
 
-	adminModel := &models.Admin{}
+	adminModel := a.Repositories.Admins
 
 	adminId, appErr := adminModel.Create(
+		request.Context(),
 		body.Name,
 		body.Username,
 		body.Password,
@@ -219,7 +479,7 @@ func (*Admin) Register(
 		return
 	}
 
-	createdAdmin, appErr := adminModel.FindById(adminId)
+	createdAdmin, appErr := adminModel.FindById(request.Context(), adminId)
 
 	if appErr != nil {
 		utils.ReturnJSONResponse(
@@ -238,27 +498,11 @@ func (*Admin) Register(
 	)
 }
 
-func (*Admin) Update(
+func (a *Admin) Update(
 	writer http.ResponseWriter,
 	request *http.Request,
 ) {
-	if request.Method != "PUT" {
-		utils.ReturnJSONResponse(writer, 405, nil)
-
-		return
-	}
-
-	admin, appErr := middlewares.AuthenticateAdmin(request)
-
-	if appErr != nil {
-		utils.ReturnJSONResponse(
-			writer,
-			appErr.StatusCode,
-			&types.ReturnError{Error: appErr.Message},
-		)
-
-		return
-	}
+	admin := middlewares.AuthenticatedAdmin(request)
 
 	var body *struct {
 		Name            string `json:"name"`
@@ -319,13 +563,113 @@ func (*Admin) Update(
 		}
 	}
 
-	adminModel := &models.Admin{}
+	appErr := a.Repositories.UnitOfWork.Run(
+		request.Context(),
+		func(transaction *models.Repositories) *types.AppError {
+			appErr := transaction.Admins.Update(
+				request.Context(),
+				body.Name,
+				body.Username,
+				body.Password,
+				admin.Id,
+			)
+
+			if appErr != nil || body.Password == "" {
+				return appErr
+			}
+
+			return transaction.AdminTokens.DisconnectAllByAdmin(
+				request.Context(),
+				admin.Id,
+			)
+		},
+	)
 
-	appErr = adminModel.Update(
-		body.Name,
-		body.Username,
-		body.Password,
-		admin.Id,
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	updatedAdmin, appErr := a.Repositories.Admins.FindById(request.Context(), admin.Id)
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	utils.ReturnJSONResponse(
+		writer,
+		200,
+		updatedAdmin,
+	)
+}
+
+func (a *Admin) SetRoles(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	admin := middlewares.AuthenticatedAdmin(request)
+
+	adminId := router.Param(request, "id")
+
+	if adminId == admin.Id {
+		utils.ReturnJSONResponse(writer, 403, &types.ReturnError{
+			Error: "You can't change your own roles.",
+		})
+
+		return
+	}
+
+	var body *struct {
+		Roles []string `json:"roles"`
+	}
+
+	err := json.NewDecoder(request.Body).Decode(&body)
+
+	if err == io.EOF {
+		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
+			Error: "Insert the roles.",
+		})
+
+		return
+	}
+
+	if err != nil {
+		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
+			Error: "Invalid data.",
+		})
+
+		return
+	}
+
+	adminModel := a.Repositories.Admins
+
+	targetAdmin, appErr := adminModel.FindById(request.Context(), adminId)
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	appErr = a.Repositories.Roles.SetAdminRoles(
+		request.Context(),
+		targetAdmin.Id,
+		body.Roles,
 	)
 
 	if appErr != nil {
@@ -338,7 +682,7 @@ func (*Admin) Update(
 		return
 	}
 
-	updatedAdmin, appErr := adminModel.FindById(admin.Id)
+	updatedAdmin, appErr := adminModel.FindById(request.Context(), targetAdmin.Id)
 
 	if appErr != nil {
 		utils.ReturnJSONResponse(
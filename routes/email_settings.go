@@ -5,37 +5,20 @@ import (
 	"io"
 	"net/http"
 
-	"github.com/sandromai/go-http-server/middlewares"
 	"github.com/sandromai/go-http-server/models"
 	"github.com/sandromai/go-http-server/types"
 	"github.com/sandromai/go-http-server/utils"
 )
 
-type EmailSetting struct{}
+type EmailSetting struct {
+	Repositories *models.Repositories
+}
 
-func (*EmailSetting) List(
+func (e *EmailSetting) List(
 	writer http.ResponseWriter,
 	request *http.Request,
 ) {
-	if request.Method != "GET" {
-		utils.ReturnJSONResponse(writer, 405, nil)
-
-		return
-	}
-
-	_, appErr := middlewares.AuthenticateAdmin(request)
-
-	if appErr != nil {
-		utils.ReturnJSONResponse(
-			writer,
-			appErr.StatusCode,
-			&types.ReturnError{Error: appErr.Message},
-		)
-
-		return
-	}
-
-	emailSettings, appErr := (&models.EmailSetting{}).List()
+	emailSettings, appErr := e.Repositories.EmailSettings.List(request.Context())
 
 	if appErr != nil {
 		utils.ReturnJSONResponse(
@@ -54,28 +37,10 @@ func (*EmailSetting) List(
 	)
 }
 
-func (*EmailSetting) Update(
+func (e *EmailSetting) Update(
 	writer http.ResponseWriter,
 	request *http.Request,
 ) {
-	if request.Method != "PUT" {
-		utils.ReturnJSONResponse(writer, 405, nil)
-
-		return
-	}
-
-	_, appErr := middlewares.AuthenticateAdmin(request)
-
-	if appErr != nil {
-		utils.ReturnJSONResponse(
-			writer,
-			appErr.StatusCode,
-			&types.ReturnError{Error: appErr.Message},
-		)
-
-		return
-	}
-
 	var body *struct {
 		Host     string `json:"host"`
 		Port     string `json:"port"`
@@ -125,7 +90,7 @@ func (*EmailSetting) Update(
 		return
 	}
 
-	appErr = (&models.EmailSetting{}).Update(map[string]string{
+	appErr := e.Repositories.EmailSettings.Update(request.Context(), map[string]string{
 		"host":     body.Host,
 		"port":     body.Port,
 		"username": body.Username,
@@ -0,0 +1,56 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/sandromai/go-http-server/models"
+	"github.com/sandromai/go-http-server/utils"
+)
+
+type Health struct {
+	Database models.HealthChecker
+}
+
+type healthStatus struct {
+	Status   string `json:"status"`
+	Database string `json:"database"`
+}
+
+func (h *Health) Live(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	status := &healthStatus{
+		Status:   "ok",
+		Database: "ok",
+	}
+
+	if appErr := h.Database.Ping(request.Context()); appErr != nil {
+		status.Database = "unreachable"
+	}
+
+	writer.Header().Set("Cache-Control", "no-store")
+
+	utils.ReturnJSONResponse(writer, 200, status)
+}
+
+func (h *Health) Ready(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	writer.Header().Set("Cache-Control", "no-store")
+
+	if appErr := h.Database.Ping(request.Context()); appErr != nil {
+		utils.ReturnJSONResponse(writer, 503, &healthStatus{
+			Status:   "unavailable",
+			Database: "unreachable",
+		})
+
+		return
+	}
+
+	utils.ReturnJSONResponse(writer, 200, &healthStatus{
+		Status:   "ok",
+		Database: "ok",
+	})
+}
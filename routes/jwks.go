@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/sandromai/go-http-server/token"
+	"github.com/sandromai/go-http-server/utils"
+)
+
+type JWKS struct {
+	Tokens *token.Engine
+}
+
+func (j *JWKS) List(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	writer.Header().Set("Cache-Control", "public, max-age=300")
+
+	utils.ReturnJSONResponse(
+		writer,
+		200,
+		j.Tokens.JWKS(),
+	)
+}
@@ -7,26 +7,53 @@ import (
 	"strings"
 	"time"
 
+	"github.com/sandromai/go-http-server/clock"
+	"github.com/sandromai/go-http-server/config"
+	"github.com/sandromai/go-http-server/metrics"
+	"github.com/sandromai/go-http-server/middlewares"
 	"github.com/sandromai/go-http-server/models"
+	"github.com/sandromai/go-http-server/ratelimit"
+	"github.com/sandromai/go-http-server/token"
 	"github.com/sandromai/go-http-server/types"
 	"github.com/sandromai/go-http-server/utils"
 )
 
 type LoginToken struct {
-	Template string
-	Timezone *time.Location
+	Repositories *models.Repositories
+	Template     string
+	Tokens       *token.Engine
+	Mail         *config.Mail
+	Lifetimes    *config.Lifetimes
+	NewMailer    func(emailSettings *types.EmailSetting) utils.MailSender
+	Clock        clock.Clock
+	Metrics      *metrics.App
+	RateLimit    *middlewares.RateLimit
+	EmailRule    ratelimit.Rule
+}
+
+func (l *LoginToken) now() time.Time {
+	return clock.Now(l.Clock)
+}
+
+func (l *LoginToken) mailer(
+	emailSettings *types.EmailSetting,
+) utils.MailSender {
+	if l.NewMailer != nil {
+		return l.NewMailer(emailSettings)
+	}
+
+	return &utils.Mailer{
+		Host:     emailSettings.Host,
+		Port:     emailSettings.Port,
+		Username: emailSettings.Username,
+		Password: emailSettings.Password,
+	}
 }
 
 func (l *LoginToken) Create(
 	writer http.ResponseWriter,
 	request *http.Request,
 ) {
-	if request.Method != "POST" {
-		utils.ReturnJSONResponse(writer, 405, nil)
-
-		return
-	}
-
 	var body *struct {
 		Email string `json:"email"`
 	}
@@ -49,7 +76,7 @@ func (l *LoginToken) Create(
 		return
 	}
 
-	body.Email = strings.TrimSpace(body.Email)
+	body.Email = utils.NormalizeEmail(body.Email)
 
 	if body.Email == "" {
 		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
@@ -67,7 +94,11 @@ func (l *LoginToken) Create(
 		return
 	}
 
-	user, _ := (&models.User{}).FindByEmail(body.Email)
+	if !l.RateLimit.Allow(writer, request, l.EmailRule, body.Email) {
+		return
+	}
+
+	user, _ := l.Repositories.Users.FindByEmail(request.Context(), body.Email)
 
 	if user != nil && user.Banned {
 		utils.ReturnJSONResponse(writer, 403, &types.ReturnError{
@@ -77,9 +108,10 @@ func (l *LoginToken) Create(
 		return
 	}
 
-	loginTokenModel := &models.LoginToken{}
+	loginTokenModel := l.Repositories.LoginTokens
 
 	activeTokens, appErr := loginTokenModel.CountActiveByEmail(
+		request.Context(),
 		body.Email,
 	)
 
@@ -94,7 +126,30 @@ func (l *LoginToken) Create(
 	}
 
 	if activeTokens >= 3 {
-		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
+		firstExpirationTime, appErr := loginTokenModel.GetFirstActiveExpirationTimeByEmail(
+			request.Context(),
+			body.Email,
+		)
+
+		if appErr != nil {
+			utils.ReturnJSONResponse(
+				writer,
+				appErr.StatusCode,
+				&types.ReturnError{Error: appErr.Message},
+			)
+
+			return
+		}
+
+		wait := firstExpirationTime.Sub(l.now())
+
+		ratelimit.SetHeaders(writer.Header(), &ratelimit.Result{
+			Limit:      3,
+			Reset:      wait,
+			RetryAfter: wait,
+		})
+
+		utils.ReturnJSONResponse(writer, 429, &types.ReturnError{
 			Error: "You've reached max active tokens, please wait to send new login tokens.",
 		})
 
@@ -102,6 +157,7 @@ func (l *LoginToken) Create(
 	}
 
 	lastTokenCreationTime, appErr := loginTokenModel.GetLastCreationTimeByEmail(
+		request.Context(),
 		body.Email,
 	)
 
@@ -115,31 +171,19 @@ func (l *LoginToken) Create(
 		return
 	}
 
-	if lastTokenCreationTime != "" {
-		lastTokenTime, err := time.ParseInLocation(
-			time.DateTime,
-			lastTokenCreationTime,
-			l.Timezone,
-		)
+	if !lastTokenCreationTime.IsZero() {
+		if wait := l.Lifetimes.LoginTokenResend - l.now().Sub(lastTokenCreationTime); wait > 0 {
+			ratelimit.SetHeaders(writer.Header(), &ratelimit.Result{RetryAfter: wait})
 
-		if err != nil {
-			utils.ReturnJSONResponse(writer, 500, &types.ReturnError{
-				Error: "Error parsing date.",
-			})
-
-			return
-		}
-
-		if time.Since(lastTokenTime).Seconds() < 60 {
-			utils.ReturnJSONResponse(writer, 500, &types.ReturnError{
-				Error: "Wait at least one minute to try again.",
+			utils.ReturnJSONResponse(writer, 429, &types.ReturnError{
+				Error: "Wait before trying again.",
 			})
 
 			return
 		}
 	}
 
-	ipAddress := strings.Split(request.RemoteAddr, ":")[0]
+	ipAddress := utils.GetRemoteIP(request)
 	platform, browser := utils.GetDeviceInfo(request.Header.Get("User-Agent"))
 
 	var device string
@@ -148,9 +192,10 @@ func (l *LoginToken) Create(
 		device = platform + ":" + browser
 	}
 
-	expiresIn := int64(10 * 60)
+	expiresIn := int64(l.Lifetimes.LoginToken.Seconds())
 
 	loginTokenId, appErr := loginTokenModel.Create(
+		request.Context(),
 		body.Email,
 		ipAddress,
 		device,
@@ -167,13 +212,15 @@ func (l *LoginToken) Create(
 		return
 	}
 
-	expiredAt := time.Now().Add(time.Minute * 10).Unix()
+	expiredAt := l.now().Add(l.Lifetimes.LoginToken).Unix()
 
 	loginTokenString, appErr := (&types.LoginTokenPayload{
+		RegisteredClaims: token.RegisteredClaims{
+			ExpiresAt: expiredAt,
+			IssuedAt:  l.now().Unix(),
+		},
 		LoginTokenId: loginTokenId,
-		ExpiresAt:    expiredAt,
-		CreatedAt:    time.Now().Unix(),
-	}).ToJWT()
+	}).ToJWT(l.Tokens)
 
 	if appErr != nil {
 		utils.ReturnJSONResponse(
@@ -185,7 +232,7 @@ func (l *LoginToken) Create(
 		return
 	}
 
-	emailSettings, appErr := (&models.EmailSetting{}).List()
+	emailSettings, appErr := l.Repositories.EmailSettings.List(request.Context())
 
 	if appErr != nil {
 		utils.ReturnJSONResponse(
@@ -201,21 +248,19 @@ func (l *LoginToken) Create(
 
 	emailBody := utils.UseTemplate(l.Template, map[string]string{"ConfirmAuthLink": confirmAuthLink})
 
-	appErr = (&utils.Mailer{
-		Host:     emailSettings.Host,
-		Port:     emailSettings.Port,
-		Username: emailSettings.Username,
-		Password: emailSettings.Password,
-	}).Send(
-		"contact@company.com",
-		"Company",
+	appErr = l.mailer(emailSettings).Send(
+		request.Context(),
+		l.Mail.FromAddress,
+		l.Mail.FromName,
 		body.Email,
 		"",
-		"Log in to Company Website",
+		l.Mail.LoginTokenSubject,
 		emailBody,
 	)
 
 	if appErr != nil {
+		l.Metrics.MailFailures.Inc()
+
 		utils.ReturnJSONResponse(
 			writer,
 			appErr.StatusCode,
@@ -225,6 +270,8 @@ func (l *LoginToken) Create(
 		return
 	}
 
+	l.Metrics.LoginTokens.Inc("created")
+
 	utils.ReturnJSONResponse(
 		writer,
 		200,
@@ -238,12 +285,6 @@ func (l *LoginToken) Check(
 	writer http.ResponseWriter,
 	request *http.Request,
 ) {
-	if request.Method != "POST" {
-		utils.ReturnJSONResponse(writer, 405, nil)
-
-		return
-	}
-
 	var body *struct {
 		Token string `json:"token"`
 	}
@@ -278,7 +319,7 @@ func (l *LoginToken) Check(
 
 	loginTokenPayload := &types.LoginTokenPayload{}
 
-	appErr := loginTokenPayload.FromJWT(body.Token)
+	appErr := loginTokenPayload.FromJWT(l.Tokens, body.Token)
 
 	if appErr != nil {
 		utils.ReturnJSONResponse(
@@ -290,7 +331,8 @@ func (l *LoginToken) Check(
 		return
 	}
 
-	loginToken, appErr := (&models.LoginToken{}).FindById(
+	loginToken, appErr := l.Repositories.LoginTokens.FindById(
+		request.Context(),
 		loginTokenPayload.LoginTokenId,
 	)
 
@@ -304,21 +346,9 @@ func (l *LoginToken) Check(
 		return
 	}
 
-	tokenExpiresAt, err := time.ParseInLocation(
-		time.DateTime,
-		loginToken.ExpiresAt,
-		l.Timezone,
-	)
-
-	if err != nil {
-		utils.ReturnJSONResponse(writer, 500, &types.ReturnError{
-			Error: "Error parsing date.",
-		})
+	if loginToken.ExpiresAt.Before(l.now()) {
+		l.Metrics.LoginTokens.Inc("expired")
 
-		return
-	}
-
-	if tokenExpiresAt.Before(time.Now()) {
 		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
 			Error: "Login token has expired.",
 		})
@@ -326,21 +356,7 @@ func (l *LoginToken) Check(
 		return
 	}
 
-	tokenCreatedAt, err := time.ParseInLocation(
-		time.DateTime,
-		loginToken.CreatedAt,
-		l.Timezone,
-	)
-
-	if err != nil {
-		utils.ReturnJSONResponse(writer, 500, &types.ReturnError{
-			Error: "Error parsing date.",
-		})
-
-		return
-	}
-
-	if tokenCreatedAt.After(time.Now()) {
+	if loginToken.CreatedAt.After(l.now()) {
 		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
 			Error: "Invalid login token date.",
 		})
@@ -375,12 +391,6 @@ func (l *LoginToken) Deny(
 	writer http.ResponseWriter,
 	request *http.Request,
 ) {
-	if request.Method != "POST" {
-		utils.ReturnJSONResponse(writer, 405, nil)
-
-		return
-	}
-
 	var body *struct {
 		Token string `json:"token"`
 	}
@@ -415,7 +425,7 @@ func (l *LoginToken) Deny(
 
 	loginTokenPayload := &types.LoginTokenPayload{}
 
-	appErr := loginTokenPayload.FromJWT(body.Token)
+	appErr := loginTokenPayload.FromJWT(l.Tokens, body.Token)
 
 	if appErr != nil {
 		utils.ReturnJSONResponse(
@@ -427,9 +437,10 @@ func (l *LoginToken) Deny(
 		return
 	}
 
-	loginTokenModel := &models.LoginToken{}
+	loginTokenModel := l.Repositories.LoginTokens
 
 	loginToken, appErr := loginTokenModel.FindById(
+		request.Context(),
 		loginTokenPayload.LoginTokenId,
 	)
 
@@ -443,21 +454,9 @@ func (l *LoginToken) Deny(
 		return
 	}
 
-	tokenExpiresAt, err := time.ParseInLocation(
-		time.DateTime,
-		loginToken.ExpiresAt,
-		l.Timezone,
-	)
-
-	if err != nil {
-		utils.ReturnJSONResponse(writer, 500, &types.ReturnError{
-			Error: "Error parsing date.",
-		})
-
-		return
-	}
+	if loginToken.ExpiresAt.Before(l.now()) {
+		l.Metrics.LoginTokens.Inc("expired")
 
-	if tokenExpiresAt.Before(time.Now()) {
 		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
 			Error: "Login token has expired.",
 		})
@@ -465,21 +464,7 @@ func (l *LoginToken) Deny(
 		return
 	}
 
-	tokenCreatedAt, err := time.ParseInLocation(
-		time.DateTime,
-		loginToken.CreatedAt,
-		l.Timezone,
-	)
-
-	if err != nil {
-		utils.ReturnJSONResponse(writer, 500, &types.ReturnError{
-			Error: "Error parsing date.",
-		})
-
-		return
-	}
-
-	if tokenCreatedAt.After(time.Now()) {
+	if loginToken.CreatedAt.After(l.now()) {
 		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
 			Error: "Invalid login token date.",
 		})
@@ -503,7 +488,7 @@ func (l *LoginToken) Deny(
 		return
 	}
 
-	appErr = loginTokenModel.Deny(loginToken.Id)
+	appErr = loginTokenModel.Deny(request.Context(), loginToken.Id)
 
 	if appErr != nil {
 		utils.ReturnJSONResponse(
@@ -515,6 +500,8 @@ func (l *LoginToken) Deny(
 		return
 	}
 
+	l.Metrics.LoginTokens.Inc("denied")
+
 	utils.ReturnJSONResponse(
 		writer,
 		200,
@@ -526,12 +513,6 @@ func (l *LoginToken) Authorize(
 	writer http.ResponseWriter,
 	request *http.Request,
 ) {
-	if request.Method != "POST" {
-		utils.ReturnJSONResponse(writer, 405, nil)
-
-		return
-	}
-
 	var body *struct {
 		Token string `json:"token"`
 	}
@@ -566,7 +547,7 @@ func (l *LoginToken) Authorize(
 
 	loginTokenPayload := &types.LoginTokenPayload{}
 
-	appErr := loginTokenPayload.FromJWT(body.Token)
+	appErr := loginTokenPayload.FromJWT(l.Tokens, body.Token)
 
 	if appErr != nil {
 		utils.ReturnJSONResponse(
@@ -578,9 +559,10 @@ func (l *LoginToken) Authorize(
 		return
 	}
 
-	loginTokenModel := &models.LoginToken{}
+	loginTokenModel := l.Repositories.LoginTokens
 
 	loginToken, appErr := loginTokenModel.FindById(
+		request.Context(),
 		loginTokenPayload.LoginTokenId,
 	)
 
@@ -594,21 +576,9 @@ func (l *LoginToken) Authorize(
 		return
 	}
 
-	tokenExpiresAt, err := time.ParseInLocation(
-		time.DateTime,
-		loginToken.ExpiresAt,
-		l.Timezone,
-	)
-
-	if err != nil {
-		utils.ReturnJSONResponse(writer, 500, &types.ReturnError{
-			Error: "Error parsing date.",
-		})
-
-		return
-	}
+	if loginToken.ExpiresAt.Before(l.now()) {
+		l.Metrics.LoginTokens.Inc("expired")
 
-	if tokenExpiresAt.Before(time.Now()) {
 		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
 			Error: "Login token has expired.",
 		})
@@ -616,21 +586,7 @@ func (l *LoginToken) Authorize(
 		return
 	}
 
-	tokenCreatedAt, err := time.ParseInLocation(
-		time.DateTime,
-		loginToken.CreatedAt,
-		l.Timezone,
-	)
-
-	if err != nil {
-		utils.ReturnJSONResponse(writer, 500, &types.ReturnError{
-			Error: "Error parsing date.",
-		})
-
-		return
-	}
-
-	if tokenCreatedAt.After(time.Now()) {
+	if loginToken.CreatedAt.After(l.now()) {
 		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
 			Error: "Invalid login token date.",
 		})
@@ -654,7 +610,7 @@ func (l *LoginToken) Authorize(
 		return
 	}
 
-	appErr = loginTokenModel.Authorize(loginToken.Id)
+	appErr = loginTokenModel.Authorize(request.Context(), loginToken.Id)
 
 	if appErr != nil {
 		utils.ReturnJSONResponse(
@@ -666,6 +622,8 @@ func (l *LoginToken) Authorize(
 		return
 	}
 
+	l.Metrics.LoginTokens.Inc("authorized")
+
 	utils.ReturnJSONResponse(
 		writer,
 		200,
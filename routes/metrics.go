@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/sandromai/go-http-server/metrics"
+)
+
+type Metrics struct {
+	Registry *metrics.Registry
+}
+
+func (m *Metrics) List(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	writer.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	writer.WriteHeader(200)
+
+	m.Registry.WriteTo(writer)
+}
@@ -0,0 +1,380 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/sandromai/go-http-server/clock"
+	"github.com/sandromai/go-http-server/config"
+	"github.com/sandromai/go-http-server/models"
+	"github.com/sandromai/go-http-server/oidc"
+	"github.com/sandromai/go-http-server/router"
+	"github.com/sandromai/go-http-server/token"
+	"github.com/sandromai/go-http-server/types"
+	"github.com/sandromai/go-http-server/utils"
+)
+
+type OAuth struct {
+	Repositories *models.Repositories
+	Tokens       *token.Engine
+	Providers    map[string]*oidc.Client
+	Lifetimes    *config.Lifetimes
+	Clock        clock.Clock
+}
+
+func (o *OAuth) now() time.Time {
+	return clock.Now(o.Clock)
+}
+
+func (o *OAuth) provider(
+	request *http.Request,
+) (*oidc.Client, *types.AppError) {
+	client, ok := o.Providers[router.Param(request, "provider")]
+
+	if !ok {
+		return nil, &types.AppError{
+			StatusCode: 404,
+			Message:    "Provider not found.",
+		}
+	}
+
+	return client, nil
+}
+
+func (o *OAuth) ListProviders(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	providers := []string{}
+
+	for name := range o.Providers {
+		providers = append(providers, name)
+	}
+
+	sort.Strings(providers)
+
+	utils.ReturnJSONResponse(
+		writer,
+		200,
+		&struct {
+			Providers []string `json:"providers"`
+		}{Providers: providers},
+	)
+}
+
+func (o *OAuth) Authorize(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	client, appErr := o.provider(request)
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	state, stateErr := oidc.NewState()
+	nonce, nonceErr := oidc.NewNonce()
+	codeVerifier, codeVerifierErr := oidc.NewCodeVerifier()
+
+	if stateErr != nil || nonceErr != nil || codeVerifierErr != nil {
+		utils.ReturnJSONResponse(writer, 500, &types.ReturnError{
+			Error: "Failed to generate authorization state.",
+		})
+
+		return
+	}
+
+	authorizationURL, err := client.AuthorizationURL(
+		request.Context(),
+		state,
+		nonce,
+		codeVerifier,
+	)
+
+	if err != nil {
+		utils.ReturnJSONResponse(writer, 502, &types.ReturnError{
+			Error: "Provider unavailable.",
+		})
+
+		return
+	}
+
+	appErr = o.Repositories.OAuthStates.Create(
+		request.Context(),
+		state,
+		client.Provider.Name,
+		nonce,
+		codeVerifier,
+		int64(o.Lifetimes.OAuthState.Seconds()),
+	)
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	utils.ReturnJSONResponse(
+		writer,
+		200,
+		&struct {
+			AuthorizationURL string `json:"authorizationUrl"`
+			State            string `json:"state"`
+		}{AuthorizationURL: authorizationURL, State: state},
+	)
+}
+
+func findOrCreateUser(
+	ctx context.Context,
+	repositories *models.Repositories,
+	identity *oidc.Identity,
+) (
+	*types.User,
+	*types.UserIdentity,
+	*types.AppError,
+) {
+	userModel := repositories.Users
+	userIdentityModel := repositories.UserIdentities
+
+	userIdentity, appErr := userIdentityModel.FindByProviderSubject(
+		ctx,
+		identity.Provider,
+		identity.Subject,
+	)
+
+	if appErr == nil {
+		user, appErr := userModel.FindById(ctx, userIdentity.UserId)
+
+		if appErr != nil {
+			return nil, nil, appErr
+		}
+
+		return user, userIdentity, nil
+	}
+
+	if appErr.StatusCode != 404 {
+		return nil, nil, appErr
+	}
+
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, nil, &types.AppError{
+			StatusCode: 403,
+			Message:    "Your provider account has no verified email.",
+		}
+	}
+
+	var user *types.User
+
+	emailAvailable, appErr := userModel.CheckEmailAvailability(
+		ctx,
+		identity.Email,
+	)
+
+	if appErr != nil {
+		return nil, nil, appErr
+	}
+
+	if emailAvailable {
+		userId, appErr := userModel.Create(
+			ctx,
+			identity.Email,
+		)
+
+		if appErr != nil {
+			return nil, nil, appErr
+		}
+
+		user, appErr = userModel.FindById(
+			ctx,
+			userId,
+		)
+
+		if appErr != nil {
+			return nil, nil, appErr
+		}
+	} else {
+		user, appErr = userModel.FindByEmail(
+			ctx,
+			identity.Email,
+		)
+
+		if appErr != nil {
+			return nil, nil, appErr
+		}
+	}
+
+	userIdentityId, appErr := userIdentityModel.Create(
+		ctx,
+		user.Id,
+		identity.Provider,
+		identity.Subject,
+		identity.Email,
+	)
+
+	if appErr != nil {
+		return nil, nil, appErr
+	}
+
+	return user, &types.UserIdentity{
+		Id:       userIdentityId,
+		UserId:   user.Id,
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+	}, nil
+}
+
+func (o *OAuth) Callback(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	client, appErr := o.provider(request)
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	var body *struct {
+		Code  string `json:"code"`
+		State string `json:"state"`
+	}
+
+	err := json.NewDecoder(request.Body).Decode(&body)
+
+	if err == io.EOF {
+		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
+			Error: "Missing authorization code.",
+		})
+
+		return
+	}
+
+	if err != nil {
+		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
+			Error: "Invalid data.",
+		})
+
+		return
+	}
+
+	if body.Code == "" || body.State == "" {
+		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
+			Error: "Missing authorization code.",
+		})
+
+		return
+	}
+
+	oauthState, appErr := o.Repositories.OAuthStates.Consume(
+		request.Context(),
+		body.State,
+		client.Provider.Name,
+	)
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	identity, err := client.Exchange(
+		request.Context(),
+		body.Code,
+		oauthState.CodeVerifier,
+		oauthState.Nonce,
+	)
+
+	if err != nil {
+		utils.ReturnJSONResponse(writer, 401, &types.ReturnError{
+			Error: "Failed to authenticate with provider.",
+		})
+
+		return
+	}
+
+	var user *types.User
+	var userToken string
+
+	signIn := func(transaction *models.Repositories) *types.AppError {
+		var userIdentity *types.UserIdentity
+
+		user, userIdentity, appErr = findOrCreateUser(
+			request.Context(),
+			transaction,
+			identity,
+		)
+
+		if appErr != nil {
+			return appErr
+		}
+
+		if user.Banned {
+			return &types.AppError{
+				StatusCode: 403,
+				Message:    "User banned.",
+			}
+		}
+
+		userToken, appErr = startUserSession(
+			request,
+			transaction,
+			o.Tokens,
+			o.now(),
+			o.Lifetimes,
+			user.Id,
+			nil,
+			&userIdentity.Id,
+		)
+
+		return appErr
+	}
+
+	appErr = o.Repositories.UnitOfWork.Run(request.Context(), signIn)
+
+	if appErr != nil && appErr.StatusCode == 409 {
+		appErr = o.Repositories.UnitOfWork.Run(request.Context(), signIn)
+	}
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	utils.ReturnJSONResponse(
+		writer,
+		200,
+		&struct {
+			User  *types.User `json:"user"`
+			Token string      `json:"token"`
+		}{User: user, Token: userToken},
+	)
+}
@@ -0,0 +1,282 @@
+package routes
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/sandromai/go-http-server/middlewares"
+	"github.com/sandromai/go-http-server/models"
+	"github.com/sandromai/go-http-server/router"
+	"github.com/sandromai/go-http-server/types"
+	"github.com/sandromai/go-http-server/utils"
+)
+
+type OAuthClient struct {
+	Repositories *models.Repositories
+}
+
+func validateRedirectURI(redirectURI string) bool {
+	parsedURI, err := url.Parse(redirectURI)
+
+	if err != nil || parsedURI.Scheme == "" || parsedURI.Fragment != "" {
+		return false
+	}
+
+	switch parsedURI.Scheme {
+	case "https":
+		return parsedURI.Host != ""
+	case "http":
+		hostname := parsedURI.Hostname()
+
+		return hostname == "localhost" || hostname == "127.0.0.1" || hostname == "::1"
+	case "javascript", "data", "file":
+		return false
+	}
+
+	return true
+}
+
+func (o *OAuthClient) List(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	clients, appErr := o.Repositories.OAuthClients.List(request.Context())
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	utils.ReturnJSONResponse(
+		writer,
+		200,
+		clients,
+	)
+}
+
+func (o *OAuthClient) Create(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	admin := middlewares.AuthenticatedAdmin(request)
+
+	var body *struct {
+		Name         string   `json:"name"`
+		RedirectURIs []string `json:"redirectUris"`
+		Scopes       []string `json:"scopes"`
+		GrantTypes   []string `json:"grantTypes"`
+		Confidential bool     `json:"confidential"`
+		FirstParty   bool     `json:"firstParty"`
+	}
+
+	err := json.NewDecoder(request.Body).Decode(&body)
+
+	if err == io.EOF {
+		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
+			Error: "Insert the client name.",
+		})
+
+		return
+	}
+
+	if err != nil {
+		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
+			Error: "Invalid data.",
+		})
+
+		return
+	}
+
+	body.Name = strings.TrimSpace(body.Name)
+
+	if body.Name == "" {
+		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
+			Error: "Insert the client name.",
+		})
+
+		return
+	}
+
+	if len(body.GrantTypes) == 0 {
+		body.GrantTypes = []string{
+			types.OAuthGrantAuthorizationCode,
+			types.OAuthGrantRefreshToken,
+		}
+	}
+
+	for _, grantType := range body.GrantTypes {
+		supportedGrantType := false
+
+		for _, value := range types.OAuthGrantTypes {
+			if value == grantType {
+				supportedGrantType = true
+			}
+		}
+
+		if !supportedGrantType {
+			utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
+				Error: "Invalid grant type: " + grantType + ".",
+			})
+
+			return
+		}
+
+		if grantType == types.OAuthGrantClientCredentials && !body.Confidential {
+			utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
+				Error: "Only confidential clients may use client credentials.",
+			})
+
+			return
+		}
+	}
+
+	for _, scope := range body.Scopes {
+		if types.FindOAuthScope(scope) == nil {
+			utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
+				Error: "Invalid scope: " + scope + ".",
+			})
+
+			return
+		}
+	}
+
+	if body.RedirectURIs == nil {
+		body.RedirectURIs = []string{}
+	}
+
+	for _, redirectURI := range body.RedirectURIs {
+		if !validateRedirectURI(redirectURI) {
+			utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
+				Error: "Invalid redirect URI: " + redirectURI + ".",
+			})
+
+			return
+		}
+	}
+
+	for _, grantType := range body.GrantTypes {
+		if grantType == types.OAuthGrantAuthorizationCode && len(body.RedirectURIs) == 0 {
+			utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
+				Error: "Insert at least one redirect URI.",
+			})
+
+			return
+		}
+	}
+
+	var clientSecret string
+	var secretHash *string
+
+	if body.Confidential {
+		var appErr *types.AppError
+
+		clientSecret, appErr = utils.GenerateSecret()
+
+		if appErr != nil {
+			utils.ReturnJSONResponse(
+				writer,
+				appErr.StatusCode,
+				&types.ReturnError{Error: appErr.Message},
+			)
+
+			return
+		}
+
+		hash := utils.HashSecret(clientSecret)
+
+		secretHash = &hash
+	}
+
+	clientModel := o.Repositories.OAuthClients
+
+	clientId, appErr := clientModel.Create(
+		request.Context(),
+		body.Name,
+		secretHash,
+		body.RedirectURIs,
+		types.ParseOAuthScope(strings.Join(body.Scopes, " ")),
+		body.GrantTypes,
+		body.Confidential,
+		body.FirstParty,
+		admin.Id,
+	)
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	client, appErr := clientModel.FindById(request.Context(), clientId)
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	utils.ReturnJSONResponse(
+		writer,
+		201,
+		&struct {
+			Client       *types.OAuthClient `json:"client"`
+			ClientSecret string             `json:"clientSecret,omitempty"`
+		}{Client: client, ClientSecret: clientSecret},
+	)
+}
+
+func (o *OAuthClient) Delete(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	clientModel := o.Repositories.OAuthClients
+
+	client, appErr := clientModel.FindById(
+		request.Context(),
+		router.Param(request, "id"),
+	)
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	appErr = clientModel.Delete(request.Context(), client.Id)
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	utils.ReturnJSONResponse(
+		writer,
+		200,
+		nil,
+	)
+}
@@ -0,0 +1,1096 @@
+package routes
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sandromai/go-http-server/clock"
+	"github.com/sandromai/go-http-server/config"
+	"github.com/sandromai/go-http-server/middlewares"
+	"github.com/sandromai/go-http-server/models"
+	"github.com/sandromai/go-http-server/token"
+	"github.com/sandromai/go-http-server/types"
+	"github.com/sandromai/go-http-server/utils"
+)
+
+type OAuthServer struct {
+	Repositories  *models.Repositories
+	Authenticator *middlewares.Authenticator
+	Lifetimes     *config.Lifetimes
+	Clock         clock.Clock
+}
+
+type oauthAuthorizationRequest struct {
+	ResponseType        string `json:"response_type"`
+	ClientId            string `json:"client_id"`
+	RedirectURI         string `json:"redirect_uri"`
+	Scope               string `json:"scope"`
+	State               string `json:"state"`
+	CodeChallenge       string `json:"code_challenge"`
+	CodeChallengeMethod string `json:"code_challenge_method"`
+	Approved            bool   `json:"approved"`
+}
+
+type oauthTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope"`
+}
+
+type oauthIntrospection struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientId  string `json:"client_id,omitempty"`
+	Subject   string `json:"sub,omitempty"`
+	Username  string `json:"username,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+	IssuedAt  int64  `json:"iat,omitempty"`
+}
+
+func (o *OAuthServer) now() time.Time {
+	return clock.Now(o.Clock)
+}
+
+func returnOAuthError(
+	writer http.ResponseWriter,
+	oauthErr *types.OAuthError,
+) {
+	utils.ReturnJSONResponse(
+		writer,
+		oauthErr.StatusCode,
+		oauthErr,
+	)
+}
+
+func oauthServerError(
+	appErr *types.AppError,
+) *types.OAuthError {
+	return &types.OAuthError{
+		StatusCode:  500,
+		Error:       "server_error",
+		Description: appErr.Message,
+	}
+}
+
+func (o *OAuthServer) authenticateClient(
+	writer http.ResponseWriter,
+	request *http.Request,
+) (*types.OAuthClient, *types.OAuthError) {
+	clientId, clientSecret, basicAuth := request.BasicAuth()
+
+	if basicAuth {
+		clientId, _ = url.QueryUnescape(clientId)
+		clientSecret, _ = url.QueryUnescape(clientSecret)
+	} else {
+		clientId = request.PostFormValue("client_id")
+		clientSecret = request.PostFormValue("client_secret")
+	}
+
+	invalidClient := &types.OAuthError{
+		StatusCode:  401,
+		Error:       "invalid_client",
+		Description: "Client authentication failed.",
+	}
+
+	if basicAuth {
+		writer.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+	}
+
+	if clientId == "" {
+		return nil, invalidClient
+	}
+
+	client, appErr := o.Repositories.OAuthClients.FindById(request.Context(), clientId)
+
+	if appErr != nil && appErr.StatusCode == 404 {
+		return nil, invalidClient
+	}
+
+	if appErr != nil {
+		return nil, oauthServerError(appErr)
+	}
+
+	if !client.Confidential {
+		if clientSecret != "" {
+			return nil, invalidClient
+		}
+
+		writer.Header().Del("WWW-Authenticate")
+
+		return client, nil
+	}
+
+	if client.SecretHash == nil || subtle.ConstantTimeCompare([]byte(utils.HashSecret(clientSecret)), []byte(*client.SecretHash)) != 1 {
+		return nil, invalidClient
+	}
+
+	writer.Header().Del("WWW-Authenticate")
+
+	return client, nil
+}
+
+func (o *OAuthServer) validateAuthorizationRequest(
+	ctx context.Context,
+	authorizationRequest *oauthAuthorizationRequest,
+) (
+	*types.OAuthClient,
+	[]string,
+	*types.OAuthError,
+) {
+	if authorizationRequest.ClientId == "" || authorizationRequest.RedirectURI == "" {
+		return nil, nil, &types.OAuthError{
+			StatusCode:  400,
+			Error:       "invalid_request",
+			Description: "Missing client_id or redirect_uri.",
+		}
+	}
+
+	client, appErr := o.Repositories.OAuthClients.FindById(ctx, authorizationRequest.ClientId)
+
+	if appErr != nil && appErr.StatusCode == 404 {
+		return nil, nil, &types.OAuthError{
+			StatusCode:  400,
+			Error:       "invalid_client",
+			Description: "Unknown client.",
+		}
+	}
+
+	if appErr != nil {
+		return nil, nil, oauthServerError(appErr)
+	}
+
+	if !client.HasRedirectURI(authorizationRequest.RedirectURI) {
+		return nil, nil, &types.OAuthError{
+			StatusCode:  400,
+			Error:       "invalid_request",
+			Description: "Unregistered redirect_uri.",
+		}
+	}
+
+	if !client.HasGrantType(types.OAuthGrantAuthorizationCode) {
+		return nil, nil, &types.OAuthError{
+			StatusCode:  400,
+			Error:       "unauthorized_client",
+			Description: "The client may not use the authorization code grant.",
+		}
+	}
+
+	if authorizationRequest.ResponseType != "code" {
+		return nil, nil, &types.OAuthError{
+			StatusCode:  400,
+			Error:       "unsupported_response_type",
+			Description: "Only the code response type is supported.",
+		}
+	}
+
+	if authorizationRequest.CodeChallengeMethod != "S256" || len(authorizationRequest.CodeChallenge) < 43 || len(authorizationRequest.CodeChallenge) > 128 {
+		return nil, nil, &types.OAuthError{
+			StatusCode:  400,
+			Error:       "invalid_request",
+			Description: "A S256 code_challenge is required.",
+		}
+	}
+
+	scopes := types.ParseOAuthScope(authorizationRequest.Scope)
+
+	if len(scopes) == 0 {
+		scopes = client.Scopes
+	}
+
+	if !types.ContainsOAuthScopes(client.Scopes, scopes) {
+		return nil, nil, &types.OAuthError{
+			StatusCode:  400,
+			Error:       "invalid_scope",
+			Description: "The requested scope is not allowed for this client.",
+		}
+	}
+
+	return client, scopes, nil
+}
+
+func buildRedirectURL(
+	redirectURI string,
+	parameters map[string]string,
+) string {
+	redirectURL, err := url.Parse(redirectURI)
+
+	if err != nil {
+		return redirectURI
+	}
+
+	query := redirectURL.Query()
+
+	for key, value := range parameters {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+
+	redirectURL.RawQuery = query.Encode()
+
+	return redirectURL.String()
+}
+
+func (o *OAuthServer) AuthorizationDetails(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	user := middlewares.AuthenticatedUser(request)
+
+	query := request.URL.Query()
+
+	client, scopes, oauthErr := o.validateAuthorizationRequest(request.Context(), &oauthAuthorizationRequest{
+		ResponseType:        query.Get("response_type"),
+		ClientId:            query.Get("client_id"),
+		RedirectURI:         query.Get("redirect_uri"),
+		Scope:               query.Get("scope"),
+		State:               query.Get("state"),
+		CodeChallenge:       query.Get("code_challenge"),
+		CodeChallengeMethod: query.Get("code_challenge_method"),
+	})
+
+	if oauthErr != nil {
+		returnOAuthError(writer, oauthErr)
+
+		return
+	}
+
+	consentRequired := !client.FirstParty
+
+	if consentRequired {
+		consentedScope, appErr := o.Repositories.OAuthConsents.FindScope(
+			request.Context(),
+			user.Id,
+			client.Id,
+		)
+
+		if appErr != nil && appErr.StatusCode != 404 {
+			utils.ReturnJSONResponse(
+				writer,
+				appErr.StatusCode,
+				&types.ReturnError{Error: appErr.Message},
+			)
+
+			return
+		}
+
+		if appErr == nil && types.ContainsOAuthScopes(types.ParseOAuthScope(consentedScope), scopes) {
+			consentRequired = false
+		}
+	}
+
+	requestedScopes := []*types.OAuthScope{}
+
+	for _, scope := range scopes {
+		if oauthScope := types.FindOAuthScope(scope); oauthScope != nil {
+			requestedScopes = append(requestedScopes, oauthScope)
+		}
+	}
+
+	type clientDetails struct {
+		Id         string `json:"id"`
+		Name       string `json:"name"`
+		FirstParty bool   `json:"firstParty"`
+	}
+
+	utils.ReturnJSONResponse(
+		writer,
+		200,
+		&struct {
+			Client          clientDetails       `json:"client"`
+			Scopes          []*types.OAuthScope `json:"scopes"`
+			ConsentRequired bool                `json:"consentRequired"`
+		}{
+			Client: clientDetails{
+				Id:         client.Id,
+				Name:       client.Name,
+				FirstParty: client.FirstParty,
+			},
+			Scopes:          requestedScopes,
+			ConsentRequired: consentRequired,
+		},
+	)
+}
+
+func (o *OAuthServer) Authorize(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	user := middlewares.AuthenticatedUser(request)
+
+	var body *oauthAuthorizationRequest
+
+	err := json.NewDecoder(request.Body).Decode(&body)
+
+	if err == io.EOF {
+		returnOAuthError(writer, &types.OAuthError{
+			StatusCode:  400,
+			Error:       "invalid_request",
+			Description: "Missing authorization request.",
+		})
+
+		return
+	}
+
+	if err != nil {
+		returnOAuthError(writer, &types.OAuthError{
+			StatusCode:  400,
+			Error:       "invalid_request",
+			Description: "Invalid data.",
+		})
+
+		return
+	}
+
+	client, scopes, oauthErr := o.validateAuthorizationRequest(request.Context(), body)
+
+	if oauthErr != nil {
+		returnOAuthError(writer, oauthErr)
+
+		return
+	}
+
+	if !body.Approved {
+		utils.ReturnJSONResponse(
+			writer,
+			200,
+			&struct {
+				RedirectURL string `json:"redirectUrl"`
+			}{
+				RedirectURL: buildRedirectURL(body.RedirectURI, map[string]string{
+					"error": "access_denied",
+					"state": body.State,
+				}),
+			},
+		)
+
+		return
+	}
+
+	if !client.FirstParty {
+		consentModel := o.Repositories.OAuthConsents
+
+		consentedScope, appErr := consentModel.FindScope(
+			request.Context(),
+			user.Id,
+			client.Id,
+		)
+
+		if appErr != nil && appErr.StatusCode != 404 {
+			utils.ReturnJSONResponse(
+				writer,
+				appErr.StatusCode,
+				&types.ReturnError{Error: appErr.Message},
+			)
+
+			return
+		}
+
+		consentedScopes := types.ParseOAuthScope(consentedScope + " " + strings.Join(scopes, " "))
+
+		appErr = consentModel.Save(
+			request.Context(),
+			user.Id,
+			client.Id,
+			strings.Join(consentedScopes, " "),
+		)
+
+		if appErr != nil {
+			utils.ReturnJSONResponse(
+				writer,
+				appErr.StatusCode,
+				&types.ReturnError{Error: appErr.Message},
+			)
+
+			return
+		}
+	}
+
+	code, appErr := utils.GenerateSecret()
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	appErr = o.Repositories.OAuthAuthorizationCodes.Create(
+		request.Context(),
+		utils.HashSecret(code),
+		client.Id,
+		user.Id,
+		body.RedirectURI,
+		strings.Join(scopes, " "),
+		body.CodeChallenge,
+		int64(o.Lifetimes.OAuthAuthorizationCode.Seconds()),
+	)
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	utils.ReturnJSONResponse(
+		writer,
+		200,
+		&struct {
+			RedirectURL string `json:"redirectUrl"`
+		}{
+			RedirectURL: buildRedirectURL(body.RedirectURI, map[string]string{
+				"code":  code,
+				"state": body.State,
+			}),
+		},
+	)
+}
+
+func (o *OAuthServer) issueUserTokens(
+	request *http.Request,
+	client *types.OAuthClient,
+	userId,
+	scope string,
+	fromUserToken *string,
+) (*oauthTokenResponse, *types.OAuthError) {
+	var refreshToken string
+	var refreshTokenHash *string
+
+	if client.HasGrantType(types.OAuthGrantRefreshToken) {
+		var appErr *types.AppError
+
+		refreshToken, appErr = utils.GenerateSecret()
+
+		if appErr != nil {
+			return nil, oauthServerError(appErr)
+		}
+
+		hash := utils.HashSecret(refreshToken)
+
+		refreshTokenHash = &hash
+	}
+
+	ipAddress, device := requestDevice(request)
+
+	userTokenId, appErr := o.Repositories.UserTokens.CreateForClient(
+		request.Context(),
+		userId,
+		client.Id,
+		scope,
+		refreshTokenHash,
+		fromUserToken,
+		ipAddress,
+		device,
+		int64(o.Lifetimes.OAuthRefreshToken.Seconds()),
+	)
+
+	if appErr != nil {
+		return nil, oauthServerError(appErr)
+	}
+
+	accessToken, appErr := (&types.OAuthAccessTokenPayload{
+		RegisteredClaims: token.RegisteredClaims{
+			Subject:   userId,
+			ExpiresAt: o.now().Add(o.Lifetimes.OAuthAccessToken).Unix(),
+			IssuedAt:  o.now().Unix(),
+		},
+		ClientId:    client.Id,
+		Scope:       scope,
+		UserTokenId: userTokenId,
+	}).ToJWT(o.Authenticator.Tokens)
+
+	if appErr != nil {
+		return nil, oauthServerError(appErr)
+	}
+
+	return &oauthTokenResponse{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(o.Lifetimes.OAuthAccessToken.Seconds()),
+		RefreshToken: refreshToken,
+		Scope:        scope,
+	}, nil
+}
+
+func (o *OAuthServer) checkUser(
+	ctx context.Context,
+	userId string,
+) *types.OAuthError {
+	user, appErr := o.Repositories.Users.FindById(ctx, userId)
+
+	if appErr != nil && appErr.StatusCode == 404 {
+		return &types.OAuthError{
+			StatusCode:  400,
+			Error:       "invalid_grant",
+			Description: "User not found.",
+		}
+	}
+
+	if appErr != nil {
+		return oauthServerError(appErr)
+	}
+
+	if user.Banned {
+		return &types.OAuthError{
+			StatusCode:  400,
+			Error:       "invalid_grant",
+			Description: "User banned.",
+		}
+	}
+
+	return nil
+}
+
+func (o *OAuthServer) authorizationCodeGrant(
+	request *http.Request,
+	client *types.OAuthClient,
+) (*oauthTokenResponse, *types.OAuthError) {
+	code := request.PostFormValue("code")
+	redirectURI := request.PostFormValue("redirect_uri")
+	codeVerifier := request.PostFormValue("code_verifier")
+
+	if code == "" || redirectURI == "" || codeVerifier == "" {
+		return nil, &types.OAuthError{
+			StatusCode:  400,
+			Error:       "invalid_request",
+			Description: "Missing code, redirect_uri or code_verifier.",
+		}
+	}
+
+	authorizationCode, appErr := o.Repositories.OAuthAuthorizationCodes.Consume(
+		request.Context(),
+		utils.HashSecret(code),
+	)
+
+	if appErr != nil && appErr.StatusCode == 400 {
+		return nil, &types.OAuthError{
+			StatusCode:  400,
+			Error:       "invalid_grant",
+			Description: appErr.Message,
+		}
+	}
+
+	if appErr != nil {
+		return nil, oauthServerError(appErr)
+	}
+
+	codeChallenge := sha256.Sum256([]byte(codeVerifier))
+
+	if authorizationCode.ClientId != client.Id || authorizationCode.RedirectURI != redirectURI || subtle.ConstantTimeCompare([]byte(base64.RawURLEncoding.EncodeToString(codeChallenge[:])), []byte(authorizationCode.CodeChallenge)) != 1 {
+		return nil, &types.OAuthError{
+			StatusCode:  400,
+			Error:       "invalid_grant",
+			Description: "Invalid or expired authorization code.",
+		}
+	}
+
+	if oauthErr := o.checkUser(request.Context(), authorizationCode.UserId); oauthErr != nil {
+		return nil, oauthErr
+	}
+
+	return o.issueUserTokens(
+		request,
+		client,
+		authorizationCode.UserId,
+		authorizationCode.Scope,
+		nil,
+	)
+}
+
+func (o *OAuthServer) refreshTokenGrant(
+	request *http.Request,
+	client *types.OAuthClient,
+) (*oauthTokenResponse, *types.OAuthError) {
+	refreshToken := request.PostFormValue("refresh_token")
+
+	if refreshToken == "" {
+		return nil, &types.OAuthError{
+			StatusCode:  400,
+			Error:       "invalid_request",
+			Description: "Missing refresh_token.",
+		}
+	}
+
+	invalidGrant := &types.OAuthError{
+		StatusCode:  400,
+		Error:       "invalid_grant",
+		Description: "Invalid or expired refresh token.",
+	}
+
+	userTokenModel := o.Repositories.UserTokens
+
+	userToken, appErr := userTokenModel.FindByRefreshTokenHash(
+		request.Context(),
+		utils.HashSecret(refreshToken),
+	)
+
+	if appErr != nil && appErr.StatusCode == 404 {
+		return nil, invalidGrant
+	}
+
+	if appErr != nil {
+		return nil, oauthServerError(appErr)
+	}
+
+	if userToken.ClientId == nil || *userToken.ClientId != client.Id {
+		return nil, invalidGrant
+	}
+
+	if userToken.ExpiresAt.Before(o.now()) {
+		return nil, invalidGrant
+	}
+
+	grantedScopes := types.ParseOAuthScope(*userToken.Scope)
+	scopes := types.ParseOAuthScope(request.PostFormValue("scope"))
+
+	if len(scopes) == 0 {
+		scopes = grantedScopes
+	}
+
+	if !types.ContainsOAuthScopes(grantedScopes, scopes) {
+		return nil, &types.OAuthError{
+			StatusCode:  400,
+			Error:       "invalid_scope",
+			Description: "The requested scope exceeds the granted scope.",
+		}
+	}
+
+	rotated, appErr := userTokenModel.DisconnectActive(request.Context(), userToken.Id)
+
+	if appErr != nil {
+		return nil, oauthServerError(appErr)
+	}
+
+	if !rotated {
+		appErr = userTokenModel.DisconnectAllByUserClient(
+			request.Context(),
+			userToken.UserId,
+			client.Id,
+		)
+
+		if appErr != nil {
+			return nil, oauthServerError(appErr)
+		}
+
+		return nil, invalidGrant
+	}
+
+	if oauthErr := o.checkUser(request.Context(), userToken.UserId); oauthErr != nil {
+		return nil, oauthErr
+	}
+
+	return o.issueUserTokens(
+		request,
+		client,
+		userToken.UserId,
+		strings.Join(scopes, " "),
+		&userToken.Id,
+	)
+}
+
+func (o *OAuthServer) clientCredentialsGrant(
+	request *http.Request,
+	client *types.OAuthClient,
+) (*oauthTokenResponse, *types.OAuthError) {
+	if !client.Confidential {
+		return nil, &types.OAuthError{
+			StatusCode:  400,
+			Error:       "unauthorized_client",
+			Description: "Public clients may not use the client credentials grant.",
+		}
+	}
+
+	scopes := types.ParseOAuthScope(request.PostFormValue("scope"))
+
+	if len(scopes) == 0 {
+		scopes = client.Scopes
+	}
+
+	if !types.ContainsOAuthScopes(client.Scopes, scopes) {
+		return nil, &types.OAuthError{
+			StatusCode:  400,
+			Error:       "invalid_scope",
+			Description: "The requested scope is not allowed for this client.",
+		}
+	}
+
+	scope := strings.Join(scopes, " ")
+
+	clientTokenId, appErr := o.Repositories.OAuthClientTokens.Create(
+		request.Context(),
+		client.Id,
+		scope,
+		int64(o.Lifetimes.OAuthAccessToken.Seconds()),
+	)
+
+	if appErr != nil {
+		return nil, oauthServerError(appErr)
+	}
+
+	accessToken, appErr := (&types.OAuthAccessTokenPayload{
+		RegisteredClaims: token.RegisteredClaims{
+			Subject:   client.Id,
+			ExpiresAt: o.now().Add(o.Lifetimes.OAuthAccessToken).Unix(),
+			IssuedAt:  o.now().Unix(),
+		},
+		ClientId:      client.Id,
+		Scope:         scope,
+		ClientTokenId: clientTokenId,
+	}).ToJWT(o.Authenticator.Tokens)
+
+	if appErr != nil {
+		return nil, oauthServerError(appErr)
+	}
+
+	return &oauthTokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(o.Lifetimes.OAuthAccessToken.Seconds()),
+		Scope:       scope,
+	}, nil
+}
+
+func (o *OAuthServer) Token(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	writer.Header().Set("Cache-Control", "no-store")
+	writer.Header().Set("Pragma", "no-cache")
+
+	if err := request.ParseForm(); err != nil {
+		returnOAuthError(writer, &types.OAuthError{
+			StatusCode:  400,
+			Error:       "invalid_request",
+			Description: "Invalid form data.",
+		})
+
+		return
+	}
+
+	client, oauthErr := o.authenticateClient(writer, request)
+
+	if oauthErr != nil {
+		returnOAuthError(writer, oauthErr)
+
+		return
+	}
+
+	grantType := request.PostFormValue("grant_type")
+
+	supportedGrantType := false
+
+	for _, value := range types.OAuthGrantTypes {
+		if value == grantType {
+			supportedGrantType = true
+		}
+	}
+
+	if !supportedGrantType {
+		returnOAuthError(writer, &types.OAuthError{
+			StatusCode:  400,
+			Error:       "unsupported_grant_type",
+			Description: "Unsupported grant_type.",
+		})
+
+		return
+	}
+
+	if !client.HasGrantType(grantType) {
+		returnOAuthError(writer, &types.OAuthError{
+			StatusCode:  400,
+			Error:       "unauthorized_client",
+			Description: "The client may not use this grant type.",
+		})
+
+		return
+	}
+
+	var response *oauthTokenResponse
+
+	switch grantType {
+	case types.OAuthGrantAuthorizationCode:
+		response, oauthErr = o.authorizationCodeGrant(request, client)
+	case types.OAuthGrantRefreshToken:
+		response, oauthErr = o.refreshTokenGrant(request, client)
+	case types.OAuthGrantClientCredentials:
+		response, oauthErr = o.clientCredentialsGrant(request, client)
+	}
+
+	if oauthErr != nil {
+		returnOAuthError(writer, oauthErr)
+
+		return
+	}
+
+	utils.ReturnJSONResponse(
+		writer,
+		200,
+		response,
+	)
+}
+
+func (o *OAuthServer) introspectAccessToken(
+	ctx context.Context,
+	tokenString string,
+) *oauthIntrospection {
+	access, appErr := o.Authenticator.InspectAccessToken(ctx, tokenString)
+
+	if appErr != nil {
+		return nil
+	}
+
+	introspection := &oauthIntrospection{
+		Active:    true,
+		Scope:     strings.Join(access.Scopes, " "),
+		ClientId:  access.ClientId,
+		Subject:   access.ClientId,
+		TokenType: "Bearer",
+		ExpiresAt: access.ExpiresAt,
+		IssuedAt:  access.IssuedAt,
+	}
+
+	if access.User != nil {
+		introspection.Subject = access.User.Id
+		introspection.Username = access.User.Email
+	}
+
+	return introspection
+}
+
+func (o *OAuthServer) introspectRefreshToken(
+	ctx context.Context,
+	refreshToken string,
+) *oauthIntrospection {
+	userToken, appErr := o.Repositories.UserTokens.FindByRefreshTokenHash(
+		ctx,
+		utils.HashSecret(refreshToken),
+	)
+
+	if appErr != nil || userToken.ClientId == nil || userToken.Disconnected {
+		return nil
+	}
+
+	if userToken.ExpiresAt.Before(o.now()) {
+		return nil
+	}
+
+	user, appErr := o.Repositories.Users.FindById(ctx, userToken.UserId)
+
+	if appErr != nil || user.Banned {
+		return nil
+	}
+
+	return &oauthIntrospection{
+		Active:    true,
+		Scope:     *userToken.Scope,
+		ClientId:  *userToken.ClientId,
+		Subject:   user.Id,
+		Username:  user.Email,
+		ExpiresAt: userToken.ExpiresAt.Unix(),
+		IssuedAt:  userToken.CreatedAt.Unix(),
+	}
+}
+
+func (o *OAuthServer) Introspect(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	if err := request.ParseForm(); err != nil {
+		returnOAuthError(writer, &types.OAuthError{
+			StatusCode:  400,
+			Error:       "invalid_request",
+			Description: "Invalid form data.",
+		})
+
+		return
+	}
+
+	client, oauthErr := o.authenticateClient(writer, request)
+
+	if oauthErr != nil {
+		returnOAuthError(writer, oauthErr)
+
+		return
+	}
+
+	if !client.Confidential {
+		returnOAuthError(writer, &types.OAuthError{
+			StatusCode:  401,
+			Error:       "invalid_client",
+			Description: "Only confidential clients may introspect tokens.",
+		})
+
+		return
+	}
+
+	tokenString := request.PostFormValue("token")
+
+	if tokenString == "" {
+		returnOAuthError(writer, &types.OAuthError{
+			StatusCode:  400,
+			Error:       "invalid_request",
+			Description: "Missing token.",
+		})
+
+		return
+	}
+
+	introspectors := []func(context.Context, string) *oauthIntrospection{
+		o.introspectAccessToken,
+		o.introspectRefreshToken,
+	}
+
+	if request.PostFormValue("token_type_hint") == "refresh_token" {
+		introspectors[0], introspectors[1] = introspectors[1], introspectors[0]
+	}
+
+	introspection := &oauthIntrospection{Active: false}
+
+	for _, introspector := range introspectors {
+		if result := introspector(request.Context(), tokenString); result != nil {
+			introspection = result
+
+			break
+		}
+	}
+
+	writer.Header().Set("Cache-Control", "no-store")
+
+	utils.ReturnJSONResponse(
+		writer,
+		200,
+		introspection,
+	)
+}
+
+func (o *OAuthServer) Revoke(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	if err := request.ParseForm(); err != nil {
+		returnOAuthError(writer, &types.OAuthError{
+			StatusCode:  400,
+			Error:       "invalid_request",
+			Description: "Invalid form data.",
+		})
+
+		return
+	}
+
+	client, oauthErr := o.authenticateClient(writer, request)
+
+	if oauthErr != nil {
+		returnOAuthError(writer, oauthErr)
+
+		return
+	}
+
+	tokenString := request.PostFormValue("token")
+
+	if tokenString == "" {
+		returnOAuthError(writer, &types.OAuthError{
+			StatusCode:  400,
+			Error:       "invalid_request",
+			Description: "Missing token.",
+		})
+
+		return
+	}
+
+	userTokenModel := o.Repositories.UserTokens
+
+	userToken, appErr := userTokenModel.FindByRefreshTokenHash(
+		request.Context(),
+		utils.HashSecret(tokenString),
+	)
+
+	if appErr != nil && appErr.StatusCode != 404 {
+		returnOAuthError(writer, oauthServerError(appErr))
+
+		return
+	}
+
+	if appErr == nil {
+		if userToken.ClientId != nil && *userToken.ClientId == client.Id {
+			if appErr = userTokenModel.Disconnect(request.Context(), userToken.Id); appErr != nil {
+				returnOAuthError(writer, oauthServerError(appErr))
+
+				return
+			}
+		}
+
+		utils.ReturnJSONResponse(writer, 200, nil)
+
+		return
+	}
+
+	payload := &types.OAuthAccessTokenPayload{}
+
+	if payload.FromJWT(o.Authenticator.Tokens, tokenString) != nil || payload.ClientId != client.Id {
+		utils.ReturnJSONResponse(writer, 200, nil)
+
+		return
+	}
+
+	if payload.UserTokenId != "" {
+		appErr = userTokenModel.Disconnect(request.Context(), payload.UserTokenId)
+	} else if payload.ClientTokenId != "" {
+		appErr = o.Repositories.OAuthClientTokens.Revoke(request.Context(), payload.ClientTokenId)
+	}
+
+	if appErr != nil {
+		returnOAuthError(writer, oauthServerError(appErr))
+
+		return
+	}
+
+	utils.ReturnJSONResponse(writer, 200, nil)
+}
+
+func (o *OAuthServer) UserInfo(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	access := middlewares.AuthenticatedOAuthAccess(request)
+
+	if access.User == nil {
+		utils.ReturnJSONResponse(writer, 403, &types.ReturnError{
+			Error: "This token does not belong to a user.",
+		})
+
+		return
+	}
+
+	userInfo := &struct {
+		Subject string `json:"sub"`
+		Email   string `json:"email,omitempty"`
+	}{Subject: access.User.Id}
+
+	if access.HasScope(types.OAuthScopeEmail) {
+		userInfo.Email = access.User.Email
+	}
+
+	utils.ReturnJSONResponse(
+		writer,
+		200,
+		userInfo,
+	)
+}
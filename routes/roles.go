@@ -0,0 +1,285 @@
+package routes
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/sandromai/go-http-server/models"
+	"github.com/sandromai/go-http-server/router"
+	"github.com/sandromai/go-http-server/types"
+	"github.com/sandromai/go-http-server/utils"
+)
+
+type Role struct {
+	Repositories *models.Repositories
+}
+
+func (r *Role) List(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	roles, appErr := r.Repositories.Roles.List(request.Context())
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	utils.ReturnJSONResponse(
+		writer,
+		200,
+		roles,
+	)
+}
+
+func (r *Role) ListPermissions(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	permissions, appErr := r.Repositories.Roles.ListPermissions(request.Context())
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	utils.ReturnJSONResponse(
+		writer,
+		200,
+		permissions,
+	)
+}
+
+func (r *Role) Create(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	var body *struct {
+		Name        string   `json:"name"`
+		Description string   `json:"description"`
+		Permissions []string `json:"permissions"`
+	}
+
+	err := json.NewDecoder(request.Body).Decode(&body)
+
+	if err == io.EOF {
+		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
+			Error: "Insert the name.",
+		})
+
+		return
+	}
+
+	if err != nil {
+		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
+			Error: "Invalid data.",
+		})
+
+		return
+	}
+
+	body.Name = strings.TrimSpace(body.Name)
+
+	if body.Name == "" {
+		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
+			Error: "Insert the name.",
+		})
+
+		return
+	}
+
+	roleModel := r.Repositories.Roles
+
+	roleId, appErr := roleModel.Create(
+		request.Context(),
+		body.Name,
+		strings.TrimSpace(body.Description),
+		body.Permissions,
+	)
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	createdRole, appErr := roleModel.FindById(request.Context(), roleId)
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	utils.ReturnJSONResponse(
+		writer,
+		201,
+		createdRole,
+	)
+}
+
+func (r *Role) Update(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	roleId := router.Param(request, "id")
+
+	if roleId == types.SuperAdminRoleId {
+		utils.ReturnJSONResponse(writer, 403, &types.ReturnError{
+			Error: "This role can't be changed.",
+		})
+
+		return
+	}
+
+	var body *struct {
+		Name        string   `json:"name"`
+		Description string   `json:"description"`
+		Permissions []string `json:"permissions"`
+	}
+
+	err := json.NewDecoder(request.Body).Decode(&body)
+
+	if err == io.EOF {
+		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
+			Error: "Insert the name.",
+		})
+
+		return
+	}
+
+	if err != nil {
+		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
+			Error: "Invalid data.",
+		})
+
+		return
+	}
+
+	body.Name = strings.TrimSpace(body.Name)
+
+	if body.Name == "" {
+		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
+			Error: "Insert the name.",
+		})
+
+		return
+	}
+
+	roleModel := r.Repositories.Roles
+
+	role, appErr := roleModel.FindById(request.Context(), roleId)
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	appErr = roleModel.Update(
+		request.Context(),
+		role.Id,
+		body.Name,
+		strings.TrimSpace(body.Description),
+		body.Permissions,
+	)
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	updatedRole, appErr := roleModel.FindById(request.Context(), role.Id)
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	utils.ReturnJSONResponse(
+		writer,
+		200,
+		updatedRole,
+	)
+}
+
+func (r *Role) Delete(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	roleId := router.Param(request, "id")
+
+	if roleId == types.SuperAdminRoleId {
+		utils.ReturnJSONResponse(writer, 403, &types.ReturnError{
+			Error: "This role can't be changed.",
+		})
+
+		return
+	}
+
+	roleModel := r.Repositories.Roles
+
+	role, appErr := roleModel.FindById(request.Context(), roleId)
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	appErr = roleModel.Delete(request.Context(), role.Id)
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	utils.ReturnJSONResponse(
+		writer,
+		200,
+		nil,
+	)
+}
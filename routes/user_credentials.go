@@ -0,0 +1,93 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/sandromai/go-http-server/middlewares"
+	"github.com/sandromai/go-http-server/models"
+	"github.com/sandromai/go-http-server/router"
+	"github.com/sandromai/go-http-server/types"
+	"github.com/sandromai/go-http-server/utils"
+)
+
+type UserCredential struct {
+	Repositories *models.Repositories
+}
+
+func (u *UserCredential) List(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	user := middlewares.AuthenticatedUser(request)
+
+	userCredentials, appErr := u.Repositories.UserCredentials.ListByUser(
+		request.Context(),
+		user.Id,
+	)
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	utils.ReturnJSONResponse(
+		writer,
+		200,
+		userCredentials,
+	)
+}
+
+func (u *UserCredential) Delete(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	user := middlewares.AuthenticatedUser(request)
+
+	userCredentialModel := u.Repositories.UserCredentials
+
+	userCredential, appErr := userCredentialModel.FindById(
+		request.Context(),
+		router.Param(request, "id"),
+	)
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	if user.Id != userCredential.UserId {
+		utils.ReturnJSONResponse(writer, 403, &types.ReturnError{
+			Error: "Unauthorized action.",
+		})
+
+		return
+	}
+
+	appErr = userCredentialModel.Delete(request.Context(), userCredential.Id)
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	utils.ReturnJSONResponse(
+		writer,
+		200,
+		nil,
+	)
+}
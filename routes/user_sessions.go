@@ -0,0 +1,67 @@
+package routes
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/sandromai/go-http-server/config"
+	"github.com/sandromai/go-http-server/models"
+	"github.com/sandromai/go-http-server/token"
+	"github.com/sandromai/go-http-server/types"
+	"github.com/sandromai/go-http-server/utils"
+)
+
+func requestDevice(
+	request *http.Request,
+) (
+	ipAddress,
+	device string,
+) {
+	ipAddress = utils.GetRemoteIP(request)
+	platform, browser := utils.GetDeviceInfo(request.Header.Get("User-Agent"))
+
+	if platform != "" && browser != "" {
+		device = platform + ":" + browser
+	}
+
+	return ipAddress, device
+}
+
+func startUserSession(
+	request *http.Request,
+	repositories *models.Repositories,
+	tokens *token.Engine,
+	now time.Time,
+	lifetimes *config.Lifetimes,
+	userId string,
+	fromCredential,
+	fromIdentity *string,
+) (string, *types.AppError) {
+	ipAddress, device := requestDevice(request)
+
+	expiresIn := int64(lifetimes.UserSession.Seconds())
+
+	userTokenId, appErr := repositories.UserTokens.Create(
+		request.Context(),
+		userId,
+		nil,
+		nil,
+		fromCredential,
+		fromIdentity,
+		ipAddress,
+		device,
+		expiresIn,
+	)
+
+	if appErr != nil {
+		return "", appErr
+	}
+
+	return (&types.UserTokenPayload{
+		RegisteredClaims: token.RegisteredClaims{
+			ExpiresAt: lifetimes.UserTokenExpiresAt(now).Unix(),
+			IssuedAt:  now.Unix(),
+		},
+		UserTokenId: userTokenId,
+	}).ToJWT(tokens)
+}
@@ -2,57 +2,30 @@ package routes
 
 import (
 	"net/http"
-	"strings"
-	"time"
 
 	"github.com/sandromai/go-http-server/middlewares"
 	"github.com/sandromai/go-http-server/models"
+	"github.com/sandromai/go-http-server/router"
 	"github.com/sandromai/go-http-server/types"
 	"github.com/sandromai/go-http-server/utils"
 )
 
 type UserToken struct {
-	Timezone *time.Location
+	Repositories *models.Repositories
 }
 
 func (u *UserToken) Disconnect(
 	writer http.ResponseWriter,
 	request *http.Request,
 ) {
-	if request.Method != "PATCH" {
-		utils.ReturnJSONResponse(writer, 405, nil)
+	user := middlewares.AuthenticatedUser(request)
 
-		return
-	}
-
-	user, _, appErr := middlewares.AuthenticateUser(
-		request,
-		u.Timezone,
-	)
-
-	if appErr != nil {
-		utils.ReturnJSONResponse(
-			writer,
-			appErr.StatusCode,
-			&types.ReturnError{Error: appErr.Message},
-		)
-
-		return
-	}
-
-	var userTokenId string
-
-	pathParts := strings.Split(request.URL.Path, "/")
-
-	if pathParts[len(pathParts)-1] != "" {
-		userTokenId = pathParts[len(pathParts)-1]
-	} else {
-		userTokenId = pathParts[len(pathParts)-2]
-	}
+	userTokenId := router.Param(request, "id")
 
-	userTokenModel := &models.UserToken{}
+	userTokenModel := u.Repositories.UserTokens
 
 	userToken, appErr := userTokenModel.FindById(
+		request.Context(),
 		userTokenId,
 	)
 
@@ -82,7 +55,7 @@ func (u *UserToken) Disconnect(
 		return
 	}
 
-	appErr = userTokenModel.Disconnect(userToken.Id)
+	appErr = userTokenModel.Disconnect(request.Context(), userToken.Id)
 
 	if appErr != nil {
 		utils.ReturnJSONResponse(
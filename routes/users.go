@@ -2,89 +2,46 @@ package routes
 
 import (
 	"net/http"
-	"strings"
-	"time"
 
+	"github.com/sandromai/go-http-server/metrics"
 	"github.com/sandromai/go-http-server/middlewares"
 	"github.com/sandromai/go-http-server/models"
+	"github.com/sandromai/go-http-server/router"
 	"github.com/sandromai/go-http-server/types"
 	"github.com/sandromai/go-http-server/utils"
 )
 
 type User struct {
-	Timezone *time.Location
+	Repositories *models.Repositories
+	Metrics      *metrics.App
 }
 
-func (u *User) Authenticate(
+func (*User) Authenticate(
 	writer http.ResponseWriter,
 	request *http.Request,
 ) {
-	if request.Method != "GET" {
-		utils.ReturnJSONResponse(writer, 405, nil)
-
-		return
-	}
-
-	user, token, appErr := middlewares.AuthenticateUser(
-		request,
-		u.Timezone,
-	)
-
-	if appErr != nil {
-		utils.ReturnJSONResponse(
-			writer,
-			appErr.StatusCode,
-			&types.ReturnError{Error: appErr.Message},
-		)
-
-		return
-	}
-
 	utils.ReturnJSONResponse(
 		writer,
 		200,
 		&struct {
 			User  *types.User `json:"user"`
 			Token string      `json:"token,omitempty"`
-		}{User: user, Token: token},
+		}{
+			User:  middlewares.AuthenticatedUser(request),
+			Token: middlewares.RefreshedUserToken(request),
+		},
 	)
 }
 
-func (*User) Ban(
+func (u *User) Ban(
 	writer http.ResponseWriter,
 	request *http.Request,
 ) {
-	if request.Method != "PATCH" {
-		utils.ReturnJSONResponse(writer, 405, nil)
+	userId := router.Param(request, "id")
 
-		return
-	}
+	userModel := u.Repositories.Users
 
-	_, appErr := middlewares.AuthenticateAdmin(request)
-
-	if appErr != nil {
-		utils.ReturnJSONResponse(
-			writer,
-			appErr.StatusCode,
-			&types.ReturnError{Error: appErr.Message},
-		)
-
-		return
-	}
-
-	var userId string
-
-	pathParts := strings.Split(request.URL.Path, "/")
-
-	if pathParts[len(pathParts)-1] != "" {
-		userId = pathParts[len(pathParts)-1]
-	} else {
-		userId = pathParts[len(pathParts)-2]
-	}
-
-	userModel := &models.User{}
-
-	user, appErr := userModel.FindById(userId)
+	user, appErr := userModel.FindById(request.Context(), userId)
 
 	if appErr != nil {
 		utils.ReturnJSONResponse(
@@ -104,7 +61,7 @@ func (*User) Ban(
 		return
 	}
 
-	appErr = userModel.Ban(user.Id)
+	appErr = userModel.Ban(request.Context(), user.Id)
 
 	if appErr != nil {
 		utils.ReturnJSONResponse(
@@ -116,6 +73,8 @@ func (*User) Ban(
 		return
 	}
 
+	u.Metrics.UserBans.Inc()
+
 	utils.ReturnJSONResponse(
 		writer,
 		200,
@@ -123,41 +82,15 @@ func (*User) Ban(
 	)
 }
 
-func (*User) Unban(
+func (u *User) Unban(
 	writer http.ResponseWriter,
 	request *http.Request,
 ) {
-	if request.Method != "PATCH" {
-		utils.ReturnJSONResponse(writer, 405, nil)
-
-		return
-	}
-
-	_, appErr := middlewares.AuthenticateAdmin(request)
-
-	if appErr != nil {
-		utils.ReturnJSONResponse(
-			writer,
-			appErr.StatusCode,
-			&types.ReturnError{Error: appErr.Message},
-		)
-
-		return
-	}
-
-	var userId string
-
-	pathParts := strings.Split(request.URL.Path, "/")
-
-	if pathParts[len(pathParts)-1] != "" {
-		userId = pathParts[len(pathParts)-1]
-	} else {
-		userId = pathParts[len(pathParts)-2]
-	}
+	userId := router.Param(request, "id")
 
-	userModel := &models.User{}
+	userModel := u.Repositories.Users
 
-	user, appErr := userModel.FindById(userId)
+	user, appErr := userModel.FindById(request.Context(), userId)
 
 	if appErr != nil {
 		utils.ReturnJSONResponse(
@@ -177,7 +110,7 @@ func (*User) Unban(
 		return
 	}
 
-	appErr = userModel.Unban(user.Id)
+	appErr = userModel.Unban(request.Context(), user.Id)
 
 	if appErr != nil {
 		utils.ReturnJSONResponse(
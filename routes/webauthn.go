@@ -0,0 +1,607 @@
+package routes
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sandromai/go-http-server/clock"
+	"github.com/sandromai/go-http-server/config"
+	"github.com/sandromai/go-http-server/middlewares"
+	"github.com/sandromai/go-http-server/models"
+	"github.com/sandromai/go-http-server/token"
+	"github.com/sandromai/go-http-server/types"
+	"github.com/sandromai/go-http-server/utils"
+	"github.com/sandromai/go-http-server/webauthn"
+)
+
+type WebAuthn struct {
+	Repositories *models.Repositories
+	Tokens       *token.Engine
+	RelyingParty *webauthn.RelyingParty
+	Lifetimes    *config.Lifetimes
+	Clock        clock.Clock
+}
+
+type webAuthnCredentialDescriptor struct {
+	Type string `json:"type"`
+	Id   string `json:"id"`
+}
+
+func (w *WebAuthn) now() time.Time {
+	return clock.Now(w.Clock)
+}
+
+func decodeBase64URL(value string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+}
+
+func (w *WebAuthn) createChallenge(
+	ctx context.Context,
+	userId *string,
+	challengeType string,
+) (
+	challengeId,
+	challenge string,
+	appErr *types.AppError,
+) {
+	challenge, err := webauthn.NewChallenge()
+
+	if err != nil {
+		return "", "", &types.AppError{
+			StatusCode: 500,
+			Message:    "Failed to generate challenge.",
+		}
+	}
+
+	challengeId, appErr = w.Repositories.WebAuthnChallenges.Create(
+		ctx,
+		userId,
+		challengeType,
+		challenge,
+		int64(w.Lifetimes.WebAuthnChallenge.Seconds()),
+	)
+
+	if appErr != nil {
+		return "", "", appErr
+	}
+
+	return challengeId, challenge, nil
+}
+
+func webAuthnError(err error) *types.AppError {
+	if errors.Is(err, webauthn.ErrUnsupportedKey) {
+		return &types.AppError{
+			StatusCode: 400,
+			Message:    "Unsupported credential type.",
+		}
+	}
+
+	return &types.AppError{
+		StatusCode: 400,
+		Message:    "Invalid credential.",
+	}
+}
+
+func (w *WebAuthn) RegistrationOptions(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	user := middlewares.AuthenticatedUser(request)
+
+	userCredentials, appErr := w.Repositories.UserCredentials.ListByUser(
+		request.Context(),
+		user.Id,
+	)
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	challengeId, challenge, appErr := w.createChallenge(
+		request.Context(),
+		&user.Id,
+		types.WebAuthnChallengeRegistration,
+	)
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	excludeCredentials := []*webAuthnCredentialDescriptor{}
+
+	for _, userCredential := range userCredentials {
+		excludeCredentials = append(excludeCredentials, &webAuthnCredentialDescriptor{
+			Type: "public-key",
+			Id:   userCredential.CredentialId,
+		})
+	}
+
+	type relyingParty struct {
+		Id   string `json:"id"`
+		Name string `json:"name"`
+	}
+
+	type userEntity struct {
+		Id          string `json:"id"`
+		Name        string `json:"name"`
+		DisplayName string `json:"displayName"`
+	}
+
+	type credentialParameter struct {
+		Type      string `json:"type"`
+		Algorithm int64  `json:"alg"`
+	}
+
+	type authenticatorSelection struct {
+		ResidentKey      string `json:"residentKey"`
+		UserVerification string `json:"userVerification"`
+	}
+
+	type publicKeyOptions struct {
+		Challenge              string                          `json:"challenge"`
+		RelyingParty           relyingParty                    `json:"rp"`
+		User                   userEntity                      `json:"user"`
+		CredentialParameters   []credentialParameter           `json:"pubKeyCredParams"`
+		Timeout                int64                           `json:"timeout"`
+		Attestation            string                          `json:"attestation"`
+		ExcludeCredentials     []*webAuthnCredentialDescriptor `json:"excludeCredentials"`
+		AuthenticatorSelection authenticatorSelection          `json:"authenticatorSelection"`
+	}
+
+	utils.ReturnJSONResponse(
+		writer,
+		200,
+		&struct {
+			ChallengeId string           `json:"challengeId"`
+			PublicKey   publicKeyOptions `json:"publicKey"`
+		}{
+			ChallengeId: challengeId,
+			PublicKey: publicKeyOptions{
+				Challenge: challenge,
+				RelyingParty: relyingParty{
+					Id:   w.RelyingParty.Id,
+					Name: w.RelyingParty.Name,
+				},
+				User: userEntity{
+					Id:          base64.RawURLEncoding.EncodeToString([]byte(user.Id)),
+					Name:        user.Email,
+					DisplayName: user.Email,
+				},
+				CredentialParameters: []credentialParameter{
+					{Type: "public-key", Algorithm: webauthn.AlgorithmES256},
+					{Type: "public-key", Algorithm: webauthn.AlgorithmEdDSA},
+					{Type: "public-key", Algorithm: webauthn.AlgorithmRS256},
+				},
+				Timeout:            5 * 60 * 1000,
+				Attestation:        "none",
+				ExcludeCredentials: excludeCredentials,
+				AuthenticatorSelection: authenticatorSelection{
+					ResidentKey:      "preferred",
+					UserVerification: "preferred",
+				},
+			},
+		},
+	)
+}
+
+func (w *WebAuthn) Register(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	user := middlewares.AuthenticatedUser(request)
+
+	var body *struct {
+		ChallengeId string `json:"challengeId"`
+		Name        string `json:"name"`
+		Credential  struct {
+			Id       string `json:"id"`
+			Response struct {
+				ClientDataJSON    string `json:"clientDataJSON"`
+				AttestationObject string `json:"attestationObject"`
+			} `json:"response"`
+		} `json:"credential"`
+	}
+
+	err := json.NewDecoder(request.Body).Decode(&body)
+
+	if err == io.EOF {
+		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
+			Error: "Missing credential.",
+		})
+
+		return
+	}
+
+	if err != nil {
+		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
+			Error: "Invalid data.",
+		})
+
+		return
+	}
+
+	body.Name = strings.TrimSpace(body.Name)
+
+	if body.Name == "" {
+		body.Name = "Passkey"
+	}
+
+	if len(body.Name) > 255 {
+		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
+			Error: "Credential name is too long.",
+		})
+
+		return
+	}
+
+	clientDataJSON, err := decodeBase64URL(body.Credential.Response.ClientDataJSON)
+
+	if err != nil {
+		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
+			Error: "Invalid data.",
+		})
+
+		return
+	}
+
+	attestationObject, err := decodeBase64URL(body.Credential.Response.AttestationObject)
+
+	if err != nil {
+		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
+			Error: "Invalid data.",
+		})
+
+		return
+	}
+
+	challenge, appErr := w.Repositories.WebAuthnChallenges.Consume(
+		request.Context(),
+		body.ChallengeId,
+		types.WebAuthnChallengeRegistration,
+	)
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	if challenge.UserId == nil || *challenge.UserId != user.Id {
+		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
+			Error: "Invalid or expired challenge.",
+		})
+
+		return
+	}
+
+	credential, err := w.RelyingParty.VerifyRegistration(
+		challenge.Challenge,
+		clientDataJSON,
+		attestationObject,
+	)
+
+	if err != nil {
+		appErr := webAuthnError(err)
+
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	credentialId := base64.RawURLEncoding.EncodeToString(credential.Id)
+
+	if body.Credential.Id != "" && strings.TrimRight(body.Credential.Id, "=") != credentialId {
+		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
+			Error: "Invalid credential.",
+		})
+
+		return
+	}
+
+	userCredentialModel := w.Repositories.UserCredentials
+
+	userCredentialId, appErr := userCredentialModel.Create(
+		request.Context(),
+		user.Id,
+		credentialId,
+		base64.RawURLEncoding.EncodeToString(credential.PublicKey),
+		credential.SignCount,
+		body.Name,
+	)
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	userCredential, appErr := userCredentialModel.FindById(
+		request.Context(),
+		userCredentialId,
+	)
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	utils.ReturnJSONResponse(
+		writer,
+		201,
+		userCredential,
+	)
+}
+
+func (w *WebAuthn) AuthenticationOptions(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	challengeId, challenge, appErr := w.createChallenge(
+		request.Context(),
+		nil,
+		types.WebAuthnChallengeAuthentication,
+	)
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	type publicKeyOptions struct {
+		Challenge        string `json:"challenge"`
+		RelyingPartyId   string `json:"rpId"`
+		Timeout          int64  `json:"timeout"`
+		UserVerification string `json:"userVerification"`
+	}
+
+	utils.ReturnJSONResponse(
+		writer,
+		200,
+		&struct {
+			ChallengeId string           `json:"challengeId"`
+			PublicKey   publicKeyOptions `json:"publicKey"`
+		}{
+			ChallengeId: challengeId,
+			PublicKey: publicKeyOptions{
+				Challenge:        challenge,
+				RelyingPartyId:   w.RelyingParty.Id,
+				Timeout:          5 * 60 * 1000,
+				UserVerification: "preferred",
+			},
+		},
+	)
+}
+
+func (w *WebAuthn) Authenticate(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	var body *struct {
+		ChallengeId string `json:"challengeId"`
+		Credential  struct {
+			Id       string `json:"id"`
+			Response struct {
+				ClientDataJSON    string `json:"clientDataJSON"`
+				AuthenticatorData string `json:"authenticatorData"`
+				Signature         string `json:"signature"`
+			} `json:"response"`
+		} `json:"credential"`
+	}
+
+	err := json.NewDecoder(request.Body).Decode(&body)
+
+	if err == io.EOF {
+		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
+			Error: "Missing credential.",
+		})
+
+		return
+	}
+
+	if err != nil {
+		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
+			Error: "Invalid data.",
+		})
+
+		return
+	}
+
+	clientDataJSON, clientDataErr := decodeBase64URL(body.Credential.Response.ClientDataJSON)
+	authenticatorData, authenticatorDataErr := decodeBase64URL(body.Credential.Response.AuthenticatorData)
+	signature, signatureErr := decodeBase64URL(body.Credential.Response.Signature)
+
+	if body.Credential.Id == "" || clientDataErr != nil || authenticatorDataErr != nil || signatureErr != nil {
+		utils.ReturnJSONResponse(writer, 400, &types.ReturnError{
+			Error: "Invalid data.",
+		})
+
+		return
+	}
+
+	challenge, appErr := w.Repositories.WebAuthnChallenges.Consume(
+		request.Context(),
+		body.ChallengeId,
+		types.WebAuthnChallengeAuthentication,
+	)
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	userCredentialModel := w.Repositories.UserCredentials
+
+	userCredential, appErr := userCredentialModel.FindByCredentialId(
+		request.Context(),
+		strings.TrimRight(body.Credential.Id, "="),
+	)
+
+	if appErr != nil && appErr.StatusCode == 404 {
+		appErr = &types.AppError{
+			StatusCode: 401,
+			Message:    "Invalid credential.",
+		}
+	}
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	publicKey, err := decodeBase64URL(userCredential.PublicKey)
+
+	if err != nil {
+		utils.ReturnJSONResponse(writer, 500, &types.ReturnError{
+			Error: "Error reading credential.",
+		})
+
+		return
+	}
+
+	signCount, err := w.RelyingParty.VerifyAssertion(
+		challenge.Challenge,
+		&webauthn.Credential{
+			PublicKey: publicKey,
+			SignCount: userCredential.SignCount,
+		},
+		clientDataJSON,
+		authenticatorData,
+		signature,
+	)
+
+	if err != nil {
+		utils.ReturnJSONResponse(writer, 401, &types.ReturnError{
+			Error: "Invalid credential.",
+		})
+
+		return
+	}
+
+	updated, appErr := userCredentialModel.UpdateSignCount(
+		request.Context(),
+		userCredential.Id,
+		userCredential.SignCount,
+		signCount,
+	)
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	if !updated && signCount != 0 {
+		utils.ReturnJSONResponse(writer, 401, &types.ReturnError{
+			Error: "Invalid credential.",
+		})
+
+		return
+	}
+
+	user, appErr := w.Repositories.Users.FindById(
+		request.Context(),
+		userCredential.UserId,
+	)
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	if user.Banned {
+		utils.ReturnJSONResponse(writer, 403, &types.ReturnError{
+			Error: "User banned.",
+		})
+
+		return
+	}
+
+	userToken, appErr := startUserSession(
+		request,
+		w.Repositories,
+		w.Tokens,
+		w.now(),
+		w.Lifetimes,
+		user.Id,
+		&userCredential.Id,
+		nil,
+	)
+
+	if appErr != nil {
+		utils.ReturnJSONResponse(
+			writer,
+			appErr.StatusCode,
+			&types.ReturnError{Error: appErr.Message},
+		)
+
+		return
+	}
+
+	utils.ReturnJSONResponse(
+		writer,
+		200,
+		&struct {
+			User  *types.User `json:"user"`
+			Token string      `json:"token"`
+		}{User: user, Token: userToken},
+	)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os/signal"
+	"syscall"
+
+	"github.com/sandromai/go-http-server/config"
+)
+
+func newServer(
+	settings config.Server,
+	handler http.Handler,
+) *http.Server {
+	server := &http.Server{
+		Addr:              settings.Address,
+		Handler:           handler,
+		ReadTimeout:       settings.ReadTimeout,
+		ReadHeaderTimeout: settings.ReadHeaderTimeout,
+		WriteTimeout:      settings.WriteTimeout,
+		IdleTimeout:       settings.IdleTimeout,
+		ErrorLog:          slog.NewLogLogger(slog.Default().Handler(), slog.LevelError),
+	}
+
+	if !settings.HTTP2 {
+		server.TLSNextProto = map[string]func(*http.Server, *tls.Conn, http.Handler){}
+	}
+
+	return server
+}
+
+func serve(
+	settings config.Server,
+	handler http.Handler,
+) error {
+	server := newServer(settings, handler)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+
+	defer stop()
+
+	serveErr := make(chan error, 1)
+
+	slog.Info("serving", "address", settings.Address, "tls", settings.TLSCertFile != "")
+
+	go func() {
+		if settings.TLSCertFile != "" {
+			serveErr <- server.ListenAndServeTLS(settings.TLSCertFile, settings.TLSKeyFile)
+		} else {
+			serveErr <- server.ListenAndServe()
+		}
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	stop()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), settings.ShutdownTimeout)
+
+	defer cancel()
+
+	shutdownErr := server.Shutdown(shutdownCtx)
+
+	if err := <-serveErr; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return shutdownErr
+}
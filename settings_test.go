@@ -0,0 +1,95 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/sandromai/go-http-server/types"
+)
+
+func TestRoles(t *testing.T) {
+	forEachBackend(t, testRoles)
+}
+
+func testRoles(
+	t *testing.T,
+	server *testServer,
+) {
+	adminToken := server.loginAdmin(testAdminUsername, testAdminPassword)
+
+	server.request("GET", "/routes/roles/", nil, nil).expect(t, 401, "No authorization provided.")
+
+	server.request("POST", "/routes/roles/", map[string]any{
+		"description": "No name",
+	}, bearer(adminToken)).expect(t, 400, "Insert the name.")
+
+	response := server.request("POST", "/routes/roles/", map[string]any{
+		"name":        "auditor",
+		"description": "Reads settings",
+		"permissions": []string{types.PermissionEmailSettingsList},
+	}, bearer(adminToken))
+
+	response.expect(t, 201, "")
+
+	var role types.Role
+
+	response.decode(t, &role)
+
+	if role.Name != "auditor" || len(role.Permissions) != 1 {
+		t.Fatalf("unexpected role %s", response.Body)
+	}
+
+	response = server.request("GET", "/routes/roles/", nil, bearer(adminToken))
+
+	response.expect(t, 200, "")
+
+	var roles []types.Role
+
+	response.decode(t, &roles)
+
+	if len(roles) != 4 {
+		t.Fatalf("got %v roles, expected 4", len(roles))
+	}
+
+	server.request("PUT", "/routes/roles/"+types.SuperAdminRoleId, map[string]any{
+		"name": "renamed",
+	}, bearer(adminToken)).expect(t, 403, "This role can't be changed.")
+
+	server.request("DELETE", "/routes/roles/"+types.SuperAdminRoleId, nil, bearer(adminToken)).expect(t, 403, "This role can't be changed.")
+
+	server.request("DELETE", "/routes/roles/"+role.Id, nil, bearer(adminToken)).expect(t, 200, "")
+}
+
+func TestEmailSettings(t *testing.T) {
+	forEachBackend(t, testEmailSettings)
+}
+
+func testEmailSettings(
+	t *testing.T,
+	server *testServer,
+) {
+	adminToken := server.loginAdmin(testAdminUsername, testAdminPassword)
+
+	server.request("PUT", "/routes/emailSettings/update", map[string]any{
+		"host": "smtp.example.com",
+		"port": "587",
+	}, bearer(adminToken)).expect(t, 400, "Insert the username.")
+
+	server.request("PUT", "/routes/emailSettings/update", map[string]any{
+		"host":     "smtp.example.com",
+		"port":     "587",
+		"username": "mailer",
+		"password": "secret",
+	}, bearer(adminToken)).expect(t, 200, "")
+
+	response := server.request("GET", "/routes/emailSettings/list", nil, bearer(adminToken))
+
+	response.expect(t, 200, "")
+
+	var emailSettings types.EmailSetting
+
+	response.decode(t, &emailSettings)
+
+	if emailSettings.Host != "smtp.example.com" || emailSettings.Port != "587" || emailSettings.Username != "mailer" {
+		t.Fatalf("unexpected email settings %s", response.Body)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sandromai/go-http-server/clock"
+	"github.com/sandromai/go-http-server/config"
+	"github.com/sandromai/go-http-server/models"
+)
+
+func newSQLiteTestServer(
+	t *testing.T,
+) *testServer {
+	t.Helper()
+
+	return newTestServerWithBackend(t, func(appConfig *config.Config, appClock clock.Clock) models.Backend {
+		return openSQLiteTestBackend(t, appConfig, appClock)
+	})
+}
+
+func forEachBackend(
+	t *testing.T,
+	test func(t *testing.T, server *testServer),
+) {
+	t.Helper()
+
+	backends := []struct {
+		name      string
+		newServer func(t *testing.T) *testServer
+	}{
+		{"memory", newTestServer},
+		{"sqlite", newSQLiteTestServer},
+	}
+
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			test(t, backend.newServer(t))
+		})
+	}
+}
+
+func openSQLiteTestBackend(
+	t *testing.T,
+	appConfig *config.Config,
+	appClock clock.Clock,
+) models.Backend {
+	t.Helper()
+
+	appConfig.Database.Driver = "sqlite"
+	appConfig.Database.Name = filepath.Join(t.TempDir(), "test.db")
+	appConfig.Database.AutoMigrate = true
+	appConfig.Database.QueryTimeout = time.Minute
+
+	backend, err := openBackend(context.Background(), appConfig, appClock)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { backend.Close() })
+
+	appErr := backend.Repositories().EmailSettings.Update(context.Background(), map[string]string{
+		"host":     "smtp.example.com",
+		"port":     "587",
+		"username": "mailer",
+		"password": "secret",
+	})
+
+	if appErr != nil {
+		t.Fatal(appErr.Message)
+	}
+
+	return backend
+}
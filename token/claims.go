@@ -0,0 +1,67 @@
+package token
+
+import (
+	"encoding/json"
+)
+
+type Claims interface {
+	Registered() *RegisteredClaims
+}
+
+type RegisteredClaims struct {
+	Issuer    string   `json:"iss,omitempty"`
+	Subject   string   `json:"sub,omitempty"`
+	Audience  Audience `json:"aud,omitempty"`
+	Id        string   `json:"jti,omitempty"`
+	NotBefore int64    `json:"nbf,omitempty"`
+	ExpiresAt int64    `json:"exp,omitempty"`
+	IssuedAt  int64    `json:"iat,omitempty"`
+}
+
+func (claims *RegisteredClaims) Registered() *RegisteredClaims {
+	return claims
+}
+
+type Audience []string
+
+func (audience Audience) Contains(
+	value string,
+) bool {
+	for _, item := range audience {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (audience Audience) MarshalJSON() ([]byte, error) {
+	if len(audience) == 1 {
+		return json.Marshal(audience[0])
+	}
+
+	return json.Marshal([]string(audience))
+}
+
+func (audience *Audience) UnmarshalJSON(
+	data []byte,
+) error {
+	var single string
+
+	if err := json.Unmarshal(data, &single); err == nil {
+		*audience = Audience{single}
+
+		return nil
+	}
+
+	var multiple []string
+
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+
+	*audience = Audience(multiple)
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package token
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"math/big"
+)
+
+type ECDSAKey struct {
+	Id         string
+	PrivateKey *ecdsa.PrivateKey
+	PublicKey  *ecdsa.PublicKey
+}
+
+func (*ECDSAKey) Algorithm() string {
+	return "ES256"
+}
+
+func (key *ECDSAKey) KeyId() string {
+	return key.Id
+}
+
+func (key *ECDSAKey) publicKey() *ecdsa.PublicKey {
+	if key.PublicKey != nil {
+		return key.PublicKey
+	}
+
+	if key.PrivateKey != nil {
+		return &key.PrivateKey.PublicKey
+	}
+
+	return nil
+}
+
+func (key *ECDSAKey) Sign(
+	data []byte,
+) ([]byte, error) {
+	if key.PrivateKey == nil {
+		return nil, ErrNoSigner
+	}
+
+	digest := sha256.Sum256(data)
+
+	r, s, err := ecdsa.Sign(rand.Reader, key.PrivateKey, digest[:])
+
+	if err != nil {
+		return nil, err
+	}
+
+	signature := make([]byte, 64)
+
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+
+	return signature, nil
+}
+
+func (key *ECDSAKey) Verify(
+	data,
+	signature []byte,
+) error {
+	publicKey := key.publicKey()
+
+	if publicKey == nil {
+		return errors.New("token: missing ECDSA public key")
+	}
+
+	if len(signature) != 64 {
+		return ErrSignature
+	}
+
+	digest := sha256.Sum256(data)
+
+	r := new(big.Int).SetBytes(signature[:32])
+	s := new(big.Int).SetBytes(signature[32:])
+
+	if !ecdsa.Verify(publicKey, digest[:], r, s) {
+		return ErrSignature
+	}
+
+	return nil
+}
+
+func (key *ECDSAKey) JWK() *JWK {
+	publicKey := key.publicKey()
+
+	x := make([]byte, 32)
+	y := make([]byte, 32)
+
+	publicKey.X.FillBytes(x)
+	publicKey.Y.FillBytes(y)
+
+	return &JWK{
+		KeyType:   "EC",
+		Use:       "sig",
+		Algorithm: key.Algorithm(),
+		KeyId:     key.Id,
+		Curve:     "P-256",
+		X:         base64.RawURLEncoding.EncodeToString(x),
+		Y:         base64.RawURLEncoding.EncodeToString(y),
+	}
+}
+
+func checkECDSACurve(
+	publicKey *ecdsa.PublicKey,
+) error {
+	if publicKey.Curve != elliptic.P256() {
+		return errors.New("token: only P-256 ECDSA keys are supported")
+	}
+
+	return nil
+}
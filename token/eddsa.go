@@ -0,0 +1,71 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+)
+
+type Ed25519Key struct {
+	Id         string
+	PrivateKey ed25519.PrivateKey
+	PublicKey  ed25519.PublicKey
+}
+
+func (*Ed25519Key) Algorithm() string {
+	return "EdDSA"
+}
+
+func (key *Ed25519Key) KeyId() string {
+	return key.Id
+}
+
+func (key *Ed25519Key) publicKey() ed25519.PublicKey {
+	if key.PublicKey != nil {
+		return key.PublicKey
+	}
+
+	if key.PrivateKey != nil {
+		return key.PrivateKey.Public().(ed25519.PublicKey)
+	}
+
+	return nil
+}
+
+func (key *Ed25519Key) Sign(
+	data []byte,
+) ([]byte, error) {
+	if key.PrivateKey == nil {
+		return nil, ErrNoSigner
+	}
+
+	return ed25519.Sign(key.PrivateKey, data), nil
+}
+
+func (key *Ed25519Key) Verify(
+	data,
+	signature []byte,
+) error {
+	publicKey := key.publicKey()
+
+	if publicKey == nil {
+		return errors.New("token: missing Ed25519 public key")
+	}
+
+	if !ed25519.Verify(publicKey, data, signature) {
+		return ErrSignature
+	}
+
+	return nil
+}
+
+func (key *Ed25519Key) JWK() *JWK {
+	return &JWK{
+		KeyType:   "OKP",
+		Use:       "sig",
+		Algorithm: key.Algorithm(),
+		KeyId:     key.Id,
+		Curve:     "Ed25519",
+		X:         base64.RawURLEncoding.EncodeToString(key.publicKey()),
+	}
+}
@@ -0,0 +1,217 @@
+package token
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/sandromai/go-http-server/clock"
+)
+
+type Signer interface {
+	Algorithm() string
+	KeyId() string
+	Sign(data []byte) ([]byte, error)
+}
+
+type Verifier interface {
+	Algorithm() string
+	KeyId() string
+	Verify(data, signature []byte) error
+}
+
+type Header struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ"`
+	KeyId     string `json:"kid,omitempty"`
+}
+
+type Engine struct {
+	Signer    Signer
+	Verifiers []Verifier
+	Issuer    string
+	Leeway    time.Duration
+	Clock     clock.Clock
+}
+
+func (engine *Engine) now() time.Time {
+	return clock.Now(engine.Clock)
+}
+
+func (engine *Engine) findVerifier(
+	header *Header,
+) (Verifier, error) {
+	var candidate Verifier
+
+	for _, verifier := range engine.Verifiers {
+		if header.KeyId != "" && verifier.KeyId() != header.KeyId {
+			continue
+		}
+
+		candidate = verifier
+
+		if verifier.Algorithm() == header.Algorithm {
+			return verifier, nil
+		}
+	}
+
+	if candidate != nil {
+		return nil, ErrAlgorithm
+	}
+
+	return nil, ErrUnknownKey
+}
+
+func generateId() (string, error) {
+	bytes := make([]byte, 16)
+
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(bytes), nil
+}
+
+func Sign[C Claims](
+	engine *Engine,
+	claims C,
+) (string, error) {
+	if engine.Signer == nil {
+		return "", ErrNoSigner
+	}
+
+	registered := claims.Registered()
+
+	if registered.Issuer == "" {
+		registered.Issuer = engine.Issuer
+	}
+
+	if registered.IssuedAt == 0 {
+		registered.IssuedAt = engine.now().Unix()
+	}
+
+	if registered.Id == "" {
+		id, err := generateId()
+
+		if err != nil {
+			return "", err
+		}
+
+		registered.Id = id
+	}
+
+	jsonHeader, err := json.Marshal(&Header{
+		Algorithm: engine.Signer.Algorithm(),
+		Type:      "JWT",
+		KeyId:     engine.Signer.KeyId(),
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	jsonClaims, err := json.Marshal(claims)
+
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(jsonHeader) + "." + base64.RawURLEncoding.EncodeToString(jsonClaims)
+
+	signature, err := engine.Signer.Sign([]byte(signingInput))
+
+	if err != nil {
+		return "", err
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func Verify[C Claims](
+	engine *Engine,
+	token string,
+	audience string,
+	claims C,
+) error {
+	tokenParts := strings.Split(token, ".")
+
+	if len(tokenParts) != 3 {
+		return ErrMalformed
+	}
+
+	headerData, err := base64.RawURLEncoding.DecodeString(tokenParts[0])
+
+	if err != nil {
+		return ErrMalformed
+	}
+
+	header := &Header{}
+
+	if err = json.Unmarshal(headerData, header); err != nil {
+		return ErrMalformed
+	}
+
+	if header.Type != "" && header.Type != "JWT" {
+		return ErrMalformed
+	}
+
+	verifier, err := engine.findVerifier(header)
+
+	if err != nil {
+		return err
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(tokenParts[2])
+
+	if err != nil {
+		return ErrMalformed
+	}
+
+	if err = verifier.Verify([]byte(tokenParts[0]+"."+tokenParts[1]), signature); err != nil {
+		return ErrSignature
+	}
+
+	claimsData, err := base64.RawURLEncoding.DecodeString(tokenParts[1])
+
+	if err != nil {
+		return ErrMalformed
+	}
+
+	if err = json.Unmarshal(claimsData, claims); err != nil {
+		return ErrMalformed
+	}
+
+	return engine.validate(claims.Registered(), audience)
+}
+
+func (engine *Engine) validate(
+	claims *RegisteredClaims,
+	audience string,
+) error {
+	now := engine.now()
+
+	if claims.ExpiresAt == 0 || !now.Before(time.Unix(claims.ExpiresAt, 0).Add(engine.Leeway)) {
+		return ErrExpired
+	}
+
+	if claims.NotBefore != 0 && now.Add(engine.Leeway).Before(time.Unix(claims.NotBefore, 0)) {
+		return ErrNotYetValid
+	}
+
+	if claims.IssuedAt != 0 && now.Add(engine.Leeway).Before(time.Unix(claims.IssuedAt, 0)) {
+		return ErrIssuedInFuture
+	}
+
+	if engine.Issuer != "" && claims.Issuer != engine.Issuer {
+		return ErrInvalidIssuer
+	}
+
+	if audience != "" && !claims.Audience.Contains(audience) {
+		return ErrInvalidAudience
+	}
+
+	return nil
+}
@@ -0,0 +1,247 @@
+package token
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sandromai/go-http-server/clock"
+)
+
+var testNow = time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+type testClaims struct {
+	RegisteredClaims
+	SessionId string `json:"sessionId"`
+}
+
+func encodeSegment(
+	t *testing.T,
+	value any,
+) string {
+	t.Helper()
+
+	data, err := json.Marshal(value)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func signRaw(
+	t *testing.T,
+	signer Signer,
+	header any,
+	claims any,
+) string {
+	t.Helper()
+
+	signingInput := encodeSegment(t, header) + "." + encodeSegment(t, claims)
+
+	signature, err := signer.Sign([]byte(signingInput))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func generateRSAKey(
+	t *testing.T,
+	id string,
+) *RSAKey {
+	t.Helper()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &RSAKey{Id: id, PrivateKey: privateKey}
+}
+
+func TestEngineRoundTrip(t *testing.T) {
+	key := &HMACKey{Id: "hmac", Secret: []byte("test-secret")}
+
+	engine := &Engine{
+		Signer:    key,
+		Verifiers: []Verifier{key},
+		Issuer:    "issuer",
+		Clock:     clock.NewManual(testNow),
+	}
+
+	tokenString, err := Sign(engine, &testClaims{
+		RegisteredClaims: RegisteredClaims{
+			Audience:  Audience{"user"},
+			ExpiresAt: testNow.Add(time.Hour).Unix(),
+		},
+		SessionId: "session",
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims := &testClaims{}
+
+	if err = Verify(engine, tokenString, "user", claims); err != nil {
+		t.Fatal(err)
+	}
+
+	if claims.SessionId != "session" || claims.Issuer != "issuer" || claims.IssuedAt != testNow.Unix() || claims.Id == "" {
+		t.Fatalf("unexpected claims %+v", claims)
+	}
+}
+
+func TestEngineRejectsAlgorithmAndKeyMismatches(t *testing.T) {
+	hmacKey := &HMACKey{Id: "hmac", Secret: []byte("test-secret")}
+	rsaKey := generateRSAKey(t, "rsa")
+
+	engine := &Engine{
+		Signer:    rsaKey,
+		Verifiers: []Verifier{rsaKey, hmacKey},
+		Clock:     clock.NewManual(testNow),
+	}
+
+	claims := &RegisteredClaims{ExpiresAt: testNow.Add(time.Hour).Unix()}
+
+	rsaPublicKey, err := x509.MarshalPKIXPublicKey(&rsaKey.PrivateKey.PublicKey)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unsigned := encodeSegment(t, &Header{Algorithm: "none", Type: "JWT", KeyId: "rsa"}) + "." + encodeSegment(t, claims) + "."
+
+	tests := []struct {
+		name     string
+		token    string
+		expected error
+	}{
+		{"alg none", unsigned, ErrAlgorithm},
+		{"alg none without kid", encodeSegment(t, &Header{Algorithm: "none"}) + "." + encodeSegment(t, claims) + ".", ErrAlgorithm},
+		{"HS256 keyed with the RSA public key", signRaw(t, &HMACKey{Secret: rsaPublicKey}, &Header{Algorithm: "HS256", KeyId: "rsa"}, claims), ErrAlgorithm},
+		{"RS256 header on the HMAC kid", signRaw(t, rsaKey, &Header{Algorithm: "RS256", KeyId: "hmac"}, claims), ErrAlgorithm},
+		{"unknown kid", signRaw(t, rsaKey, &Header{Algorithm: "RS256", KeyId: "rotated-out"}, claims), ErrUnknownKey},
+		{"HS256 with a different secret", signRaw(t, &HMACKey{Secret: []byte("other")}, &Header{Algorithm: "HS256", KeyId: "hmac"}, claims), ErrSignature},
+		{"valid RS256", signRaw(t, rsaKey, &Header{Algorithm: "RS256", KeyId: "rsa"}, claims), nil},
+		{"valid HS256", signRaw(t, hmacKey, &Header{Algorithm: "HS256", KeyId: "hmac"}, claims), nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := Verify(engine, test.token, "", &RegisteredClaims{}); !errors.Is(err, test.expected) {
+				t.Fatalf("got %v, expected %v", err, test.expected)
+			}
+		})
+	}
+}
+
+func TestEngineRejectsTamperedAndMalformedTokens(t *testing.T) {
+	key := &HMACKey{Secret: []byte("test-secret")}
+
+	engine := &Engine{
+		Signer:    key,
+		Verifiers: []Verifier{key},
+		Clock:     clock.NewManual(testNow),
+	}
+
+	header := &Header{Algorithm: "HS256", Type: "JWT"}
+	claims := &RegisteredClaims{Subject: "user", ExpiresAt: testNow.Add(time.Hour).Unix()}
+
+	valid := signRaw(t, key, header, claims)
+	parts := strings.Split(valid, ".")
+
+	signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+
+	signature[0] ^= 0xff
+
+	tests := []struct {
+		name     string
+		token    string
+		expected error
+	}{
+		{"flipped signature byte", parts[0] + "." + parts[1] + "." + base64.RawURLEncoding.EncodeToString(signature), ErrSignature},
+		{"swapped claims", parts[0] + "." + encodeSegment(t, &RegisteredClaims{Subject: "admin", ExpiresAt: claims.ExpiresAt}) + "." + parts[2], ErrSignature},
+		{"empty signature", parts[0] + "." + parts[1] + ".", ErrSignature},
+		{"two segments", parts[0] + "." + parts[1], ErrMalformed},
+		{"four segments", valid + ".extra", ErrMalformed},
+		{"empty", "", ErrMalformed},
+		{"header not base64", "%%%." + parts[1] + "." + parts[2], ErrMalformed},
+		{"header not JSON", base64.RawURLEncoding.EncodeToString([]byte("header")) + "." + parts[1] + "." + parts[2], ErrMalformed},
+		{"unexpected typ", signRaw(t, key, &Header{Algorithm: "HS256", Type: "JWE"}, claims), ErrMalformed},
+		{"signature not base64", parts[0] + "." + parts[1] + ".%%%", ErrMalformed},
+		{"claims not JSON", signRaw(t, key, header, "claims"), ErrMalformed},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := Verify(engine, test.token, "", &RegisteredClaims{}); !errors.Is(err, test.expected) {
+				t.Fatalf("got %v, expected %v", err, test.expected)
+			}
+		})
+	}
+}
+
+func TestEngineValidatesClaims(t *testing.T) {
+	key := &HMACKey{Secret: []byte("test-secret")}
+
+	engine := &Engine{
+		Signer:    key,
+		Verifiers: []Verifier{key},
+		Issuer:    "issuer",
+		Leeway:    30 * time.Second,
+		Clock:     clock.NewManual(testNow),
+	}
+
+	at := func(offset time.Duration) int64 {
+		return testNow.Add(offset).Unix()
+	}
+
+	tests := []struct {
+		name     string
+		claims   RegisteredClaims
+		audience string
+		expected error
+	}{
+		{"valid", RegisteredClaims{Issuer: "issuer", Audience: Audience{"user"}, ExpiresAt: at(time.Hour)}, "user", nil},
+		{"missing exp", RegisteredClaims{Issuer: "issuer"}, "", ErrExpired},
+		{"expired", RegisteredClaims{Issuer: "issuer", ExpiresAt: at(-time.Minute)}, "", ErrExpired},
+		{"expired within leeway", RegisteredClaims{Issuer: "issuer", ExpiresAt: at(-10 * time.Second)}, "", nil},
+		{"not yet valid", RegisteredClaims{Issuer: "issuer", NotBefore: at(time.Minute), ExpiresAt: at(time.Hour)}, "", ErrNotYetValid},
+		{"not yet valid within leeway", RegisteredClaims{Issuer: "issuer", NotBefore: at(10 * time.Second), ExpiresAt: at(time.Hour)}, "", nil},
+		{"issued in the future", RegisteredClaims{Issuer: "issuer", IssuedAt: at(time.Minute), ExpiresAt: at(time.Hour)}, "", ErrIssuedInFuture},
+		{"other issuer", RegisteredClaims{Issuer: "other", ExpiresAt: at(time.Hour)}, "", ErrInvalidIssuer},
+		{"login token presented as user token", RegisteredClaims{Issuer: "issuer", Audience: Audience{"login"}, ExpiresAt: at(time.Hour)}, "user", ErrInvalidAudience},
+		{"missing audience", RegisteredClaims{Issuer: "issuer", ExpiresAt: at(time.Hour)}, "user", ErrInvalidAudience},
+		{"one of several audiences", RegisteredClaims{Issuer: "issuer", Audience: Audience{"login", "user"}, ExpiresAt: at(time.Hour)}, "user", nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			claims := test.claims
+
+			tokenString := signRaw(t, key, &Header{Algorithm: "HS256", Type: "JWT"}, &claims)
+
+			if err := Verify(engine, tokenString, test.audience, &RegisteredClaims{}); !errors.Is(err, test.expected) {
+				t.Fatalf("got %v, expected %v", err, test.expected)
+			}
+		})
+	}
+}
+
+func TestSignWithoutSigner(t *testing.T) {
+	if _, err := Sign(&Engine{}, &RegisteredClaims{}); !errors.Is(err, ErrNoSigner) {
+		t.Fatalf("got %v, expected %v", err, ErrNoSigner)
+	}
+}
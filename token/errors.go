@@ -0,0 +1,16 @@
+package token
+
+import "errors"
+
+var (
+	ErrMalformed       = errors.New("token: malformed token")
+	ErrAlgorithm       = errors.New("token: unexpected signing algorithm")
+	ErrUnknownKey      = errors.New("token: no key available to verify token")
+	ErrSignature       = errors.New("token: invalid signature")
+	ErrExpired         = errors.New("token: token has expired")
+	ErrNotYetValid     = errors.New("token: token is not valid yet")
+	ErrIssuedInFuture  = errors.New("token: token was issued in the future")
+	ErrInvalidIssuer   = errors.New("token: invalid issuer")
+	ErrInvalidAudience = errors.New("token: invalid audience")
+	ErrNoSigner        = errors.New("token: no signing key configured")
+)
@@ -0,0 +1,48 @@
+package token
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+)
+
+type HMACKey struct {
+	Id     string
+	Secret []byte
+}
+
+func (*HMACKey) Algorithm() string {
+	return "HS256"
+}
+
+func (key *HMACKey) KeyId() string {
+	return key.Id
+}
+
+func (key *HMACKey) Sign(
+	data []byte,
+) ([]byte, error) {
+	hash := hmac.New(sha256.New, key.Secret)
+
+	if _, err := hash.Write(data); err != nil {
+		return nil, err
+	}
+
+	return hash.Sum(nil), nil
+}
+
+func (key *HMACKey) Verify(
+	data,
+	signature []byte,
+) error {
+	expectedSignature, err := key.Sign(data)
+
+	if err != nil {
+		return err
+	}
+
+	if !hmac.Equal(expectedSignature, signature) {
+		return ErrSignature
+	}
+
+	return nil
+}
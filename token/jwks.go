@@ -0,0 +1,155 @@
+package token
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+)
+
+type PublicKey interface {
+	JWK() *JWK
+}
+
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use,omitempty"`
+	Algorithm string `json:"alg,omitempty"`
+	KeyId     string `json:"kid,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []*JWK `json:"keys"`
+}
+
+func (jwk *JWK) Thumbprint() (string, error) {
+	var members any
+
+	switch jwk.KeyType {
+	case "RSA":
+		members = &struct {
+			E       string `json:"e"`
+			KeyType string `json:"kty"`
+			N       string `json:"n"`
+		}{E: jwk.E, KeyType: jwk.KeyType, N: jwk.N}
+	case "EC":
+		members = &struct {
+			Curve   string `json:"crv"`
+			KeyType string `json:"kty"`
+			X       string `json:"x"`
+			Y       string `json:"y"`
+		}{Curve: jwk.Curve, KeyType: jwk.KeyType, X: jwk.X, Y: jwk.Y}
+	default:
+		members = &struct {
+			Curve   string `json:"crv"`
+			KeyType string `json:"kty"`
+			X       string `json:"x"`
+		}{Curve: jwk.Curve, KeyType: jwk.KeyType, X: jwk.X}
+	}
+
+	data, err := json.Marshal(members)
+
+	if err != nil {
+		return "", err
+	}
+
+	digest := sha256.Sum256(data)
+
+	return base64.RawURLEncoding.EncodeToString(digest[:]), nil
+}
+
+func (jwk *JWK) Verifier() (Verifier, error) {
+	decode := func(value string) (*big.Int, error) {
+		data, err := base64.RawURLEncoding.DecodeString(value)
+
+		if err != nil || len(data) == 0 {
+			return nil, errors.New("token: invalid JWK parameter")
+		}
+
+		return new(big.Int).SetBytes(data), nil
+	}
+
+	switch {
+	case jwk.KeyType == "RSA" && (jwk.Algorithm == "" || jwk.Algorithm == "RS256"):
+		n, err := decode(jwk.N)
+
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := decode(jwk.E)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if !e.IsInt64() || e.Int64() > 1<<31-1 || n.BitLen() < 2048 {
+			return nil, errors.New("token: unsupported RSA key")
+		}
+
+		return &RSAKey{
+			Id:        jwk.KeyId,
+			PublicKey: &rsa.PublicKey{N: n, E: int(e.Int64())},
+		}, nil
+	case jwk.KeyType == "EC" && jwk.Curve == "P-256" && (jwk.Algorithm == "" || jwk.Algorithm == "ES256"):
+		x, err := decode(jwk.X)
+
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := decode(jwk.Y)
+
+		if err != nil {
+			return nil, err
+		}
+
+		publicKey := &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+
+		if !publicKey.Curve.IsOnCurve(x, y) {
+			return nil, errors.New("token: invalid EC point")
+		}
+
+		return &ECDSAKey{
+			Id:        jwk.KeyId,
+			PublicKey: publicKey,
+		}, nil
+	case jwk.KeyType == "OKP" && jwk.Curve == "Ed25519" && (jwk.Algorithm == "" || jwk.Algorithm == "EdDSA"):
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("token: invalid Ed25519 key")
+		}
+
+		return &Ed25519Key{
+			Id:        jwk.KeyId,
+			PublicKey: ed25519.PublicKey(x),
+		}, nil
+	}
+
+	return nil, errors.New("token: unsupported JWK")
+}
+
+func (engine *Engine) JWKS() *JWKSet {
+	set := &JWKSet{
+		Keys: []*JWK{},
+	}
+
+	for _, verifier := range engine.Verifiers {
+		if publicKey, ok := verifier.(PublicKey); ok {
+			set.Keys = append(set.Keys, publicKey.JWK())
+		}
+	}
+
+	return set
+}
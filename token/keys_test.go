@@ -0,0 +1,340 @@
+package token
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sandromai/go-http-server/clock"
+)
+
+func encodePEM(
+	t *testing.T,
+	blockType string,
+	key any,
+) []byte {
+	t.Helper()
+
+	var data []byte
+	var err error
+
+	switch blockType {
+	case "PRIVATE KEY":
+		data, err = x509.MarshalPKCS8PrivateKey(key)
+	case "PUBLIC KEY":
+		data, err = x509.MarshalPKIXPublicKey(key)
+	}
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: data})
+}
+
+func generateKeys(
+	t *testing.T,
+) map[string]any {
+	t.Helper()
+
+	rsaKey := generateRSAKey(t, "")
+
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return map[string]any{
+		"RS256": rsaKey.PrivateKey,
+		"ES256": ecdsaKey,
+		"EdDSA": ed25519Key,
+	}
+}
+
+func publicPart(
+	key any,
+) any {
+	switch typedKey := key.(type) {
+	case *ecdsa.PrivateKey:
+		return &typedKey.PublicKey
+	case ed25519.PrivateKey:
+		return typedKey.Public()
+	}
+
+	return &key.(*rsa.PrivateKey).PublicKey
+}
+
+func TestKeysRoundTripPerAlgorithm(t *testing.T) {
+	for algorithm, privateKey := range generateKeys(t) {
+		t.Run(algorithm, func(t *testing.T) {
+			signingKey, err := ParsePEM(encodePEM(t, "PRIVATE KEY", privateKey), "signing")
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if signingKey.Algorithm() != algorithm || signingKey.KeyId() != "signing" || !CanSign(signingKey) {
+				t.Fatalf("unexpected key %v %v", signingKey.Algorithm(), signingKey.KeyId())
+			}
+
+			publicKey, err := ParsePEM(encodePEM(t, "PUBLIC KEY", publicPart(privateKey)), "")
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if CanSign(publicKey) {
+				t.Fatal("expected a public key not to sign")
+			}
+
+			thumbprint, err := publicKey.(PublicKey).JWK().Thumbprint()
+
+			if err != nil || publicKey.KeyId() != thumbprint {
+				t.Fatalf("expected the kid to default to the JWK thumbprint, got %q", publicKey.KeyId())
+			}
+
+			jwkKey, err := signingKey.(PublicKey).JWK().Verifier()
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			signer := &Engine{
+				Signer: signingKey.(Signer),
+				Clock:  clock.NewManual(testNow),
+			}
+
+			tokenString, err := Sign(signer, &RegisteredClaims{ExpiresAt: testNow.Add(time.Hour).Unix()})
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, verifier := range []Verifier{signingKey, jwkKey} {
+				engine := &Engine{
+					Verifiers: []Verifier{verifier},
+					Clock:     clock.NewManual(testNow),
+				}
+
+				if err = Verify(engine, tokenString, "", &RegisteredClaims{}); err != nil {
+					t.Fatalf("verifying with %T: %v", verifier, err)
+				}
+			}
+
+			parts := strings.Split(tokenString, ".")
+
+			tampered := parts[0] + "." + encodeSegment(t, &RegisteredClaims{Subject: "admin", ExpiresAt: testNow.Add(time.Hour).Unix()}) + "." + parts[2]
+
+			engine := &Engine{
+				Verifiers: []Verifier{jwkKey},
+				Clock:     clock.NewManual(testNow),
+			}
+
+			if err = Verify(engine, tampered, "", &RegisteredClaims{}); !errors.Is(err, ErrSignature) {
+				t.Fatalf("got %v, expected %v", err, ErrSignature)
+			}
+		})
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	oldKey := generateRSAKey(t, "2024-01")
+
+	newKey, err := ParsePEM(encodePEM(t, "PRIVATE KEY", generateKeys(t)["ES256"]), "2024-02")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	retiredKey := generateRSAKey(t, "2023-12")
+
+	claims := &RegisteredClaims{ExpiresAt: testNow.Add(time.Hour).Unix()}
+
+	oldToken, err := Sign(&Engine{Signer: oldKey, Clock: clock.NewManual(testNow)}, claims)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	retiredToken, err := Sign(&Engine{Signer: retiredKey, Clock: clock.NewManual(testNow)}, &RegisteredClaims{ExpiresAt: claims.ExpiresAt})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	engine := &Engine{
+		Signer:    newKey.(Signer),
+		Verifiers: []Verifier{newKey, &RSAKey{Id: oldKey.Id, PublicKey: &oldKey.PrivateKey.PublicKey}},
+		Clock:     clock.NewManual(testNow),
+	}
+
+	newToken, err := Sign(engine, &RegisteredClaims{ExpiresAt: claims.ExpiresAt})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if header := decodeHeader(t, newToken); header.KeyId != "2024-02" || header.Algorithm != "ES256" {
+		t.Fatalf("expected new tokens under the new kid, got %+v", header)
+	}
+
+	for name, tokenString := range map[string]string{"new": newToken, "old": oldToken} {
+		if err = Verify(engine, tokenString, "", &RegisteredClaims{}); err != nil {
+			t.Fatalf("%v token: %v", name, err)
+		}
+	}
+
+	if err = Verify(engine, retiredToken, "", &RegisteredClaims{}); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("got %v, expected %v", err, ErrUnknownKey)
+	}
+}
+
+func decodeHeader(
+	t *testing.T,
+	tokenString string,
+) *Header {
+	t.Helper()
+
+	header := &Header{}
+
+	data, err := base64.RawURLEncoding.DecodeString(strings.Split(tokenString, ".")[0])
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = json.Unmarshal(data, header); err != nil {
+		t.Fatal(err)
+	}
+
+	return header
+}
+
+func TestJWKSPublishesOnlyPublicParts(t *testing.T) {
+	keys := generateKeys(t)
+
+	var verifiers []Verifier
+
+	for _, algorithm := range []string{"RS256", "ES256", "EdDSA"} {
+		key, err := ParsePEM(encodePEM(t, "PRIVATE KEY", keys[algorithm]), algorithm+"-key")
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		verifiers = append(verifiers, key)
+	}
+
+	verifiers = append(verifiers, &HMACKey{Id: "shared", Secret: []byte("secret")})
+
+	data, err := json.Marshal((&Engine{Verifiers: verifiers}).JWKS())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var set struct {
+		Keys []map[string]string `json:"keys"`
+	}
+
+	if err = json.Unmarshal(data, &set); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(set.Keys) != 3 {
+		t.Fatalf("expected the three asymmetric keys, got %s", data)
+	}
+
+	expectedMembers := map[string][]string{
+		"RSA": {"alg", "e", "kid", "kty", "n", "use"},
+		"EC":  {"alg", "crv", "kid", "kty", "use", "x", "y"},
+		"OKP": {"alg", "crv", "kid", "kty", "use", "x"},
+	}
+
+	for _, jwk := range set.Keys {
+		for _, private := range []string{"d", "p", "q", "dp", "dq", "qi", "k"} {
+			if _, found := jwk[private]; found {
+				t.Fatalf("JWK %v exposes private member %q", jwk["kid"], private)
+			}
+		}
+
+		expected := expectedMembers[jwk["kty"]]
+
+		if len(jwk) != len(expected) {
+			t.Fatalf("JWK %v has members %v, expected %v", jwk["kid"], jwk, expected)
+		}
+
+		for _, member := range expected {
+			if jwk[member] == "" {
+				t.Fatalf("JWK %v is missing %q", jwk["kid"], member)
+			}
+		}
+	}
+
+	if strings.Contains(string(data), "secret") || strings.Contains(string(data), "shared") {
+		t.Fatalf("expected HMAC keys to stay out of the JWKS, got %s", data)
+	}
+}
+
+func TestParsePEMErrors(t *testing.T) {
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	x25519Key, err := ecdh.X25519().GenerateKey(rand.Reader)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block, _ := pem.Decode(encodePEM(t, "PRIVATE KEY", generateKeys(t)["EdDSA"]))
+
+	truncated := pem.EncodeToMemory(&pem.Block{Type: block.Type, Bytes: block.Bytes[:len(block.Bytes)-4]})
+
+	tests := []struct {
+		name    string
+		data    []byte
+		message string
+	}{
+		{"no PEM block", []byte("not a key"), "no PEM block"},
+		{"unsupported block", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte{1}}), "unsupported PEM block type CERTIFICATE"},
+		{"garbage DER", pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: []byte{1, 2, 3}}), ""},
+		{"truncated PKCS8", truncated, ""},
+		{"P-384", encodePEM(t, "PRIVATE KEY", p384Key), "only P-256"},
+		{"X25519", encodePEM(t, "PRIVATE KEY", x25519Key), "unsupported key type"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			key, err := ParsePEM(test.data, "")
+
+			if err == nil {
+				t.Fatalf("expected an error, got %T", key)
+			}
+
+			if !strings.Contains(err.Error(), test.message) {
+				t.Fatalf("got %q, expected it to mention %q", err, test.message)
+			}
+		})
+	}
+}
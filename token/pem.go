@@ -0,0 +1,120 @@
+package token
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+)
+
+func ParsePEM(
+	data []byte,
+	id string,
+) (Verifier, error) {
+	block, _ := pem.Decode(data)
+
+	if block == nil {
+		return nil, errors.New("token: no PEM block found")
+	}
+
+	var parsedKey any
+	var err error
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsedKey, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsedKey, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		parsedKey, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsedKey, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsedKey, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, errors.New("token: unsupported PEM block type " + block.Type)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var key Verifier
+
+	switch typedKey := parsedKey.(type) {
+	case *rsa.PrivateKey:
+		key = &RSAKey{PrivateKey: typedKey}
+	case *rsa.PublicKey:
+		key = &RSAKey{PublicKey: typedKey}
+	case *ecdsa.PrivateKey:
+		if err = checkECDSACurve(&typedKey.PublicKey); err != nil {
+			return nil, err
+		}
+
+		key = &ECDSAKey{PrivateKey: typedKey}
+	case *ecdsa.PublicKey:
+		if err = checkECDSACurve(typedKey); err != nil {
+			return nil, err
+		}
+
+		key = &ECDSAKey{PublicKey: typedKey}
+	case ed25519.PrivateKey:
+		key = &Ed25519Key{PrivateKey: typedKey}
+	case ed25519.PublicKey:
+		key = &Ed25519Key{PublicKey: typedKey}
+	default:
+		return nil, errors.New("token: unsupported key type")
+	}
+
+	if id == "" {
+		id, err = key.(PublicKey).JWK().Thumbprint()
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch typedKey := key.(type) {
+	case *RSAKey:
+		typedKey.Id = id
+	case *ECDSAKey:
+		typedKey.Id = id
+	case *Ed25519Key:
+		typedKey.Id = id
+	}
+
+	return key, nil
+}
+
+func LoadKeyFile(
+	path,
+	id string,
+) (Verifier, error) {
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ParsePEM(data, id)
+}
+
+func CanSign(
+	key Verifier,
+) bool {
+	switch typedKey := key.(type) {
+	case *HMACKey:
+		return len(typedKey.Secret) > 0
+	case *RSAKey:
+		return typedKey.PrivateKey != nil
+	case *ECDSAKey:
+		return typedKey.PrivateKey != nil
+	case *Ed25519Key:
+		return typedKey.PrivateKey != nil
+	}
+
+	return false
+}
@@ -0,0 +1,81 @@
+package token
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"math/big"
+)
+
+type RSAKey struct {
+	Id         string
+	PrivateKey *rsa.PrivateKey
+	PublicKey  *rsa.PublicKey
+}
+
+func (*RSAKey) Algorithm() string {
+	return "RS256"
+}
+
+func (key *RSAKey) KeyId() string {
+	return key.Id
+}
+
+func (key *RSAKey) publicKey() *rsa.PublicKey {
+	if key.PublicKey != nil {
+		return key.PublicKey
+	}
+
+	if key.PrivateKey != nil {
+		return &key.PrivateKey.PublicKey
+	}
+
+	return nil
+}
+
+func (key *RSAKey) Sign(
+	data []byte,
+) ([]byte, error) {
+	if key.PrivateKey == nil {
+		return nil, ErrNoSigner
+	}
+
+	digest := sha256.Sum256(data)
+
+	return rsa.SignPKCS1v15(rand.Reader, key.PrivateKey, crypto.SHA256, digest[:])
+}
+
+func (key *RSAKey) Verify(
+	data,
+	signature []byte,
+) error {
+	publicKey := key.publicKey()
+
+	if publicKey == nil {
+		return errors.New("token: missing RSA public key")
+	}
+
+	digest := sha256.Sum256(data)
+
+	if err := rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest[:], signature); err != nil {
+		return ErrSignature
+	}
+
+	return nil
+}
+
+func (key *RSAKey) JWK() *JWK {
+	publicKey := key.publicKey()
+
+	return &JWK{
+		KeyType:   "RSA",
+		Use:       "sig",
+		Algorithm: key.Algorithm(),
+		KeyId:     key.Id,
+		N:         base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+		E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+	}
+}
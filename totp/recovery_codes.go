@@ -0,0 +1,38 @@
+package totp
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+func GenerateRecoveryCodes(
+	quantity int,
+) ([]string, error) {
+	codes := make([]string, 0, quantity)
+
+	for i := 0; i < quantity; i++ {
+		bytes := make([]byte, 6)
+
+		if _, err := rand.Read(bytes); err != nil {
+			return nil, err
+		}
+
+		code := strings.ToLower(encoding.EncodeToString(bytes))[:10]
+
+		codes = append(codes, code[:5]+"-"+code[5:])
+	}
+
+	return codes, nil
+}
+
+func HashRecoveryCode(
+	code string,
+) string {
+	normalizedCode := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+
+	digest := sha256.Sum256([]byte(normalizedCode))
+
+	return hex.EncodeToString(digest[:])
+}
@@ -0,0 +1,130 @@
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	Digits = 6
+	Period = 30
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+func GenerateSecret() (string, error) {
+	bytes := make([]byte, 20)
+
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+
+	return encoding.EncodeToString(bytes), nil
+}
+
+func decodeSecret(
+	secret string,
+) ([]byte, error) {
+	return encoding.DecodeString(
+		strings.ToUpper(strings.ReplaceAll(strings.TrimRight(secret, "="), " ", "")),
+	)
+}
+
+func Step(
+	moment time.Time,
+) int64 {
+	return moment.Unix() / Period
+}
+
+func generate(
+	key []byte,
+	counter uint64,
+) string {
+	message := make([]byte, 8)
+
+	binary.BigEndian.PutUint64(message, counter)
+
+	hash := hmac.New(sha1.New, key)
+
+	hash.Write(message)
+
+	sum := hash.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%06d", value%1000000)
+}
+
+func Code(
+	secret string,
+	moment time.Time,
+) (string, error) {
+	key, err := decodeSecret(secret)
+
+	if err != nil {
+		return "", err
+	}
+
+	return generate(key, uint64(Step(moment))), nil
+}
+
+func Validate(
+	secret,
+	code string,
+	moment time.Time,
+	skew int64,
+) (
+	step int64,
+	valid bool,
+) {
+	key, err := decodeSecret(secret)
+
+	if err != nil || len(code) != Digits {
+		return 0, false
+	}
+
+	currentStep := Step(moment)
+
+	for offset := -skew; offset <= skew; offset++ {
+		candidateStep := currentStep + offset
+
+		if candidateStep < 0 {
+			continue
+		}
+
+		expectedCode := generate(key, uint64(candidateStep))
+
+		if subtle.ConstantTimeCompare([]byte(expectedCode), []byte(code)) == 1 {
+			return candidateStep, true
+		}
+	}
+
+	return 0, false
+}
+
+func URI(
+	issuer,
+	account,
+	secret string,
+) string {
+	values := url.Values{}
+
+	values.Set("secret", secret)
+	values.Set("issuer", issuer)
+	values.Set("algorithm", "SHA1")
+	values.Set("digits", fmt.Sprintf("%v", Digits))
+	values.Set("period", fmt.Sprintf("%v", Period))
+
+	label := url.PathEscape(issuer + ":" + account)
+
+	return "otpauth://totp/" + label + "?" + values.Encode()
+}
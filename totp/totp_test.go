@@ -0,0 +1,107 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(
+	[]byte("12345678901234567890"),
+)
+
+func TestCodeMatchesRFCVectors(t *testing.T) {
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+
+	for unix, expected := range vectors {
+		code, err := Code(rfcSecret, time.Unix(unix, 0))
+
+		if err != nil {
+			t.Fatalf("Code(%v) returned error: %v", unix, err)
+		}
+
+		if code != expected {
+			t.Errorf("Code(%v) = %v, expected %v", unix, code, expected)
+		}
+	}
+}
+
+func TestValidateAcceptsSkewedCodes(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+
+	previousCode, _ := Code(rfcSecret, now.Add(-Period*time.Second))
+
+	step, valid := Validate(rfcSecret, previousCode, now, 1)
+
+	if !valid {
+		t.Fatal("expected previous step code to be valid with skew 1")
+	}
+
+	if step != Step(now)-1 {
+		t.Errorf("expected step %v, got %v", Step(now)-1, step)
+	}
+
+	if _, valid = Validate(rfcSecret, previousCode, now, 0); valid {
+		t.Error("expected previous step code to be rejected without skew")
+	}
+}
+
+func TestValidateRejectsInvalidCodes(t *testing.T) {
+	now := time.Unix(59, 0)
+
+	for _, code := range []string{"", "12345", "000000", "2870820", "abcdef"} {
+		if _, valid := Validate(rfcSecret, code, now, 1); valid {
+			t.Errorf("expected %q to be rejected", code)
+		}
+	}
+}
+
+func TestURI(t *testing.T) {
+	uri := URI("Company", "admin", "SECRET")
+
+	if !strings.HasPrefix(uri, "otpauth://totp/Company:admin?") {
+		t.Errorf("unexpected URI prefix: %v", uri)
+	}
+
+	if !strings.Contains(uri, "secret=SECRET") || !strings.Contains(uri, "issuer=Company") {
+		t.Errorf("URI is missing parameters: %v", uri)
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(10)
+
+	if err != nil {
+		t.Fatalf("GenerateRecoveryCodes returned error: %v", err)
+	}
+
+	if len(codes) != 10 {
+		t.Fatalf("expected 10 codes, got %v", len(codes))
+	}
+
+	seen := map[string]bool{}
+
+	for _, code := range codes {
+		if len(code) != 11 || code[5] != '-' {
+			t.Errorf("unexpected code format: %v", code)
+		}
+
+		if seen[code] {
+			t.Errorf("duplicate code: %v", code)
+		}
+
+		seen[code] = true
+
+		if HashRecoveryCode(code) != HashRecoveryCode(" "+strings.ToUpper(strings.ReplaceAll(code, "-", ""))+" ") {
+			t.Errorf("hash of %v should ignore case, dashes and spaces", code)
+		}
+	}
+}
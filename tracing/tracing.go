@@ -0,0 +1,67 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/sandromai/go-http-server/config"
+)
+
+const instrumentationName = "github.com/sandromai/go-http-server"
+
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+func NewProvider(
+	exporter sdktrace.SpanExporter,
+	settings config.Tracing,
+) *sdktrace.TracerProvider {
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(settings.SampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(
+			attribute.String("service.name", settings.ServiceName),
+		)),
+	)
+}
+
+func Install(
+	provider trace.TracerProvider,
+) {
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+}
+
+func Setup(
+	ctx context.Context,
+	settings config.Tracing,
+) (func(ctx context.Context) error, error) {
+	if settings.Endpoint == "" {
+		Install(otel.GetTracerProvider())
+
+		return func(ctx context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(
+		ctx,
+		otlptracehttp.WithEndpointURL(settings.Endpoint),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	provider := NewProvider(exporter, settings)
+
+	Install(provider)
+
+	return provider.Shutdown, nil
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/sandromai/go-http-server/config"
+	"github.com/sandromai/go-http-server/tracing"
+)
+
+func installTestTracer(
+	t *testing.T,
+) (*sdktrace.TracerProvider, *tracetest.InMemoryExporter) {
+	t.Helper()
+
+	exporter := tracetest.NewInMemoryExporter()
+
+	provider := tracing.NewProvider(exporter, config.Tracing{
+		ServiceName: "test",
+		SampleRatio: 1,
+	})
+
+	previous := otel.GetTracerProvider()
+
+	tracing.Install(provider)
+
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+
+		provider.Shutdown(context.Background())
+	})
+
+	return provider, exporter
+}
+
+func findSpan(
+	t *testing.T,
+	provider *sdktrace.TracerProvider,
+	exporter *tracetest.InMemoryExporter,
+	name string,
+) tracetest.SpanStub {
+	t.Helper()
+
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		provider.ForceFlush(context.Background())
+
+		for _, span := range exporter.GetSpans() {
+			if span.Name == name {
+				return span
+			}
+		}
+	}
+
+	t.Fatalf("span %q was not exported", name)
+
+	return tracetest.SpanStub{}
+}
+
+func spanAttribute(
+	span tracetest.SpanStub,
+	key attribute.Key,
+) attribute.Value {
+	for _, keyValue := range span.Attributes {
+		if keyValue.Key == key {
+			return keyValue.Value
+		}
+	}
+
+	return attribute.Value{}
+}
+
+func TestTracingPropagatesTraceparent(t *testing.T) {
+	provider, exporter := installTestTracer(t)
+
+	server := newSQLiteTestServer(t)
+
+	response := server.request("POST", "/routes/loginTokens/create", map[string]any{
+		"email": "user@example.com",
+	}, http.Header{
+		"Traceparent": {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+	})
+
+	response.expect(t, 200, "")
+
+	serverSpan := findSpan(t, provider, exporter, "POST /routes/loginTokens/create")
+
+	if serverSpan.SpanKind != trace.SpanKindServer || serverSpan.SpanContext.TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("expected the server span to join the incoming trace, got %+v", serverSpan.SpanContext)
+	}
+
+	if !serverSpan.Parent.IsRemote() || serverSpan.Parent.SpanID().String() != "00f067aa0ba902b7" {
+		t.Fatalf("expected the incoming span as parent, got %+v", serverSpan.Parent)
+	}
+
+	if spanAttribute(serverSpan, "http.route").AsString() != "/routes/loginTokens/create" || spanAttribute(serverSpan, "http.response.status_code").AsInt64() != 200 {
+		t.Fatalf("unexpected server span attributes %v", serverSpan.Attributes)
+	}
+
+	queries := map[string]bool{}
+
+	for _, span := range exporter.GetSpans() {
+		if span.SpanContext.TraceID() != serverSpan.SpanContext.TraceID() || spanAttribute(span, "db.system").AsString() != "sqlite" {
+			continue
+		}
+
+		if span.SpanKind != trace.SpanKindClient || span.Parent.SpanID() != serverSpan.SpanContext.SpanID() {
+			t.Fatalf("expected query %q to be a child of the request span", spanAttribute(span, "db.statement").AsString())
+		}
+
+		queries[span.Name] = true
+	}
+
+	if !queries["SELECT"] || !queries["INSERT"] {
+		t.Fatalf("expected SELECT and INSERT query spans, got %v", queries)
+	}
+}
+
+func TestTracingStartsNewTraceWithoutTraceparent(t *testing.T) {
+	provider, exporter := installTestTracer(t)
+
+	server := newTestServer(t)
+
+	server.request("GET", "/health/live", nil, nil).expect(t, 200, "")
+
+	serverSpan := findSpan(t, provider, exporter, "GET /health/live")
+
+	if serverSpan.Parent.IsValid() || !serverSpan.SpanContext.IsValid() {
+		t.Fatalf("expected a root span, got parent %+v", serverSpan.Parent)
+	}
+}
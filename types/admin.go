@@ -1,9 +1,26 @@
 package types
 
+import "time"
+
 type Admin struct {
-	Id        string  `json:"id"`
-	Name      string  `json:"name"`
-	Username  string  `json:"username"`
-	CreatedBy *string `json:"createdBy"`
-	CreatedAt string  `json:"createdAt"`
+	Id               string    `json:"id"`
+	Name             string    `json:"name"`
+	Username         string    `json:"username"`
+	TwoFactorEnabled bool      `json:"twoFactorEnabled"`
+	Roles            []string  `json:"roles"`
+	Permissions      []string  `json:"permissions"`
+	CreatedBy        *string   `json:"createdBy"`
+	CreatedAt        time.Time `json:"createdAt"`
+}
+
+func (admin *Admin) HasPermission(
+	permission string,
+) bool {
+	for _, adminPermission := range admin.Permissions {
+		if adminPermission == permission {
+			return true
+		}
+	}
+
+	return false
 }
@@ -0,0 +1,14 @@
+package types
+
+import "time"
+
+type AdminToken struct {
+	Id           string    `json:"id"`
+	AdminId      string    `json:"adminId"`
+	IPAddress    string    `json:"IPAddress"`
+	Device       string    `json:"device"`
+	Disconnected bool      `json:"disconnected"`
+	LastActivity time.Time `json:"lastActivity"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
@@ -0,0 +1,11 @@
+package types
+
+import "time"
+
+type AdminTwoFactor struct {
+	AdminId      string    `json:"adminId"`
+	Secret       string    `json:"-"`
+	Enabled      bool      `json:"enabled"`
+	LastUsedStep int64     `json:"-"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
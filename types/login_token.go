@@ -1,12 +1,14 @@
 package types
 
+import "time"
+
 type LoginToken struct {
-	Id         string `json:"id"`
-	Email      string `json:"email"`
-	IPAddress  string `json:"IPAddress"`
-	Device     string `json:"device"`
-	Authorized bool   `json:"authorized"`
-	Denied     bool   `json:"denied"`
-	ExpiresAt  string `json:"expiresAt"`
-	CreatedAt  string `json:"createdAt"`
+	Id         string    `json:"id"`
+	Email      string    `json:"email"`
+	IPAddress  string    `json:"IPAddress"`
+	Device     string    `json:"device"`
+	Authorized bool      `json:"authorized"`
+	Denied     bool      `json:"denied"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	CreatedAt  time.Time `json:"createdAt"`
 }
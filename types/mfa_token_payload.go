@@ -0,0 +1,25 @@
+package types
+
+import "github.com/sandromai/go-http-server/token"
+
+type MFATokenPayload struct {
+	token.RegisteredClaims
+	AdminId    string `json:"adminId"`
+	RememberMe bool   `json:"rememberMe"`
+}
+
+func (payload *MFATokenPayload) ToJWT(
+	engine *token.Engine,
+) (
+	tokenString string,
+	appErr *AppError,
+) {
+	return signTokenPayload(engine, "mfa", payload)
+}
+
+func (payload *MFATokenPayload) FromJWT(
+	engine *token.Engine,
+	tokenString string,
+) *AppError {
+	return verifyTokenPayload(engine, "mfa", tokenString, payload)
+}
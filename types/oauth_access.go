@@ -0,0 +1,15 @@
+package types
+
+type OAuthAccess struct {
+	ClientId      string
+	Scopes        []string
+	User          *User
+	UserTokenId   string
+	ClientTokenId string
+	ExpiresAt     int64
+	IssuedAt      int64
+}
+
+func (access *OAuthAccess) HasScope(scope string) bool {
+	return ContainsOAuthScope(access.Scopes, scope)
+}
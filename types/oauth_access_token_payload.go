@@ -0,0 +1,27 @@
+package types
+
+import "github.com/sandromai/go-http-server/token"
+
+type OAuthAccessTokenPayload struct {
+	token.RegisteredClaims
+	ClientId      string `json:"client_id"`
+	Scope         string `json:"scope"`
+	UserTokenId   string `json:"userTokenId,omitempty"`
+	ClientTokenId string `json:"clientTokenId,omitempty"`
+}
+
+func (payload *OAuthAccessTokenPayload) ToJWT(
+	engine *token.Engine,
+) (
+	tokenString string,
+	appErr *AppError,
+) {
+	return signTokenPayload(engine, "oauth", payload)
+}
+
+func (payload *OAuthAccessTokenPayload) FromJWT(
+	engine *token.Engine,
+	tokenString string,
+) *AppError {
+	return verifyTokenPayload(engine, "oauth", tokenString, payload)
+}
@@ -0,0 +1,14 @@
+package types
+
+import "time"
+
+type OAuthAuthorizationCode struct {
+	CodeHash      string    `json:"-"`
+	ClientId      string    `json:"clientId"`
+	UserId        string    `json:"userId"`
+	RedirectURI   string    `json:"redirectUri"`
+	Scope         string    `json:"scope"`
+	CodeChallenge string    `json:"-"`
+	ExpiresAt     time.Time `json:"expiresAt"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
@@ -0,0 +1,36 @@
+package types
+
+import "time"
+
+type OAuthClient struct {
+	Id           string    `json:"id"`
+	Name         string    `json:"name"`
+	SecretHash   *string   `json:"-"`
+	RedirectURIs []string  `json:"redirectUris"`
+	Scopes       []string  `json:"scopes"`
+	GrantTypes   []string  `json:"grantTypes"`
+	Confidential bool      `json:"confidential"`
+	FirstParty   bool      `json:"firstParty"`
+	CreatedBy    *string   `json:"createdBy"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+func (client *OAuthClient) HasRedirectURI(redirectURI string) bool {
+	for _, value := range client.RedirectURIs {
+		if value == redirectURI {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (client *OAuthClient) HasGrantType(grantType string) bool {
+	for _, value := range client.GrantTypes {
+		if value == grantType {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,12 @@
+package types
+
+import "time"
+
+type OAuthClientToken struct {
+	Id        string    `json:"id"`
+	ClientId  string    `json:"clientId"`
+	Scope     string    `json:"scope"`
+	Revoked   bool      `json:"revoked"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -0,0 +1,7 @@
+package types
+
+type OAuthError struct {
+	StatusCode  uint16 `json:"-"`
+	Error       string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
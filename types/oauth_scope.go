@@ -0,0 +1,72 @@
+package types
+
+import "strings"
+
+const (
+	OAuthScopeProfile = "profile"
+	OAuthScopeEmail   = "email"
+)
+
+const (
+	OAuthGrantAuthorizationCode = "authorization_code"
+	OAuthGrantRefreshToken      = "refresh_token"
+	OAuthGrantClientCredentials = "client_credentials"
+)
+
+var OAuthScopes = []*OAuthScope{
+	{Id: OAuthScopeProfile, Description: "Read your user ID"},
+	{Id: OAuthScopeEmail, Description: "Read your email address"},
+}
+
+var OAuthGrantTypes = []string{
+	OAuthGrantAuthorizationCode,
+	OAuthGrantRefreshToken,
+	OAuthGrantClientCredentials,
+}
+
+type OAuthScope struct {
+	Id          string `json:"id"`
+	Description string `json:"description"`
+}
+
+func FindOAuthScope(id string) *OAuthScope {
+	for _, scope := range OAuthScopes {
+		if scope.Id == id {
+			return scope
+		}
+	}
+
+	return nil
+}
+
+func ParseOAuthScope(scope string) []string {
+	scopes := []string{}
+
+	for _, value := range strings.Fields(scope) {
+		if !ContainsOAuthScope(scopes, value) {
+			scopes = append(scopes, value)
+		}
+	}
+
+	return scopes
+}
+
+func ContainsOAuthScope(scopes []string, scope string) bool {
+	for _, value := range scopes {
+		if value == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+func ContainsOAuthScopes(scopes []string, requested []string) bool {
+	for _, scope := range requested {
+		if !ContainsOAuthScope(scopes, scope) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,12 @@
+package types
+
+import "time"
+
+type OAuthState struct {
+	State        string    `json:"state"`
+	Provider     string    `json:"provider"`
+	Nonce        string    `json:"-"`
+	CodeVerifier string    `json:"-"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
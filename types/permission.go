@@ -0,0 +1,18 @@
+package types
+
+const (
+	PermissionAdminsRegister      = "admins.register"
+	PermissionAdminsRoles         = "admins.roles"
+	PermissionRolesManage         = "roles.manage"
+	PermissionUsersBan            = "users.ban"
+	PermissionEmailSettingsList   = "emailSettings.list"
+	PermissionEmailSettingsUpdate = "emailSettings.update"
+	PermissionOAuthClientsManage  = "oauthClients.manage"
+)
+
+const SuperAdminRoleId = "super-admin"
+
+type Permission struct {
+	Id          string `json:"id"`
+	Description string `json:"description"`
+}
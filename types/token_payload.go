@@ -0,0 +1,58 @@
+package types
+
+import (
+	"errors"
+
+	"github.com/sandromai/go-http-server/token"
+)
+
+func signTokenPayload[C token.Claims](
+	engine *token.Engine,
+	audience string,
+	payload C,
+) (string, *AppError) {
+	payload.Registered().Audience = token.Audience{audience}
+
+	tokenString, err := token.Sign(engine, payload)
+
+	if err != nil {
+		return "", &AppError{
+			StatusCode: 500,
+			Message:    "Failed to create token.",
+		}
+	}
+
+	return tokenString, nil
+}
+
+func verifyTokenPayload[C token.Claims](
+	engine *token.Engine,
+	audience string,
+	tokenString string,
+	payload C,
+) *AppError {
+	err := token.Verify(engine, tokenString, audience, payload)
+
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, token.ErrExpired) {
+		return &AppError{
+			StatusCode: 401,
+			Message:    "Expired token.",
+		}
+	}
+
+	if errors.Is(err, token.ErrIssuedInFuture) || errors.Is(err, token.ErrNotYetValid) {
+		return &AppError{
+			StatusCode: 401,
+			Message:    "Invalid token date.",
+		}
+	}
+
+	return &AppError{
+		StatusCode: 401,
+		Message:    "Invalid token.",
+	}
+}
@@ -0,0 +1,78 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sandromai/go-http-server/clock"
+	"github.com/sandromai/go-http-server/token"
+)
+
+func TestTokenPayloadsRejectOtherAudiences(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	key := &token.HMACKey{Secret: []byte("test-secret")}
+
+	engine := &token.Engine{
+		Signer:    key,
+		Verifiers: []token.Verifier{key},
+		Clock:     clock.NewManual(now),
+	}
+
+	registered := token.RegisteredClaims{ExpiresAt: now.Add(time.Hour).Unix()}
+
+	loginToken, appErr := (&LoginTokenPayload{RegisteredClaims: registered, LoginTokenId: "id"}).ToJWT(engine)
+
+	if appErr != nil {
+		t.Fatal(appErr.Message)
+	}
+
+	userToken, appErr := (&UserTokenPayload{RegisteredClaims: registered, UserTokenId: "id"}).ToJWT(engine)
+
+	if appErr != nil {
+		t.Fatal(appErr.Message)
+	}
+
+	adminToken, appErr := (&AdminTokenPayload{RegisteredClaims: registered, AdminTokenId: "id"}).ToJWT(engine)
+
+	if appErr != nil {
+		t.Fatal(appErr.Message)
+	}
+
+	mfaToken, appErr := (&MFATokenPayload{RegisteredClaims: registered, AdminId: "id"}).ToJWT(engine)
+
+	if appErr != nil {
+		t.Fatal(appErr.Message)
+	}
+
+	tests := []struct {
+		name    string
+		payload interface {
+			FromJWT(engine *token.Engine, tokenString string) *AppError
+		}
+		token string
+		valid bool
+	}{
+		{"user token as user token", &UserTokenPayload{}, userToken, true},
+		{"login token as user token", &UserTokenPayload{}, loginToken, false},
+		{"admin token as user token", &UserTokenPayload{}, adminToken, false},
+		{"user token as admin token", &AdminTokenPayload{}, userToken, false},
+		{"mfa token as admin token", &AdminTokenPayload{}, mfaToken, false},
+		{"admin token as mfa token", &MFATokenPayload{}, adminToken, false},
+		{"user token as login token", &LoginTokenPayload{}, userToken, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			appErr := test.payload.FromJWT(engine, test.token)
+
+			if test.valid && appErr != nil {
+				t.Fatalf("unexpected error: %v", appErr.Message)
+			}
+
+			if !test.valid && (appErr == nil || appErr.StatusCode != 401 || appErr.Message != "Invalid token.") {
+				t.Fatalf("expected an invalid token error, got %+v", appErr)
+			}
+		})
+	}
+}
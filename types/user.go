@@ -1,8 +1,10 @@
 package types
 
+import "time"
+
 type User struct {
-	Id        string `json:"id"`
-	Email     string `json:"email"`
-	Banned    bool   `json:"banned"`
-	CreatedAt string `json:"createdAt"`
+	Id        string    `json:"id"`
+	Email     string    `json:"email"`
+	Banned    bool      `json:"banned"`
+	CreatedAt time.Time `json:"createdAt"`
 }
@@ -0,0 +1,14 @@
+package types
+
+import "time"
+
+type UserCredential struct {
+	Id           string     `json:"id"`
+	UserId       string     `json:"userId"`
+	CredentialId string     `json:"credentialId"`
+	PublicKey    string     `json:"-"`
+	SignCount    uint32     `json:"signCount"`
+	Name         string     `json:"name"`
+	LastUsedAt   *time.Time `json:"lastUsedAt"`
+	CreatedAt    time.Time  `json:"createdAt"`
+}
@@ -0,0 +1,12 @@
+package types
+
+import "time"
+
+type UserIdentity struct {
+	Id        string    `json:"id"`
+	UserId    string    `json:"userId"`
+	Provider  string    `json:"provider"`
+	Subject   string    `json:"subject"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"createdAt"`
+}
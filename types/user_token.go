@@ -1,14 +1,20 @@
 package types
 
+import "time"
+
 type UserToken struct {
-	Id             string  `json:"id"`
-	UserId         string  `json:"userId"`
-	FromLoginToken *string `json:"fromLoginToken"`
-	FromUserToken  *string `json:"fromUserToken"`
-	IPAddress      string  `json:"IPAddress"`
-	Device         string  `json:"device"`
-	Disconnected   bool    `json:"disconnected"`
-	LastActivity   string  `json:"lastActivity"`
-	ExpiresAt      string  `json:"expiresAt"`
-	CreatedAt      string  `json:"createdAt"`
+	Id             string    `json:"id"`
+	UserId         string    `json:"userId"`
+	FromLoginToken *string   `json:"fromLoginToken"`
+	FromUserToken  *string   `json:"fromUserToken"`
+	FromCredential *string   `json:"fromCredential"`
+	FromIdentity   *string   `json:"fromIdentity"`
+	ClientId       *string   `json:"clientId"`
+	Scope          *string   `json:"scope"`
+	IPAddress      string    `json:"IPAddress"`
+	Device         string    `json:"device"`
+	Disconnected   bool      `json:"disconnected"`
+	LastActivity   time.Time `json:"lastActivity"`
+	ExpiresAt      time.Time `json:"expiresAt"`
+	CreatedAt      time.Time `json:"createdAt"`
 }
@@ -0,0 +1,17 @@
+package types
+
+import "time"
+
+const (
+	WebAuthnChallengeRegistration   = "registration"
+	WebAuthnChallengeAuthentication = "authentication"
+)
+
+type WebAuthnChallenge struct {
+	Id        string    `json:"id"`
+	UserId    *string   `json:"userId"`
+	Type      string    `json:"type"`
+	Challenge string    `json:"challenge"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/sandromai/go-http-server/types"
+)
+
+func TestUserCredentials(t *testing.T) {
+	forEachBackend(t, testUserCredentials)
+}
+
+func testUserCredentials(
+	t *testing.T,
+	server *testServer,
+) {
+	user := server.loginUser("user@example.com")
+	other := server.loginUser("other@example.com")
+
+	laptop := newTestPasskey(t, "laptop")
+	phone := newTestPasskey(t, "phone")
+
+	laptopCredential := server.registerPasskey(user.Token, laptop)
+
+	server.registerPasskey(user.Token, phone)
+
+	options := server.webAuthnOptions("/routes/webAuthn/registration/options", bearer(other.Token))
+
+	server.request("POST", "/routes/webAuthn/registration", laptop.registration(options.ChallengeId, options.PublicKey.Challenge, "http://localhost:3000"), bearer(other.Token)).expect(t, 409, "Credential already registered.")
+
+	server.request("GET", "/routes/userCredentials/", nil, nil).expect(t, 401, "No authorization provided.")
+
+	list := func(userToken string) []*types.UserCredential {
+		t.Helper()
+
+		response := server.request("GET", "/routes/userCredentials/", nil, bearer(userToken))
+
+		response.expect(t, 200, "")
+
+		var userCredentials []*types.UserCredential
+
+		response.decode(t, &userCredentials)
+
+		return userCredentials
+	}
+
+	if userCredentials := list(user.Token); len(userCredentials) != 2 {
+		t.Fatalf("expected two credentials, got %+v", userCredentials)
+	}
+
+	if userCredentials := list(other.Token); len(userCredentials) != 0 {
+		t.Fatalf("expected another user to see no credentials, got %+v", userCredentials)
+	}
+
+	server.request("DELETE", "/routes/userCredentials/"+laptopCredential.Id, nil, bearer(other.Token)).expect(t, 403, "Unauthorized action.")
+
+	server.request("DELETE", "/routes/userCredentials/unknown", nil, bearer(user.Token)).expect(t, 404, "Credential not found.")
+
+	server.request("DELETE", "/routes/userCredentials/"+laptopCredential.Id, nil, bearer(user.Token)).expect(t, 200, "")
+
+	userCredentials := list(user.Token)
+
+	if len(userCredentials) != 1 || userCredentials[0].CredentialId != phone.id() {
+		t.Fatalf("expected only the phone passkey to remain, got %+v", userCredentials)
+	}
+
+	server.authenticatePasskey(laptop).expect(t, 401, "Invalid credential.")
+
+	server.authenticatePasskey(phone).expect(t, 200, "")
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sandromai/go-http-server/types"
+)
+
+func TestUserSessionRefreshWindow(t *testing.T) {
+	forEachBackend(t, testUserSessionRefreshWindow)
+}
+
+func testUserSessionRefreshWindow(
+	t *testing.T,
+	server *testServer,
+) {
+	user := server.loginUser("user@example.com")
+
+	lifetimes := server.config.Lifetimes
+
+	server.clock.Advance(lifetimes.UserSession - time.Hour)
+
+	response := server.request("GET", "/routes/users/authenticate", nil, bearer(user.Token))
+
+	response.expect(t, 200, "")
+
+	var result authenticatedUser
+
+	response.decode(t, &result)
+
+	if result.Token != "" {
+		t.Fatalf("expected no refresh before the session expires, got %s", response.Body)
+	}
+
+	server.clock.Advance(2 * time.Hour)
+
+	response = server.request("GET", "/routes/users/authenticate", nil, bearer(user.Token))
+
+	response.expect(t, 200, "")
+
+	response.decode(t, &result)
+
+	if result.Token == "" || response.Header.Get("X-Refreshed-Token") != result.Token {
+		t.Fatalf("expected a refreshed token within the refresh window, got %s", response.Body)
+	}
+
+	response = server.request("GET", "/routes/users/authenticate", nil, bearer(result.Token))
+
+	response.expect(t, 200, "")
+
+	if response.Header.Get("X-Refreshed-Token") != "" {
+		t.Fatal("expected the refreshed token to be used as is")
+	}
+}
+
+func TestUserSessionExpiresAfterRefreshWindow(t *testing.T) {
+	forEachBackend(t, testUserSessionExpiresAfterRefreshWindow)
+}
+
+func testUserSessionExpiresAfterRefreshWindow(
+	t *testing.T,
+	server *testServer,
+) {
+	user := server.loginUser("user@example.com")
+
+	server.clock.Advance(server.config.Lifetimes.UserSession + time.Hour)
+
+	server.request("GET", "/routes/users/authenticate", nil, bearer(user.Token)).expect(t, 400, "User token has expired.")
+}
+
+func TestUserTokenOutlivesSessionByRefreshWindow(t *testing.T) {
+	forEachBackend(t, testUserTokenOutlivesSessionByRefreshWindow)
+}
+
+func testUserTokenOutlivesSessionByRefreshWindow(
+	t *testing.T,
+	server *testServer,
+) {
+	lifetimes := server.config.Lifetimes
+
+	expiresAt := func(userToken string) time.Time {
+		t.Helper()
+
+		payload := &types.UserTokenPayload{}
+
+		if appErr := payload.FromJWT(server.tokens, userToken); appErr != nil {
+			t.Fatal(appErr.Message)
+		}
+
+		return time.Unix(payload.ExpiresAt, 0)
+	}
+
+	user := server.loginUser("user@example.com")
+
+	if expected := lifetimes.UserTokenExpiresAt(server.clock.Now()); !expiresAt(user.Token).Equal(expected) {
+		t.Fatalf("got a first login token expiring at %v, expected %v", expiresAt(user.Token), expected)
+	}
+
+	server.clock.Advance(lifetimes.UserSession - time.Hour)
+
+	server.request("GET", "/routes/users/authenticate", nil, bearer(user.Token)).expect(t, 200, "")
+
+	server.clock.Advance(2 * time.Hour)
+
+	response := server.request("GET", "/routes/users/authenticate", nil, bearer(user.Token))
+
+	response.expect(t, 200, "")
+
+	refreshedToken := response.Header.Get("X-Refreshed-Token")
+
+	if refreshedToken == "" {
+		t.Fatal("expected the session to be refreshed")
+	}
+
+	refreshedAt := server.clock.Now()
+
+	if expected := lifetimes.UserTokenExpiresAt(refreshedAt); !expiresAt(refreshedToken).Equal(expected) {
+		t.Fatalf("got a refreshed token expiring at %v, expected %v", expiresAt(refreshedToken), expected)
+	}
+
+	server.clock.Set(refreshedAt.Add(lifetimes.UserSession - time.Hour))
+
+	server.request("GET", "/routes/users/authenticate", nil, bearer(refreshedToken)).expect(t, 200, "")
+
+	server.clock.Set(refreshedAt.Add(lifetimes.UserSession + lifetimes.UserSessionRefresh - 2*time.Hour))
+
+	response = server.request("GET", "/routes/users/authenticate", nil, bearer(refreshedToken))
+
+	response.expect(t, 200, "")
+
+	if response.Header.Get("X-Refreshed-Token") == "" {
+		t.Fatal("expected a token past its session to refresh until the refresh window closes")
+	}
+}
+
+func TestUserBan(t *testing.T) {
+	forEachBackend(t, testUserBan)
+}
+
+func testUserBan(
+	t *testing.T,
+	server *testServer,
+) {
+	adminToken := server.loginAdmin(testAdminUsername, testAdminPassword)
+
+	user := server.loginUser("user@example.com")
+
+	server.request("PATCH", "/routes/users/"+user.User.Id+"/ban", nil, nil).expect(t, 401, "No authorization provided.")
+
+	server.request("PATCH", "/routes/users/unknown/ban", nil, bearer(adminToken)).expect(t, 404, "User not found.")
+
+	server.request("PATCH", "/routes/users/"+user.User.Id+"/unban", nil, bearer(adminToken)).expect(t, 400, "User is not banned.")
+
+	server.request("PATCH", "/routes/users/"+user.User.Id+"/ban", nil, bearer(adminToken)).expect(t, 200, "")
+
+	server.request("PATCH", "/routes/users/"+user.User.Id+"/ban", nil, bearer(adminToken)).expect(t, 400, "User is already banned.")
+
+	server.request("GET", "/routes/users/authenticate", nil, bearer(user.Token)).expect(t, 403, "User banned.")
+
+	server.clock.Advance(server.config.Lifetimes.LoginTokenResend + time.Second)
+
+	server.request("POST", "/routes/loginTokens/create", map[string]any{
+		"email": "user@example.com",
+	}, nil).expect(t, 403, "User banned.")
+
+	server.request("PATCH", "/routes/users/"+user.User.Id+"/unban", nil, bearer(adminToken)).expect(t, 200, "")
+
+	server.request("GET", "/routes/users/authenticate", nil, bearer(user.Token)).expect(t, 200, "")
+}
+
+func TestUserTokenDisconnect(t *testing.T) {
+	forEachBackend(t, testUserTokenDisconnect)
+}
+
+func testUserTokenDisconnect(
+	t *testing.T,
+	server *testServer,
+) {
+	user := server.loginUser("user@example.com")
+	other := server.loginUser("other@example.com")
+
+	userTokenId := server.userTokenId(user.Token)
+
+	server.request("PATCH", "/routes/userTokens/"+userTokenId+"/disconnect", nil, bearer(other.Token)).expect(t, 403, "Unauthorized action.")
+
+	server.request("PATCH", "/routes/userTokens/"+userTokenId+"/disconnect", nil, bearer(user.Token)).expect(t, 200, "")
+
+	server.request("GET", "/routes/users/authenticate", nil, bearer(user.Token)).expect(t, 400, "Session disconnected.")
+
+	server.request("GET", "/routes/users/authenticate", nil, bearer(other.Token)).expect(t, 200, "")
+}
@@ -12,3 +12,7 @@ func CheckEmail(email string) bool {
 
 	return true
 }
+
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -6,19 +6,17 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"io"
-	"os"
 
 	"github.com/sandromai/go-http-server/types"
 )
 
 func Encrypt(
+	key []byte,
 	data string,
 ) (
 	encryptedData string,
 	appErr *types.AppError,
 ) {
-	key := []byte(os.Getenv("ENCRYPTION_KEY"))
-
 	block, err := aes.NewCipher(key)
 
 	if err != nil {
@@ -47,13 +45,12 @@ func Encrypt(
 }
 
 func Decrypt(
+	key []byte,
 	encryptedData string,
 ) (
 	decryptedData string,
 	appErr *types.AppError,
 ) {
-	key := []byte(os.Getenv("ENCRYPTION_KEY"))
-
 	block, err := aes.NewCipher(key)
 
 	if err != nil {
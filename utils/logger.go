@@ -1,267 +1,394 @@
 package utils
 
 import (
-	"crypto/md5"
+	"bufio"
+	"compress/gzip"
+	"errors"
 	"fmt"
-	"io/fs"
+	"io"
 	"os"
 	"path/filepath"
-	"regexp"
-	"strconv"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/sandromai/go-http-server/clock"
 	"github.com/sandromai/go-http-server/types"
 )
 
+const (
+	defaultLogFlushInterval = time.Second
+	logBufferSize           = 64 * 1024
+	logRotationLayout       = "2006-01-02T15-04-05"
+)
+
 type Logger struct {
-	FolderPath     string
-	FileName       string
-	MessagesPrefix string
+	FolderPath    string
+	FileName      string
+	MaxSize       int64
+	MaxFiles      int
+	Retention     time.Duration
+	FlushInterval time.Duration
+	Clock         clock.Clock
+
+	mutex    sync.Mutex
+	file     *os.File
+	buffer   *bufio.Writer
+	size     int64
+	fileDate string
+	rotated  chan struct{}
+	stop     chan struct{}
+	done     chan struct{}
 }
 
-func (log *Logger) formatMessage(
-	message string,
-) (string, *types.AppError) {
-	formattedMessage := "[" + time.Now().Format(time.DateTime) + "] "
+func (log *Logger) now() time.Time {
+	return clock.Now(log.Clock)
+}
 
-	if log.MessagesPrefix != "" {
-		formattedMessage += log.MessagesPrefix + " "
+func (log *Logger) fileNameParts() (string, string) {
+	extension := filepath.Ext(log.FileName)
+
+	return strings.TrimSuffix(log.FileName, extension) + "-", extension
+}
+
+func (log *Logger) Open() *types.AppError {
+	if err := os.MkdirAll(log.FolderPath, 0755); err != nil {
+		return &types.AppError{
+			StatusCode: 500,
+			Message:    "Failed to create log folder.",
+		}
 	}
 
-	cleanMessage, appErr := FormatLineBreaks(message)
+	log.mutex.Lock()
+
+	appErr := log.openFile()
+
+	log.mutex.Unlock()
 
 	if appErr != nil {
-		return "", appErr
+		return appErr
 	}
 
-	spacesRegExp, err := regexp.Compile(`\n +`)
+	log.cleanUp()
 
-	if err != nil {
-		return "", &types.AppError{
-			StatusCode: 500,
-			Message:    "Failed to format spaces.",
-		}
+	flushInterval := log.FlushInterval
+
+	if flushInterval <= 0 {
+		flushInterval = defaultLogFlushInterval
 	}
 
-	formattedMessage += spacesRegExp.ReplaceAllString(cleanMessage, " ")
-	formattedMessage = strings.TrimSpace(formattedMessage) + "\n"
+	log.rotated = make(chan struct{}, 1)
+	log.stop = make(chan struct{})
+	log.done = make(chan struct{})
+
+	go log.run(flushInterval)
 
-	return formattedMessage, nil
+	return nil
 }
 
-func (log *Logger) createFile() (
-	string,
-	*types.AppError,
-) {
-	newFileName := ""
-	fileExists := true
+func (log *Logger) openFile() *types.AppError {
+	filePath := filepath.Join(log.FolderPath, log.FileName)
 
-	for i := 0; i < 20 && fileExists; i++ {
-		newFileName = fmt.Sprintf("%v", time.Now().Unix()) + "-" + fmt.Sprintf("%x", md5.Sum([]byte(fmt.Sprintf("%v", time.Now().UnixNano())+log.FileName))) + "-" + log.FileName
+	if fileInfo, err := os.Stat(filePath); err == nil {
+		modifiedAt := fileInfo.ModTime().UTC()
 
-		if _, err := os.Stat(filepath.Join(log.FolderPath, newFileName)); err != nil && os.IsNotExist(err) {
-			fileExists = false
+		if modifiedAt.Format(time.DateOnly) != log.now().Format(time.DateOnly) {
+			if appErr := log.rename(filePath, modifiedAt); appErr != nil {
+				return appErr
+			}
 		}
 	}
 
-	if newFileName == "" || fileExists {
-		return "", &types.AppError{
+	file, err := os.OpenFile(
+		filePath,
+		os.O_CREATE|os.O_APPEND|os.O_WRONLY,
+		0644,
+	)
+
+	if err != nil {
+		return &types.AppError{
 			StatusCode: 500,
-			Message:    "Failed to generate log file name.",
+			Message:    "Failed to open log file.",
 		}
 	}
 
-	fileHandler, err := os.Create(filepath.Join(log.FolderPath, newFileName))
+	fileInfo, err := file.Stat()
 
 	if err != nil {
-		return "", &types.AppError{
+		file.Close()
+
+		return &types.AppError{
 			StatusCode: 500,
-			Message:    "Failed to create log file.",
+			Message:    "Failed to get log file data.",
 		}
 	}
 
-	fileHandler.Close()
+	log.file = file
+	log.buffer = bufio.NewWriterSize(file, logBufferSize)
+	log.size = fileInfo.Size()
+	log.fileDate = log.now().Format(time.DateOnly)
 
-	return newFileName, nil
+	return nil
 }
 
-func (log *Logger) getCurrentFilePath() (
-	string,
-	*types.AppError,
-) {
-	folderInfo, err := os.Stat(log.FolderPath)
+func (log *Logger) rename(
+	filePath string,
+	rotatedAt time.Time,
+) *types.AppError {
+	prefix, extension := log.fileNameParts()
 
-	if err != nil {
-		if os.IsNotExist(err) {
-			err = os.MkdirAll(log.FolderPath, 0755)
-
-			if err != nil {
-				return "", &types.AppError{
-					StatusCode: 500,
-					Message:    "Failed to create log folder.",
-				}
-			}
+	for i := 0; i < 1000; i++ {
+		rotatedPath := filepath.Join(
+			log.FolderPath,
+			prefix+rotatedAt.Format(logRotationLayout)+fmt.Sprintf(".%03d", i)+extension,
+		)
 
-			folderInfo, err = os.Stat(log.FolderPath)
+		if _, err := os.Stat(rotatedPath); err == nil {
+			continue
+		}
 
-			if err != nil {
-				return "", &types.AppError{
-					StatusCode: 500,
-					Message:    "Failed to get log folder data.",
-				}
-			}
-		} else {
-			return "", &types.AppError{
+		if _, err := os.Stat(rotatedPath + ".gz"); err == nil {
+			continue
+		}
+
+		if err := os.Rename(filePath, rotatedPath); err != nil {
+			return &types.AppError{
 				StatusCode: 500,
-				Message:    "Failed to get log folder data.",
+				Message:    "Failed to rotate log file.",
 			}
 		}
+
+		return nil
 	}
 
-	if !folderInfo.IsDir() {
-		err = os.MkdirAll(log.FolderPath, 0755)
+	return &types.AppError{
+		StatusCode: 500,
+		Message:    "Failed to generate log file name.",
+	}
+}
 
-		if err != nil {
-			return "", &types.AppError{
-				StatusCode: 500,
-				Message:    "Failed to create log folder.",
-			}
+func (log *Logger) rotate() *types.AppError {
+	if err := log.buffer.Flush(); err != nil {
+		return &types.AppError{
+			StatusCode: 500,
+			Message:    "Failed to save log.",
 		}
 	}
 
+	log.file.Close()
+
+	log.file = nil
+	log.buffer = nil
+
+	renameErr := log.rename(filepath.Join(log.FolderPath, log.FileName), log.now())
+
+	if appErr := log.openFile(); appErr != nil {
+		return appErr
+	}
+
+	if renameErr != nil {
+		return renameErr
+	}
+
+	select {
+	case log.rotated <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+func (log *Logger) rotatedFiles() []string {
 	files, err := os.ReadDir(log.FolderPath)
 
 	if err != nil {
-		return "", &types.AppError{
-			StatusCode: 500,
-			Message:    "Failed to open log folder.",
-		}
+		return nil
 	}
 
-	var currentFile *fs.FileInfo
+	prefix, extension := log.fileNameParts()
+
+	var names []string
 
 	for _, file := range files {
-		if file.IsDir() {
+		name := file.Name()
+
+		if file.IsDir() || !strings.HasPrefix(name, prefix) {
 			continue
 		}
 
-		fileNameParts := strings.Split(file.Name(), "-")
-
-		if fileNameParts[len(fileNameParts)-1] != log.FileName {
-			continue
+		if strings.HasSuffix(name, extension) || strings.HasSuffix(name, extension+".gz") {
+			names = append(names, name)
 		}
+	}
 
-		completeFilePath := filepath.Join(log.FolderPath, file.Name())
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
 
-		fileInfo, err := os.Stat(completeFilePath)
+	return names
+}
 
-		if err != nil {
-			continue
-		}
+func compressFile(
+	filePath string,
+) error {
+	source, err := os.Open(filePath)
 
-		fileTime := fileInfo.ModTime().Unix()
+	if err != nil {
+		return err
+	}
 
-		if fileTime <= time.Now().Add(-time.Hour*24*30).Unix() {
-			os.Remove(completeFilePath)
+	defer source.Close()
 
-			continue
-		}
+	destination, err := os.OpenFile(
+		filePath+".gz",
+		os.O_CREATE|os.O_TRUNC|os.O_WRONLY,
+		0644,
+	)
 
-		if currentFile == nil {
-			currentFile = &fileInfo
+	if err != nil {
+		return err
+	}
 
-			continue
-		}
+	writer := gzip.NewWriter(destination)
 
-		currentFileTime := (*currentFile).ModTime().Unix()
+	_, err = io.Copy(writer, source)
 
-		if fileTime > currentFileTime {
-			currentFile = &fileInfo
-		}
+	if err == nil {
+		err = writer.Close()
+	}
+
+	if closeErr := destination.Close(); err == nil {
+		err = closeErr
 	}
 
-	var currentFileName string
-	var appErr *types.AppError
+	if err != nil {
+		os.Remove(filePath + ".gz")
 
-	if currentFile != nil {
-		currentFileName = (*currentFile).Name()
+		return err
+	}
 
-		currentFileParts := strings.Split(currentFileName, "-")
+	return os.Remove(filePath)
+}
 
-		if len(currentFileParts) >= 2 && !strings.Contains(currentFileParts[0], ".") {
-			currentFileCreationTime, err := strconv.Atoi(currentFileParts[0])
+func (log *Logger) cleanUp() {
+	expiresBefore := log.now().Add(-log.Retention)
 
-			if err != nil {
-				currentFileName, appErr = log.createFile()
+	for i, name := range log.rotatedFiles() {
+		filePath := filepath.Join(log.FolderPath, name)
 
-				if appErr != nil {
-					return "", appErr
-				}
-			}
+		if log.MaxFiles > 0 && i >= log.MaxFiles {
+			os.Remove(filePath)
 
-			currentYear, currentMonth, currentDay := time.Now().Date()
-			fileYear, fileMonth, fileDay := time.Unix(int64(currentFileCreationTime), 0).Date()
+			continue
+		}
 
-			if currentYear != fileYear || currentMonth != fileMonth || currentDay != fileDay {
-				currentFileName, appErr = log.createFile()
+		if log.Retention > 0 {
+			if fileInfo, err := os.Stat(filePath); err == nil && fileInfo.ModTime().Before(expiresBefore) {
+				os.Remove(filePath)
 
-				if appErr != nil {
-					return "", appErr
-				}
+				continue
 			}
 		}
-	} else {
-		currentFileName, appErr = log.createFile()
 
-		if appErr != nil {
-			return "", appErr
+		if !strings.HasSuffix(name, ".gz") {
+			compressFile(filePath)
 		}
 	}
+}
+
+func (log *Logger) run(
+	flushInterval time.Duration,
+) {
+	defer close(log.done)
+
+	ticker := time.NewTicker(flushInterval)
 
-	return filepath.Join(log.FolderPath, currentFileName), nil
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-log.stop:
+			return
+		case <-log.rotated:
+			log.cleanUp()
+		case <-ticker.C:
+			log.mutex.Lock()
+
+			if log.buffer != nil {
+				log.buffer.Flush()
+			}
+
+			log.mutex.Unlock()
+		}
+	}
 }
 
-func (log *Logger) Save(
-	message string,
-) *types.AppError {
-	currentFilePath, appErr := log.getCurrentFilePath()
+func (log *Logger) Write(
+	message []byte,
+) (int, error) {
+	log.mutex.Lock()
 
-	if appErr != nil {
-		return appErr
+	defer log.mutex.Unlock()
+
+	if log.file == nil {
+		return 0, errors.New("logger: log file is not open")
 	}
 
-	fileHandler, err := os.OpenFile(
-		currentFilePath,
-		os.O_APPEND|os.O_WRONLY,
-		0644,
-	)
+	rotateBySize := log.MaxSize > 0 && log.size > 0 && log.size+int64(len(message)) > log.MaxSize
 
-	if err != nil {
-		return &types.AppError{
-			StatusCode: 500,
-			Message:    "Failed to open log file.",
+	if rotateBySize || log.now().Format(time.DateOnly) != log.fileDate {
+		if appErr := log.rotate(); appErr != nil {
+			return 0, errors.New(appErr.Message)
 		}
 	}
 
-	defer fileHandler.Close()
+	written, err := log.buffer.Write(message)
 
-	formattedMessage, appErr := log.formatMessage(message)
+	log.size += int64(written)
 
-	if appErr != nil {
-		return appErr
+	return written, err
+}
+
+func (log *Logger) Flush() error {
+	log.mutex.Lock()
+
+	defer log.mutex.Unlock()
+
+	if log.buffer == nil {
+		return nil
 	}
 
-	_, err = fileHandler.Write([]byte(
-		formattedMessage,
-	))
+	return log.buffer.Flush()
+}
 
-	if err != nil {
-		return &types.AppError{
-			StatusCode: 500,
-			Message:    "Failed to save log.",
-		}
+func (log *Logger) Close() error {
+	if log.stop != nil {
+		close(log.stop)
+
+		<-log.done
+
+		log.stop = nil
 	}
 
-	return nil
+	log.mutex.Lock()
+
+	if log.file == nil {
+		log.mutex.Unlock()
+
+		return nil
+	}
+
+	err := log.buffer.Flush()
+
+	if closeErr := log.file.Close(); err == nil {
+		err = closeErr
+	}
+
+	log.file = nil
+	log.buffer = nil
+
+	log.mutex.Unlock()
+
+	log.cleanUp()
+
+	return err
 }
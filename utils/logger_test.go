@@ -0,0 +1,258 @@
+package utils
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sandromai/go-http-server/clock"
+)
+
+func readLogLines(
+	t *testing.T,
+	folderPath string,
+) map[string][]string {
+	t.Helper()
+
+	files, err := os.ReadDir(folderPath)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := map[string][]string{}
+
+	for _, file := range files {
+		handle, err := os.Open(filepath.Join(folderPath, file.Name()))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var reader io.Reader = handle
+
+		if strings.HasSuffix(file.Name(), ".gz") {
+			gzipReader, err := gzip.NewReader(handle)
+
+			if err != nil {
+				t.Fatalf("%v is not a valid gzip file: %v", file.Name(), err)
+			}
+
+			reader = gzipReader
+		}
+
+		scanner := bufio.NewScanner(reader)
+
+		for scanner.Scan() {
+			lines[file.Name()] = append(lines[file.Name()], scanner.Text())
+		}
+
+		if err := scanner.Err(); err != nil {
+			t.Fatal(err)
+		}
+
+		handle.Close()
+	}
+
+	return lines
+}
+
+func TestLoggerConcurrentRotation(t *testing.T) {
+	folderPath := t.TempDir()
+
+	logger := &Logger{
+		FolderPath:    folderPath,
+		FileName:      "server.log",
+		MaxSize:       4096,
+		FlushInterval: time.Millisecond,
+	}
+
+	if appErr := logger.Open(); appErr != nil {
+		t.Fatal(appErr.Message)
+	}
+
+	const (
+		writers = 8
+		entries = 250
+	)
+
+	var group sync.WaitGroup
+
+	for writer := 0; writer < writers; writer++ {
+		group.Add(1)
+
+		go func(writer int) {
+			defer group.Done()
+
+			for entry := 0; entry < entries; entry++ {
+				line := fmt.Sprintf("{\"writer\":%03d,\"entry\":%03d,\"padding\":\"%v\"}\n", writer, entry, strings.Repeat("x", 40))
+
+				if _, err := logger.Write([]byte(line)); err != nil {
+					t.Error(err)
+
+					return
+				}
+			}
+		}(writer)
+	}
+
+	group.Wait()
+
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	seen := map[string]bool{}
+	compressed := 0
+
+	for name, lines := range readLogLines(t, folderPath) {
+		if strings.HasSuffix(name, ".gz") {
+			compressed++
+		} else if name != "server.log" {
+			t.Fatalf("expected rotated files to be compressed, found %v", name)
+		}
+
+		size := 0
+
+		for _, line := range lines {
+			var writer, entry int
+
+			if _, err := fmt.Sscanf(line, "{\"writer\":%03d,\"entry\":%03d,", &writer, &entry); err != nil || !strings.HasSuffix(line, "\"}") {
+				t.Fatalf("interleaved line %q in %v", line, name)
+			}
+
+			seen[line] = true
+			size += len(line) + 1
+		}
+
+		if size > 4096 {
+			t.Fatalf("%v holds %v bytes, expected at most 4096", name, size)
+		}
+	}
+
+	if len(seen) != writers*entries {
+		t.Fatalf("got %v distinct lines, expected %v", len(seen), writers*entries)
+	}
+
+	if compressed < 2 {
+		t.Fatalf("expected several size rotations, got %v", compressed)
+	}
+}
+
+func TestLoggerRotatesByDate(t *testing.T) {
+	folderPath := t.TempDir()
+
+	logClock := clock.NewManual(time.Now())
+
+	logger := &Logger{
+		FolderPath: folderPath,
+		FileName:   "server.log",
+		Clock:      logClock,
+	}
+
+	if appErr := logger.Open(); appErr != nil {
+		t.Fatal(appErr.Message)
+	}
+
+	logger.Write([]byte("first day\n"))
+
+	logClock.Advance(24 * time.Hour)
+
+	logger.Write([]byte("second day\n"))
+
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := readLogLines(t, folderPath)
+
+	if len(lines) != 2 || strings.Join(lines["server.log"], "") != "second day" {
+		t.Fatalf("unexpected log files %v", lines)
+	}
+
+	for name, fileLines := range lines {
+		if name != "server.log" && (!strings.HasSuffix(name, ".log.gz") || strings.Join(fileLines, "") != "first day") {
+			t.Fatalf("unexpected rotated file %v with %v", name, fileLines)
+		}
+	}
+}
+
+func TestLoggerRetention(t *testing.T) {
+	folderPath := t.TempDir()
+
+	expired := filepath.Join(folderPath, "server-2000-01-01T00-00-00.000.log.gz")
+
+	if err := os.WriteFile(expired, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	oldTime := time.Now().Add(-48 * time.Hour)
+
+	if err := os.Chtimes(expired, oldTime, oldTime); err != nil {
+		t.Fatal(err)
+	}
+
+	unrelated := filepath.Join(folderPath, "other.log")
+
+	if err := os.WriteFile(unrelated, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	logger := &Logger{
+		FolderPath: folderPath,
+		FileName:   "server.log",
+		MaxSize:    16,
+		MaxFiles:   2,
+		Retention:  24 * time.Hour,
+	}
+
+	if appErr := logger.Open(); appErr != nil {
+		t.Fatal(appErr.Message)
+	}
+
+	if _, err := os.Stat(expired); !os.IsNotExist(err) {
+		t.Fatal("expected a file older than the retention to be removed")
+	}
+
+	for i := 0; i < 5; i++ {
+		logger.Write([]byte(fmt.Sprintf("rotation %02d\n", i)))
+	}
+
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := readLogLines(t, folderPath)
+
+	if len(lines) != 3 || strings.Join(lines["server.log"], "") != "rotation 04" {
+		t.Fatalf("expected the active file and two rotated files, got %v", lines)
+	}
+
+	if _, err := os.Stat(unrelated); err != nil {
+		t.Fatal("expected files of other logs to be kept")
+	}
+}
+
+func TestLoggerWriteAfterClose(t *testing.T) {
+	logger := &Logger{
+		FolderPath: t.TempDir(),
+		FileName:   "server.log",
+	}
+
+	if appErr := logger.Open(); appErr != nil {
+		t.Fatal(appErr.Message)
+	}
+
+	logger.Close()
+
+	if _, err := logger.Write([]byte("late\n")); err == nil {
+		t.Fatal("expected writes after close to fail")
+	}
+}
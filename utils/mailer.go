@@ -1,12 +1,22 @@
 package utils
 
 import (
+	"context"
 	"net/smtp"
 	"strings"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/sandromai/go-http-server/tracing"
 	"github.com/sandromai/go-http-server/types"
 )
 
+type MailSender interface {
+	Send(ctx context.Context, fromEmail, fromName, toEmail, toName, subject, body string) *types.AppError
+}
+
 type Mailer struct {
 	Host     string
 	Port     string
@@ -15,6 +25,7 @@ type Mailer struct {
 }
 
 func (mailer *Mailer) Send(
+	ctx context.Context,
 	fromEmail,
 	fromName,
 	toEmail,
@@ -44,9 +55,24 @@ func (mailer *Mailer) Send(
 		body,
 	)
 
+	_, span := tracing.Tracer().Start(
+		ctx,
+		"smtp.send",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("server.address", mailer.Host),
+			attribute.String("server.port", mailer.Port),
+		),
+	)
+
+	defer span.End()
+
 	auth := smtp.PlainAuth("", mailer.Username, mailer.Password, mailer.Host)
 
 	if err := smtp.SendMail(mailer.Host+":"+mailer.Port, auth, mailer.Username, []string{toEmail}, formattedMessage); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
 		return &types.AppError{
 			StatusCode: 500,
 			Message:    "Error sending email.",
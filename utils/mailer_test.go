@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestMailerSendRecordsSpan(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	previous := otel.GetTracerProvider()
+
+	otel.SetTracerProvider(provider)
+
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	listener.Close()
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+
+	mailer := &Mailer{
+		Host:     "127.0.0.1",
+		Port:     strconv.Itoa(port),
+		Username: "sender@example.com",
+	}
+
+	if appErr := mailer.Send(ctx, "sender@example.com", "", "user@example.com", "", "Subject", "Body"); appErr == nil {
+		t.Fatal("expected sending to a closed port to fail")
+	}
+
+	parent.End()
+
+	spans := exporter.GetSpans()
+
+	if len(spans) != 2 || spans[0].Name != "smtp.send" {
+		t.Fatalf("unexpected spans %+v", spans)
+	}
+
+	span := spans[0]
+
+	if span.SpanKind != trace.SpanKindClient || span.Parent.SpanID() != parent.SpanContext().SpanID() {
+		t.Fatalf("expected a client span under the caller's span, got %+v", span)
+	}
+
+	if span.Status.Code != codes.Error || len(span.Events) == 0 {
+		t.Fatalf("expected the send failure to be recorded, got %+v", span.Status)
+	}
+}
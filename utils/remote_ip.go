@@ -0,0 +1,19 @@
+package utils
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+func GetRemoteIP(
+	request *http.Request,
+) string {
+	host, _, err := net.SplitHostPort(request.RemoteAddr)
+
+	if err != nil {
+		return strings.Split(request.RemoteAddr, ":")[0]
+	}
+
+	return host
+}
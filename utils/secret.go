@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+
+	"github.com/sandromai/go-http-server/types"
+)
+
+func GenerateSecret() (string, *types.AppError) {
+	bytes := make([]byte, 32)
+
+	if _, err := rand.Read(bytes); err != nil {
+		return "", &types.AppError{
+			StatusCode: 500,
+			Message:    "Failed to generate secret.",
+		}
+	}
+
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
+func HashSecret(secret string) string {
+	digest := sha256.Sum256([]byte(secret))
+
+	return hex.EncodeToString(digest[:])
+}
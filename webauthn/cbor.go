@@ -0,0 +1,217 @@
+package webauthn
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+const maxCBORDepth = 16
+
+var errInvalidCBOR = errors.New("webauthn: invalid CBOR data")
+
+type cborDecoder struct {
+	data   []byte
+	offset int
+	depth  int
+}
+
+func (decoder *cborDecoder) remaining() uint64 {
+	return uint64(len(decoder.data) - decoder.offset)
+}
+
+func (decoder *cborDecoder) readByte() (byte, error) {
+	if decoder.offset >= len(decoder.data) {
+		return 0, errInvalidCBOR
+	}
+
+	value := decoder.data[decoder.offset]
+
+	decoder.offset++
+
+	return value, nil
+}
+
+func (decoder *cborDecoder) readBytes(
+	length uint64,
+) ([]byte, error) {
+	if length > decoder.remaining() {
+		return nil, errInvalidCBOR
+	}
+
+	value := decoder.data[decoder.offset : decoder.offset+int(length)]
+
+	decoder.offset += int(length)
+
+	return value, nil
+}
+
+func (decoder *cborDecoder) readArgument(
+	additionalInfo byte,
+) (uint64, error) {
+	switch {
+	case additionalInfo < 24:
+		return uint64(additionalInfo), nil
+	case additionalInfo == 24:
+		value, err := decoder.readByte()
+
+		return uint64(value), err
+	case additionalInfo == 25:
+		value, err := decoder.readBytes(2)
+
+		if err != nil {
+			return 0, err
+		}
+
+		return uint64(binary.BigEndian.Uint16(value)), nil
+	case additionalInfo == 26:
+		value, err := decoder.readBytes(4)
+
+		if err != nil {
+			return 0, err
+		}
+
+		return uint64(binary.BigEndian.Uint32(value)), nil
+	case additionalInfo == 27:
+		value, err := decoder.readBytes(8)
+
+		if err != nil {
+			return 0, err
+		}
+
+		return binary.BigEndian.Uint64(value), nil
+	}
+
+	return 0, errInvalidCBOR
+}
+
+func (decoder *cborDecoder) decode() (any, error) {
+	initialByte, err := decoder.readByte()
+
+	if err != nil {
+		return nil, err
+	}
+
+	majorType := initialByte >> 5
+	additionalInfo := initialByte & 0x1f
+
+	if majorType == 7 {
+		switch additionalInfo {
+		case 20:
+			return false, nil
+		case 21:
+			return true, nil
+		case 22, 23:
+			return nil, nil
+		}
+
+		return nil, errInvalidCBOR
+	}
+
+	argument, err := decoder.readArgument(additionalInfo)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if majorType >= 4 {
+		if decoder.depth >= maxCBORDepth {
+			return nil, errInvalidCBOR
+		}
+
+		decoder.depth++
+
+		defer func() { decoder.depth-- }()
+	}
+
+	switch majorType {
+	case 0:
+		if argument > math.MaxInt64 {
+			return nil, errInvalidCBOR
+		}
+
+		return int64(argument), nil
+	case 1:
+		if argument > math.MaxInt64 {
+			return nil, errInvalidCBOR
+		}
+
+		return -1 - int64(argument), nil
+	case 2:
+		return decoder.readBytes(argument)
+	case 3:
+		value, err := decoder.readBytes(argument)
+
+		return string(value), err
+	case 4:
+		if argument > decoder.remaining() {
+			return nil, errInvalidCBOR
+		}
+
+		items := make([]any, 0, argument)
+
+		for i := uint64(0); i < argument; i++ {
+			item, err := decoder.decode()
+
+			if err != nil {
+				return nil, err
+			}
+
+			items = append(items, item)
+		}
+
+		return items, nil
+	case 5:
+		if argument > decoder.remaining()/2 {
+			return nil, errInvalidCBOR
+		}
+
+		items := make(map[any]any, argument)
+
+		for i := uint64(0); i < argument; i++ {
+			key, err := decoder.decode()
+
+			if err != nil {
+				return nil, err
+			}
+
+			switch key.(type) {
+			case int64, string:
+			default:
+				return nil, errInvalidCBOR
+			}
+
+			value, err := decoder.decode()
+
+			if err != nil {
+				return nil, err
+			}
+
+			items[key] = value
+		}
+
+		return items, nil
+	case 6:
+		return decoder.decode()
+	}
+
+	return nil, errInvalidCBOR
+}
+
+func decodeCBOR(
+	data []byte,
+) (
+	value any,
+	length int,
+	err error,
+) {
+	decoder := &cborDecoder{data: data}
+
+	value, err = decoder.decode()
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return value, decoder.offset, nil
+}
@@ -0,0 +1,120 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+const (
+	AlgorithmES256 = -7
+	AlgorithmEdDSA = -8
+	AlgorithmRS256 = -257
+)
+
+var ErrUnsupportedKey = errors.New("webauthn: unsupported public key")
+
+type publicKey struct {
+	algorithm int64
+	key       crypto.PublicKey
+}
+
+func parsePublicKey(
+	data []byte,
+) (*publicKey, error) {
+	value, length, err := decodeCBOR(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if length != len(data) {
+		return nil, errInvalidCBOR
+	}
+
+	parameters, ok := value.(map[any]any)
+
+	if !ok {
+		return nil, ErrUnsupportedKey
+	}
+
+	keyType, _ := parameters[int64(1)].(int64)
+	algorithm, _ := parameters[int64(3)].(int64)
+
+	switch {
+	case keyType == 2 && algorithm == AlgorithmES256:
+		curve, _ := parameters[int64(-1)].(int64)
+		x, xOk := parameters[int64(-2)].([]byte)
+		y, yOk := parameters[int64(-3)].([]byte)
+
+		if curve != 1 || !xOk || !yOk || len(x) != 32 || len(y) != 32 {
+			return nil, ErrUnsupportedKey
+		}
+
+		key := &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}
+
+		if !key.Curve.IsOnCurve(key.X, key.Y) {
+			return nil, ErrUnsupportedKey
+		}
+
+		return &publicKey{algorithm: algorithm, key: key}, nil
+	case keyType == 1 && algorithm == AlgorithmEdDSA:
+		curve, _ := parameters[int64(-1)].(int64)
+		x, xOk := parameters[int64(-2)].([]byte)
+
+		if curve != 6 || !xOk || len(x) != ed25519.PublicKeySize {
+			return nil, ErrUnsupportedKey
+		}
+
+		return &publicKey{algorithm: algorithm, key: ed25519.PublicKey(x)}, nil
+	case keyType == 3 && algorithm == AlgorithmRS256:
+		n, nOk := parameters[int64(-1)].([]byte)
+		e, eOk := parameters[int64(-2)].([]byte)
+
+		if !nOk || !eOk || len(e) == 0 || len(e) > 4 {
+			return nil, ErrUnsupportedKey
+		}
+
+		key := &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+
+		if key.N.BitLen() < 2048 {
+			return nil, ErrUnsupportedKey
+		}
+
+		return &publicKey{algorithm: algorithm, key: key}, nil
+	}
+
+	return nil, ErrUnsupportedKey
+}
+
+func (key *publicKey) verify(
+	data,
+	signature []byte,
+) bool {
+	switch typedKey := key.key.(type) {
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(data)
+
+		return ecdsa.VerifyASN1(typedKey, digest[:], signature)
+	case ed25519.PublicKey:
+		return ed25519.Verify(typedKey, data, signature)
+	case *rsa.PublicKey:
+		digest := sha256.Sum256(data)
+
+		return rsa.VerifyPKCS1v15(typedKey, crypto.SHA256, digest[:], signature) == nil
+	}
+
+	return false
+}
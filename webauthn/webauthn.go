@@ -0,0 +1,343 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+)
+
+const (
+	flagUserPresent            = 0x01
+	flagUserVerified           = 0x04
+	flagAttestedCredentialData = 0x40
+)
+
+var (
+	ErrInvalidClientData        = errors.New("webauthn: invalid client data")
+	ErrChallengeMismatch        = errors.New("webauthn: challenge mismatch")
+	ErrOriginMismatch           = errors.New("webauthn: origin mismatch")
+	ErrInvalidAuthenticatorData = errors.New("webauthn: invalid authenticator data")
+	ErrRelyingPartyMismatch     = errors.New("webauthn: relying party ID mismatch")
+	ErrUserNotPresent           = errors.New("webauthn: user presence flag not set")
+	ErrUserNotVerified          = errors.New("webauthn: user verification flag not set")
+	ErrInvalidAttestation       = errors.New("webauthn: invalid attestation")
+	ErrInvalidSignature         = errors.New("webauthn: invalid signature")
+	ErrSignCount                = errors.New("webauthn: signature counter did not increase")
+)
+
+type RelyingParty struct {
+	Id                      string
+	Name                    string
+	Origins                 []string
+	RequireUserVerification bool
+}
+
+type Credential struct {
+	Id        []byte
+	PublicKey []byte
+	SignCount uint32
+}
+
+type clientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+type authenticatorData struct {
+	rpIdHash     []byte
+	flags        byte
+	signCount    uint32
+	credentialId []byte
+	publicKey    []byte
+}
+
+func NewChallenge() (string, error) {
+	bytes := make([]byte, 32)
+
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
+func (relyingParty *RelyingParty) verifyClientData(
+	data []byte,
+	expectedType,
+	expectedChallenge string,
+) error {
+	parsedData := &clientData{}
+
+	if err := json.Unmarshal(data, parsedData); err != nil {
+		return ErrInvalidClientData
+	}
+
+	if parsedData.Type != expectedType {
+		return ErrInvalidClientData
+	}
+
+	if subtle.ConstantTimeCompare([]byte(parsedData.Challenge), []byte(expectedChallenge)) != 1 {
+		return ErrChallengeMismatch
+	}
+
+	for _, origin := range relyingParty.Origins {
+		if parsedData.Origin == origin {
+			return nil
+		}
+	}
+
+	return ErrOriginMismatch
+}
+
+func parseAuthenticatorData(
+	data []byte,
+) (*authenticatorData, error) {
+	if len(data) < 37 {
+		return nil, ErrInvalidAuthenticatorData
+	}
+
+	parsedData := &authenticatorData{
+		rpIdHash:  data[:32],
+		flags:     data[32],
+		signCount: binary.BigEndian.Uint32(data[33:37]),
+	}
+
+	if parsedData.flags&flagAttestedCredentialData == 0 {
+		return parsedData, nil
+	}
+
+	if len(data) < 55 {
+		return nil, ErrInvalidAuthenticatorData
+	}
+
+	credentialIdLength := int(binary.BigEndian.Uint16(data[53:55]))
+
+	if len(data) < 55+credentialIdLength {
+		return nil, ErrInvalidAuthenticatorData
+	}
+
+	parsedData.credentialId = data[55 : 55+credentialIdLength]
+
+	_, publicKeyLength, err := decodeCBOR(data[55+credentialIdLength:])
+
+	if err != nil {
+		return nil, ErrInvalidAuthenticatorData
+	}
+
+	parsedData.publicKey = data[55+credentialIdLength : 55+credentialIdLength+publicKeyLength]
+
+	return parsedData, nil
+}
+
+func (relyingParty *RelyingParty) verifyAuthenticatorData(
+	parsedData *authenticatorData,
+) error {
+	rpIdHash := sha256.Sum256([]byte(relyingParty.Id))
+
+	if !bytes.Equal(parsedData.rpIdHash, rpIdHash[:]) {
+		return ErrRelyingPartyMismatch
+	}
+
+	if parsedData.flags&flagUserPresent == 0 {
+		return ErrUserNotPresent
+	}
+
+	if relyingParty.RequireUserVerification && parsedData.flags&flagUserVerified == 0 {
+		return ErrUserNotVerified
+	}
+
+	return nil
+}
+
+func verifyAttestationStatement(
+	format string,
+	statement map[any]any,
+	rawAuthenticatorData,
+	clientDataHash []byte,
+	credentialPublicKey *publicKey,
+) error {
+	switch format {
+	case "none":
+		if len(statement) != 0 {
+			return ErrInvalidAttestation
+		}
+
+		return nil
+	case "packed":
+		algorithm, _ := statement["alg"].(int64)
+		signature, ok := statement["sig"].([]byte)
+
+		if !ok {
+			return ErrInvalidAttestation
+		}
+
+		signedData := append(append([]byte{}, rawAuthenticatorData...), clientDataHash...)
+
+		certificates, hasCertificates := statement["x5c"].([]any)
+
+		if !hasCertificates {
+			if algorithm != credentialPublicKey.algorithm || !credentialPublicKey.verify(signedData, signature) {
+				return ErrInvalidAttestation
+			}
+
+			return nil
+		}
+
+		if len(certificates) == 0 {
+			return ErrInvalidAttestation
+		}
+
+		certificateData, ok := certificates[0].([]byte)
+
+		if !ok {
+			return ErrInvalidAttestation
+		}
+
+		certificate, err := x509.ParseCertificate(certificateData)
+
+		if err != nil {
+			return ErrInvalidAttestation
+		}
+
+		attestationKey := &publicKey{algorithm: algorithm, key: certificate.PublicKey}
+
+		if !attestationKey.verify(signedData, signature) {
+			return ErrInvalidAttestation
+		}
+
+		return nil
+	}
+
+	return ErrInvalidAttestation
+}
+
+func (relyingParty *RelyingParty) VerifyRegistration(
+	challenge string,
+	clientDataJSON,
+	attestationObject []byte,
+) (*Credential, error) {
+	err := relyingParty.verifyClientData(
+		clientDataJSON,
+		"webauthn.create",
+		challenge,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	value, _, err := decodeCBOR(attestationObject)
+
+	if err != nil {
+		return nil, ErrInvalidAttestation
+	}
+
+	attestation, ok := value.(map[any]any)
+
+	if !ok {
+		return nil, ErrInvalidAttestation
+	}
+
+	format, _ := attestation["fmt"].(string)
+	statement, _ := attestation["attStmt"].(map[any]any)
+	rawAuthenticatorData, ok := attestation["authData"].([]byte)
+
+	if !ok {
+		return nil, ErrInvalidAttestation
+	}
+
+	parsedData, err := parseAuthenticatorData(rawAuthenticatorData)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = relyingParty.verifyAuthenticatorData(parsedData); err != nil {
+		return nil, err
+	}
+
+	if parsedData.credentialId == nil {
+		return nil, ErrInvalidAuthenticatorData
+	}
+
+	credentialPublicKey, err := parsePublicKey(parsedData.publicKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+
+	err = verifyAttestationStatement(
+		format,
+		statement,
+		rawAuthenticatorData,
+		clientDataHash[:],
+		credentialPublicKey,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Credential{
+		Id:        append([]byte{}, parsedData.credentialId...),
+		PublicKey: append([]byte{}, parsedData.publicKey...),
+		SignCount: parsedData.signCount,
+	}, nil
+}
+
+func (relyingParty *RelyingParty) VerifyAssertion(
+	challenge string,
+	credential *Credential,
+	clientDataJSON,
+	rawAuthenticatorData,
+	signature []byte,
+) (uint32, error) {
+	err := relyingParty.verifyClientData(
+		clientDataJSON,
+		"webauthn.get",
+		challenge,
+	)
+
+	if err != nil {
+		return 0, err
+	}
+
+	parsedData, err := parseAuthenticatorData(rawAuthenticatorData)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if err = relyingParty.verifyAuthenticatorData(parsedData); err != nil {
+		return 0, err
+	}
+
+	credentialPublicKey, err := parsePublicKey(credential.PublicKey)
+
+	if err != nil {
+		return 0, err
+	}
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+
+	signedData := append(append([]byte{}, rawAuthenticatorData...), clientDataHash[:]...)
+
+	if !credentialPublicKey.verify(signedData, signature) {
+		return 0, ErrInvalidSignature
+	}
+
+	if (parsedData.signCount != 0 || credential.SignCount != 0) && parsedData.signCount <= credential.SignCount {
+		return 0, ErrSignCount
+	}
+
+	return parsedData.signCount, nil
+}
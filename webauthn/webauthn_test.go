@@ -0,0 +1,394 @@
+package webauthn
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"sort"
+	"testing"
+)
+
+func encodeCBORHead(major byte, value uint64) []byte {
+	switch {
+	case value < 24:
+		return []byte{major<<5 | byte(value)}
+	case value <= 0xff:
+		return []byte{major<<5 | 24, byte(value)}
+	case value <= 0xffff:
+		return binary.BigEndian.AppendUint16([]byte{major<<5 | 25}, uint16(value))
+	default:
+		return binary.BigEndian.AppendUint32([]byte{major<<5 | 26}, uint32(value))
+	}
+}
+
+func encodeCBOR(value any) []byte {
+	switch value := value.(type) {
+	case int:
+		if value < 0 {
+			return encodeCBORHead(1, uint64(-1-value))
+		}
+
+		return encodeCBORHead(0, uint64(value))
+	case []byte:
+		return append(encodeCBORHead(2, uint64(len(value))), value...)
+	case string:
+		return append(encodeCBORHead(3, uint64(len(value))), value...)
+	case map[any]any:
+		encodedPairs := [][]byte{}
+
+		for key, item := range value {
+			encodedPairs = append(encodedPairs, append(encodeCBOR(key), encodeCBOR(item)...))
+		}
+
+		sort.Slice(encodedPairs, func(i, j int) bool {
+			return string(encodedPairs[i]) < string(encodedPairs[j])
+		})
+
+		encoded := encodeCBORHead(5, uint64(len(value)))
+
+		for _, pair := range encodedPairs {
+			encoded = append(encoded, pair...)
+		}
+
+		return encoded
+	}
+
+	panic("unsupported CBOR value")
+}
+
+type testAuthenticator struct {
+	algorithm    int
+	credentialId []byte
+	publicKey    []byte
+	sign         func(data []byte) []byte
+}
+
+func newECDSAAuthenticator(t *testing.T) *testAuthenticator {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &testAuthenticator{
+		algorithm:    AlgorithmES256,
+		credentialId: []byte("ecdsa-credential"),
+		publicKey: encodeCBOR(map[any]any{
+			1:  2,
+			3:  AlgorithmES256,
+			-1: 1,
+			-2: privateKey.X.FillBytes(make([]byte, 32)),
+			-3: privateKey.Y.FillBytes(make([]byte, 32)),
+		}),
+		sign: func(data []byte) []byte {
+			digest := sha256.Sum256(data)
+
+			signature, err := ecdsa.SignASN1(rand.Reader, privateKey, digest[:])
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			return signature
+		},
+	}
+}
+
+func newEd25519Authenticator(t *testing.T) *testAuthenticator {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &testAuthenticator{
+		algorithm:    AlgorithmEdDSA,
+		credentialId: []byte("ed25519-credential"),
+		publicKey: encodeCBOR(map[any]any{
+			1:  1,
+			3:  AlgorithmEdDSA,
+			-1: 6,
+			-2: []byte(publicKey),
+		}),
+		sign: func(data []byte) []byte {
+			return ed25519.Sign(privateKey, data)
+		},
+	}
+}
+
+func (authenticator *testAuthenticator) authenticatorData(
+	rpId string,
+	flags byte,
+	signCount uint32,
+	attested bool,
+) []byte {
+	rpIdHash := sha256.Sum256([]byte(rpId))
+
+	data := append([]byte{}, rpIdHash[:]...)
+
+	if attested {
+		flags |= flagAttestedCredentialData
+	}
+
+	data = append(data, flags)
+	data = binary.BigEndian.AppendUint32(data, signCount)
+
+	if attested {
+		data = append(data, make([]byte, 16)...)
+		data = binary.BigEndian.AppendUint16(data, uint16(len(authenticator.credentialId)))
+		data = append(data, authenticator.credentialId...)
+		data = append(data, authenticator.publicKey...)
+	}
+
+	return data
+}
+
+func clientDataJSON(t *testing.T, clientDataType, challenge, origin string) []byte {
+	data, err := json.Marshal(map[string]any{
+		"type":        clientDataType,
+		"challenge":   challenge,
+		"origin":      origin,
+		"crossOrigin": false,
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
+
+func testRelyingParty() *RelyingParty {
+	return &RelyingParty{
+		Id:      "example.com",
+		Name:    "Example",
+		Origins: []string{"https://example.com"},
+	}
+}
+
+func register(
+	t *testing.T,
+	relyingParty *RelyingParty,
+	authenticator *testAuthenticator,
+	format string,
+) (*Credential, error) {
+	challenge, err := NewChallenge()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clientData := clientDataJSON(t, "webauthn.create", challenge, "https://example.com")
+	authenticatorData := authenticator.authenticatorData("example.com", flagUserPresent, 0, true)
+
+	statement := map[any]any{}
+
+	if format == "packed" {
+		clientDataHash := sha256.Sum256(clientData)
+
+		statement["alg"] = authenticator.algorithm
+		statement["sig"] = authenticator.sign(append(append([]byte{}, authenticatorData...), clientDataHash[:]...))
+	}
+
+	attestationObject := encodeCBOR(map[any]any{
+		"fmt":      format,
+		"attStmt":  statement,
+		"authData": authenticatorData,
+	})
+
+	return relyingParty.VerifyRegistration(challenge, clientData, attestationObject)
+}
+
+func TestRegistrationAndAssertion(t *testing.T) {
+	for name, newAuthenticator := range map[string]func(*testing.T) *testAuthenticator{
+		"ES256": newECDSAAuthenticator,
+		"EdDSA": newEd25519Authenticator,
+	} {
+		t.Run(name, func(t *testing.T) {
+			relyingParty := testRelyingParty()
+			authenticator := newAuthenticator(t)
+
+			for _, format := range []string{"none", "packed"} {
+				credential, err := register(t, relyingParty, authenticator, format)
+
+				if err != nil {
+					t.Fatalf("%s registration: %v", format, err)
+				}
+
+				if string(credential.Id) != string(authenticator.credentialId) {
+					t.Fatalf("credential ID = %q", credential.Id)
+				}
+			}
+
+			credential, _ := register(t, relyingParty, authenticator, "none")
+
+			challenge, _ := NewChallenge()
+			clientData := clientDataJSON(t, "webauthn.get", challenge, "https://example.com")
+			authenticatorData := authenticator.authenticatorData("example.com", flagUserPresent, 5, false)
+			clientDataHash := sha256.Sum256(clientData)
+			signature := authenticator.sign(append(append([]byte{}, authenticatorData...), clientDataHash[:]...))
+
+			signCount, err := relyingParty.VerifyAssertion(challenge, credential, clientData, authenticatorData, signature)
+
+			if err != nil {
+				t.Fatalf("assertion: %v", err)
+			}
+
+			if signCount != 5 {
+				t.Fatalf("sign count = %d, want 5", signCount)
+			}
+
+			credential.SignCount = signCount
+
+			if _, err = relyingParty.VerifyAssertion(challenge, credential, clientData, authenticatorData, signature); !errors.Is(err, ErrSignCount) {
+				t.Fatalf("replayed assertion error = %v, want %v", err, ErrSignCount)
+			}
+
+			signature[len(signature)-1] ^= 0xff
+			credential.SignCount = 0
+
+			if _, err = relyingParty.VerifyAssertion(challenge, credential, clientData, authenticatorData, signature); !errors.Is(err, ErrInvalidSignature) {
+				t.Fatalf("tampered assertion error = %v, want %v", err, ErrInvalidSignature)
+			}
+		})
+	}
+}
+
+func TestAssertionRejectsMismatches(t *testing.T) {
+	relyingParty := testRelyingParty()
+	authenticator := newECDSAAuthenticator(t)
+
+	credential, err := register(t, relyingParty, authenticator, "none")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	challenge, _ := NewChallenge()
+
+	tests := []struct {
+		name              string
+		clientData        []byte
+		authenticatorData []byte
+		expectedErr       error
+	}{
+		{
+			name:              "challenge",
+			clientData:        clientDataJSON(t, "webauthn.get", "other", "https://example.com"),
+			authenticatorData: authenticator.authenticatorData("example.com", flagUserPresent, 1, false),
+			expectedErr:       ErrChallengeMismatch,
+		},
+		{
+			name:              "origin",
+			clientData:        clientDataJSON(t, "webauthn.get", challenge, "https://evil.com"),
+			authenticatorData: authenticator.authenticatorData("example.com", flagUserPresent, 1, false),
+			expectedErr:       ErrOriginMismatch,
+		},
+		{
+			name:              "type",
+			clientData:        clientDataJSON(t, "webauthn.create", challenge, "https://example.com"),
+			authenticatorData: authenticator.authenticatorData("example.com", flagUserPresent, 1, false),
+			expectedErr:       ErrInvalidClientData,
+		},
+		{
+			name:              "relying party",
+			clientData:        clientDataJSON(t, "webauthn.get", challenge, "https://example.com"),
+			authenticatorData: authenticator.authenticatorData("evil.com", flagUserPresent, 1, false),
+			expectedErr:       ErrRelyingPartyMismatch,
+		},
+		{
+			name:              "user presence",
+			clientData:        clientDataJSON(t, "webauthn.get", challenge, "https://example.com"),
+			authenticatorData: authenticator.authenticatorData("example.com", 0, 1, false),
+			expectedErr:       ErrUserNotPresent,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clientDataHash := sha256.Sum256(test.clientData)
+			signature := authenticator.sign(append(append([]byte{}, test.authenticatorData...), clientDataHash[:]...))
+
+			_, err := relyingParty.VerifyAssertion(challenge, credential, test.clientData, test.authenticatorData, signature)
+
+			if !errors.Is(err, test.expectedErr) {
+				t.Fatalf("error = %v, want %v", err, test.expectedErr)
+			}
+		})
+	}
+}
+
+func TestRegistrationRejectsBadAttestation(t *testing.T) {
+	relyingParty := testRelyingParty()
+	authenticator := newECDSAAuthenticator(t)
+
+	if _, err := register(t, relyingParty, authenticator, "fido-u2f"); !errors.Is(err, ErrInvalidAttestation) {
+		t.Fatalf("error = %v, want %v", err, ErrInvalidAttestation)
+	}
+
+	authenticator.publicKey = encodeCBOR(map[any]any{1: 2, 3: -36})
+
+	if _, err := register(t, relyingParty, authenticator, "none"); !errors.Is(err, ErrUnsupportedKey) {
+		t.Fatalf("error = %v, want %v", err, ErrUnsupportedKey)
+	}
+}
+
+func TestDecodeCBORLimits(t *testing.T) {
+	nested := func(head byte, depth int, leaf []byte) []byte {
+		data := make([]byte, depth, depth+len(leaf))
+
+		for i := range data {
+			data[i] = head
+		}
+
+		return append(data, leaf...)
+	}
+
+	tests := []struct {
+		name  string
+		data  []byte
+		valid bool
+	}{
+		{"arrays at the depth limit", nested(0x81, maxCBORDepth, []byte{0x01}), true},
+		{"arrays past the depth limit", nested(0x81, maxCBORDepth+1, []byte{0x01}), false},
+		{"deeply nested arrays", nested(0x81, 8<<20, nil), false},
+		{"deeply nested maps", nested(0xa1, 1<<20, nil), false},
+		{"deeply nested tags", nested(0xc0, 1<<20, nil), false},
+		{"array count past the input", []byte{0x9a, 0xff, 0xff, 0xff, 0xff, 0x01}, false},
+		{"map count past the input", []byte{0xa2, 0x01, 0x02}, false},
+		{"sibling arrays", encodeCBOR(map[any]any{1: []byte{1}, 2: "two"}), true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, err := decodeCBOR(test.data)
+
+			if test.valid && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !test.valid && !errors.Is(err, errInvalidCBOR) {
+				t.Fatalf("error = %v, want %v", err, errInvalidCBOR)
+			}
+		})
+	}
+
+	challenge, err := NewChallenge()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clientData := clientDataJSON(t, "webauthn.create", challenge, "https://example.com")
+
+	_, err = testRelyingParty().VerifyRegistration(challenge, clientData, nested(0x81, 8<<20, nil))
+
+	if !errors.Is(err, ErrInvalidAttestation) {
+		t.Fatalf("error = %v, want %v", err, ErrInvalidAttestation)
+	}
+}
@@ -0,0 +1,363 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sandromai/go-http-server/types"
+	"github.com/sandromai/go-http-server/webauthn"
+)
+
+func encodeCBORHead(
+	major byte,
+	value uint64,
+) []byte {
+	switch {
+	case value < 24:
+		return []byte{major<<5 | byte(value)}
+	case value <= 0xff:
+		return []byte{major<<5 | 24, byte(value)}
+	default:
+		return binary.BigEndian.AppendUint16([]byte{major<<5 | 25}, uint16(value))
+	}
+}
+
+func encodeCBOR(
+	value any,
+) []byte {
+	switch value := value.(type) {
+	case int:
+		if value < 0 {
+			return encodeCBORHead(1, uint64(-1-value))
+		}
+
+		return encodeCBORHead(0, uint64(value))
+	case []byte:
+		return append(encodeCBORHead(2, uint64(len(value))), value...)
+	case string:
+		return append(encodeCBORHead(3, uint64(len(value))), value...)
+	case map[any]any:
+		encoded := encodeCBORHead(5, uint64(len(value)))
+
+		for key, item := range value {
+			encoded = append(encoded, encodeCBOR(key)...)
+			encoded = append(encoded, encodeCBOR(item)...)
+		}
+
+		return encoded
+	}
+
+	panic("unsupported CBOR value")
+}
+
+type testPasskey struct {
+	t            *testing.T
+	credentialId []byte
+	privateKey   *ecdsa.PrivateKey
+	signCount    uint32
+}
+
+func newTestPasskey(
+	t *testing.T,
+	credentialId string,
+) *testPasskey {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &testPasskey{
+		t:            t,
+		credentialId: []byte(credentialId),
+		privateKey:   privateKey,
+	}
+}
+
+func (passkey *testPasskey) id() string {
+	return base64.RawURLEncoding.EncodeToString(passkey.credentialId)
+}
+
+func (passkey *testPasskey) clientData(
+	clientDataType,
+	challenge,
+	origin string,
+) []byte {
+	data, err := json.Marshal(map[string]any{
+		"type":      clientDataType,
+		"challenge": challenge,
+		"origin":    origin,
+	})
+
+	if err != nil {
+		passkey.t.Fatal(err)
+	}
+
+	return data
+}
+
+func (passkey *testPasskey) authenticatorData(
+	attested bool,
+) []byte {
+	rpIdHash := sha256.Sum256([]byte("localhost"))
+
+	flags := byte(0x01)
+
+	if attested {
+		flags |= 0x40
+	}
+
+	data := append(append([]byte{}, rpIdHash[:]...), flags)
+
+	data = binary.BigEndian.AppendUint32(data, passkey.signCount)
+
+	if !attested {
+		return data
+	}
+
+	data = append(data, make([]byte, 16)...)
+	data = binary.BigEndian.AppendUint16(data, uint16(len(passkey.credentialId)))
+	data = append(data, passkey.credentialId...)
+
+	return append(data, encodeCBOR(map[any]any{
+		1:  2,
+		3:  webauthn.AlgorithmES256,
+		-1: 1,
+		-2: passkey.privateKey.X.FillBytes(make([]byte, 32)),
+		-3: passkey.privateKey.Y.FillBytes(make([]byte, 32)),
+	})...)
+}
+
+func (passkey *testPasskey) registration(
+	challengeId,
+	challenge,
+	origin string,
+) map[string]any {
+	return map[string]any{
+		"challengeId": challengeId,
+		"name":        "Laptop",
+		"credential": map[string]any{
+			"id": passkey.id(),
+			"response": map[string]any{
+				"clientDataJSON": base64.RawURLEncoding.EncodeToString(passkey.clientData("webauthn.create", challenge, origin)),
+				"attestationObject": base64.RawURLEncoding.EncodeToString(encodeCBOR(map[any]any{
+					"fmt":      "none",
+					"attStmt":  map[any]any{},
+					"authData": passkey.authenticatorData(true),
+				})),
+			},
+		},
+	}
+}
+
+func (passkey *testPasskey) assertion(
+	challengeId,
+	challenge string,
+) map[string]any {
+	clientData := passkey.clientData("webauthn.get", challenge, "http://localhost:3000")
+	authenticatorData := passkey.authenticatorData(false)
+
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte{}, authenticatorData...), clientDataHash[:]...))
+
+	signature, err := ecdsa.SignASN1(rand.Reader, passkey.privateKey, digest[:])
+
+	if err != nil {
+		passkey.t.Fatal(err)
+	}
+
+	return map[string]any{
+		"challengeId": challengeId,
+		"credential": map[string]any{
+			"id": passkey.id(),
+			"response": map[string]any{
+				"clientDataJSON":    base64.RawURLEncoding.EncodeToString(clientData),
+				"authenticatorData": base64.RawURLEncoding.EncodeToString(authenticatorData),
+				"signature":         base64.RawURLEncoding.EncodeToString(signature),
+			},
+		},
+	}
+}
+
+type webAuthnOptions struct {
+	ChallengeId string `json:"challengeId"`
+	PublicKey   struct {
+		Challenge          string `json:"challenge"`
+		ExcludeCredentials []struct {
+			Id string `json:"id"`
+		} `json:"excludeCredentials"`
+	} `json:"publicKey"`
+}
+
+func (server *testServer) webAuthnOptions(
+	path string,
+	header http.Header,
+) *webAuthnOptions {
+	server.t.Helper()
+
+	response := server.request("POST", path, nil, header)
+
+	response.expect(server.t, 200, "")
+
+	options := &webAuthnOptions{}
+
+	response.decode(server.t, options)
+
+	if options.ChallengeId == "" || options.PublicKey.Challenge == "" {
+		server.t.Fatalf("unexpected options %s", response.Body)
+	}
+
+	return options
+}
+
+func (server *testServer) registerPasskey(
+	userToken string,
+	passkey *testPasskey,
+) *types.UserCredential {
+	server.t.Helper()
+
+	options := server.webAuthnOptions("/routes/webAuthn/registration/options", bearer(userToken))
+
+	response := server.request("POST", "/routes/webAuthn/registration", passkey.registration(options.ChallengeId, options.PublicKey.Challenge, "http://localhost:3000"), bearer(userToken))
+
+	response.expect(server.t, 201, "")
+
+	userCredential := &types.UserCredential{}
+
+	response.decode(server.t, userCredential)
+
+	return userCredential
+}
+
+func (server *testServer) authenticatePasskey(
+	passkey *testPasskey,
+) *testResponse {
+	server.t.Helper()
+
+	passkey.signCount++
+
+	options := server.webAuthnOptions("/routes/webAuthn/authentication/options", nil)
+
+	return server.request("POST", "/routes/webAuthn/authentication", passkey.assertion(options.ChallengeId, options.PublicKey.Challenge), nil)
+}
+
+func TestWebAuthnPasskeyLogin(t *testing.T) {
+	forEachBackend(t, testWebAuthnPasskeyLogin)
+}
+
+func testWebAuthnPasskeyLogin(
+	t *testing.T,
+	server *testServer,
+) {
+	user := server.loginUser("user@example.com")
+
+	server.request("POST", "/routes/webAuthn/registration/options", nil, nil).expect(t, 401, "No authorization provided.")
+
+	passkey := newTestPasskey(t, "passkey")
+
+	options := server.webAuthnOptions("/routes/webAuthn/registration/options", bearer(user.Token))
+
+	server.request("POST", "/routes/webAuthn/registration", passkey.registration(options.ChallengeId, options.PublicKey.Challenge, "https://evil.example.com"), bearer(user.Token)).expect(t, 400, "Invalid credential.")
+
+	server.request("POST", "/routes/webAuthn/registration", passkey.registration(options.ChallengeId, options.PublicKey.Challenge, "http://localhost:3000"), bearer(user.Token)).expect(t, 400, "Invalid or expired challenge.")
+
+	options = server.webAuthnOptions("/routes/webAuthn/registration/options", bearer(user.Token))
+
+	server.request("POST", "/routes/webAuthn/registration", passkey.registration(options.ChallengeId, options.PublicKey.Challenge, "http://localhost:3000"), bearer(server.loginUser("other@example.com").Token)).expect(t, 400, "Invalid or expired challenge.")
+
+	options = server.webAuthnOptions("/routes/webAuthn/registration/options", bearer(user.Token))
+
+	server.clock.Advance(server.config.Lifetimes.WebAuthnChallenge + time.Second)
+
+	server.request("POST", "/routes/webAuthn/registration", passkey.registration(options.ChallengeId, options.PublicKey.Challenge, "http://localhost:3000"), bearer(user.Token)).expect(t, 400, "Invalid or expired challenge.")
+
+	userCredential := server.registerPasskey(user.Token, passkey)
+
+	if userCredential.CredentialId != passkey.id() || userCredential.UserId != user.User.Id || userCredential.Name != "Laptop" {
+		t.Fatalf("unexpected credential %+v", userCredential)
+	}
+
+	options = server.webAuthnOptions("/routes/webAuthn/registration/options", bearer(user.Token))
+
+	if len(options.PublicKey.ExcludeCredentials) != 1 || options.PublicKey.ExcludeCredentials[0].Id != passkey.id() {
+		t.Fatalf("expected the registered passkey to be excluded, got %+v", options.PublicKey.ExcludeCredentials)
+	}
+
+	response := server.authenticatePasskey(passkey)
+
+	response.expect(t, 200, "")
+
+	var session authenticatedUser
+
+	response.decode(t, &session)
+
+	if session.User == nil || session.User.Id != user.User.Id {
+		t.Fatalf("expected a session for the passkey owner, got %s", response.Body)
+	}
+
+	server.request("GET", "/routes/users/authenticate", nil, bearer(session.Token)).expect(t, 200, "")
+
+	passkey.signCount--
+
+	server.authenticatePasskey(passkey).expect(t, 401, "Invalid credential.")
+
+	server.authenticatePasskey(newTestPasskey(t, "passkey")).expect(t, 401, "Invalid credential.")
+
+	server.authenticatePasskey(newTestPasskey(t, "unknown")).expect(t, 401, "Invalid credential.")
+
+	passkey.signCount++
+
+	options = server.webAuthnOptions("/routes/webAuthn/authentication/options", nil)
+
+	assertion := passkey.assertion(options.ChallengeId, options.PublicKey.Challenge)
+
+	server.request("POST", "/routes/webAuthn/authentication", assertion, nil).expect(t, 200, "")
+
+	server.request("POST", "/routes/webAuthn/authentication", assertion, nil).expect(t, 400, "Invalid or expired challenge.")
+
+	adminToken := server.loginAdmin(testAdminUsername, testAdminPassword)
+
+	server.request("PATCH", "/routes/users/"+user.User.Id+"/ban", nil, bearer(adminToken)).expect(t, 200, "")
+
+	server.authenticatePasskey(passkey).expect(t, 403, "User banned.")
+}
+
+func TestWebAuthnRejectsOversizedBodies(t *testing.T) {
+	server := newTestServer(t)
+
+	server.request("POST", "/routes/webAuthn/authentication", map[string]any{
+		"credential": map[string]any{
+			"id": strings.Repeat("a", 2<<20),
+		},
+	}, nil).expect(t, 413, "Request body too large.")
+
+	body := io.MultiReader(strings.NewReader(`{"credential":{"id":"`), strings.NewReader(strings.Repeat("a", 2<<20)), strings.NewReader(`"}}`))
+
+	request, err := http.NewRequest("POST", server.server.URL+"/routes/webAuthn/authentication", body)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := server.server.Client().Do(request)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response.Body.Close()
+
+	if response.StatusCode != 400 {
+		t.Fatalf("expected a streamed oversized body to be cut off with 400, got %v", response.StatusCode)
+	}
+}